@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain/usecase"
+)
+
+// decodeReport is printed by the decode command.
+type decodeReport struct {
+	SignatureAlgorithm string                 `json:"signature_alg,omitempty"`
+	SignatureKeyID     string                 `json:"signature_kid,omitempty"`
+	Verified           bool                   `json:"verified"`
+	VerificationKeyID  string                 `json:"verification_kid,omitempty"`
+	EncryptionHeader   map[string]interface{} `json:"encryption_header,omitempty"`
+	Payload            interface{}            `json:"payload,omitempty"`
+	Error              string                 `json:"error,omitempty"`
+}
+
+// runDecode verifies and decrypts a captured encrypted body offline, with the
+// keys and limits of the environment, like the service does, and prints what
+// each step found. The flags override the key locations.
+func runDecode(args []string, stdin io.Reader, stdout io.Writer, log *logrus.Logger) error {
+	flags := flag.NewFlagSet("decode", flag.ContinueOnError)
+	privateKeys := flags.String("keys", "", "the private key files, separated by ';' (default PRIVATE_KEY_PATH)")
+	publicKeys := flags.String("public-keys", "", "the public key location, file:// or url:// (default PUBLIC_KEY_PATH)")
+	input := flags.String("input", "-", "the file with the request body, or the encrypted body, '-' for stdin")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := configuration.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load app configuration: %v", err)
+	}
+	if *privateKeys != "" {
+		cfg.KeysConfig.PrivateKeyPath = *privateKeys
+	}
+	if *publicKeys != "" {
+		cfg.KeysConfig.PublicKeyLocation = *publicKeys
+	}
+
+	keyConfig, err := keys.LoadKeys(cfg.KeysConfig)
+	if err != nil {
+		return fmt.Errorf("unable to load keys: %v", err)
+	}
+
+	data, err := readDecodeInput(*input, stdin)
+	if err != nil {
+		return err
+	}
+
+	uc := usecase.NewNotificationUsecase(*cfg, log, keys.NewStore(keyConfig, nil), nil, nil)
+	inspection, inspectErr := uc.Inspect(encryptedBody(data))
+
+	report := decodeReport{
+		SignatureAlgorithm: inspection.SignatureAlgorithm,
+		SignatureKeyID:     inspection.SignatureKeyID,
+		Verified:           inspection.Verified,
+		VerificationKeyID:  inspection.VerificationKeyID,
+		EncryptionHeader:   inspection.EncryptionHeader,
+	}
+	if inspection.Payload != "" {
+		// A JSON payload is printed as is, anything else as a string.
+		report.Payload = inspection.Payload
+		if json.Valid([]byte(inspection.Payload)) {
+			report.Payload = json.RawMessage(inspection.Payload)
+		}
+	}
+	if inspectErr != nil {
+		report.Error = inspectErr.Error()
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	return inspectErr
+}
+
+func readDecodeInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading stdin: %v", err)
+		}
+		return data, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading input %s: %v", path, err)
+	}
+
+	return data, nil
+}
+
+// encryptedBody accepts the request body, with the encrypted_body field, or
+// the encrypted body itself.
+func encryptedBody(data []byte) string {
+	data = bytes.TrimSpace(data)
+
+	var body struct {
+		EncryptedBody string `json:"encrypted_body"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.EncryptedBody != "" {
+		return strings.TrimSpace(body.EncryptedBody)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func Test_runDecode(t *testing.T) {
+	keyArgs := []string{"-keys", "../tests/partner/fakekey.pem", "-public-keys", "file://../tests/stone/fakekey1.pub.jwt"}
+
+	cfg, err := configuration.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	cfg.KeysConfig.PrivateKeyPath = "../tests/partner/fakekey.pem"
+	cfg.KeysConfig.PublicKeyLocation = "file://../tests/stone/fakekey1.pub.jwt"
+
+	keyConfig, err := keys.LoadKeys(cfg.KeysConfig)
+	if err != nil {
+		t.Fatalf("LoadKeys() error = %v", err)
+	}
+
+	sample := func(path string) string {
+		signingKey, err := keys.LoadSampleSigningKey(path)
+		if err != nil {
+			t.Fatalf("LoadSampleSigningKey() error = %v", err)
+		}
+		body, err := keys.NewSampleBody(keyConfig, signingKey)
+		if err != nil {
+			t.Fatalf("NewSampleBody() error = %v", err)
+		}
+		return body
+	}
+
+	tests := []struct {
+		name         string
+		input        string
+		wantVerified bool
+		wantPayload  string
+		wantErr      bool
+	}{
+		{
+			name:         "Request body",
+			input:        `{"encrypted_body":"` + sample("../tests/stone/fakekey1.pem.jwt") + `"}`,
+			wantVerified: true,
+			wantPayload:  keys.SamplePayload,
+		},
+		{
+			name:         "Encrypted body",
+			input:        sample("../tests/stone/fakekey1.pem.jwt") + "\n",
+			wantVerified: true,
+			wantPayload:  keys.SamplePayload,
+		},
+		{
+			name:    "Signed by an unknown key",
+			input:   sample("../tests/stone/fakekey3.pem.jwt"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := runDecode(keyArgs, strings.NewReader(tt.input), &stdout, logrus.New())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runDecode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var report struct {
+				Verified bool            `json:"verified"`
+				Payload  json.RawMessage `json:"payload"`
+				Error    string          `json:"error"`
+			}
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("invalid report %q: %v", stdout.String(), err)
+			}
+			if report.Verified != tt.wantVerified {
+				t.Errorf("verified = %t, want %t", report.Verified, tt.wantVerified)
+			}
+			// The payload is indented with the report.
+			var payload bytes.Buffer
+			if len(report.Payload) > 0 {
+				if err := json.Compact(&payload, report.Payload); err != nil {
+					t.Fatalf("invalid payload %s: %v", report.Payload, err)
+				}
+			}
+			if payload.String() != tt.wantPayload {
+				t.Errorf("payload = %s, want %s", payload.String(), tt.wantPayload)
+			}
+			if tt.wantErr && report.Error == "" {
+				t.Error("the report has no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/archivers/s3"
+)
+
+var archiverTypes = map[string]domain.RawArchiver{
+	"s3": s3.New(),
+}
+
+// defineArchiver returns nil when no archiver is configured.
+func defineArchiver(archiver string, log *logrus.Logger) (domain.RawArchiver, error) {
+	archiver = strings.ToLower(strings.TrimSpace(archiver))
+	if archiver == "" {
+		return nil, nil
+	}
+
+	impl, ok := archiverTypes[archiver]
+	if !ok {
+		return nil, fmt.Errorf("undefined archiver: %v", archiver)
+	}
+
+	if err := impl.Configure(log); err != nil {
+		return nil, fmt.Errorf("configure failed in [%s] archiver: %v", archiver, err)
+	}
+
+	return impl, nil
+}
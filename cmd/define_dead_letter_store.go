@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/deadletters/file"
+)
+
+var deadLetterStoreTypes = map[string]domain.DeadLetterStore{
+	"file": file.New(),
+}
+
+// defineDeadLetterStore returns nil when no dead letter store is configured.
+func defineDeadLetterStore(store string, log *logrus.Logger) (domain.DeadLetterStore, error) {
+	store = strings.ToLower(strings.TrimSpace(store))
+	if store == "" {
+		return nil, nil
+	}
+
+	impl, ok := deadLetterStoreTypes[store]
+	if !ok {
+		return nil, fmt.Errorf("undefined dead letter store: %v", store)
+	}
+
+	if err := impl.Configure(log); err != nil {
+		return nil, fmt.Errorf("configure failed in [%s] dead letter store: %v", store, err)
+	}
+
+	return impl, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/idempotency/memory"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/idempotency/redis"
+)
+
+var idempotencyStoreTypes = map[string]domain.IdempotencyStore{
+	"memory": memory.New(),
+	"redis":  redis.New(),
+}
+
+// defineIdempotencyStore returns nil when no idempotency store is configured.
+func defineIdempotencyStore(store string, log *logrus.Logger) (domain.IdempotencyStore, error) {
+	store = strings.ToLower(strings.TrimSpace(store))
+	if store == "" {
+		return nil, nil
+	}
+
+	impl, ok := idempotencyStoreTypes[store]
+	if !ok {
+		return nil, fmt.Errorf("undefined idempotency store: %v", store)
+	}
+
+	if err := impl.Configure(log); err != nil {
+		return nil, fmt.Errorf("configure failed in [%s] idempotency store: %v", store, err)
+	}
+
+	return impl, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notificationstores/memory"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notificationstores/redis"
+)
+
+var notificationStoreTypes = map[string]domain.NotificationStore{
+	"memory": memory.New(),
+	"redis":  redis.New(),
+}
+
+// defineNotificationStore returns nil when no notification store is
+// configured.
+func defineNotificationStore(store string, log *logrus.Logger) (domain.NotificationStore, error) {
+	store = strings.ToLower(strings.TrimSpace(store))
+	if store == "" {
+		return nil, nil
+	}
+
+	impl, ok := notificationStoreTypes[store]
+	if !ok {
+		return nil, fmt.Errorf("undefined notification store: %v", store)
+	}
+
+	if err := impl.Configure(log); err != nil {
+		return nil, fmt.Errorf("configure failed in [%s] notification store: %v", store, err)
+	}
+
+	return impl, nil
+}
@@ -5,36 +5,198 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/async"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/batch"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/debugdir"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/failover"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/kafka"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/proxy"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/redis"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/sns"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/sqs"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/stdout"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/tee"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/throttle"
 )
 
-// Stdout notifier is used only to debug purpose.
+// Stdout and debugdir notifiers are used only to debug purpose.
 var notificationTypes = map[string]domain.Notifier{
-	"stdout": stdout.New(),
-	"proxy":  proxy.New(),
-	"redis":  redis.New(),
+	"stdout":   stdout.New(),
+	"debugdir": debugdir.New(),
+	"proxy":    proxy.New(),
+	"redis":    redis.New(),
+	"kafka":    kafka.New(),
+	"sqs":      sqs.New(),
+	"sns":      sns.New(),
 }
 
-func defineNotifiers(notifierList string, log *logrus.Logger) ([]domain.Notifier, error) {
+// defineNotifiers returns the notifiers in order, and the notifier sending to
+// each name, which is the tee or the failover for their members.
+func defineNotifiers(notifierList string, throttleConfig configuration.ThrottleConfig, batchConfig configuration.BatchConfig, teeConfig configuration.TeeConfig, failoverConfig configuration.FailoverConfig, asyncConfig configuration.AsyncConfig, serializer domain.MessageSerializer, log *logrus.Logger) ([]domain.Notifier, map[string]domain.Notifier, error) {
 	notifiersToConfig, err := extractNotifiersFromConfig(notifierList)
 	if err != nil {
-		return nil, fmt.Errorf("configure failed when loading notifiers: %v", err)
+		return nil, nil, fmt.Errorf("configure failed when loading notifiers: %v", err)
 	}
 
+	throttled, err := extractThrottledNotifiers(throttleConfig, notifiersToConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure failed when loading throttled notifiers: %v", err)
+	}
+
+	batched, err := extractBatchedNotifiers(batchConfig, notifiersToConfig, throttled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure failed when loading batched notifiers: %v", err)
+	}
+
+	teed, err := extractTeedNotifiers(teeConfig, notifiersToConfig, throttled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure failed when loading teed notifiers: %v", err)
+	}
+
+	failedOver, err := extractFailoverNotifiers(failoverConfig, notifiersToConfig, throttled, teed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure failed when loading failover notifiers: %v", err)
+	}
+
+	queued, err := extractAsyncNotifiers(asyncConfig, notifiersToConfig, throttled, batched, teed, failedOver)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configure failed when loading async notifiers: %v", err)
+	}
+
+	// The teed and failover notifiers are replaced by the tee and the
+	// failover, in the primary position.
+	teedImpls := map[string]domain.Notifier{}
+	teeIndex := -1
+	failoverImpls := map[string]domain.Notifier{}
+	failoverIndex := -1
+
 	result := []domain.Notifier{}
+	byName := map[string]domain.Notifier{}
 	for _, notifier := range notifiersToConfig {
 		impl := notificationTypes[notifier]
+		if serialized, ok := impl.(domain.SerializedNotifier); ok {
+			serialized.SetSerializer(serializer)
+		}
+		if throttled[notifier] {
+			impl = throttle.New(notifier, impl, throttleConfig.Rate, throttleConfig.QueueSize)
+		}
+		if batched[notifier] {
+			impl = batch.New(notifier, impl.(domain.BatchNotifier), batchConfig.Size, batchConfig.FlushInterval)
+		}
+		if queued[notifier] {
+			impl = async.New(notifier, impl, asyncConfig.Workers, asyncConfig.QueueSize, asyncConfig.EnqueueTimeout, asyncConfig.SendTimeout)
+		}
+
+		if containsNotifier(teed, notifier) {
+			teedImpls[notifier] = impl
+			if notifier == teed[0] {
+				teeIndex = len(result)
+				result = append(result, nil)
+			}
+			continue
+		}
+
+		if containsNotifier(failedOver, notifier) {
+			failoverImpls[notifier] = impl
+			if notifier == failedOver[0] {
+				failoverIndex = len(result)
+				result = append(result, nil)
+			}
+			continue
+		}
+
 		if err := impl.Configure(log); err != nil {
-			return nil, fmt.Errorf("configure failed in [%s] notifier: %v", notifier, err)
+			return nil, nil, fmt.Errorf("configure failed in [%s] notifier: %v", notifier, err)
 		}
 
 		result = append(result, impl)
+		byName[notifier] = impl
 	}
 
-	return result, nil
+	if teeIndex >= 0 {
+		members := []tee.Member{}
+		for _, notifier := range teed {
+			members = append(members, tee.Member{Name: notifier, Notifier: teedImpls[notifier]})
+		}
+
+		impl, err := tee.New(members, teeConfig.Policy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("configure failed when loading teed notifiers: %v", err)
+		}
+
+		if err := impl.Configure(log); err != nil {
+			return nil, nil, fmt.Errorf("configure failed in tee notifier: %v", err)
+		}
+
+		result[teeIndex] = impl
+		for _, notifier := range teed {
+			byName[notifier] = impl
+		}
+	}
+
+	if failoverIndex >= 0 {
+		impl, err := failover.New(
+			failover.Member{Name: failedOver[0], Notifier: failoverImpls[failedOver[0]]},
+			failover.Member{Name: failedOver[1], Notifier: failoverImpls[failedOver[1]]},
+			failoverConfig.ReconcileSize, failoverConfig.ReconcileInterval,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("configure failed when loading failover notifiers: %v", err)
+		}
+
+		if err := impl.Configure(log); err != nil {
+			return nil, nil, fmt.Errorf("configure failed in failover notifier: %v", err)
+		}
+
+		result[failoverIndex] = impl
+		for _, notifier := range failedOver {
+			byName[notifier] = impl
+		}
+	}
+
+	return result, byName, nil
+}
+
+// defineTestNotifiers defines the notifiers of the test-mode notifications.
+// They can't be in the notifier list, which receives only the production
+// notifications.
+func defineTestNotifiers(cfg configuration.TestModeConfig, notifierList string, serializer domain.MessageSerializer, log *logrus.Logger) ([]domain.Notifier, error) {
+	if err := checkTestNotifiers(cfg, notifierList); err != nil {
+		return nil, err
+	}
+
+	if len(configuration.SplitList(cfg.NotifierList)) == 0 {
+		return nil, nil
+	}
+
+	notifiers, _, err := defineNotifiers(cfg.NotifierList, configuration.ThrottleConfig{}, configuration.BatchConfig{}, configuration.TeeConfig{}, configuration.FailoverConfig{}, configuration.AsyncConfig{}, serializer, log)
+	return notifiers, err
+}
+
+func checkTestNotifiers(cfg configuration.TestModeConfig, notifierList string) error {
+	testNotifiers := configuration.SplitList(cfg.NotifierList)
+	if len(testNotifiers) == 0 {
+		return nil
+	}
+
+	if cfg.Path == "" && cfg.EventTypeSuffix == "" {
+		return fmt.Errorf("test-mode notifiers without a test-mode rule")
+	}
+
+	notifiers, err := extractNotifiersFromConfig(notifierList)
+	if err != nil {
+		return err
+	}
+
+	for _, notifier := range testNotifiers {
+		if containsNotifier(notifiers, strings.ToLower(notifier)) {
+			return fmt.Errorf("test-mode notifier can't be in the notifier list: %v", notifier)
+		}
+	}
+
+	return nil
 }
 
 func extractNotifiersFromConfig(notifiers string) ([]string, error) {
@@ -68,3 +230,168 @@ func extractNotifiersFromConfig(notifiers string) ([]string, error) {
 
 	return result, nil
 }
+
+func extractThrottledNotifiers(cfg configuration.ThrottleConfig, notifiers []string) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, notifier := range configuration.SplitList(cfg.NotifierList) {
+		notifier = strings.ToLower(notifier)
+
+		found := false
+		for _, configured := range notifiers {
+			found = found || configured == notifier
+		}
+		if !found {
+			return nil, fmt.Errorf("throttled notifier is not in the notifier list: %v", notifier)
+		}
+
+		result[notifier] = true
+	}
+
+	if len(result) > 0 && (cfg.Rate <= 0 || cfg.QueueSize <= 0) {
+		return nil, fmt.Errorf("invalid throttle rate %v or queue size %d", cfg.Rate, cfg.QueueSize)
+	}
+
+	return result, nil
+}
+
+func extractBatchedNotifiers(cfg configuration.BatchConfig, notifiers []string, throttled map[string]bool) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, notifier := range configuration.SplitList(cfg.NotifierList) {
+		notifier = strings.ToLower(notifier)
+
+		found := false
+		for _, configured := range notifiers {
+			found = found || configured == notifier
+		}
+		if !found {
+			return nil, fmt.Errorf("batched notifier is not in the notifier list: %v", notifier)
+		}
+
+		if _, ok := notificationTypes[notifier].(domain.BatchNotifier); !ok {
+			return nil, fmt.Errorf("notifier doesn't support batches: %v", notifier)
+		}
+
+		if throttled[notifier] {
+			return nil, fmt.Errorf("notifier can't be throttled and batched: %v", notifier)
+		}
+
+		result[notifier] = true
+	}
+
+	if len(result) > 0 && (cfg.Size <= 0 || cfg.FlushInterval <= 0) {
+		return nil, fmt.Errorf("invalid batch size %d or flush interval %s", cfg.Size, cfg.FlushInterval)
+	}
+
+	return result, nil
+}
+
+// extractTeedNotifiers returns the teed notifiers in order, the primary first.
+// They can't be throttled, since the tee answers by their results.
+func extractTeedNotifiers(cfg configuration.TeeConfig, notifiers []string, throttled map[string]bool) ([]string, error) {
+	result := []string{}
+	for _, notifier := range configuration.SplitList(cfg.NotifierList) {
+		notifier = strings.ToLower(notifier)
+
+		if !containsNotifier(notifiers, notifier) {
+			return nil, fmt.Errorf("teed notifier is not in the notifier list: %v", notifier)
+		}
+
+		if containsNotifier(result, notifier) {
+			return nil, fmt.Errorf("duplicated teed notifier: %v", notifier)
+		}
+
+		if throttled[notifier] {
+			return nil, fmt.Errorf("notifier can't be throttled and teed: %v", notifier)
+		}
+
+		result = append(result, notifier)
+	}
+
+	if len(result) == 1 {
+		return nil, fmt.Errorf("tee needs at least two notifiers: %v", result[0])
+	}
+
+	if len(result) > 0 && cfg.Policy != tee.PolicyPrimary && cfg.Policy != tee.PolicyAll {
+		return nil, fmt.Errorf("invalid tee policy: %v", cfg.Policy)
+	}
+
+	return result, nil
+}
+
+// extractFailoverNotifiers returns the primary and the secondary notifiers.
+// They can't be throttled or teed, since the failover answers by their
+// results.
+func extractFailoverNotifiers(cfg configuration.FailoverConfig, notifiers []string, throttled map[string]bool, teed []string) ([]string, error) {
+	result := []string{}
+	for _, notifier := range configuration.SplitList(cfg.NotifierList) {
+		notifier = strings.ToLower(notifier)
+
+		if !containsNotifier(notifiers, notifier) {
+			return nil, fmt.Errorf("failover notifier is not in the notifier list: %v", notifier)
+		}
+
+		if containsNotifier(result, notifier) {
+			return nil, fmt.Errorf("duplicated failover notifier: %v", notifier)
+		}
+
+		if throttled[notifier] {
+			return nil, fmt.Errorf("notifier can't be throttled and failed over: %v", notifier)
+		}
+
+		if containsNotifier(teed, notifier) {
+			return nil, fmt.Errorf("notifier can't be teed and failed over: %v", notifier)
+		}
+
+		result = append(result, notifier)
+	}
+
+	if len(result) != 0 && len(result) != 2 {
+		return nil, fmt.Errorf("failover needs a primary and a secondary notifier, got %v", result)
+	}
+
+	if len(result) > 0 && (cfg.ReconcileSize < 0 || (cfg.ReconcileSize > 0 && cfg.ReconcileInterval <= 0)) {
+		return nil, fmt.Errorf("invalid failover reconcile size %d or interval %s", cfg.ReconcileSize, cfg.ReconcileInterval)
+	}
+
+	return result, nil
+}
+
+// extractAsyncNotifiers returns the notifiers sent by the workers. They
+// can't be throttled or batched, which already queue the notifications, nor
+// teed or failed over, since those answer by their results.
+func extractAsyncNotifiers(cfg configuration.AsyncConfig, notifiers []string, throttled, batched map[string]bool, teed, failedOver []string) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, notifier := range configuration.SplitList(cfg.NotifierList) {
+		notifier = strings.ToLower(notifier)
+
+		if !containsNotifier(notifiers, notifier) {
+			return nil, fmt.Errorf("async notifier is not in the notifier list: %v", notifier)
+		}
+
+		if throttled[notifier] || batched[notifier] {
+			return nil, fmt.Errorf("notifier can't be async and throttled or batched: %v", notifier)
+		}
+
+		if containsNotifier(teed, notifier) || containsNotifier(failedOver, notifier) {
+			return nil, fmt.Errorf("notifier can't be async and teed or failed over: %v", notifier)
+		}
+
+		result[notifier] = true
+	}
+
+	if len(result) > 0 && (cfg.Workers <= 0 || cfg.QueueSize <= 0 || cfg.EnqueueTimeout < 0) {
+		return nil, fmt.Errorf("invalid async workers %d, queue size %d or enqueue timeout %s", cfg.Workers, cfg.QueueSize, cfg.EnqueueTimeout)
+	}
+
+	return result, nil
+}
+
+func containsNotifier(notifiers []string, notifier string) bool {
+	for _, configured := range notifiers {
+		if configured == notifier {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
 )
 
 func Test_extractNotifiersFromConfig(t *testing.T) {
@@ -75,3 +78,386 @@ func Test_extractNotifiersFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_extractThrottledNotifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       configuration.ThrottleConfig
+		notifiers []string
+		want      map[string]bool
+		wantErr   bool
+	}{
+		{
+			name:      "No throttled notifiers",
+			cfg:       configuration.ThrottleConfig{Rate: 10, QueueSize: 10},
+			notifiers: []string{"stdout"},
+			want:      map[string]bool{},
+		},
+		{
+			name:      "Throttled notifier is case insensitive",
+			cfg:       configuration.ThrottleConfig{NotifierList: "PROXY", Rate: 10, QueueSize: 10},
+			notifiers: []string{"stdout", "proxy"},
+			want:      map[string]bool{"proxy": true},
+		},
+		{
+			name:      "Throttled notifier must be in the notifier list",
+			cfg:       configuration.ThrottleConfig{NotifierList: "redis", Rate: 10, QueueSize: 10},
+			notifiers: []string{"stdout", "proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Rate must be positive",
+			cfg:       configuration.ThrottleConfig{NotifierList: "proxy", Rate: 0, QueueSize: 10},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractThrottledNotifiers(tt.cfg, tt.notifiers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractThrottledNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractThrottledNotifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractBatchedNotifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       configuration.BatchConfig
+		notifiers []string
+		throttled map[string]bool
+		want      map[string]bool
+		wantErr   bool
+	}{
+		{
+			name:      "No batched notifiers",
+			cfg:       configuration.BatchConfig{Size: 10, FlushInterval: time.Second},
+			notifiers: []string{"stdout"},
+			want:      map[string]bool{},
+		},
+		{
+			name:      "Batched notifier is case insensitive",
+			cfg:       configuration.BatchConfig{NotifierList: "REDIS", Size: 10, FlushInterval: time.Second},
+			notifiers: []string{"stdout", "redis"},
+			want:      map[string]bool{"redis": true},
+		},
+		{
+			name:      "Batched notifier must be in the notifier list",
+			cfg:       configuration.BatchConfig{NotifierList: "redis", Size: 10, FlushInterval: time.Second},
+			notifiers: []string{"stdout"},
+			wantErr:   true,
+		},
+		{
+			name:      "Batched notifier must support batches",
+			cfg:       configuration.BatchConfig{NotifierList: "proxy", Size: 10, FlushInterval: time.Second},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Batched notifier can't be throttled",
+			cfg:       configuration.BatchConfig{NotifierList: "redis", Size: 10, FlushInterval: time.Second},
+			notifiers: []string{"redis"},
+			throttled: map[string]bool{"redis": true},
+			wantErr:   true,
+		},
+		{
+			name:      "Size must be positive",
+			cfg:       configuration.BatchConfig{NotifierList: "redis", Size: 0, FlushInterval: time.Second},
+			notifiers: []string{"redis"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractBatchedNotifiers(tt.cfg, tt.notifiers, tt.throttled)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractBatchedNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractBatchedNotifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractTeedNotifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       configuration.TeeConfig
+		notifiers []string
+		throttled map[string]bool
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "No teed notifiers",
+			cfg:       configuration.TeeConfig{Policy: "primary"},
+			notifiers: []string{"stdout"},
+			want:      []string{},
+		},
+		{
+			name:      "Teed notifiers keep the tee order",
+			cfg:       configuration.TeeConfig{NotifierList: "REDIS;proxy", Policy: "all"},
+			notifiers: []string{"proxy", "redis"},
+			want:      []string{"redis", "proxy"},
+		},
+		{
+			name:      "Teed notifier must be in the notifier list",
+			cfg:       configuration.TeeConfig{NotifierList: "redis;proxy", Policy: "primary"},
+			notifiers: []string{"redis"},
+			wantErr:   true,
+		},
+		{
+			name:      "Tee needs two notifiers",
+			cfg:       configuration.TeeConfig{NotifierList: "redis", Policy: "primary"},
+			notifiers: []string{"redis"},
+			wantErr:   true,
+		},
+		{
+			name:      "Duplicated teed notifier",
+			cfg:       configuration.TeeConfig{NotifierList: "redis;redis", Policy: "primary"},
+			notifiers: []string{"redis"},
+			wantErr:   true,
+		},
+		{
+			name:      "Teed notifier can't be throttled",
+			cfg:       configuration.TeeConfig{NotifierList: "redis;proxy", Policy: "primary"},
+			notifiers: []string{"proxy", "redis"},
+			throttled: map[string]bool{"proxy": true},
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid policy",
+			cfg:       configuration.TeeConfig{NotifierList: "redis;proxy", Policy: "any"},
+			notifiers: []string{"proxy", "redis"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractTeedNotifiers(tt.cfg, tt.notifiers, tt.throttled)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractTeedNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractTeedNotifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_extractFailoverNotifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       configuration.FailoverConfig
+		notifiers []string
+		throttled map[string]bool
+		teed      []string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "No failover notifiers",
+			notifiers: []string{"stdout"},
+			want:      []string{},
+		},
+		{
+			name:      "Primary first",
+			cfg:       configuration.FailoverConfig{NotifierList: "PROXY;redis", ReconcileSize: 10, ReconcileInterval: time.Second},
+			notifiers: []string{"redis", "proxy"},
+			want:      []string{"proxy", "redis"},
+		},
+		{
+			name:      "Reconciliation disabled",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis"},
+			notifiers: []string{"redis", "proxy"},
+			want:      []string{"proxy", "redis"},
+		},
+		{
+			name:      "Failover notifier must be in the notifier list",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis"},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Failover needs exactly two notifiers",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis;stdout"},
+			notifiers: []string{"proxy", "redis", "stdout"},
+			wantErr:   true,
+		},
+		{
+			name:      "Duplicated failover notifier",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;proxy"},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Failover notifier can't be throttled",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis"},
+			notifiers: []string{"proxy", "redis"},
+			throttled: map[string]bool{"redis": true},
+			wantErr:   true,
+		},
+		{
+			name:      "Failover notifier can't be teed",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis"},
+			notifiers: []string{"proxy", "redis", "stdout"},
+			teed:      []string{"stdout", "redis"},
+			wantErr:   true,
+		},
+		{
+			name:      "Reconciliation without an interval",
+			cfg:       configuration.FailoverConfig{NotifierList: "proxy;redis", ReconcileSize: 10},
+			notifiers: []string{"proxy", "redis"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractFailoverNotifiers(tt.cfg, tt.notifiers, tt.throttled, tt.teed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractFailoverNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractFailoverNotifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkTestNotifiers(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          configuration.TestModeConfig
+		notifierList string
+		wantErr      bool
+	}{
+		{
+			name:         "No test-mode notifiers",
+			notifierList: "proxy",
+		},
+		{
+			name:         "Test-mode notifiers",
+			cfg:          configuration.TestModeConfig{EventTypeSuffix: ".test", NotifierList: "stdout"},
+			notifierList: "proxy",
+		},
+		{
+			name:         "Test-mode notifier in the notifier list",
+			cfg:          configuration.TestModeConfig{Path: "test", NotifierList: "PROXY"},
+			notifierList: "proxy;redis",
+			wantErr:      true,
+		},
+		{
+			name:         "Test-mode notifiers without a rule",
+			cfg:          configuration.TestModeConfig{NotifierList: "stdout"},
+			notifierList: "proxy",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkTestNotifiers(tt.cfg, tt.notifierList); (err != nil) != tt.wantErr {
+				t.Errorf("checkTestNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_extractAsyncNotifiers(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        configuration.AsyncConfig
+		notifiers  []string
+		throttled  map[string]bool
+		batched    map[string]bool
+		teed       []string
+		failedOver []string
+		want       map[string]bool
+		wantErr    bool
+	}{
+		{
+			name:      "No async notifiers",
+			notifiers: []string{"stdout"},
+			want:      map[string]bool{},
+		},
+		{
+			name:      "Async notifiers",
+			cfg:       configuration.AsyncConfig{NotifierList: "PROXY", Workers: 4, QueueSize: 10},
+			notifiers: []string{"proxy", "stdout"},
+			want:      map[string]bool{"proxy": true},
+		},
+		{
+			name:      "Async notifier must be in the notifier list",
+			cfg:       configuration.AsyncConfig{NotifierList: "redis", Workers: 4, QueueSize: 10},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Async notifier can't be throttled",
+			cfg:       configuration.AsyncConfig{NotifierList: "proxy", Workers: 4, QueueSize: 10},
+			notifiers: []string{"proxy"},
+			throttled: map[string]bool{"proxy": true},
+			wantErr:   true,
+		},
+		{
+			name:      "Async notifier can't be batched",
+			cfg:       configuration.AsyncConfig{NotifierList: "redis", Workers: 4, QueueSize: 10},
+			notifiers: []string{"redis"},
+			batched:   map[string]bool{"redis": true},
+			wantErr:   true,
+		},
+		{
+			name:      "Async notifier can't be teed",
+			cfg:       configuration.AsyncConfig{NotifierList: "proxy", Workers: 4, QueueSize: 10},
+			notifiers: []string{"proxy", "redis"},
+			teed:      []string{"redis", "proxy"},
+			wantErr:   true,
+		},
+		{
+			name:       "Async notifier can't be failed over",
+			cfg:        configuration.AsyncConfig{NotifierList: "proxy", Workers: 4, QueueSize: 10},
+			notifiers:  []string{"proxy", "redis"},
+			failedOver: []string{"proxy", "redis"},
+			wantErr:    true,
+		},
+		{
+			name:      "Async notifier needs workers",
+			cfg:       configuration.AsyncConfig{NotifierList: "proxy", QueueSize: 10},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+		{
+			name:      "Async notifier needs a queue",
+			cfg:       configuration.AsyncConfig{NotifierList: "proxy", Workers: 4},
+			notifiers: []string{"proxy"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractAsyncNotifiers(tt.cfg, tt.notifiers, tt.throttled, tt.batched, tt.teed, tt.failedOver)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractAsyncNotifiers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractAsyncNotifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
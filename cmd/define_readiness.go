@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/healthcheck"
+)
+
+// defineReadiness checks the keys, the configured notifiers and the backends
+// that can check their health. The nil backends aren't configured.
+func defineReadiness(timeout time.Duration, keyStore *keys.Store, notifierList string, backends map[string]interface{}) (*healthcheck.Readiness, error) {
+	notifiers, err := extractNotifiersFromConfig(notifierList)
+	if err != nil {
+		return nil, fmt.Errorf("configure failed when loading notifiers: %v", err)
+	}
+
+	readiness := healthcheck.NewReadiness(timeout)
+	readiness.Add("keys", keyStore.CheckHealth)
+
+	for _, notifier := range notifiers {
+		if checker, ok := notificationTypes[notifier].(domain.HealthChecker); ok {
+			readiness.Add("notifier:"+notifier, checker.CheckHealth)
+		}
+	}
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if checker, ok := backends[name].(domain.HealthChecker); ok {
+			readiness.Add(name, checker.CheckHealth)
+		}
+	}
+
+	return readiness, nil
+}
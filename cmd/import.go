@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventtype"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/replay"
+)
+
+// runImport sends the records of the replay file through the usecase.
+func runImport(cfg configuration.ImportConfig, eventTypeConfig configuration.EventTypeConfig, uc domain.NotificationUsecase, log *logrus.Logger) (replay.Summary, error) {
+	eventTypes, err := eventtype.New(eventTypeConfig)
+	if err != nil {
+		return replay.Summary{}, err
+	}
+
+	file, err := os.Open(cfg.File)
+	if err != nil {
+		return replay.Summary{}, fmt.Errorf("opening replay file %s: %v", cfg.File, err)
+	}
+	defer file.Close()
+
+	importer := replay.New(log, uc, cfg.Concurrency, cfg.SkipDuplicates)
+	importer.SetEventTypeNormalizer(eventTypes)
+	return importer.Import(context.Background(), file)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,12 +11,31 @@ import (
 
 	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
 	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
 	"github.com/stone-co/webhook-consumer/pkg/domain/usecase"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/authorizers/tenant"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/confirmers/callback"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/http"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/failover"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/tee"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/tracing"
 )
 
 func main() {
 	log := logrus.New()
+
+	// The decode command only inspects a captured body and exits, without
+	// the notifiers or the API.
+	if len(os.Args) > 1 && os.Args[1] == "decode" {
+		if err := runDecode(os.Args[2:], os.Stdin, os.Stdout, log); err != nil {
+			log.WithError(err).Error("decode failed")
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Infoln("starting webhook-consumer service...")
 
 	cfg, err := configuration.LoadConfig()
@@ -25,17 +45,192 @@ func main() {
 
 	log.Infof("config: %s", cfg)
 
-	keys, err := keys.LoadKeys(cfg.PrivateKeyPath, cfg.PublicKeyLocation)
+	// Without a collector the spans are no-ops.
+	tracer, err := tracing.New(cfg.TracingConfig)
+	if err != nil {
+		log.WithError(err).Fatal("unable to define the tracing")
+	}
+
+	keyConfig, err := keys.LoadKeys(cfg.KeysConfig)
 	if err != nil {
 		log.WithError(err).Fatal("unable to load keys")
 	}
 
-	notifiers, err := defineNotifiers(cfg.NotifierList, log)
+	// The keys can be reloaded by the admin API, like after a key rotation.
+	keyStore := keys.NewStore(keyConfig, func() (*keys.Config, error) {
+		return keys.LoadKeys(cfg.KeysConfig)
+	})
+
+	// The keys can also be refreshed periodically, picking up the rotated
+	// keys of a JWKS url.
+	if cfg.KeysConfig.RefreshInterval > 0 {
+		refreshCtx, stopRefresh := context.WithCancel(context.Background())
+		defer stopRefresh()
+
+		go keyStore.Refresh(refreshCtx, cfg.KeysConfig.RefreshInterval, func(err error) {
+			log.WithError(err).Error("unable to refresh the keys, keeping the current ones")
+		})
+	}
+
+	// A SIGHUP reloads the keys from the disk too, like after adding a
+	// decryption key to PRIVATE_KEY_PATH.
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	go func() {
+		for range reloadSignal {
+			if err := keyStore.Reload(); err != nil {
+				log.WithError(err).Error("unable to reload the keys, keeping the current ones")
+				continue
+			}
+			log.Info("keys reloaded")
+		}
+	}()
+
+	serializer, err := serializers.New(cfg.SerializerConfig, cfg.MetadataConfig)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define serializer: %v", err)
+	}
+
+	instrument.SetMaxEventTypes(cfg.MetricsConfig.MaxEventTypes)
+	notifiers, notifiersByName, err := defineNotifiers(cfg.NotifierList, cfg.ThrottleConfig, cfg.BatchConfig, cfg.TeeConfig, cfg.FailoverConfig, cfg.AsyncConfig, serializer, log)
 	if err != nil {
 		log.WithError(err).Fatalf("unable to define notifiers: %v", err)
 	}
 
-	usecase := usecase.NewNotificationUsecase(log, keys, notifiers)
+	testNotifiers, err := defineTestNotifiers(cfg.TestModeConfig, cfg.NotifierList, serializer, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define test-mode notifiers: %v", err)
+	}
+
+	archiver, err := defineArchiver(cfg.ArchiverConfig.Archiver, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define archiver: %v", err)
+	}
+
+	deadLetterStore, err := defineDeadLetterStore(cfg.DeadLetterConfig.Store, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define dead letter store: %v", err)
+	}
+
+	idempotencyStore, err := defineIdempotencyStore(cfg.IdempotencyConfig.Store, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define idempotency store: %v", err)
+	}
+
+	notificationStore, err := defineNotificationStore(cfg.NotificationStoreConfig.Store, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define notification store: %v", err)
+	}
+
+	if err := usecase.CheckOrderingConfig(cfg.OrderingConfig); err != nil {
+		log.WithError(err).Fatal("invalid ordering config")
+	}
+	if err := usecase.CheckPayloadConfig(cfg.PayloadCheckConfig); err != nil {
+		log.WithError(err).Fatal("invalid payload check config")
+	}
+	if err := usecase.CheckRelayConfig(*cfg); err != nil {
+		log.WithError(err).Fatal("invalid relay config")
+	}
+	if err := usecase.CheckRetryConfig(cfg.RetryConfig); err != nil {
+		log.WithError(err).Fatal("invalid retry config")
+	}
+	if err := usecase.CheckIdempotencyConfig(cfg.IdempotencyConfig); err != nil {
+		log.WithError(err).Fatal("invalid idempotency config")
+	}
+	if err := usecase.CheckNotificationStoreConfig(cfg.NotificationStoreConfig); err != nil {
+		log.WithError(err).Fatal("invalid notification store config")
+	}
+	routes, err := usecase.NewRoutes(cfg.RoutingConfig, notifiersByName)
+	if err != nil {
+		log.WithError(err).Fatal("invalid routing config")
+	}
+	transforms, err := usecase.NewTransforms(cfg.TransformConfig)
+	if err != nil {
+		log.WithError(err).Fatal("invalid transform config")
+	}
+	schemas, err := usecase.NewSchemas(*cfg)
+	if err != nil {
+		log.WithError(err).Fatal("invalid payload schema config")
+	}
+
+	usecase := usecase.NewNotificationUsecase(*cfg, log, keyStore, notifiers, archiver)
+	usecase.SetTestNotifiers(testNotifiers)
+	if deadLetterStore != nil {
+		usecase.SetDeadLetterStore(deadLetterStore)
+		checkpointNotifiers(append(notifiers, testNotifiers...), deadLetterStore)
+	}
+	if idempotencyStore != nil {
+		usecase.SetIdempotencyStore(idempotencyStore)
+	}
+	if notificationStore != nil {
+		usecase.SetNotificationStore(notificationStore)
+	}
+	if routes != nil {
+		usecase.SetRoutes(routes)
+	}
+	if transforms != nil {
+		usecase.SetTransforms(transforms)
+	}
+	if schemas != nil {
+		usecase.SetSchemas(schemas)
+	}
+
+	authorizer, err := tenant.New(cfg.AuthorizerConfig)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define authorizer: %v", err)
+	}
+	if authorizer != nil {
+		usecase.SetAuthorizer(authorizer)
+	}
+
+	if cfg.SelfTestConfig.Enabled {
+		if err := runSelfTest(cfg.SelfTestConfig, keyConfig, usecase); err != nil {
+			log.WithError(err).Fatal("self-test failed, check the keys")
+		}
+		log.Infoln("self-test passed")
+	}
+
+	// The import mode sends the replay file and exits, without the API.
+	if cfg.ImportConfig.File != "" {
+		summary, err := runImport(cfg.ImportConfig, cfg.EventTypeConfig, usecase, log)
+
+		// The detached publishes, and the deferred and batched notifiers,
+		// still have to send theirs.
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.HTTPConfig.DrainTimeout)
+		if err := usecase.Drain(drainCtx); err != nil {
+			log.WithError(err).Error("could not send all the pending notifications")
+		}
+		stopNotifiers(drainCtx, append(notifiers, testNotifiers...), log)
+		cancelDrain()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTPConfig.ShutdownTimeout)
+		if err := tracer.Shutdown(ctx); err != nil {
+			log.WithError(err).Error("could not export all the spans")
+		}
+		cancel()
+
+		if err != nil {
+			log.WithError(err).Fatalf("import failed: %s", summary)
+		}
+		log.Infof("import finished: %s", summary)
+		if summary.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Only the notifications received by the API are confirmed to the
+	// provider, not the imported ones.
+	confirmer, err := callback.New(cfg.CallbackConfig, log)
+	if err != nil {
+		log.WithError(err).Fatalf("unable to define the provider callback: %v", err)
+	}
+	if confirmer != nil {
+		if deadLetterStore != nil {
+			confirmer.SetDeadLetterStore(deadLetterStore)
+		}
+		usecase.SetConfirmer(confirmer)
+	}
 
 	// Make a channel to listen for an interrupt or terminate signal from the OS.
 	// Use a buffered channel because the signal package requires it.
@@ -44,15 +239,47 @@ func main() {
 
 	// Make a channel to listen for errors coming from the listener. Use a
 	// buffered channel so the goroutine can exit if we don't collect this error.
-	serverErrors := make(chan error, 1)
+	serverErrors := make(chan error, 2)
 
 	// NewServer HTTP Server listening for requests.
-	httpServer := http.NewHttpServer(*cfg, log, usecase)
+	readiness, err := defineReadiness(cfg.HTTPConfig.ReadinessTimeout, keyStore, cfg.NotifierList, map[string]interface{}{
+		"archiver":           archiver,
+		"dead_letter_store":  deadLetterStore,
+		"idempotency_store":  idempotencyStore,
+		"notification_store": notificationStore,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("unable to define the readiness checks")
+	}
+
+	servers, err := http.NewHttpServers(*cfg, log, usecase, keyStore, notificationStore, readiness)
+	if err != nil {
+		log.WithError(err).Fatal("unable to create the http server")
+	}
+
+	listener, err := http.NewListener(cfg.HTTPConfig, servers.Public.Addr)
+	if err != nil {
+		log.WithError(err).Fatal("unable to listen")
+	}
+
 	go func() {
-		log.Infof("starting http api at %s", httpServer.Addr)
-		serverErrors <- httpServer.ListenAndServe()
+		log.Infof("starting http api at %s", listener.Addr())
+		serverErrors <- http.Serve(servers.Public, listener)
 	}()
 
+	// The admin listener is always on TCP, never on the unix socket.
+	if servers.Admin != nil {
+		adminListener, err := net.Listen("tcp", servers.Admin.Addr)
+		if err != nil {
+			log.WithError(err).Fatal("unable to listen on the admin address")
+		}
+
+		go func() {
+			log.Infof("starting admin api at %s", adminListener.Addr())
+			serverErrors <- http.Serve(servers.Admin, adminListener)
+		}()
+	}
+
 	// =================
 	// Shutdown
 
@@ -71,10 +298,84 @@ func main() {
 		log.Infof("stopping http server %v\n", sig)
 
 		// Asking listener to shutdown and shed load.
-		if err := httpServer.Shutdown(ctx); err != nil {
-			_ = httpServer.Close()
+		if err := servers.Public.Shutdown(ctx); err != nil {
+			_ = servers.Public.Close()
 			log.WithError(err).Error("could not stop server gracefully")
 		}
 		log.Infof("http server stopped %v\n", sig)
+
+		// The admin server stays up until the public one is drained, so
+		// the healthcheck and metrics cover the shutdown.
+		if servers.Admin != nil {
+			if err := servers.Admin.Shutdown(ctx); err != nil {
+				_ = servers.Admin.Close()
+				log.WithError(err).Error("could not stop admin server gracefully")
+			}
+		}
+
+		// Nothing is received anymore. The notifications queued behind their
+		// ordering keys and the detached publishes go to the notifiers before
+		// they're stopped, each drain bounded by its own timeout. What isn't
+		// sent by then is stored as a dead letter.
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.HTTPConfig.DrainTimeout)
+		defer cancelDrain()
+
+		if err := usecase.Drain(drainCtx); err != nil {
+			log.WithError(err).Error("could not send all the pending notifications")
+		}
+
+		// Send the notifications still waiting in the deferred and batched notifiers.
+		stopNotifiers(drainCtx, append(notifiers, testNotifiers...), log)
+
+		ctx, cancel = context.WithTimeout(context.Background(), cfg.HTTPConfig.ShutdownTimeout)
+		defer cancel()
+
+		if confirmer != nil {
+			if err := confirmer.Shutdown(ctx); err != nil {
+				log.WithError(err).Error("could not send all the provider confirmations")
+			}
+		}
+
+		// The spans of the last notifications are exported last.
+		if err := tracer.Shutdown(ctx); err != nil {
+			log.WithError(err).Error("could not export all the spans")
+		}
+	}
+}
+
+// checkpointNotifiers sets the dead letter store of the deferred notifiers,
+// including the ones wrapped by a tee or a failover, to keep the
+// notifications they drop on shutdown.
+func checkpointNotifiers(notifiers []domain.Notifier, store domain.DeadLetterStore) {
+	for _, notifier := range notifiers {
+		if checkpointed, ok := notifier.(interface {
+			SetDeadLetterStore(store domain.DeadLetterStore)
+		}); ok {
+			checkpointed.SetDeadLetterStore(store)
+		}
+		if teed, ok := notifier.(*tee.Tee); ok {
+			checkpointNotifiers(teed.Notifiers(), store)
+		}
+		if failedOver, ok := notifier.(*failover.Failover); ok {
+			checkpointNotifiers(failedOver.Notifiers(), store)
+		}
+	}
+}
+
+// stopNotifiers stops the deferred notifiers, including the ones
+// wrapped by a tee or a failover.
+func stopNotifiers(ctx context.Context, notifiers []domain.Notifier, log *logrus.Logger) {
+	for _, notifier := range notifiers {
+		if deferred, ok := notifier.(domain.DeferredNotifier); ok {
+			if err := deferred.Shutdown(ctx); err != nil {
+				log.WithError(err).Error("could not stop notifier gracefully")
+			}
+		}
+		if teed, ok := notifier.(*tee.Tee); ok {
+			stopNotifiers(ctx, teed.Notifiers(), log)
+		}
+		if failedOver, ok := notifier.(*failover.Failover); ok {
+			stopNotifiers(ctx, failedOver.Notifiers(), log)
+		}
 	}
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain/usecase"
+)
+
+// runSelfTest verifies and decodes the sample body, read from the file or
+// generated with the signing key.
+func runSelfTest(cfg configuration.SelfTestConfig, keyConfig *keys.Config, uc *usecase.NotificationUsecase) error {
+	var body string
+
+	switch {
+	case cfg.SamplePath != "":
+		sample, err := ioutil.ReadFile(cfg.SamplePath)
+		if err != nil {
+			return fmt.Errorf("reading sample %s: %v", cfg.SamplePath, err)
+		}
+		body = strings.TrimSpace(string(sample))
+
+	case cfg.SigningKeyPath != "":
+		signingKey, err := keys.LoadSampleSigningKey(cfg.SigningKeyPath)
+		if err != nil {
+			return fmt.Errorf("loading signing key %s: %v", cfg.SigningKeyPath, err)
+		}
+
+		body, err = keys.NewSampleBody(keyConfig, signingKey)
+		if err != nil {
+			return fmt.Errorf("generating sample: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("no sample body or signing key configured")
+	}
+
+	return uc.SelfTest(body)
+}
@@ -2,6 +2,8 @@ package configuration
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -9,19 +11,638 @@ import (
 
 // Config defines the service configuration
 type Config struct {
-	HTTPConfig     HTTPConfig
+	HTTPConfig HTTPConfig
+	KeysConfig KeysConfig
+	// NotifierList has stdout and proxy availables.
+	NotifierList            string `envconfig:"NOTIFIER_LIST" default:"stdout"`
+	ThrottleConfig          ThrottleConfig
+	BatchConfig             BatchConfig
+	TeeConfig               TeeConfig
+	FailoverConfig          FailoverConfig
+	AsyncConfig             AsyncConfig
+	RoutingConfig           RoutingConfig
+	TransformConfig         TransformConfig
+	SchemaConfig            SchemaConfig
+	ArchiverConfig          ArchiverConfig
+	OrderingConfig          OrderingConfig
+	PayloadCheckConfig      PayloadCheckConfig
+	AdminConfig             AdminConfig
+	MaintenanceConfig       MaintenanceConfig
+	ExtractionConfig        ExtractionConfig
+	MetricsConfig           MetricsConfig
+	SelfTestConfig          SelfTestConfig
+	ImportConfig            ImportConfig
+	PackConfig              PackConfig
+	SerializerConfig        SerializerConfig
+	DecryptLimits           DecryptLimits
+	PublishConfig           PublishConfig
+	RetryConfig             RetryConfig
+	DeadLetterConfig        DeadLetterConfig
+	IdempotencyConfig       IdempotencyConfig
+	NotificationStoreConfig NotificationStoreConfig
+	EventVersionConfig      EventVersionConfig
+	LagConfig               LagConfig
+	TestModeConfig          TestModeConfig
+	AuthorizerConfig        AuthorizerConfig
+	CallbackConfig          CallbackConfig
+	QuarantineConfig        QuarantineConfig
+	RelayConfig             RelayConfig
+	SheddingConfig          SheddingConfig
+	RateLimitConfig         RateLimitConfig
+	EventTypeConfig         EventTypeConfig
+	MetadataConfig          MetadataConfig
+	TracingConfig           TracingConfig
+	InboundAuthConfig       InboundAuthConfig
+	ClientIPConfig          ClientIPConfig
+}
+
+type HTTPConfig struct {
+	Port int `envconfig:"API_PORT" default:"3000"`
+	// UnixSocket is a socket path used instead of the TCP port, when set.
+	UnixSocket string `envconfig:"API_UNIX_SOCKET"`
+	// BasePath prefixes all the routes, like "/stone/v1", for ingresses that
+	// don't strip it. The healthcheck and metrics stay at the root when
+	// OperationalRoutesAtRoot is true.
+	BasePath                string        `envconfig:"API_BASE_PATH"`
+	OperationalRoutesAtRoot bool          `envconfig:"API_OPERATIONAL_ROUTES_AT_ROOT" default:"false"`
+	ShutdownTimeout         time.Duration `envconfig:"API_SHUTDOWN_TIMEOUT" default:"5s"`
+	// DrainTimeout bounds the publishes and queues still running after the
+	// servers are stopped.
+	DrainTimeout time.Duration `envconfig:"API_DRAIN_TIMEOUT" default:"10s"`
+	// The timeouts below protect the server against slow clients. The write
+	// timeout must be greater than the time spent to send the notifications.
+	ReadTimeout       time.Duration `envconfig:"API_READ_TIMEOUT" default:"10s"`
+	ReadHeaderTimeout time.Duration `envconfig:"API_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `envconfig:"API_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `envconfig:"API_IDLE_TIMEOUT" default:"60s"`
+	// MaxBodySize is the maximum number of bytes read from a request body,
+	// with or without Content-Length.
+	MaxBodySize int64 `envconfig:"API_MAX_BODY_SIZE" default:"1048576"`
+	// StrictJSON rejects request bodies with unknown fields or trailing data.
+	StrictJSON bool `envconfig:"API_STRICT_JSON" default:"false"`
+	// ExposeInternalErrors adds the error details to the error responses,
+	// instead of only a stable message by category. The details are always
+	// logged.
+	ExposeInternalErrors bool `envconfig:"API_EXPOSE_INTERNAL_ERRORS" default:"false"`
+	// SuccessStatus answers the notifications sent successfully, with an
+	// empty body. Only 204 and 200 are accepted.
+	SuccessStatus int `envconfig:"API_SUCCESS_STATUS" default:"204"`
+	// AckEventTypes, separated by ';', are answered with AckStatus and the
+	// AckBody template, instead of 204. The template data has EventID and
+	// EventType, which must be rendered with json for a JSON content type.
+	AckEventTypes  string `envconfig:"API_ACK_EVENT_TYPES"`
+	AckStatus      int    `envconfig:"API_ACK_STATUS" default:"200"`
+	AckContentType string `envconfig:"API_ACK_CONTENT_TYPE" default:"application/json"`
+	AckBody        string `envconfig:"API_ACK_BODY"`
+	// TimestampHeader has the RFC 3339 event creation timestamp, used to
+	// measure the delivery lag. It's ignored when empty.
+	TimestampHeader string `envconfig:"API_TIMESTAMP_HEADER"`
+	// ProbePath is a route answering the provider health probes with 200,
+	// without any verification. It's disabled when empty.
+	ProbePath string `envconfig:"API_PROBE_PATH"`
+	// ProbeHeader, as "name=value", recognizes the health probes sent to the
+	// notifications route. Only the bodies without an envelope are probes.
+	ProbeHeader string `envconfig:"API_PROBE_HEADER"`
+	// UnsignedPath is a route receiving the notifications whose encrypted body
+	// is a JWE without the outer JWS. The JWE is decrypted without any
+	// signature verification, so it's only for the sources in
+	// UnsignedAllowedCIDRs, separated by ';', authenticated by the shared
+	// passphrase of UNSIGNED_KEY_ALGORITHMS. The notifications route always
+	// verifies the signature. It's disabled when empty.
+	UnsignedPath         string `envconfig:"API_UNSIGNED_PATH"`
+	UnsignedAllowedCIDRs string `envconfig:"API_UNSIGNED_ALLOWED_CIDRS"`
+	// BatchPath is a route receiving several signed notifications at once,
+	// each one with its own event id and type, answered with the status of
+	// each one. It's disabled when empty.
+	BatchPath     string `envconfig:"API_BATCH_PATH"`
+	BatchMaxItems int    `envconfig:"API_BATCH_MAX_ITEMS" default:"100"`
+	// BodySignatureHeader has the HMAC-SHA256 of the raw request body, as
+	// "sha256=<hex>", keyed by BodySignatureSecret. It's not verified when
+	// empty.
+	BodySignatureHeader string `envconfig:"API_BODY_SIGNATURE_HEADER"`
+	BodySignatureSecret string `envconfig:"API_BODY_SIGNATURE_SECRET" redact:"true"`
+	// ReadinessTimeout bounds each dependency check of the readiness.
+	ReadinessTimeout time.Duration `envconfig:"API_READINESS_TIMEOUT" default:"2s"`
+	// AdminPort serves the healthcheck, metrics, pprof and admin routes on
+	// their own listener at AdminHost, leaving only the notifications on the
+	// public port. Zero serves all the routes on the public port.
+	AdminPort int    `envconfig:"API_ADMIN_PORT" default:"3001"`
+	AdminHost string `envconfig:"API_ADMIN_HOST" default:"127.0.0.1"`
+	// PprofEnabled serves the pprof routes on the admin listener. They are
+	// never served on the public port.
+	PprofEnabled bool `envconfig:"API_PPROF_ENABLED" default:"false"`
+	// The certificate and key of each listener, in PEM. The listener serves
+	// plain HTTP when they are empty.
+	TLSCertPath      string `envconfig:"API_TLS_CERT_PATH"`
+	TLSKeyPath       string `envconfig:"API_TLS_KEY_PATH"`
+	AdminTLSCertPath string `envconfig:"API_ADMIN_TLS_CERT_PATH"`
+	AdminTLSKeyPath  string `envconfig:"API_ADMIN_TLS_KEY_PATH"`
+}
+
+// KeysConfig defines the keys used to verify and decrypt the notifications.
+type KeysConfig struct {
+	// PrivateKeyPath has the files, separated by ';', with the decryption
+	// keys. The first is the current one, the others are still accepted,
+	// like while a key is rotated.
 	PrivateKeyPath string `envconfig:"PRIVATE_KEY_PATH" default:"tests/partner/fakekey.pem"`
 	// PublicKeyLocation can be used to specify a file or a URL.
 	// To specify a file: "file://./tests/stone/fakekey1.pub.jwt"
 	// To specify a URL: "url://https://sandbox-api.openbank.stone.com.br/api/v1/discovery/keys"
 	PublicKeyLocation string `envconfig:"PUBLIC_KEY_PATH" default:"url://https://sandbox-api.openbank.stone.com.br/api/v1/discovery/keys"`
-	// NotifierList has stdout and proxy availables.
-	NotifierList string `envconfig:"NOTIFIER_LIST" default:"stdout"`
+	// FallbackPublicKeyLocation, in the same format, is a break-glass key
+	// only tried when the public keys fail. It's disabled when empty.
+	FallbackPublicKeyLocation string `envconfig:"FALLBACK_PUBLIC_KEY_PATH"`
+	// JWKSMaxSize and JWKSTimeout bound the fetch of the url:// key sets, so
+	// a broken endpoint can't exhaust the memory. Zero is unlimited.
+	JWKSMaxSize int64         `envconfig:"JWKS_MAX_SIZE" default:"1048576"`
+	JWKSTimeout time.Duration `envconfig:"JWKS_TIMEOUT" default:"10s"`
+	// RefreshInterval reloads all the keys periodically, picking up the keys
+	// rotated in the url:// key sets. It's disabled when zero.
+	RefreshInterval time.Duration `envconfig:"KEYS_REFRESH_INTERVAL" default:"0s"`
+	// SymmetricKeyPath has the files, separated by ';', with the JWK shared
+	// secrets used to verify HMAC signatures. It's optional.
+	SymmetricKeyPath string `envconfig:"SYMMETRIC_KEY_PATH"`
+	// SignatureAlgorithms has the accepted signature algorithms, separated by
+	// ';'. HMAC algorithms (HS256, HS384 and HS512) must be explicitly allowed.
+	SignatureAlgorithms string `envconfig:"SIGNATURE_ALGORITHMS" default:"RS256;RS384;RS512;PS256;PS384;PS512;ES256;ES384;ES512;EdDSA"`
+	// KeyAlgorithms has the accepted JWE key management algorithms, separated
+	// by ';'. PBES2 algorithms must be explicitly allowed, and are decrypted
+	// with PBES2Passphrase instead of the private key.
+	KeyAlgorithms   string `envconfig:"KEY_ALGORITHMS" default:"RSA1_5;RSA-OAEP;RSA-OAEP-256;ECDH-ES;ECDH-ES+A128KW;ECDH-ES+A192KW;ECDH-ES+A256KW"`
+	PBES2Passphrase string `envconfig:"PBES2_PASSPHRASE" redact:"true"`
+	// UnsignedKeyAlgorithms replaces KeyAlgorithms on the unsigned route. A
+	// public-key algorithm would let anyone with the public key encrypt a
+	// notification, so only the PBES2 algorithms, with the shared passphrase,
+	// are accepted.
+	UnsignedKeyAlgorithms string `envconfig:"UNSIGNED_KEY_ALGORITHMS" default:"PBES2-HS256+A128KW;PBES2-HS384+A192KW;PBES2-HS512+A256KW"`
+	// VerifyParallelism verifies a signature with up to this number of keys
+	// at a time, only worth it for large key sets. It's sequential when 1.
+	VerifyParallelism int `envconfig:"VERIFY_PARALLELISM" default:"1"`
+	// MinRSAKeyBits and AllowedCurves, separated by ';', are the minimum
+	// strength of the keys, checked when they're loaded and for the keys
+	// embedded in the notifications, like in x5c chains.
+	MinRSAKeyBits int    `envconfig:"MIN_RSA_KEY_BITS" default:"2048"`
+	AllowedCurves string `envconfig:"ALLOWED_EC_CURVES" default:"P-256;P-384;P-521"`
+	// X5CEnabled verifies the signatures with a x5c certificate chain, when
+	// the header has one, instead of the public keys. The chain must be
+	// trusted by the CAs in X5CCAPath.
+	X5CEnabled bool   `envconfig:"X5C_ENABLED" default:"false"`
+	X5CCAPath  string `envconfig:"X5C_CA_PATH"`
+	// X5CAllowedNames, separated by ';', restricts the leaf certificate
+	// common name or DNS names. Any trusted name is accepted when empty.
+	X5CAllowedNames string `envconfig:"X5C_ALLOWED_NAMES"`
+	// ReceiptSigningKeyPath is the private key signing the receipts returned
+	// to the accepted notifications. The receipts are disabled when empty.
+	ReceiptSigningKeyPath string `envconfig:"RECEIPT_SIGNING_KEY_PATH"`
 }
 
-type HTTPConfig struct {
-	Port            int           `envconfig:"API_PORT" default:"3000"`
-	ShutdownTimeout time.Duration `envconfig:"API_SHUTDOWN_TIMEOUT" default:"5s"`
+// ThrottleConfig defines the notifiers that receive the notifications at a
+// fixed rate. Their notifications are queued, and answered with 202.
+type ThrottleConfig struct {
+	// NotifierList has the throttled notifiers, separated by ';'.
+	NotifierList string `envconfig:"THROTTLE_NOTIFIER_LIST"`
+	// Rate is the maximum number of notifications per second, by notifier.
+	Rate      float64 `envconfig:"THROTTLE_RATE" default:"10"`
+	QueueSize int     `envconfig:"THROTTLE_QUEUE_SIZE" default:"1000"`
+}
+
+// BatchConfig defines the notifiers that receive the notifications in
+// batches, flushed at Size notifications or after FlushInterval.
+type BatchConfig struct {
+	// NotifierList has the batched notifiers, separated by ';'.
+	NotifierList  string        `envconfig:"BATCH_NOTIFIER_LIST"`
+	Size          int           `envconfig:"BATCH_SIZE" default:"100"`
+	FlushInterval time.Duration `envconfig:"BATCH_FLUSH_INTERVAL" default:"50ms"`
+}
+
+// TeeConfig writes every notification to all the teed notifiers, like during
+// a migration between backends.
+type TeeConfig struct {
+	// NotifierList has the teed notifiers, separated by ';'. The first one is
+	// the primary, whose failure always fails the notification.
+	NotifierList string `envconfig:"TEE_NOTIFIER_LIST"`
+	// Policy is primary, ignoring the secondary failures, or all.
+	Policy string `envconfig:"TEE_POLICY" default:"primary"`
+}
+
+// FailoverConfig sends the notifications to a secondary notifier when the
+// primary fails.
+type FailoverConfig struct {
+	// NotifierList has the primary and the secondary notifiers, separated by
+	// ';'.
+	NotifierList string `envconfig:"FAILOVER_NOTIFIER_LIST"`
+	// ReconcileSize is the number of failed over notifications kept to be sent
+	// again to the primary when it recovers. Zero disables the reconciliation.
+	ReconcileSize int `envconfig:"FAILOVER_RECONCILE_SIZE" default:"1000"`
+	// ReconcileInterval is the interval between the reconciliation attempts.
+	ReconcileInterval time.Duration `envconfig:"FAILOVER_RECONCILE_INTERVAL" default:"30s"`
+}
+
+// AsyncConfig defines the notifiers that receive the notifications from a
+// queue, sent by a pool of workers. Their notifications are answered with 202.
+type AsyncConfig struct {
+	// NotifierList has the async notifiers, separated by ';'.
+	NotifierList string `envconfig:"ASYNC_NOTIFIER_LIST"`
+	// Workers is the number of notifications sent at a time, by notifier.
+	Workers   int `envconfig:"ASYNC_WORKERS" default:"4"`
+	QueueSize int `envconfig:"ASYNC_QUEUE_SIZE" default:"1000"`
+	// EnqueueTimeout is how long a notification waits for room in a full
+	// queue before being rejected. It's rejected at once when zero.
+	EnqueueTimeout time.Duration `envconfig:"ASYNC_ENQUEUE_TIMEOUT" default:"0s"`
+	// SendTimeout bounds each send of the workers. It's unbounded when zero.
+	SendTimeout time.Duration `envconfig:"ASYNC_SEND_TIMEOUT" default:"30s"`
+}
+
+// RoutingConfig sends the notifications to some of the notifiers, or drops
+// them, by their event type.
+type RoutingConfig struct {
+	// Rules are "pattern=targets" items, separated by ';', evaluated in order.
+	// The pattern is a glob over the event type, like "cash_in_*", and the
+	// targets are notifiers separated by ',', or "drop".
+	Rules string `envconfig:"ROUTING_RULES"`
+	// Default are the targets of the notifications matching no rule. It's
+	// all the notifiers when empty.
+	Default string `envconfig:"ROUTING_DEFAULT"`
+}
+
+// TransformConfig reshapes the decrypted payloads, by their event type,
+// before they're sent to the notifiers.
+type TransformConfig struct {
+	// Templates are "pattern=path" items, separated by ';', evaluated in
+	// order. The pattern is a glob over the event type, like "cash_in_*", and
+	// the path is a Go template file rendering the JSON sent instead of the
+	// payload.
+	Templates string `envconfig:"TRANSFORM_TEMPLATES"`
+}
+
+// SchemaConfig validates the decrypted payloads, by their event type, with
+// JSON Schemas.
+type SchemaConfig struct {
+	// Schemas are "pattern=path" items, separated by ';', evaluated in order.
+	// The pattern is a glob over the event type, and the path is a JSON
+	// Schema file.
+	Schemas string `envconfig:"PAYLOAD_SCHEMAS"`
+	// Action is reject, failing the invalid payloads, or dead_letter,
+	// storing them as dead letters and acking them.
+	Action string `envconfig:"PAYLOAD_SCHEMA_ACTION" default:"reject"`
+}
+
+// ArchiverConfig defines if and how the raw notifications are archived.
+type ArchiverConfig struct {
+	// Archiver is disabled when empty. Only s3 is available.
+	Archiver string `envconfig:"RAW_ARCHIVER"`
+	// Fatal rejects the notification when it can't be archived. Otherwise the
+	// failure is only logged.
+	Fatal bool `envconfig:"RAW_ARCHIVER_FATAL" default:"false"`
+}
+
+// PayloadCheckConfig compares the notification headers with the decrypted
+// body, to detect headers spliced onto another payload.
+type PayloadCheckConfig struct {
+	EventIDCheck bool `envconfig:"EVENT_ID_CHECK" default:"false"`
+	// EventIDPath is the JSON path of the event id in the decrypted body.
+	// Payloads without it are not checked.
+	EventIDPath    string `envconfig:"EVENT_ID_PATH" default:"id"`
+	EventTypeCheck bool   `envconfig:"EVENT_TYPE_CHECK" default:"false"`
+	// EventTypePath is the JSON path of the event type in the decrypted
+	// body. Payloads without it are not checked.
+	EventTypePath string `envconfig:"EVENT_TYPE_PATH" default:"type"`
+	// EmptyPayloadEventTypes, separated by ';', are the event types allowed
+	// to have an empty decrypted body, like heartbeats.
+	EmptyPayloadEventTypes string `envconfig:"EMPTY_PAYLOAD_EVENT_TYPES"`
+	// FieldMaxLengths, as "path=length" items separated by ';', rejects the
+	// payloads with a longer string in the JSON path. The length is in
+	// characters.
+	FieldMaxLengths string `envconfig:"FIELD_MAX_LENGTHS"`
+}
+
+// ExtractionConfig has the JSON paths of the well-known fields extracted from
+// the decrypted body. An empty path disables the extraction of its field.
+type ExtractionConfig struct {
+	// TimestampPath must point to a RFC 3339 timestamp.
+	TimestampPath  string `envconfig:"EXTRACT_TIMESTAMP_PATH"`
+	EntityTypePath string `envconfig:"EXTRACT_ENTITY_TYPE_PATH"`
+	VersionPath    string `envconfig:"EXTRACT_VERSION_PATH"`
+	// PartitionKeyPath, like "target_data.account_id", keeps the notifications
+	// of the same account in the same partition of the publishers.
+	PartitionKeyPath string `envconfig:"EXTRACT_PARTITION_KEY_PATH"`
+}
+
+// SelfTestConfig defines the startup check verifying and decoding a sample
+// body, failing fast when the keys are misconfigured.
+type SelfTestConfig struct {
+	Enabled bool `envconfig:"SELF_TEST_ENABLED" default:"false"`
+	// SamplePath is a file with a signed and encrypted body. When empty, the
+	// sample is generated and signed with the key in SigningKeyPath.
+	SamplePath     string `envconfig:"SELF_TEST_SAMPLE_PATH"`
+	SigningKeyPath string `envconfig:"SELF_TEST_SIGNING_KEY_PATH"`
+}
+
+// ImportConfig defines the disaster recovery import, which sends the raw
+// envelopes of a replay file and exits, instead of starting the API.
+type ImportConfig struct {
+	// File has a JSON object per line, with the event_id, event_type and
+	// encrypted_body. The import is disabled when empty.
+	File        string `envconfig:"IMPORT_FILE"`
+	Concurrency int    `envconfig:"IMPORT_CONCURRENCY" default:"4"`
+	// SkipDuplicates sends only the first record of each event id.
+	SkipDuplicates bool `envconfig:"IMPORT_SKIP_DUPLICATES" default:"true"`
+}
+
+// PackConfig defines the internal endpoint signing and encrypting cleartext
+// payloads, to relay them to another webhook consumer. It requires the admin
+// API.
+type PackConfig struct {
+	Enabled        bool   `envconfig:"PACK_ENABLED" default:"false"`
+	SigningKeyPath string `envconfig:"PACK_SIGNING_KEY_PATH"`
+	// EncryptionKeyPath is the public key of the receiving consumer.
+	EncryptionKeyPath string `envconfig:"PACK_ENCRYPTION_KEY_PATH"`
+}
+
+// DecryptLimits bounds the cost of decrypting a notification, checked before
+// the expensive work. A zero limit is disabled.
+type DecryptLimits struct {
+	// MaxCiphertextSize is the maximum size of the encrypted payload, once
+	// the signature is verified. It also bounds the decompressed size in
+	// memory, since DEFLATE expands at most 1032 times.
+	MaxCiphertextSize int `envconfig:"DECRYPT_MAX_CIPHERTEXT_SIZE" default:"524288"`
+	// MaxDecompressedSize is the maximum size of the decrypted payload.
+	MaxDecompressedSize int `envconfig:"DECRYPT_MAX_DECOMPRESSED_SIZE" default:"1048576"`
+	// AllowCompression accepts the payloads compressed with "zip", which are
+	// inflated before MaxDecompressedSize can be checked.
+	AllowCompression bool `envconfig:"DECRYPT_ALLOW_COMPRESSION" default:"false"`
+	// MaxPBES2Iterations is the maximum "p2c" accepted, since each iteration
+	// is computed before the payload is authenticated.
+	MaxPBES2Iterations int `envconfig:"PBES2_MAX_ITERATIONS" default:"310000"`
+}
+
+// PublishConfig bounds the time to answer Stone. A publish still running
+// after SoftDeadline is detached and answered with 202, and it goes on until
+// HardTimeout. The soft deadline is disabled when zero.
+type PublishConfig struct {
+	SoftDeadline time.Duration `envconfig:"PUBLISH_SOFT_DEADLINE" default:"0s"`
+	HardTimeout  time.Duration `envconfig:"PUBLISH_HARD_TIMEOUT" default:"30s"`
+}
+
+// RetryConfig retries the failed sends to each notifier, up to MaxAttempts
+// including the first one, with an exponential backoff from Backoff up to
+// MaxBackoff. Jitter, from 0 to 1, randomizes each delay by up to that
+// fraction.
+type RetryConfig struct {
+	MaxAttempts int           `envconfig:"RETRY_MAX_ATTEMPTS" default:"1"`
+	Backoff     time.Duration `envconfig:"RETRY_BACKOFF" default:"100ms"`
+	MaxBackoff  time.Duration `envconfig:"RETRY_MAX_BACKOFF" default:"5s"`
+	Jitter      float64       `envconfig:"RETRY_JITTER" default:"0.2"`
+}
+
+// DeadLetterConfig stores the notifications whose publish failed after all
+// the attempts, to be investigated and replayed.
+type DeadLetterConfig struct {
+	// Store is disabled when empty. Only file is available.
+	Store string `envconfig:"DEAD_LETTER_STORE"`
+}
+
+// IdempotencyConfig acks the redeliveries of the event ids already sent
+// within the window, without sending them again.
+type IdempotencyConfig struct {
+	// Store is memory or redis, and disabled when empty.
+	Store  string        `envconfig:"IDEMPOTENCY_STORE" default:"memory"`
+	Window time.Duration `envconfig:"IDEMPOTENCY_WINDOW" default:"24h"`
+}
+
+// NotificationStoreConfig records the delivery of the verified notifications,
+// to be listed and replayed by the admin API.
+type NotificationStoreConfig struct {
+	// Store is memory or redis, and disabled when empty.
+	Store string `envconfig:"NOTIFICATION_STORE"`
+	// Retention is how long a notification is kept after its last update.
+	Retention time.Duration `envconfig:"NOTIFICATION_STORE_RETENTION" default:"72h"`
+}
+
+// EventVersionConfig rejects the event types, like "payment.created.v2",
+// whose version isn't supported.
+type EventVersionConfig struct {
+	Check bool `envconfig:"EVENT_VERSION_CHECK" default:"false"`
+	// Pattern is a regular expression whose first group captures the
+	// "major" or "major.minor" version of the event type.
+	Pattern string `envconfig:"EVENT_VERSION_PATTERN" default:"[.]v([0-9]+([.][0-9]+)?)$"`
+	// MinVersion and MaxVersion are the supported range, inclusive. There's
+	// no upper bound when MaxVersion is empty.
+	MinVersion string `envconfig:"EVENT_VERSION_MIN" default:"1"`
+	MaxVersion string `envconfig:"EVENT_VERSION_MAX"`
+}
+
+// LagConfig defines the delivery lag, from the event creation timestamp, in
+// the API_TIMESTAMP_HEADER header or the EXTRACT_TIMESTAMP_PATH field, to its
+// processing.
+type LagConfig struct {
+	// WarnThreshold logs the notifications delivered later than it. It's
+	// disabled when zero.
+	WarnThreshold time.Duration `envconfig:"LAG_WARN_THRESHOLD" default:"0s"`
+}
+
+// TestModeConfig detects the test-mode notifications, which never reach the
+// notifiers in NOTIFIER_LIST. The detection is disabled when both rules are
+// empty.
+type TestModeConfig struct {
+	// Path is the JSON path of a boolean, true in the test-mode payloads.
+	Path string `envconfig:"TEST_MODE_PATH"`
+	// EventTypeSuffix, like ".test", marks the test-mode event types.
+	EventTypeSuffix string `envconfig:"TEST_MODE_EVENT_TYPE_SUFFIX"`
+	// NotifierList, separated by ';', receives the test-mode notifications.
+	// They are acked and dropped when empty.
+	NotifierList string `envconfig:"TEST_MODE_NOTIFIER_LIST"`
+}
+
+// AuthorizerConfig accepts only the notifications of the allowed tenants. It's
+// disabled, allowing all, when TenantPath is empty.
+type AuthorizerConfig struct {
+	// TenantPath is the JSON path of the tenant, or account, in the
+	// decrypted body. The payloads without it are rejected.
+	TenantPath string `envconfig:"AUTHORIZER_TENANT_PATH"`
+	// AllowedTenants are separated by ';'.
+	AllowedTenants string `envconfig:"AUTHORIZER_ALLOWED_TENANTS"`
+}
+
+// CallbackConfig confirms the processed notifications to a provider endpoint.
+// It's disabled when the URL is empty.
+type CallbackConfig struct {
+	URL string `envconfig:"PROVIDER_CALLBACK_URL"`
+	// Token is sent as a bearer token, when set.
+	Token   string        `envconfig:"PROVIDER_CALLBACK_TOKEN" redact:"true"`
+	Timeout time.Duration `envconfig:"PROVIDER_CALLBACK_TIMEOUT" default:"5s"`
+	// MaxAttempts, including the first one, with an exponential backoff
+	// starting at Backoff. The failures after the last attempt are logged.
+	MaxAttempts int           `envconfig:"PROVIDER_CALLBACK_MAX_ATTEMPTS" default:"5"`
+	Backoff     time.Duration `envconfig:"PROVIDER_CALLBACK_BACKOFF" default:"1s"`
+	QueueSize   int           `envconfig:"PROVIDER_CALLBACK_QUEUE_SIZE" default:"1000"`
+	Concurrency int           `envconfig:"PROVIDER_CALLBACK_CONCURRENCY" default:"4"`
+}
+
+// QuarantineConfig acks the redeliveries of the event ids that failed
+// Threshold times, so a poison notification isn't retried forever. It's
+// disabled when the threshold is zero.
+type QuarantineConfig struct {
+	Threshold int `envconfig:"QUARANTINE_THRESHOLD" default:"0"`
+	// MaxEvents bounds the event ids tracked in memory, forgetting the least
+	// recently failed ones.
+	MaxEvents int           `envconfig:"QUARANTINE_MAX_EVENTS" default:"10000"`
+	TTL       time.Duration `envconfig:"QUARANTINE_TTL" default:"24h"`
+}
+
+// SheddingConfig rejects, before any crypto work, the requests of the client
+// IPs that failed the verification Threshold consecutive times, and of all
+// the clients after GlobalThreshold consecutive failures. Each is disabled
+// when its threshold is zero.
+type SheddingConfig struct {
+	Threshold       int `envconfig:"SHEDDING_THRESHOLD" default:"0"`
+	GlobalThreshold int `envconfig:"SHEDDING_GLOBAL_THRESHOLD" default:"0"`
+	// Duration is how long the requests are shed.
+	Duration time.Duration `envconfig:"SHEDDING_DURATION" default:"1m"`
+	// MaxSources bounds the client IPs tracked in memory, forgetting the least
+	// recently failed ones.
+	MaxSources int `envconfig:"SHEDDING_MAX_SOURCES" default:"10000"`
+}
+
+// RateLimitConfig limits the requests to the notification routes with token
+// buckets, one per client IP and a global one. Each limit is disabled when
+// its rate is 0.
+type RateLimitConfig struct {
+	// Rate is the requests per second of each client IP, up to Burst at once.
+	Rate  float64 `envconfig:"RATE_LIMIT_RATE" default:"0"`
+	Burst int     `envconfig:"RATE_LIMIT_BURST" default:"20"`
+	// GlobalRate is the requests per second of all the clients, up to
+	// GlobalBurst at once.
+	GlobalRate  float64 `envconfig:"RATE_LIMIT_GLOBAL_RATE" default:"0"`
+	GlobalBurst int     `envconfig:"RATE_LIMIT_GLOBAL_BURST" default:"100"`
+	// MaxSources bounds the client IPs tracked in memory, forgetting the least
+	// recently seen ones.
+	MaxSources int `envconfig:"RATE_LIMIT_MAX_SOURCES" default:"10000"`
+}
+
+// EventTypeConfig normalizes the event type header before it's used, so the
+// filters, routes and metric labels are stable. It's a no-op by default.
+type EventTypeConfig struct {
+	Lowercase bool `envconfig:"EVENT_TYPE_LOWERCASE" default:"false"`
+	Trim      bool `envconfig:"EVENT_TYPE_TRIM" default:"false"`
+	// Separators has the characters replaced by Separator, like "-:/".
+	Separators string `envconfig:"EVENT_TYPE_SEPARATORS"`
+	Separator  string `envconfig:"EVENT_TYPE_SEPARATOR" default:"."`
+}
+
+// RelayConfig defines the verify-only mode of a relay that must not see the
+// plaintext: the signature is verified, but the inner JWE is sent to the
+// notifiers without being decrypted.
+type RelayConfig struct {
+	VerifyOnly bool `envconfig:"RELAY_VERIFY_ONLY" default:"false"`
+}
+
+// SerializerConfig defines the format of the messages published by the
+// notifiers.
+type SerializerConfig struct {
+	// Serializer is json, the decrypted body, or cloudevents, a CloudEvents
+	// JSON envelope.
+	Serializer        string `envconfig:"SERIALIZER" default:"json"`
+	CloudEventsSource string `envconfig:"CLOUDEVENTS_SOURCE" default:"webhook-consumer"`
+}
+
+// MetadataConfig adds the processing metadata to the published messages.
+type MetadataConfig struct {
+	// Fields, separated by ';', are received_at, processing_latency,
+	// instance_id and kid. Nothing is added when empty.
+	Fields string `envconfig:"METADATA_FIELDS"`
+	// Target is headers, or envelope for the extension attributes of the
+	// cloudevents serializer.
+	Target       string `envconfig:"METADATA_TARGET" default:"headers"`
+	HeaderPrefix string `envconfig:"METADATA_HEADER_PREFIX" default:"X-Webhook-Consumer-"`
+	// InstanceID identifies this consumer, the host name by default.
+	InstanceID string `envconfig:"METADATA_INSTANCE_ID"`
+}
+
+// TracingConfig exports the OpenTelemetry spans to a collector, with OTLP
+// over HTTP.
+type TracingConfig struct {
+	// Endpoint is the collector URL, like http://localhost:4318. The tracing
+	// is disabled when empty.
+	Endpoint string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// Headers are sent to the collector, as "name=value" items separated by
+	// ','. They usually have its credentials.
+	Headers     string `envconfig:"OTEL_EXPORTER_OTLP_HEADERS" redact:"true"`
+	ServiceName string `envconfig:"OTEL_SERVICE_NAME" default:"webhook-consumer"`
+	// SampleRatio is the fraction of the traces started here that are
+	// sampled. The traces of the callers follow their sampling decision.
+	SampleRatio float64 `envconfig:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
+}
+
+// InboundAuthConfig authenticates the clients of the notification routes,
+// before their bodies are read. Each check is disabled when empty.
+type InboundAuthConfig struct {
+	// ClientCAPath has the PEM CAs verifying the client certificates, which
+	// are then required. It requires the TLS of the public listener.
+	ClientCAPath string `envconfig:"INBOUND_CLIENT_CA_PATH"`
+	// ClientAllowedNames, separated by ';', restricts the client certificate
+	// common name or DNS names.
+	ClientAllowedNames string `envconfig:"INBOUND_CLIENT_ALLOWED_NAMES"`
+	// AllowedCIDRs, separated by ';', like "10.0.0.0/8;192.0.2.10", are the
+	// client addresses accepted.
+	AllowedCIDRs string `envconfig:"INBOUND_ALLOWED_CIDRS"`
+}
+
+// ClientIPConfig defines the client address of the inbound authentication,
+// the rate limits and the shedding.
+type ClientIPConfig struct {
+	// Header, like X-Forwarded-For, has the addresses appended by the trusted
+	// proxies. The peer address is used when empty.
+	Header string `envconfig:"CLIENT_IP_HEADER"`
+	// TrustedHops is the number of proxies appending to the header, so the
+	// client address is the TrustedHops-th from the right.
+	TrustedHops int `envconfig:"CLIENT_IP_TRUSTED_HOPS" default:"1"`
+}
+
+// MetricsConfig defines the metrics labels.
+type MetricsConfig struct {
+	// MaxEventTypes bounds the distinct event types in the metric labels,
+	// the next ones are labeled "other".
+	MaxEventTypes int `envconfig:"METRICS_MAX_EVENT_TYPES" default:"50"`
+}
+
+// AdminConfig defines the access to the admin API.
+type AdminConfig struct {
+	// Token must be sent as "Authorization: Bearer <token>". The admin API is
+	// disabled when empty.
+	Token string `envconfig:"ADMIN_TOKEN" redact:"true"`
+	// TailSize is the number of recent processed notifications kept to the
+	// tail endpoint.
+	TailSize int `envconfig:"ADMIN_TAIL_SIZE" default:"100"`
+}
+
+// MaintenanceConfig defines the maintenance mode, which can also be toggled
+// by the admin API.
+type MaintenanceConfig struct {
+	Enabled bool `envconfig:"MAINTENANCE_MODE" default:"false"`
+	// RetryAfter is sent in the Retry-After header of the rejected requests.
+	RetryAfter time.Duration `envconfig:"MAINTENANCE_RETRY_AFTER" default:"60s"`
+}
+
+// OrderingConfig serializes the notifications with the same key, extracted
+// from the decrypted body, while notifications with distinct keys are sent
+// concurrently.
+type OrderingConfig struct {
+	// KeyPath is a JSON path, like "target_data.account_id". Ordering is
+	// disabled when empty.
+	KeyPath string `envconfig:"ORDERING_KEY_PATH"`
+	// EventTypes, separated by ';', restricts the ordering to these event
+	// types. When empty, all the event types are ordered.
+	EventTypes string `envconfig:"ORDERING_EVENT_TYPES"`
+	// Policy is wait, holding the request until the key is free, or buffer,
+	// queueing the notification behind the key and answering 202.
+	Policy string `envconfig:"ORDERING_POLICY" default:"wait"`
+	// MaxWait bounds the wait for the key, besides the request deadline. It's
+	// only bounded by the request deadline when zero.
+	MaxWait time.Duration `envconfig:"ORDERING_MAX_WAIT" default:"0s"`
+	// BufferSize is the number of notifications queued for each key by the
+	// buffer policy.
+	BufferSize int `envconfig:"ORDERING_BUFFER_SIZE" default:"100"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -35,7 +656,35 @@ func LoadConfig() (*Config, error) {
 	return &config, nil
 }
 
+// String lists the config values by their environment variable names, in
+// order, with the secrets redacted like in /admin/config.
 func (cfg Config) String() string {
-	return fmt.Sprintf("port:[%d] shutdown_timeout:[%s] private_key_path:[%s] public_key_location:[%s] notifier_list:[%s]",
-		cfg.HTTPConfig.Port, cfg.HTTPConfig.ShutdownTimeout, cfg.PrivateKeyPath, cfg.PublicKeyLocation, cfg.NotifierList)
+	values := Redacted(cfg)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]string, len(names))
+	for i, name := range names {
+		items[i] = fmt.Sprintf("%s:[%s]", name, values[name])
+	}
+
+	return strings.Join(items, " ")
+}
+
+// SplitList splits a list separated by ';', ignoring the empty items.
+func SplitList(list string) []string {
+	result := []string{}
+	for _, item := range strings.Split(list, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	return result
 }
@@ -0,0 +1,73 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const RedactedValue = "****"
+
+// secretNames marks a field as secret even without the redact tag, so a new
+// secret field is not exposed by mistake.
+var secretNames = []string{"password", "secret", "token", "passphrase", "credential"}
+
+// Redacted returns the config values keyed by their environment variable
+// names. Fields tagged with `redact:"true"`, or named like a secret, have
+// their values replaced by RedactedValue.
+func Redacted(cfg interface{}) map[string]string {
+	result := map[string]string{}
+	redact(reflect.ValueOf(cfg), result)
+
+	return result
+}
+
+func redact(v reflect.Value, result map[string]string) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && field.Tag.Get("envconfig") == "" {
+			redact(value, result)
+			continue
+		}
+
+		name := field.Tag.Get("envconfig")
+		if name == "" {
+			name = field.Name
+		}
+
+		if isSecret(field) {
+			result[name] = RedactedValue
+			continue
+		}
+
+		result[name] = fmt.Sprint(value.Interface())
+	}
+}
+
+func isSecret(field reflect.StructField) bool {
+	if field.Tag.Get("redact") == "true" {
+		return true
+	}
+
+	name := strings.ToLower(field.Name)
+	for _, secretName := range secretNames {
+		if strings.Contains(name, secretName) {
+			return true
+		}
+	}
+
+	return false
+}
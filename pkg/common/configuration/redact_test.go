@@ -0,0 +1,83 @@
+package configuration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	type innerConfig struct {
+		Tagged   string `envconfig:"TAGGED" redact:"true"`
+		Password string `envconfig:"PASSWORD"`
+		Public   int    `envconfig:"PUBLIC"`
+	}
+
+	type config struct {
+		Inner        innerConfig
+		ClientSecret string `envconfig:"CLIENT_SECRET"`
+		Untagged     bool
+		unexported   string
+	}
+
+	cfg := config{
+		Inner: innerConfig{
+			Tagged:   "tagged-value",
+			Password: "password-value",
+			Public:   3000,
+		},
+		ClientSecret: "secret-value",
+		Untagged:     true,
+		unexported:   "unexported-value",
+	}
+
+	want := map[string]string{
+		"TAGGED":        RedactedValue,
+		"PASSWORD":      RedactedValue,
+		"PUBLIC":        "3000",
+		"CLIENT_SECRET": RedactedValue,
+		"Untagged":      "true",
+	}
+
+	if got := Redacted(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redacted() = %v, want %v", got, want)
+	}
+
+	if got := Redacted(&cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redacted() with pointer = %v, want %v", got, want)
+	}
+}
+
+func TestRedacted_Config(t *testing.T) {
+	cfg := Config{AdminConfig: AdminConfig{Token: "admin-token"}}
+
+	got := Redacted(cfg)
+	if got["ADMIN_TOKEN"] != RedactedValue {
+		t.Errorf("Redacted() ADMIN_TOKEN = %v, want %v", got["ADMIN_TOKEN"], RedactedValue)
+	}
+	for name, value := range got {
+		if value == "admin-token" {
+			t.Errorf("Redacted() %s has a secret value", name)
+		}
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	cfg := Config{
+		NotifierList: "proxy;redis",
+		AdminConfig:  AdminConfig{Token: "admin-token"},
+	}
+
+	got := cfg.String()
+	for _, want := range []string{"NOTIFIER_LIST:[proxy;redis]", "ADMIN_TOKEN:[" + RedactedValue + "]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %s, want %s", got, want)
+		}
+	}
+	if strings.Contains(got, "admin-token") {
+		t.Errorf("String() = %s, has a secret value", got)
+	}
+	if got != cfg.String() {
+		t.Error("String() isn't in a stable order")
+	}
+}
@@ -0,0 +1,60 @@
+package eventtype
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// Normalizer canonicalizes the event types, like "Payment-Created " to
+// "payment.created". A nil normalizer keeps them as received.
+type Normalizer struct {
+	lowercase bool
+	trim      bool
+	// separators replaces each separator with the canonical one.
+	separators *strings.Replacer
+}
+
+// New returns nil when the normalization is a no-op.
+func New(cfg configuration.EventTypeConfig) (*Normalizer, error) {
+	if !cfg.Lowercase && !cfg.Trim && cfg.Separators == "" {
+		return nil, nil
+	}
+
+	n := &Normalizer{lowercase: cfg.Lowercase, trim: cfg.Trim}
+
+	if cfg.Separators != "" {
+		if cfg.Separator == "" {
+			return nil, fmt.Errorf("the event type separators require the canonical separator")
+		}
+
+		pairs := []string{}
+		for _, separator := range cfg.Separators {
+			pairs = append(pairs, string(separator), cfg.Separator)
+		}
+		n.separators = strings.NewReplacer(pairs...)
+	}
+
+	return n, nil
+}
+
+// Normalize trims, lowercases and canonicalizes the separators of the event
+// type, as configured.
+func (n *Normalizer) Normalize(eventType string) string {
+	if n == nil {
+		return eventType
+	}
+
+	if n.trim {
+		eventType = strings.TrimSpace(eventType)
+	}
+	if n.lowercase {
+		eventType = strings.ToLower(eventType)
+	}
+	if n.separators != nil {
+		eventType = n.separators.Replace(eventType)
+	}
+
+	return eventType
+}
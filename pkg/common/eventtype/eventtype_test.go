@@ -0,0 +1,63 @@
+package eventtype
+
+import (
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	all := configuration.EventTypeConfig{Lowercase: true, Trim: true, Separators: "-:", Separator: "."}
+
+	tests := []struct {
+		name      string
+		cfg       configuration.EventTypeConfig
+		eventType string
+		want      string
+	}{
+		{name: "No-op by default", eventType: " Payment-Created ", want: " Payment-Created "},
+		{name: "Lowercase", cfg: configuration.EventTypeConfig{Lowercase: true}, eventType: "Payment.Created", want: "payment.created"},
+		{name: "Trim", cfg: configuration.EventTypeConfig{Trim: true}, eventType: "\tpayment.created ", want: "payment.created"},
+		{name: "Separators", cfg: configuration.EventTypeConfig{Separators: "-:", Separator: "."}, eventType: "payment-created:v2", want: "payment.created.v2"},
+		{name: "Underscores are kept", cfg: all, eventType: "Cash_In-Internal_Transfer", want: "cash_in.internal_transfer"},
+		{name: "Mixed case and separators", cfg: all, eventType: " PAYMENT:Created ", want: "payment.created"},
+		{name: "Already normalized", cfg: all, eventType: "payment.created", want: "payment.created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := n.Normalize(tt.eventType); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.EventTypeConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "Disabled", cfg: configuration.EventTypeConfig{Separator: "."}, wantNil: true},
+		{name: "Lowercase", cfg: configuration.EventTypeConfig{Lowercase: true}},
+		{name: "Separators without the canonical one", cfg: configuration.EventTypeConfig{Separators: "-"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.wantNil {
+				t.Errorf("New() = %v, want nil %t", got, tt.wantNil)
+			}
+		})
+	}
+}
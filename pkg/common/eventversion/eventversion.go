@@ -0,0 +1,126 @@
+package eventversion
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+var (
+	// ErrUnparseable is returned when the event type has no version.
+	ErrUnparseable = errors.New("event type without a version")
+	// ErrUnsupported is returned when the version is out of the supported
+	// range.
+	ErrUnsupported = errors.New("unsupported event type version")
+)
+
+// Version is a "major" or "major.minor" version, like "2" or "2.1".
+type Version struct {
+	Major int
+	Minor int
+}
+
+// Parse parses a version, with an optional "v" prefix.
+func Parse(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > 2 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var numbers [2]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1]}, nil
+}
+
+// Less reports whether v is older than other.
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+
+	return v.Minor < other.Minor
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Policy accepts the event types with a version in the supported range. A nil
+// policy accepts all the event types.
+type Policy struct {
+	pattern *regexp.Regexp
+	min     Version
+	// max is nil when there's no upper bound.
+	max *Version
+}
+
+// New returns the policy, or nil when the check is disabled.
+func New(cfg configuration.EventVersionConfig) (*Policy, error) {
+	if !cfg.Check {
+		return nil, nil
+	}
+
+	pattern, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event version pattern: %v", err)
+	}
+	if pattern.NumSubexp() < 1 {
+		return nil, fmt.Errorf("the event version pattern must capture the version")
+	}
+
+	policy := &Policy{pattern: pattern}
+
+	if cfg.MinVersion != "" {
+		if policy.min, err = Parse(cfg.MinVersion); err != nil {
+			return nil, fmt.Errorf("invalid minimum event version: %v", err)
+		}
+	}
+
+	if cfg.MaxVersion != "" {
+		max, err := Parse(cfg.MaxVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum event version: %v", err)
+		}
+		if max.Less(policy.min) {
+			return nil, fmt.Errorf("the maximum event version %s is lower than the minimum %s", max, policy.min)
+		}
+		policy.max = &max
+	}
+
+	return policy, nil
+}
+
+// Check fails when the event type has no version, or when it's out of the
+// supported range.
+func (p *Policy) Check(eventType string) error {
+	if p == nil {
+		return nil
+	}
+
+	match := p.pattern.FindStringSubmatch(eventType)
+	if match == nil || match[1] == "" {
+		return fmt.Errorf("%w: %s", ErrUnparseable, eventType)
+	}
+
+	version, err := Parse(match[1])
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrUnparseable, eventType, err)
+	}
+
+	if version.Less(p.min) || (p.max != nil && p.max.Less(version)) {
+		return fmt.Errorf("%w: %s", ErrUnsupported, eventType)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package eventversion
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+const defaultPattern = "[.]v([0-9]+([.][0-9]+)?)$"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Version
+		wantErr bool
+	}{
+		{version: "2", want: Version{Major: 2}},
+		{version: "v2", want: Version{Major: 2}},
+		{version: "2.1", want: Version{Major: 2, Minor: 1}},
+		{version: "2.1.0", wantErr: true},
+		{version: "v", wantErr: true},
+		{version: "two", wantErr: true},
+		{version: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := Parse(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        configuration.EventVersionConfig
+		wantPolicy bool
+		wantErr    bool
+	}{
+		{
+			name: "Disabled",
+			cfg:  configuration.EventVersionConfig{Pattern: "("},
+		},
+		{
+			name:       "Enabled",
+			cfg:        configuration.EventVersionConfig{Check: true, Pattern: defaultPattern, MinVersion: "1", MaxVersion: "2"},
+			wantPolicy: true,
+		},
+		{
+			name:    "Invalid pattern",
+			cfg:     configuration.EventVersionConfig{Check: true, Pattern: "("},
+			wantErr: true,
+		},
+		{
+			name:    "Pattern without a capture",
+			cfg:     configuration.EventVersionConfig{Check: true, Pattern: "[.]v[0-9]+$"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid minimum",
+			cfg:     configuration.EventVersionConfig{Check: true, Pattern: defaultPattern, MinVersion: "one"},
+			wantErr: true,
+		},
+		{
+			name:    "Maximum lower than the minimum",
+			cfg:     configuration.EventVersionConfig{Check: true, Pattern: defaultPattern, MinVersion: "2", MaxVersion: "1.9"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got != nil) != tt.wantPolicy {
+				t.Errorf("New() = %v, wantPolicy %v", got, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy, err := New(configuration.EventVersionConfig{Check: true, Pattern: defaultPattern, MinVersion: "1", MaxVersion: "2.1"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		eventType string
+		wantErr   error
+	}{
+		{eventType: "payment.created.v1"},
+		{eventType: "payment.created.v2"},
+		{eventType: "payment.created.v2.1"},
+		{eventType: "payment.created.v2.2", wantErr: ErrUnsupported},
+		{eventType: "payment.created.v3", wantErr: ErrUnsupported},
+		{eventType: "payment.created.v0", wantErr: ErrUnsupported},
+		{eventType: "payment.created", wantErr: ErrUnparseable},
+		{eventType: "payment.created.vnext", wantErr: ErrUnparseable},
+		{eventType: "payment.created.v99999999999999999999", wantErr: ErrUnparseable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			err := policy.Check(tt.eventType)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check_Nil(t *testing.T) {
+	var policy *Policy
+	if err := policy.Check("payment.created"); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,53 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Lookup returns the value in the dot separated path, like "target_data.id".
+// Numbers are kept as json.Number to not lose precision.
+func Lookup(data []byte, path string) (interface{}, bool) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	for _, field := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[field]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// LookupString returns the scalar value in the path as a string. Null values,
+// objects and arrays are not found.
+func LookupString(data []byte, path string) (string, bool) {
+	value, ok := Lookup(data, path)
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,80 @@
+package jsonpath
+
+import "testing"
+
+func TestLookupString(t *testing.T) {
+	body := []byte(`{"id":"1","target_data":{"account_id":"09c016b2","amount":12345678901234567890,"ledger_id":9007199254740993,"rate":0.123456789012345678901234,"active":true,"tags":["a"],"note":null}}`)
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "Top-level string",
+			path:   "id",
+			want:   "1",
+			wantOk: true,
+		},
+		{
+			name:   "Nested string",
+			path:   "target_data.account_id",
+			want:   "09c016b2",
+			wantOk: true,
+		},
+		{
+			name:   "Large number keeps precision",
+			path:   "target_data.amount",
+			want:   "12345678901234567890",
+			wantOk: true,
+		},
+		{
+			name:   "64-bit integer above the float64 precision",
+			path:   "target_data.ledger_id",
+			want:   "9007199254740993",
+			wantOk: true,
+		},
+		{
+			name:   "High-precision decimal",
+			path:   "target_data.rate",
+			want:   "0.123456789012345678901234",
+			wantOk: true,
+		},
+		{
+			name:   "Boolean",
+			path:   "target_data.active",
+			want:   "true",
+			wantOk: true,
+		},
+		{
+			name: "Array is not a scalar",
+			path: "target_data.tags",
+		},
+		{
+			name: "Null is not found",
+			path: "target_data.note",
+		},
+		{
+			name: "Missing field",
+			path: "target_data.missing",
+		},
+		{
+			name: "Path through a scalar",
+			path: "id.missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LookupString(body, tt.path)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("LookupString() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	if _, ok := LookupString([]byte("not a json"), "id"); ok {
+		t.Errorf("LookupString() must not find values in an invalid JSON")
+	}
+}
@@ -0,0 +1,319 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+)
+
+const (
+	numberPrecision = 256
+	maxCount        = 1 << 30
+)
+
+// ignoredKeywords are the annotations, which don't validate anything. The
+// "format" isn't one of them, since it's expected to be checked.
+var ignoredKeywords = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+}
+
+var validTypes = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"string":  true,
+	"integer": true,
+}
+
+// Schema is a compiled JSON Schema. Only a subset of the keywords is
+// supported, without references, and a schema with any other keyword fails to
+// compile, so nothing is silently left unchecked. The numbers are compared
+// without a float64 conversion.
+type Schema struct {
+	// always is the result of the boolean schemas, true and false.
+	always *bool
+
+	types                []string
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	items                *Schema
+	enum                 []interface{}
+	constValue           interface{}
+	hasConst             bool
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minimum              *bound
+	maximum              *bound
+	exclusiveMinimum     *bound
+	exclusiveMaximum     *bound
+	minItems             *int
+	maxItems             *int
+	allOf                []*Schema
+	anyOf                []*Schema
+	oneOf                []*Schema
+	not                  *Schema
+}
+
+// bound is a number of the schema, with its text as written for the errors.
+type bound struct {
+	value *big.Float
+	text  string
+}
+
+// Error is a failed keyword, at the path of the value, like
+// "$.target_data.amount" or "$.items[0]". The message never has the value,
+// only the constraint, so it can be logged and answered.
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e Error) String() string {
+	return e.Path + ": " + e.Message
+}
+
+// Compile parses the JSON Schema.
+func Compile(data []byte) (*Schema, error) {
+	value, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the schema: %v", err)
+	}
+
+	return compile(value, "$")
+}
+
+// Validate decodes the JSON document and returns the errors of all the failed
+// keywords. The error is returned when the document isn't valid JSON.
+func (s *Schema) Validate(data []byte) ([]Error, error) {
+	value, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.validate(value, "$"), nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return value, nil
+}
+
+func compile(value interface{}, at string) (*Schema, error) {
+	if always, ok := value.(bool); ok {
+		return &Schema{always: &always}, nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: the schema must be an object or a boolean", at)
+	}
+
+	s := &Schema{}
+	keywords := make([]string, 0, len(object))
+	for keyword := range object {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		if err := s.compileKeyword(keyword, object[keyword], at); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Schema) compileKeyword(keyword string, value interface{}, at string) error {
+	var err error
+	switch keyword {
+	case "type":
+		s.types, err = compileTypes(value)
+	case "properties":
+		s.properties, err = compileProperties(value, at)
+	case "required":
+		s.required, err = compileStrings(value)
+	case "additionalProperties":
+		s.additionalProperties, err = compile(value, at+".additionalProperties")
+	case "items":
+		s.items, err = compile(value, at+".items")
+	case "enum":
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			err = fmt.Errorf("must be a non-empty array")
+		}
+		s.enum = list
+	case "const":
+		s.constValue, s.hasConst = value, true
+	case "minLength":
+		s.minLength, err = compileCount(value)
+	case "maxLength":
+		s.maxLength, err = compileCount(value)
+	case "minItems":
+		s.minItems, err = compileCount(value)
+	case "maxItems":
+		s.maxItems, err = compileCount(value)
+	case "pattern":
+		text, ok := value.(string)
+		if !ok {
+			err = fmt.Errorf("must be a string")
+			break
+		}
+		s.pattern, err = regexp.Compile(text)
+	case "minimum":
+		s.minimum, err = compileNumber(value)
+	case "maximum":
+		s.maximum, err = compileNumber(value)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = compileNumber(value)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = compileNumber(value)
+	case "allOf":
+		s.allOf, err = compileList(value, at+".allOf")
+	case "anyOf":
+		s.anyOf, err = compileList(value, at+".anyOf")
+	case "oneOf":
+		s.oneOf, err = compileList(value, at+".oneOf")
+	case "not":
+		s.not, err = compile(value, at+".not")
+	default:
+		if !ignoredKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword %q", at, keyword)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: invalid %s: %v", at, keyword, err)
+	}
+
+	return nil
+}
+
+func compileTypes(value interface{}) ([]string, error) {
+	if text, ok := value.(string); ok {
+		value = []interface{}{text}
+	}
+
+	types, err := compileStrings(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range types {
+		if !validTypes[name] {
+			return nil, fmt.Errorf("unknown type %q", name)
+		}
+	}
+
+	return types, nil
+}
+
+func compileProperties(value interface{}, at string) (map[string]*Schema, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an object")
+	}
+
+	properties := map[string]*Schema{}
+	for name, property := range object {
+		schema, err := compile(property, at+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = schema
+	}
+
+	return properties, nil
+}
+
+func compileStrings(value interface{}) ([]string, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+
+	texts := make([]string, 0, len(list))
+	for _, item := range list {
+		text, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of strings")
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, nil
+}
+
+func compileList(value interface{}, at string) ([]*Schema, error) {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("must be a non-empty array of schemas")
+	}
+
+	schemas := make([]*Schema, 0, len(list))
+	for i, item := range list {
+		schema, err := compile(item, fmt.Sprintf("%s[%d]", at, i))
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+func compileCount(value interface{}) (*int, error) {
+	number, ok := toNumber(value)
+	if !ok || !number.IsInt() || number.Sign() < 0 {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+
+	count, accuracy := number.Int64()
+	if accuracy != big.Exact || count > maxCount {
+		return nil, fmt.Errorf("must be at most %d", maxCount)
+	}
+
+	n := int(count)
+	return &n, nil
+}
+
+func compileNumber(value interface{}) (*bound, error) {
+	number, ok := toNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+
+	return &bound{value: number, text: value.(json.Number).String()}, nil
+}
+
+// toNumber converts the JSON number. The precision is enough for the 64-bit
+// integers and the usual decimals, and a huge exponent isn't expanded.
+func toNumber(value interface{}) (*big.Float, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+
+	f, _, err := big.ParseFloat(number.String(), 10, numberPrecision, big.ToNearestEven)
+	return f, err == nil
+}
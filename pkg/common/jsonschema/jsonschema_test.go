@@ -0,0 +1,156 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "Object", schema: `{"$schema":"http://json-schema.org/draft-07/schema#","title":"t","type":"object","properties":{"id":{"type":"string","description":"uuid"}},"required":["id"]}`},
+		{name: "Unchecked format", schema: `{"properties":{"id":{"type":"string","format":"uuid"}}}`, wantErr: true},
+		{name: "Boolean", schema: `true`},
+		{name: "Not a schema", schema: `[]`, wantErr: true},
+		{name: "Invalid JSON", schema: `{`, wantErr: true},
+		{name: "Unsupported keyword", schema: `{"$ref":"#/definitions/id"}`, wantErr: true},
+		{name: "Nested unsupported keyword", schema: `{"properties":{"id":{"dependencies":{}}}}`, wantErr: true},
+		{name: "Unknown type", schema: `{"type":"text"}`, wantErr: true},
+		{name: "Negative length", schema: `{"minLength":-1}`, wantErr: true},
+		{name: "Fractional length", schema: `{"maxItems":1.5}`, wantErr: true},
+		{name: "Invalid pattern", schema: `{"pattern":"["}`, wantErr: true},
+		{name: "Empty enum", schema: `{"enum":[]}`, wantErr: true},
+		{name: "Invalid minimum", schema: `{"minimum":"0"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := Compile([]byte(`{
+		"type": "object",
+		"required": ["id", "amount"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "minLength": 2, "maxLength": 8, "pattern": "^[a-z0-9]+$"},
+			"amount": {"type": "integer", "minimum": 0, "exclusiveMaximum": 9007199254740993},
+			"rate": {"type": "number", "exclusiveMinimum": 0, "maximum": 1},
+			"status": {"enum": ["pending", "settled", 1]},
+			"kind": {"const": "transfer"},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+			"note": {"type": ["string", "null"]},
+			"target": {"oneOf": [{"required": ["account_id"]}, {"required": ["pix_key"]}]},
+			"origin": {"anyOf": [{"type": "string"}, {"type": "object"}], "not": {"const": ""}},
+			"extra": {"allOf": [{"type": "object"}, {"required": ["a"]}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		document string
+		want     []Error
+		wantErr  bool
+	}{
+		{
+			name:     "Valid",
+			document: `{"id":"ab1","amount":9007199254740992,"rate":0.5,"status":1.0,"kind":"transfer","tags":["a"],"note":null,"target":{"account_id":"1"},"origin":"x","extra":{"a":1}}`,
+		},
+		{
+			name:     "Missing required",
+			document: `{}`,
+			want: []Error{
+				{Path: "$", Message: "missing required property id"},
+				{Path: "$", Message: "missing required property amount"},
+			},
+		},
+		{
+			name:     "Large integer compared exactly",
+			document: `{"id":"ab","amount":9007199254740993}`,
+			want:     []Error{{Path: "$.amount", Message: "must be less than 9007199254740993"}},
+		},
+		{
+			name:     "Wrong types",
+			document: `{"id":1,"amount":1.5,"note":true}`,
+			want: []Error{
+				{Path: "$.amount", Message: "must be of type integer"},
+				{Path: "$.id", Message: "must be of type string"},
+				{Path: "$.note", Message: "must be of type string or null"},
+			},
+		},
+		{
+			name:     "String constraints",
+			document: `{"id":"ABCDEFGHIJ","amount":-1}`,
+			want: []Error{
+				{Path: "$.amount", Message: "must be at least 0"},
+				{Path: "$.id", Message: "must have at most 8 characters"},
+				{Path: "$.id", Message: "must match the pattern ^[a-z0-9]+$"},
+			},
+		},
+		{
+			name:     "Enum, const and numbers",
+			document: `{"id":"ab","amount":0,"rate":0,"status":"failed","kind":"pix"}`,
+			want: []Error{
+				{Path: "$.kind", Message: "must be the const value"},
+				{Path: "$.rate", Message: "must be greater than 0"},
+				{Path: "$.status", Message: "must be one of the enum values"},
+			},
+		},
+		{
+			name:     "Arrays",
+			document: `{"id":"ab","amount":0,"tags":["a",2,"c"]}`,
+			want: []Error{
+				{Path: "$.tags", Message: "must have at most 2 items"},
+				{Path: "$.tags[1]", Message: "must be of type string"},
+			},
+		},
+		{
+			name:     "Combinations",
+			document: `{"id":"ab","amount":0,"target":{"account_id":"1","pix_key":"k"},"origin":"","extra":{}}`,
+			want: []Error{
+				{Path: "$.extra", Message: "missing required property a"},
+				{Path: "$.origin", Message: "must not match the not schema"},
+				{Path: "$.target", Message: "must match exactly one of the oneOf schemas, matched 2"},
+			},
+		},
+		{
+			name:     "Additional property",
+			document: `{"id":"ab","amount":0,"unknown":1}`,
+			want:     []Error{{Path: "$.unknown", Message: "no value is allowed"}},
+		},
+		{
+			name:     "Invalid JSON",
+			document: `{"id":`,
+			wantErr:  true,
+		},
+		{
+			name:     "Trailing data",
+			document: `{"id":"ab","amount":0} {}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Validate([]byte(tt.document))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func (s *Schema) validate(value interface{}, at string) []Error {
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []Error{{Path: at, Message: "no value is allowed"}}
+	}
+
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: at, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !hasType(value, s.types) {
+		fail("must be of type %s", strings.Join(s.types, " or "))
+		// The other keywords would only repeat the type mismatch.
+		return errs
+	}
+
+	if len(s.enum) > 0 && !contains(s.enum, value) {
+		fail("must be one of the enum values")
+	}
+	if s.hasConst && !equal(s.constValue, value) {
+		fail("must be the const value")
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			fail("must have at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			fail("must have at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match the pattern %s", s.pattern)
+		}
+	case json.Number:
+		number, ok := toNumber(v)
+		if !ok {
+			break
+		}
+		if s.minimum != nil && number.Cmp(s.minimum.value) < 0 {
+			fail("must be at least %s", s.minimum.text)
+		}
+		if s.maximum != nil && number.Cmp(s.maximum.value) > 0 {
+			fail("must be at most %s", s.maximum.text)
+		}
+		if s.exclusiveMinimum != nil && number.Cmp(s.exclusiveMinimum.value) <= 0 {
+			fail("must be greater than %s", s.exclusiveMinimum.text)
+		}
+		if s.exclusiveMaximum != nil && number.Cmp(s.exclusiveMaximum.value) >= 0 {
+			fail("must be less than %s", s.exclusiveMaximum.text)
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				errs = append(errs, s.items.validate(item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(v, at)...)
+	}
+
+	for _, schema := range s.allOf {
+		errs = append(errs, schema.validate(value, at)...)
+	}
+	if len(s.anyOf) > 0 && countValid(s.anyOf, value, at) == 0 {
+		fail("must match at least one of the anyOf schemas")
+	}
+	if len(s.oneOf) > 0 {
+		if matched := countValid(s.oneOf, value, at); matched != 1 {
+			fail("must match exactly one of the oneOf schemas, matched %d", matched)
+		}
+	}
+	if s.not != nil && len(s.not.validate(value, at)) == 0 {
+		fail("must not match the not schema")
+	}
+
+	return errs
+}
+
+// validateObject checks the properties in order of their names, so the errors
+// are always in the same order.
+func (s *Schema) validateObject(object map[string]interface{}, at string) []Error {
+	var errs []Error
+
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			errs = append(errs, Error{Path: at, Message: fmt.Sprintf("missing required property %s", name)})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if schema, ok := s.properties[name]; ok {
+			errs = append(errs, schema.validate(object[name], at+"."+name)...)
+			continue
+		}
+		if s.additionalProperties != nil {
+			errs = append(errs, s.additionalProperties.validate(object[name], at+"."+name)...)
+		}
+	}
+
+	return errs
+}
+
+func countValid(schemas []*Schema, value interface{}, at string) int {
+	valid := 0
+	for _, schema := range schemas {
+		if len(schema.validate(value, at)) == 0 {
+			valid++
+		}
+	}
+
+	return valid
+}
+
+func hasType(value interface{}, types []string) bool {
+	for _, name := range types {
+		switch v := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case json.Number:
+			if name == "number" {
+				return true
+			}
+			if name == "integer" {
+				if number, ok := toNumber(v); ok && number.IsInt() {
+					return true
+				}
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func contains(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if equal(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares the JSON values, with the numbers compared by value, so 1
+// and 1.0 are equal.
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		nx, okx := toNumber(x)
+		ny, oky := toNumber(y)
+		return okx && oky && nx.Cmp(ny) == 0
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for name, value := range x {
+			other, ok := y[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
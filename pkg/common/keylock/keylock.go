@@ -0,0 +1,58 @@
+package keylock
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedLock is a mutex per key. Different keys don't block each other, and
+// the locks are released from memory when nobody is using them.
+type KeyedLock struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+type entry struct {
+	ch   chan struct{}
+	refs int
+}
+
+func New() *KeyedLock {
+	return &KeyedLock{
+		locks: map[string]*entry{},
+	}
+}
+
+// Lock waits until the key is free, or the context is done. The returned
+// function must be called to release the key.
+func (k *KeyedLock) Lock(ctx context.Context, key string) (func(), error) {
+	k.mu.Lock()
+	e, ok := k.locks[key]
+	if !ok {
+		e = &entry{ch: make(chan struct{}, 1)}
+		k.locks[key] = e
+	}
+	e.refs++
+	k.mu.Unlock()
+
+	select {
+	case e.ch <- struct{}{}:
+		return func() {
+			<-e.ch
+			k.release(key, e)
+		}, nil
+	case <-ctx.Done():
+		k.release(key, e)
+		return nil, ctx.Err()
+	}
+}
+
+func (k *KeyedLock) release(key string, e *entry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	e.refs--
+	if e.refs == 0 {
+		delete(k.locks, key)
+	}
+}
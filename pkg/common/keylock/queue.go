@@ -0,0 +1,120 @@
+package keylock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// drainInterval is how often Drain checks the keys.
+const drainInterval = 10 * time.Millisecond
+
+var ErrQueueFull = errors.New("queue of the key is full")
+
+// Queue runs the work of each key in order, one at a time, queueing it when
+// the key is busy instead of blocking the caller. Each key queues at most
+// size works, besides the one running.
+type Queue struct {
+	size int
+
+	mu   sync.Mutex
+	keys map[string]*keyQueue
+}
+
+type keyQueue struct {
+	pending []func(release func())
+}
+
+func NewQueue(size int) *Queue {
+	return &Queue{
+		size: size,
+		keys: map[string]*keyQueue{},
+	}
+}
+
+// Run calls fn right away, in the caller goroutine, when the key is free, or
+// queues it to be called in its own goroutine after the previous ones of the
+// key. fn must call release once its work is done, which may be after it
+// returns. Run returns true when fn was queued.
+func (q *Queue) Run(key string, fn func(queued bool, release func())) (bool, error) {
+	q.mu.Lock()
+	kq, busy := q.keys[key]
+	if !busy {
+		kq = &keyQueue{}
+		q.keys[key] = kq
+		q.mu.Unlock()
+
+		fn(false, q.releaser(key, kq))
+		return false, nil
+	}
+
+	if len(kq.pending) >= q.size {
+		q.mu.Unlock()
+		return false, ErrQueueFull
+	}
+
+	kq.pending = append(kq.pending, func(release func()) { fn(true, release) })
+	q.mu.Unlock()
+
+	return true, nil
+}
+
+// Len returns the number of works queued for the key, besides the running
+// one.
+func (q *Queue) Len(key string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if kq, ok := q.keys[key]; ok {
+		return len(kq.pending)
+	}
+	return 0
+}
+
+// Drain waits until the works of all the keys are done, or the context is
+// done.
+func (q *Queue) Drain(ctx context.Context) error {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		q.mu.Lock()
+		busy := len(q.keys)
+		q.mu.Unlock()
+
+		if busy == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%d keys still have queued works: %w", busy, ctx.Err())
+		}
+	}
+}
+
+// releaser starts the next work of the key, or frees the key. Releasing more
+// than once has no effect.
+func (q *Queue) releaser(key string, kq *keyQueue) func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			if len(kq.pending) == 0 {
+				delete(q.keys, key)
+				q.mu.Unlock()
+				return
+			}
+
+			next := kq.pending[0]
+			kq.pending = kq.pending[1:]
+			q.mu.Unlock()
+
+			go next(q.releaser(key, kq))
+		})
+	}
+}
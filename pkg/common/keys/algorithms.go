@@ -0,0 +1,188 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+var signatureAlgorithms = map[jose.SignatureAlgorithm]bool{
+	jose.EdDSA: true,
+	jose.HS256: true,
+	jose.HS384: true,
+	jose.HS512: true,
+	jose.RS256: true,
+	jose.RS384: true,
+	jose.RS512: true,
+	jose.ES256: true,
+	jose.ES384: true,
+	jose.ES512: true,
+	jose.PS256: true,
+	jose.PS384: true,
+	jose.PS512: true,
+}
+
+var keyAlgorithms = map[jose.KeyAlgorithm]bool{
+	jose.RSA1_5:             true,
+	jose.RSA_OAEP:           true,
+	jose.RSA_OAEP_256:       true,
+	jose.ECDH_ES:            true,
+	jose.ECDH_ES_A128KW:     true,
+	jose.ECDH_ES_A192KW:     true,
+	jose.ECDH_ES_A256KW:     true,
+	jose.PBES2_HS256_A128KW: true,
+	jose.PBES2_HS384_A192KW: true,
+	jose.PBES2_HS512_A256KW: true,
+}
+
+// IsSymmetricAlgorithm checks if the algorithm is verified with a shared secret.
+func IsSymmetricAlgorithm(alg jose.SignatureAlgorithm) bool {
+	return alg == jose.HS256 || alg == jose.HS384 || alg == jose.HS512
+}
+
+// AllowsSignatureAlgorithm checks if the algorithm is in the configured allowlist.
+func (c Config) AllowsSignatureAlgorithm(alg jose.SignatureAlgorithm) bool {
+	for _, allowed := range c.SignatureAlgorithms {
+		if allowed == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPBES2Algorithm checks if the key is derived from a passphrase.
+func IsPBES2Algorithm(alg jose.KeyAlgorithm) bool {
+	return alg == jose.PBES2_HS256_A128KW || alg == jose.PBES2_HS384_A192KW || alg == jose.PBES2_HS512_A256KW
+}
+
+// AllowsKeyAlgorithm checks if the key management algorithm is in the
+// configured allowlist.
+func (c Config) AllowsKeyAlgorithm(alg jose.KeyAlgorithm) bool {
+	for _, allowed := range c.KeyAlgorithms {
+		if allowed == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsUnsignedKeyAlgorithm checks if the key management algorithm is in the
+// allowlist of the unsigned notifications, which requires the passphrase.
+func (c Config) AllowsUnsignedKeyAlgorithm(alg jose.KeyAlgorithm) bool {
+	if len(c.Passphrase) == 0 {
+		return false
+	}
+
+	for _, allowed := range c.UnsignedKeyAlgorithms {
+		if allowed == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Config) allowsPBES2() bool {
+	for _, allowed := range c.KeyAlgorithms {
+		if IsPBES2Algorithm(allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseSignatureAlgorithms(algorithms string) ([]jose.SignatureAlgorithm, error) {
+	result := []jose.SignatureAlgorithm{}
+	for _, alg := range strings.Split(algorithms, ";") {
+		alg = strings.TrimSpace(alg)
+		if alg == "" {
+			continue
+		}
+
+		if !signatureAlgorithms[jose.SignatureAlgorithm(alg)] {
+			return nil, fmt.Errorf("undefined signature algorithm: %v", alg)
+		}
+
+		result = append(result, jose.SignatureAlgorithm(alg))
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty signature algorithm list")
+	}
+
+	return result, nil
+}
+
+func parseKeyAlgorithms(algorithms string) ([]jose.KeyAlgorithm, error) {
+	result := []jose.KeyAlgorithm{}
+	for _, alg := range strings.Split(algorithms, ";") {
+		alg = strings.TrimSpace(alg)
+		if alg == "" {
+			continue
+		}
+
+		if !keyAlgorithms[jose.KeyAlgorithm(alg)] {
+			return nil, fmt.Errorf("undefined key algorithm: %v", alg)
+		}
+
+		result = append(result, jose.KeyAlgorithm(alg))
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty key algorithm list")
+	}
+
+	return result, nil
+}
+
+// signatureAlgorithmForKey returns the signature algorithm used with the
+// private key type.
+func signatureAlgorithmForKey(key interface{}) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *jose.JSONWebKey:
+		return signatureAlgorithmForKey(k.Key)
+	case *rsa.PrivateKey:
+		return jose.PS256, nil
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported signing key type %T", key)
+}
+
+// parseUnsignedKeyAlgorithms only accepts the PBES2 algorithms, whose shared
+// passphrase authenticates the sender.
+func parseUnsignedKeyAlgorithms(algorithms string) ([]jose.KeyAlgorithm, error) {
+	result := []jose.KeyAlgorithm{}
+	for _, alg := range strings.Split(algorithms, ";") {
+		alg = strings.TrimSpace(alg)
+		if alg == "" {
+			continue
+		}
+
+		if !IsPBES2Algorithm(jose.KeyAlgorithm(alg)) {
+			return nil, fmt.Errorf("key algorithm %v doesn't authenticate the sender, only the PBES2 algorithms are accepted", alg)
+		}
+
+		result = append(result, jose.KeyAlgorithm(alg))
+	}
+
+	return result, nil
+}
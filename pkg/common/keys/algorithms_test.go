@@ -0,0 +1,122 @@
+package keys
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+func Test_parseSignatureAlgorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    []jose.SignatureAlgorithm
+		wantErr bool
+	}{
+		{
+			name: "Valid list with asymmetric and symmetric algorithms",
+			args: "PS256; HS256 ;",
+			want: []jose.SignatureAlgorithm{jose.PS256, jose.HS256},
+		},
+		{
+			name:    "Empty list must fail",
+			args:    " ; ",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown algorithm must fail",
+			args:    "PS256;none",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSignatureAlgorithms(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSignatureAlgorithms() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSignatureAlgorithms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseKeyAlgorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    []jose.KeyAlgorithm
+		wantErr bool
+	}{
+		{
+			name: "Valid list with asymmetric and PBES2 algorithms",
+			args: "RSA-OAEP-256; PBES2-HS256+A128KW ;",
+			want: []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.PBES2_HS256_A128KW},
+		},
+		{
+			name:    "Empty list must fail",
+			args:    " ; ",
+			wantErr: true,
+		},
+		{
+			name:    "Direct encryption must fail",
+			args:    "RSA-OAEP-256;dir",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKeyAlgorithms(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseKeyAlgorithms() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeyAlgorithms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseUnsignedKeyAlgorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    []jose.KeyAlgorithm
+		wantErr bool
+	}{
+		{
+			name: "PBES2 algorithms",
+			args: "PBES2-HS256+A128KW; PBES2-HS512+A256KW",
+			want: []jose.KeyAlgorithm{jose.PBES2_HS256_A128KW, jose.PBES2_HS512_A256KW},
+		},
+		{
+			name: "Empty list disables the unsigned notifications",
+			args: "",
+			want: []jose.KeyAlgorithm{},
+		},
+		{
+			name:    "Public-key algorithm must fail",
+			args:    "PBES2-HS256+A128KW;RSA-OAEP-256",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUnsignedKeyAlgorithms(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseUnsignedKeyAlgorithms() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseUnsignedKeyAlgorithms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package keys
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// loadDecryptionKeyListFromFile loads the private keys, separated by ';', in
+// the configured order. The keys without a kid, like the PEM ones, are
+// identified by their RFC 7638 thumbprint.
+func loadDecryptionKeyListFromFile(fileList string, strength KeyStrength) ([]*jose.JSONWebKey, error) {
+	result := []*jose.JSONWebKey{}
+	kids := map[string]string{}
+	for _, file := range strings.Split(fileList, ";") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		keyBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %v", file, err)
+		}
+
+		privateKey, err := LoadPrivateKey(keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read private key %s: %v", file, err)
+		}
+		if err := strength.Check(privateKey); err != nil {
+			return nil, fmt.Errorf("private key %s: %w", file, err)
+		}
+
+		key, err := decryptionKey(privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("private key %s: %v", file, err)
+		}
+		if other, ok := kids[key.KeyID]; ok {
+			return nil, fmt.Errorf("private keys %s and %s have the same kid %q", other, file, key.KeyID)
+		}
+		kids[key.KeyID] = file
+
+		result = append(result, key)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty file list")
+	}
+
+	return result, nil
+}
+
+// decryptionKey returns the private key as a JWK with a kid.
+func decryptionKey(privateKey interface{}) (*jose.JSONWebKey, error) {
+	key, ok := privateKey.(*jose.JSONWebKey)
+	if !ok {
+		key = &jose.JSONWebKey{Key: privateKey}
+	}
+	if key.KeyID != "" {
+		return key, nil
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("computing the kid: %v", err)
+	}
+
+	withKeyID := *key
+	withKeyID.KeyID = thumbprintKeyID(thumbprint)
+	return &withKeyID, nil
+}
+
+func thumbprintKeyID(thumbprint []byte) string {
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+// declaresKeyID reports whether the key has its own kid, instead of the
+// thumbprint given to the PEM keys.
+func declaresKeyID(key *jose.JSONWebKey) bool {
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	return err != nil || key.KeyID != thumbprintKeyID(thumbprint)
+}
+
+// DecryptionKeys returns the private keys to try with the kid of a JWE
+// header: only the key with the kid, or all of them, in order, without a kid.
+// An unknown kid, like the provider's own kid for a PEM key, also tries all of
+// them when there is a single key or none declares a kid.
+func (c *Config) DecryptionKeys(kid string) []*jose.JSONWebKey {
+	if kid == "" {
+		return c.DecryptionKeyList
+	}
+
+	for _, key := range c.DecryptionKeyList {
+		if key.KeyID == kid {
+			return []*jose.JSONWebKey{key}
+		}
+	}
+
+	if len(c.DecryptionKeyList) == 1 {
+		return c.DecryptionKeyList
+	}
+	for _, key := range c.DecryptionKeyList {
+		if declaresKeyID(key) {
+			return nil
+		}
+	}
+
+	return c.DecryptionKeyList
+}
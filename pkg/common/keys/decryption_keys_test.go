@@ -0,0 +1,105 @@
+package keys
+
+import (
+	"crypto"
+	"encoding/base64"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestLoadKeys_DecryptionKeyList(t *testing.T) {
+	pub, err := ioutil.ReadFile("../../../tests/partner/fakekey.pub")
+	if err != nil {
+		t.Fatalf("reading public key: %v", err)
+	}
+	partnerKey, err := LoadPublicKey(pub)
+	if err != nil {
+		t.Fatalf("loading public key: %v", err)
+	}
+	thumbprint, err := (&jose.JSONWebKey{Key: partnerKey}).Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatalf("computing thumbprint: %v", err)
+	}
+	partnerKID := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	tests := []struct {
+		name     string
+		paths    string
+		wantKIDs []string
+		// wantUnknown is the number of keys tried with an unknown kid.
+		wantUnknown int
+		wantErr     bool
+	}{
+		{
+			name:        "Single PEM key",
+			paths:       "../../../tests/partner/fakekey.pem",
+			wantKIDs:    []string{partnerKID},
+			wantUnknown: 1,
+		},
+		{
+			name:        "Single JWK key",
+			paths:       "../../../tests/stone/fakekey1.pem.jwt",
+			wantKIDs:    []string{"fake-stone-1"},
+			wantUnknown: 1,
+		},
+		{
+			name:        "JWK keys keep their kid",
+			paths:       "../../../tests/stone/fakekey1.pem.jwt; ../../../tests/partner/fakekey.pem;../../../tests/stone/fakekey2.pem.jwt",
+			wantKIDs:    []string{"fake-stone-1", partnerKID, "fake-stone-2"},
+			wantUnknown: 0,
+		},
+		{
+			name:    "Same kid twice",
+			paths:   "../../../tests/stone/fakekey1.pem.jwt;../../../tests/stone/fakekey1.pem.jwt",
+			wantErr: true,
+		},
+		{
+			name:    "Missing file",
+			paths:   "../../../tests/partner/fakekey.pem;../../../tests/partner/missing.pem",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadKeys(configuration.KeysConfig{
+				PrivateKeyPath:      tt.paths,
+				PublicKeyLocation:   "file://../../../tests/stone/fakekey1.pub.jwt",
+				SignatureAlgorithms: "PS256",
+				KeyAlgorithms:       "RSA-OAEP-256",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			kids := []string{}
+			for _, key := range config.DecryptionKeyList {
+				kids = append(kids, key.KeyID)
+			}
+			if !reflect.DeepEqual(kids, tt.wantKIDs) {
+				t.Errorf("LoadKeys() kids = %v, want %v", kids, tt.wantKIDs)
+			}
+			if config.PrivateKey != config.DecryptionKeyList[0] {
+				t.Error("LoadKeys() private key must be the first decryption key")
+			}
+
+			if got := config.DecryptionKeys(tt.wantKIDs[0]); len(got) != 1 || got[0].KeyID != tt.wantKIDs[0] {
+				t.Errorf("DecryptionKeys(%q) = %v", tt.wantKIDs[0], got)
+			}
+			if got := config.DecryptionKeys(""); len(got) != len(tt.wantKIDs) {
+				t.Errorf("DecryptionKeys() without kid = %d keys, want %d", len(got), len(tt.wantKIDs))
+			}
+			if got := config.DecryptionKeys("unknown"); len(got) != tt.wantUnknown {
+				t.Errorf("DecryptionKeys() of an unknown kid = %d keys, want %d", len(got), tt.wantUnknown)
+			}
+		})
+	}
+}
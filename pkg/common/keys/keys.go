@@ -1,14 +1,20 @@
 package keys
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
 )
 
 const (
@@ -16,33 +22,131 @@ const (
 	URLLocation  = "url://"
 )
 
+var ErrJWKSTooLarge = errors.New("key set response too large")
+
+// fetchLimits bound the fetch of the key sets by URL. A zero value is
+// unlimited.
+type fetchLimits struct {
+	maxSize int64
+	timeout time.Duration
+}
+
 type Config struct {
-	PrivateKey          interface{}
+	// PrivateKey is the first of the DecryptionKeyList, the current key.
+	PrivateKey interface{}
+	// DecryptionKeyList has all the private keys, each with a kid, so the
+	// key can be rotated while the old one still decrypts.
+	DecryptionKeyList   []*jose.JSONWebKey
 	VerificationKeyList []*jose.JSONWebKey
+	// FallbackKeyList is only tried when the verification keys fail.
+	FallbackKeyList []*jose.JSONWebKey
+	// SymmetricKeyList is only used to verify HMAC signatures.
+	SymmetricKeyList    []*jose.JSONWebKey
+	SignatureAlgorithms []jose.SignatureAlgorithm
+	KeyAlgorithms       []jose.KeyAlgorithm
+	// UnsignedKeyAlgorithms replaces KeyAlgorithms for the unsigned
+	// notifications, only with the PBES2 algorithms.
+	UnsignedKeyAlgorithms []jose.KeyAlgorithm
+	// Passphrase decrypts the PBES2 payloads.
+	Passphrase []byte
+	// CertificateRoots are the CAs trusted to verify x5c certificate chains.
+	// It's nil when the x5c chains aren't accepted.
+	CertificateRoots *x509.CertPool
+	CertificateNames []string
+	// KeyStrength is the minimum strength of the keys embedded in the
+	// notifications. The loaded keys are checked by LoadKeys.
+	KeyStrength KeyStrength
+	// ReceiptSigner signs the processing receipts. It's nil when the
+	// receipts are disabled.
+	ReceiptSigner jose.Signer
 }
 
-func LoadKeys(privateKeyPath, publicKeyLocation string) (*Config, error) {
+func LoadKeys(cfg configuration.KeysConfig) (*Config, error) {
 	var config Config
 
-	keyBytes, err := ioutil.ReadFile(privateKeyPath)
+	config.KeyStrength = NewKeyStrength(cfg)
+
+	// Only a relay in the verify-only mode runs without the private key.
+	if cfg.PrivateKeyPath != "" {
+		keyList, err := loadDecryptionKeyListFromFile(cfg.PrivateKeyPath, config.KeyStrength)
+		if err != nil {
+			return nil, fmt.Errorf("loading private key %s: %w", cfg.PrivateKeyPath, err)
+		}
+
+		config.DecryptionKeyList = keyList
+		config.PrivateKey = keyList[0]
+	}
+
+	var err error
+
+	limits := fetchLimits{maxSize: cfg.JWKSMaxSize, timeout: cfg.JWKSTimeout}
+
+	config.VerificationKeyList, err = loadVerificationKeyList(cfg.PublicKeyLocation, limits)
 	if err != nil {
-		return nil, fmt.Errorf("reading file %s: %v", privateKeyPath, err)
+		return nil, fmt.Errorf("loading verification key %s: %w", cfg.PublicKeyLocation, err)
+	}
+	if err := config.KeyStrength.checkKeyList(config.VerificationKeyList); err != nil {
+		return nil, fmt.Errorf("verification key %s: %w", cfg.PublicKeyLocation, err)
 	}
 
-	config.PrivateKey, err = LoadPrivateKey(keyBytes)
+	if cfg.FallbackPublicKeyLocation != "" {
+		config.FallbackKeyList, err = loadVerificationKeyList(cfg.FallbackPublicKeyLocation, limits)
+		if err != nil {
+			return nil, fmt.Errorf("loading fallback verification key %s: %w", cfg.FallbackPublicKeyLocation, err)
+		}
+		if err := config.KeyStrength.checkKeyList(config.FallbackKeyList); err != nil {
+			return nil, fmt.Errorf("fallback verification key %s: %w", cfg.FallbackPublicKeyLocation, err)
+		}
+	}
+
+	if cfg.SymmetricKeyPath != "" {
+		config.SymmetricKeyList, err = loadSymmetricKeyListFromFile(cfg.SymmetricKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading symmetric key %s: %v", cfg.SymmetricKeyPath, err)
+		}
+	}
+
+	config.SignatureAlgorithms, err = parseSignatureAlgorithms(cfg.SignatureAlgorithms)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read private key: %v", err)
+		return nil, fmt.Errorf("loading signature algorithms: %v", err)
 	}
 
-	config.VerificationKeyList, err = loadVerificationKeyList(publicKeyLocation)
+	config.KeyAlgorithms, err = parseKeyAlgorithms(cfg.KeyAlgorithms)
 	if err != nil {
-		return nil, fmt.Errorf("loading verification key %s: %v", publicKeyLocation, err)
+		return nil, fmt.Errorf("loading key algorithms: %v", err)
+	}
+
+	config.UnsignedKeyAlgorithms, err = parseUnsignedKeyAlgorithms(cfg.UnsignedKeyAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("loading unsigned key algorithms: %v", err)
+	}
+
+	if config.allowsPBES2() && cfg.PBES2Passphrase == "" {
+		return nil, fmt.Errorf("PBES2 key algorithms require a passphrase")
+	}
+	if cfg.PBES2Passphrase != "" {
+		config.Passphrase = []byte(cfg.PBES2Passphrase)
+	}
+
+	if cfg.X5CEnabled {
+		config.CertificateRoots, err = loadCertificatePoolFromFile(cfg.X5CCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading x5c trusted CAs %s: %v", cfg.X5CCAPath, err)
+		}
+		config.CertificateNames = configuration.SplitList(cfg.X5CAllowedNames)
+	}
+
+	if cfg.ReceiptSigningKeyPath != "" {
+		config.ReceiptSigner, err = loadReceiptSignerFromFile(cfg.ReceiptSigningKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading receipt signing key %s: %v", cfg.ReceiptSigningKeyPath, err)
+		}
 	}
 
 	return &config, nil
 }
 
-func loadVerificationKeyList(location string) ([]*jose.JSONWebKey, error) {
+func loadVerificationKeyList(location string, limits fetchLimits) ([]*jose.JSONWebKey, error) {
 	var keyList []*jose.JSONWebKey
 	var err error
 
@@ -52,9 +156,9 @@ func loadVerificationKeyList(location string) ([]*jose.JSONWebKey, error) {
 			return nil, fmt.Errorf("loading verification key from file %s: %v", location, err)
 		}
 	} else if strings.HasPrefix(location, URLLocation) {
-		keyList, err = loadVerificationKeyListFromURL(strings.TrimPrefix(location, URLLocation))
+		keyList, err = loadVerificationKeyListFromURL(strings.TrimPrefix(location, URLLocation), limits)
 		if err != nil {
-			return nil, fmt.Errorf("loading verification key from file %s: %v", location, err)
+			return nil, fmt.Errorf("loading verification key from url %s: %w", location, err)
 		}
 	} else {
 		return nil, fmt.Errorf("invalid public key location: %s", location)
@@ -93,24 +197,60 @@ func loadVerificationKeyListFromFile(fileList string) ([]*jose.JSONWebKey, error
 	return result, nil
 }
 
-func loadVerificationKeyListFromURL(serviceURL string) ([]*jose.JSONWebKey, error) {
+func loadSymmetricKeyListFromFile(fileList string) ([]*jose.JSONWebKey, error) {
+	result := []*jose.JSONWebKey{}
+	for _, file := range strings.Split(fileList, ";") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		keyBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %v", file, err)
+		}
+
+		symmetricKey, err := LoadSymmetricKeyFromJWK(keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read symmetric key: %v", err)
+		}
+		result = append(result, symmetricKey)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty file list")
+	}
+
+	return result, nil
+}
+
+func loadVerificationKeyListFromURL(serviceURL string, limits fetchLimits) ([]*jose.JSONWebKey, error) {
 	keysURL, err := url.Parse(serviceURL)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse url %s: %v", serviceURL, err)
 	}
 
-	client := http.DefaultClient
+	// The timeout covers the whole fetch, including reading the body.
+	client := &http.Client{Timeout: limits.timeout}
 	response, err := client.Get(keysURL.String())
 	if err != nil {
 		return nil, fmt.Errorf("unable to get url keys %s: %v", keysURL.String(), err)
 	}
 	defer response.Body.Close()
 
-	body, err := ioutil.ReadAll(response.Body)
+	var reader io.Reader = response.Body
+	if limits.maxSize > 0 {
+		reader = io.LimitReader(response.Body, limits.maxSize+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read body: %v", err)
 	}
 
+	if limits.maxSize > 0 && int64(len(body)) > limits.maxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrJWKSTooLarge, limits.maxSize)
+	}
+
 	type responseBody struct {
 		Keys []*jose.JSONWebKey `json:"keys"`
 	}
@@ -0,0 +1,127 @@
+package keys
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// newKeySetServer serves the key set, padded with padding bytes, after the
+// delay.
+func newKeySetServer(t *testing.T, padding *int32, delay *int64) *httptest.Server {
+	t.Helper()
+
+	key, err := ioutil.ReadFile("../../../tests/stone/fakekey1.pub.jwt")
+	if err != nil {
+		t.Fatalf("reading key: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Duration(atomic.LoadInt64(delay))):
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprintf(w, `{"keys":[%s]}%s`, key, strings.Repeat(" ", int(atomic.LoadInt32(padding))))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func Test_loadVerificationKeyListFromURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		padding    int32
+		delay      time.Duration
+		limits     fetchLimits
+		wantErr    bool
+		wantTooBig bool
+	}{
+		{
+			name:   "Within the limits",
+			limits: fetchLimits{maxSize: 4096, timeout: time.Second},
+		},
+		{
+			name:    "Unlimited",
+			padding: 1 << 20,
+		},
+		{
+			name:       "Oversized response",
+			padding:    4096,
+			limits:     fetchLimits{maxSize: 4096, timeout: time.Second},
+			wantErr:    true,
+			wantTooBig: true,
+		},
+		{
+			name:    "Timed out response",
+			delay:   time.Second,
+			limits:  fetchLimits{maxSize: 4096, timeout: 50 * time.Millisecond},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			padding, delay := tt.padding, int64(tt.delay)
+			srv := newKeySetServer(t, &padding, &delay)
+
+			keyList, err := loadVerificationKeyListFromURL(srv.URL, tt.limits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadVerificationKeyListFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrJWKSTooLarge) != tt.wantTooBig {
+				t.Errorf("loadVerificationKeyListFromURL() error = %v, want too large %v", err, tt.wantTooBig)
+			}
+			if !tt.wantErr && len(keyList) != 1 {
+				t.Errorf("loadVerificationKeyListFromURL() = %d keys, want 1", len(keyList))
+			}
+		})
+	}
+}
+
+func TestStore_Reload_KeepsKeysOnBadKeySet(t *testing.T) {
+	var padding int32
+	var delay int64
+	srv := newKeySetServer(t, &padding, &delay)
+
+	load := func() (*Config, error) {
+		return LoadKeys(configuration.KeysConfig{
+			PrivateKeyPath:      "../../../tests/partner/fakekey.pem",
+			PublicKeyLocation:   "url://" + srv.URL,
+			SignatureAlgorithms: "PS256",
+			KeyAlgorithms:       "RSA-OAEP-256",
+			JWKSMaxSize:         4096,
+			JWKSTimeout:         50 * time.Millisecond,
+		})
+	}
+
+	config, err := load()
+	if err != nil {
+		t.Fatalf("LoadKeys() error = %v", err)
+	}
+	store := NewStore(config, load)
+
+	atomic.StoreInt32(&padding, 4096)
+	if err := store.Reload(); !errors.Is(err, ErrJWKSTooLarge) {
+		t.Errorf("Reload() error = %v, want %v", err, ErrJWKSTooLarge)
+	}
+
+	atomic.StoreInt32(&padding, 0)
+	atomic.StoreInt64(&delay, int64(time.Second))
+	if err := store.Reload(); err == nil {
+		t.Error("Reload() of a slow key set must fail")
+	}
+
+	if store.Get() != config {
+		t.Error("Reload() must keep the last good keys")
+	}
+}
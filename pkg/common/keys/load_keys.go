@@ -5,6 +5,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/ioutil"
 
 	"gopkg.in/square/go-jose.v2"
 )
@@ -101,3 +102,36 @@ func LoadPublicKeyFromJWK(data []byte) (*jose.JSONWebKey, error) {
 
 	return nil, fmt.Errorf("square/go-jose: jwk parse error, got '%s'", err)
 }
+
+// LoadSymmetricKeyFromJWK loads a shared secret from an "oct" JWK-encoded data.
+func LoadSymmetricKeyFromJWK(data []byte) (*jose.JSONWebKey, error) {
+	jwk, err := LoadJSONWebKey(data, false)
+	if err != nil {
+		return nil, fmt.Errorf("square/go-jose: jwk parse error, got '%s'", err)
+	}
+
+	if _, ok := jwk.Key.([]byte); !ok {
+		return nil, errors.New("JWK key is not symmetric")
+	}
+
+	return jwk, nil
+}
+
+// loadCertificatePoolFromFile loads the PEM encoded certificates in the file.
+func loadCertificatePoolFromFile(path string) (*x509.CertPool, error) {
+	if path == "" {
+		return nil, errors.New("no CA file")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %v", path, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificate in %s", path)
+	}
+
+	return pool, nil
+}
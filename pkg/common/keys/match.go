@@ -0,0 +1,96 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+var ErrKeyMismatch = errors.New("public key doesn't match the private key")
+
+// MatchPublicKey checks if the public key is the counterpart of the private
+// key: the same modulus for RSA, and the same curve and point for EC. The keys
+// can also be JWKs.
+func MatchPublicKey(privateKey, publicKey interface{}) error {
+	private, err := publicKeyOf(privateKey)
+	if err != nil {
+		return fmt.Errorf("private key: %v", err)
+	}
+
+	if jwk, ok := publicKey.(*jose.JSONWebKey); ok {
+		if !jwk.IsPublic() {
+			return errors.New("the key to match must be public")
+		}
+		publicKey = jwk.Key
+	}
+
+	switch public := publicKey.(type) {
+	case *rsa.PublicKey:
+		rsaPrivate, ok := private.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: RSA key, but the private key is %s", ErrKeyMismatch, keyType(private))
+		}
+		if rsaPrivate.N.Cmp(public.N) != 0 || rsaPrivate.E != public.E {
+			return fmt.Errorf("%w: different RSA modulus or exponent", ErrKeyMismatch)
+		}
+	case *ecdsa.PublicKey:
+		ecPrivate, ok := private.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: EC key, but the private key is %s", ErrKeyMismatch, keyType(private))
+		}
+		if ecPrivate.Curve.Params().Name != public.Curve.Params().Name {
+			return fmt.Errorf("%w: curve %s, but the private key is %s", ErrKeyMismatch, public.Curve.Params().Name, ecPrivate.Curve.Params().Name)
+		}
+		if ecPrivate.X.Cmp(public.X) != 0 || ecPrivate.Y.Cmp(public.Y) != 0 {
+			return fmt.Errorf("%w: different EC point", ErrKeyMismatch)
+		}
+	case ed25519.PublicKey:
+		edPrivate, ok := private.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: Ed25519 key, but the private key is %s", ErrKeyMismatch, keyType(private))
+		}
+		if !edPrivate.Equal(public) {
+			return fmt.Errorf("%w: different Ed25519 key", ErrKeyMismatch)
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return nil
+}
+
+// publicKeyOf returns the public part of the private key.
+func publicKeyOf(privateKey interface{}) (crypto.PublicKey, error) {
+	if jwk, ok := privateKey.(*jose.JSONWebKey); ok {
+		privateKey = jwk.Key
+	}
+
+	switch private := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return &private.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &private.PublicKey, nil
+	case ed25519.PrivateKey:
+		return private.Public(), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+func keyType(key crypto.PublicKey) string {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return "RSA"
+	case *ecdsa.PublicKey:
+		return "EC"
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", key)
+	}
+}
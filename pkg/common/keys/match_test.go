@@ -0,0 +1,66 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestMatchPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherP256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		privateKey   interface{}
+		publicKey    interface{}
+		wantErr      bool
+		wantMismatch bool
+	}{
+		{name: "RSA match", privateKey: rsaKey, publicKey: &rsaKey.PublicKey},
+		{name: "RSA JWKs match", privateKey: &jose.JSONWebKey{Key: rsaKey}, publicKey: &jose.JSONWebKey{Key: &rsaKey.PublicKey}},
+		{name: "RSA mismatch", privateKey: rsaKey, publicKey: &otherRSAKey.PublicKey, wantErr: true, wantMismatch: true},
+		{name: "EC match", privateKey: p256Key, publicKey: &p256Key.PublicKey},
+		{name: "EC point mismatch", privateKey: p256Key, publicKey: &otherP256Key.PublicKey, wantErr: true, wantMismatch: true},
+		{name: "EC curve mismatch", privateKey: p256Key, publicKey: &p384Key.PublicKey, wantErr: true, wantMismatch: true},
+		{name: "Key type mismatch", privateKey: rsaKey, publicKey: &p256Key.PublicKey, wantErr: true, wantMismatch: true},
+		{name: "Private JWK to match", privateKey: rsaKey, publicKey: &jose.JSONWebKey{Key: rsaKey}, wantErr: true},
+		{name: "Unsupported public key", privateKey: rsaKey, publicKey: []byte("secret"), wantErr: true},
+		{name: "Unsupported private key", privateKey: []byte("secret"), publicKey: &rsaKey.PublicKey, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MatchPublicKey(tt.privateKey, tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MatchPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrKeyMismatch) != tt.wantMismatch {
+				t.Errorf("MatchPublicKey() error = %v, wantMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// Packer signs and encrypts cleartext payloads the same way Stone does, so
+// they can be relayed to another webhook consumer.
+type Packer struct {
+	signer    jose.Signer
+	encrypter jose.Encrypter
+}
+
+// NewPacker creates a packer encrypting to encryptionKey, a public or private
+// RSA or ECDSA key, and signing with signingKey.
+func NewPacker(signingKey, encryptionKey interface{}) (*Packer, error) {
+	recipient, err := encryptionRecipient(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, recipient, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating encrypter: %v", err)
+	}
+
+	alg, err := signatureAlgorithmForKey(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: signingKey}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating signer: %v", err)
+	}
+
+	return &Packer{signer: signer, encrypter: encrypter}, nil
+}
+
+// LoadPacker loads the keys of the packer.
+func LoadPacker(cfg configuration.PackConfig) (*Packer, error) {
+	signingKey, err := loadPackSigningKey(cfg.SigningKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := ioutil.ReadFile(cfg.EncryptionKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %v", cfg.EncryptionKeyPath, err)
+	}
+	encryptionKey, err := LoadPublicKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read encryption key: %v", err)
+	}
+
+	return NewPacker(signingKey, encryptionKey)
+}
+
+// loadPackSigningKey loads the private key signing the packed payloads.
+func loadPackSigningKey(path string) (interface{}, error) {
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %v", path, err)
+	}
+
+	signingKey, err := LoadPrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pack signing key: %v", err)
+	}
+
+	return signingKey, nil
+}
+
+// Pack encrypts and then signs the payload, returning the encrypted body.
+func (p *Packer) Pack(payload []byte) (string, error) {
+	encrypted, err := p.encrypter.Encrypt(payload)
+	if err != nil {
+		return "", fmt.Errorf("encrypting: %v", err)
+	}
+	encryptedBody, err := encrypted.CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("serializing encrypted: %v", err)
+	}
+
+	signed, err := p.signer.Sign([]byte(encryptedBody))
+	if err != nil {
+		return "", fmt.Errorf("signing: %v", err)
+	}
+
+	return signed.CompactSerialize()
+}
+
+func encryptionRecipient(key interface{}) (jose.Recipient, error) {
+	switch k := key.(type) {
+	case *jose.JSONWebKey:
+		return encryptionRecipient(k.Key)
+	case *rsa.PrivateKey:
+		return encryptionRecipient(&k.PublicKey)
+	case *ecdsa.PrivateKey:
+		return encryptionRecipient(&k.PublicKey)
+	case *rsa.PublicKey:
+		return jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: k}, nil
+	case *ecdsa.PublicKey:
+		return jose.Recipient{Algorithm: jose.ECDH_ES_A256KW, Key: k}, nil
+	}
+
+	return jose.Recipient{}, fmt.Errorf("unsupported encryption key type %T", key)
+}
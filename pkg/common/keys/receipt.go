@@ -0,0 +1,33 @@
+package keys
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// NewReceiptSigner returns the signer of the processing receipts, with the
+// signature algorithm matching the private key type.
+func NewReceiptSigner(key interface{}) (jose.Signer, error) {
+	alg, err := signatureAlgorithmForKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, (&jose.SignerOptions{}).WithType("JWT"))
+}
+
+func loadReceiptSignerFromFile(path string) (jose.Signer, error) {
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %v", path, err)
+	}
+
+	key, err := LoadPrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read private key: %v", err)
+	}
+
+	return NewReceiptSigner(key)
+}
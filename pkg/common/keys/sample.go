@@ -0,0 +1,31 @@
+package keys
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// SamplePayload is the payload of the generated sample bodies.
+const SamplePayload = `{"self_test":true}`
+
+// NewSampleBody signs and encrypts a sample payload the same way Stone does:
+// encrypted to the private key and signed by signingKey, whose public key
+// must be in the verification keys.
+func NewSampleBody(config *Config, signingKey interface{}) (string, error) {
+	packer, err := NewPacker(signingKey, config.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	return packer.Pack([]byte(SamplePayload))
+}
+
+// LoadSampleSigningKey loads the private key signing the sample bodies.
+func LoadSampleSigningKey(path string) (interface{}, error) {
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %v", path, err)
+	}
+
+	return LoadPrivateKey(keyBytes)
+}
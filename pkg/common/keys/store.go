@@ -0,0 +1,96 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrReloadInProgress = errors.New("a key reload is already in progress")
+	ErrReloadDisabled   = errors.New("key reload is not configured")
+	ErrKeysNotLoaded    = errors.New("the keys aren't loaded")
+)
+
+// Store holds the current keys, swapped as a whole on reload, so a request
+// using a Get result always sees a complete and consistent set.
+type Store struct {
+	current   atomic.Value
+	reloading int32
+	load      func() (*Config, error)
+}
+
+// NewStore holds the keys, reloaded with load. Reload is disabled when load
+// is nil.
+func NewStore(config *Config, load func() (*Config, error)) *Store {
+	s := &Store{load: load}
+	s.current.Store(config)
+	return s
+}
+
+// Get returns the current keys. A nil store has no keys.
+func (s *Store) Get() *Config {
+	if s == nil {
+		return nil
+	}
+
+	return s.current.Load().(*Config)
+}
+
+// CheckHealth checks if the keys are loaded, with the keys to verify the
+// signatures. A failed reload keeps the current keys, so it's still healthy.
+func (s *Store) CheckHealth(ctx context.Context) error {
+	config := s.Get()
+	switch {
+	case config == nil:
+		return ErrKeysNotLoaded
+	case len(config.VerificationKeyList) == 0:
+		return fmt.Errorf("%w: no verification keys", ErrKeysNotLoaded)
+	}
+
+	return nil
+}
+
+// Reload loads the keys and swaps them. Only one reload runs at a time, the
+// concurrent ones fail with ErrReloadInProgress. The current keys are kept
+// when the load fails.
+func (s *Store) Reload() error {
+	if s == nil || s.load == nil {
+		return ErrReloadDisabled
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.reloading, 0, 1) {
+		return ErrReloadInProgress
+	}
+	defer atomic.StoreInt32(&s.reloading, 0)
+
+	config, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	s.current.Store(config)
+	return nil
+}
+
+// Refresh reloads the keys every interval until the context is done, so the
+// rotated keys of a url:// key set are picked up without the admin API. The
+// load errors are passed to onError, keeping the current keys.
+func (s *Store) Refresh(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A reload from the admin API already refreshes the keys.
+			if err := s.Reload(); err != nil && !errors.Is(err, ErrReloadInProgress) {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// generationConfig builds a key set whose fields all carry the generation,
+// to detect a set mixing two generations.
+func generationConfig(generation int) *Config {
+	keyList := make([]*jose.JSONWebKey, generation)
+	return &Config{
+		PrivateKey:          generation,
+		VerificationKeyList: keyList,
+		SymmetricKeyList:    keyList,
+	}
+}
+
+func TestStore_Reload_Concurrent(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	loading := make(chan struct{})
+
+	store := NewStore(generationConfig(1), func() (*Config, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(loading)
+		}
+		<-release
+		return generationConfig(2), nil
+	})
+
+	// The first reload holds the load until released.
+	firstErr := make(chan error, 1)
+	go func() {
+		firstErr <- store.Reload()
+	}()
+	<-loading
+
+	var wg sync.WaitGroup
+	conflicts := int32(0)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Reload(); errors.Is(err, ErrReloadInProgress) {
+				atomic.AddInt32(&conflicts, 1)
+			}
+		}()
+	}
+
+	// The readers always see a complete set, from a single generation.
+	stop := make(chan struct{})
+	inconsistent := int32(0)
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				config := store.Get()
+				generation := config.PrivateKey.(int)
+				if len(config.VerificationKeyList) != generation || len(config.SymmetricKeyList) != generation {
+					atomic.AddInt32(&inconsistent, 1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(release)
+	if err := <-firstErr; err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	close(stop)
+	readers.Wait()
+
+	if conflicts != 10 {
+		t.Errorf("Reload() concurrent conflicts = %d, want 10", conflicts)
+	}
+	if loads != 1 {
+		t.Errorf("Reload() loads = %d, want 1", loads)
+	}
+	if inconsistent != 0 {
+		t.Errorf("Get() returned %d inconsistent key sets", inconsistent)
+	}
+	if got := store.Get().PrivateKey; got != 2 {
+		t.Errorf("Get() generation = %v, want 2", got)
+	}
+
+	// A new reload is accepted once the previous one is finished.
+	if err := store.Reload(); err != nil {
+		t.Errorf("Reload() after the previous one error = %v", err)
+	}
+}
+
+func TestStore_Reload_Failure(t *testing.T) {
+	store := NewStore(generationConfig(1), func() (*Config, error) {
+		return nil, errors.New("unable to load")
+	})
+
+	if err := store.Reload(); err == nil {
+		t.Errorf("Reload() must fail")
+	}
+	if got := store.Get().PrivateKey; got != 1 {
+		t.Errorf("Get() generation = %v, want the current keys", got)
+	}
+
+	if err := NewStore(generationConfig(1), nil).Reload(); !errors.Is(err, ErrReloadDisabled) {
+		t.Errorf("Reload() error = %v, want %v", err, ErrReloadDisabled)
+	}
+}
+
+func TestStore_Refresh(t *testing.T) {
+	var loads int32
+	store := NewStore(generationConfig(1), func() (*Config, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			return nil, errors.New("unable to load")
+		}
+		return generationConfig(2), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		store.Refresh(ctx, time.Millisecond, func(err error) { errs <- err })
+		close(done)
+	}()
+
+	// The first refresh fails, keeping the current keys, and the next one
+	// swaps them.
+	deadline := time.After(5 * time.Second)
+	for store.Get().PrivateKey != 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("Refresh() generation = %v, want 2", store.Get().PrivateKey)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	if len(errs) != 1 {
+		t.Errorf("Refresh() reported %d errors, want 1", len(errs))
+	}
+}
+
+func TestStore_CheckHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   *Store
+		wantErr bool
+	}{
+		{
+			name:  "Loaded",
+			store: NewStore(generationConfig(1), nil),
+		},
+		{
+			name:    "Without verification keys",
+			store:   NewStore(&Config{}, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Not loaded",
+			store:   NewStore(nil, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Nil store",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.store.CheckHealth(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckHealth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrKeysNotLoaded) {
+				t.Errorf("CheckHealth() error = %v, want %v", err, ErrKeysNotLoaded)
+			}
+		})
+	}
+}
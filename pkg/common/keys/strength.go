@@ -0,0 +1,74 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// ErrWeakKey is returned when a RSA key is smaller than the minimum size, or
+// an EC key uses a curve that isn't allowed.
+var ErrWeakKey = errors.New("key doesn't meet the minimum strength")
+
+// KeyStrength defines the minimum strength of the RSA and EC keys. The other
+// key types, like Ed25519, aren't checked.
+type KeyStrength struct {
+	MinRSABits int
+	// Curves are the allowed EC curves, like "P-256". Any curve is allowed
+	// when empty.
+	Curves []string
+}
+
+// NewKeyStrength loads the minimum key strength.
+func NewKeyStrength(cfg configuration.KeysConfig) KeyStrength {
+	return KeyStrength{
+		MinRSABits: cfg.MinRSAKeyBits,
+		Curves:     configuration.SplitList(cfg.AllowedCurves),
+	}
+}
+
+// Check checks if the public or private key meets the minimum strength.
+func (s KeyStrength) Check(key interface{}) error {
+	switch k := key.(type) {
+	case *jose.JSONWebKey:
+		return s.Check(k.Key)
+	case *rsa.PrivateKey:
+		return s.Check(&k.PublicKey)
+	case *ecdsa.PrivateKey:
+		return s.Check(&k.PublicKey)
+	case *rsa.PublicKey:
+		if bits := k.N.BitLen(); bits < s.MinRSABits {
+			return fmt.Errorf("%w: RSA key with %d bits, the minimum is %d", ErrWeakKey, bits, s.MinRSABits)
+		}
+	case *ecdsa.PublicKey:
+		if len(s.Curves) == 0 {
+			return nil
+		}
+
+		curve := k.Curve.Params().Name
+		for _, allowed := range s.Curves {
+			if allowed == curve {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: EC curve %s is not allowed", ErrWeakKey, curve)
+	}
+
+	return nil
+}
+
+// checkKeyList checks the strength of all the keys.
+func (s KeyStrength) checkKeyList(keyList []*jose.JSONWebKey) error {
+	for _, key := range keyList {
+		if err := s.Check(key); err != nil {
+			return fmt.Errorf("key %s: %w", key.KeyID, err)
+		}
+	}
+
+	return nil
+}
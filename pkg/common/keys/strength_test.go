@@ -0,0 +1,122 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestKeyStrength_Check(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating Ed25519 key: %v", err)
+	}
+
+	strength := KeyStrength{MinRSABits: 2048, Curves: []string{"P-256", "P-384"}}
+
+	tests := []struct {
+		name     string
+		strength KeyStrength
+		key      interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "Undersized RSA key",
+			strength: strength,
+			key:      &rsaKey.PublicKey,
+			wantErr:  true,
+		},
+		{
+			name:     "Undersized RSA private key in a JWK",
+			strength: strength,
+			key:      &jose.JSONWebKey{Key: rsaKey},
+			wantErr:  true,
+		},
+		{
+			name:     "RSA key with the minimum size",
+			strength: KeyStrength{MinRSABits: 1024},
+			key:      rsaKey,
+		},
+		{
+			name:     "Curve not allowed",
+			strength: strength,
+			key:      &p224Key.PublicKey,
+			wantErr:  true,
+		},
+		{
+			name:     "Allowed curve",
+			strength: strength,
+			key:      p256Key,
+		},
+		{
+			name:     "Any curve when none is configured",
+			strength: KeyStrength{MinRSABits: 2048},
+			key:      p224Key,
+		},
+		{
+			name:     "Ed25519 keys aren't checked",
+			strength: strength,
+			key:      edKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.strength.Check(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrWeakKey) {
+				t.Errorf("Check() error = %v, want %v", err, ErrWeakKey)
+			}
+		})
+	}
+}
+
+func TestLoadKeys_WeakPrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "weak.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	if err := ioutil.WriteFile(path, keyPEM, 0600); err != nil {
+		t.Fatalf("writing key: %v", err)
+	}
+
+	_, err = LoadKeys(configuration.KeysConfig{
+		PrivateKeyPath:      path,
+		PublicKeyLocation:   "file://../../../tests/stone/fakekey1.pub.jwt",
+		SignatureAlgorithms: "PS256",
+		KeyAlgorithms:       "RSA-OAEP-256",
+		MinRSAKeyBits:       2048,
+	})
+	if !errors.Is(err, ErrWeakKey) {
+		t.Errorf("LoadKeys() error = %v, want %v", err, ErrWeakKey)
+	}
+}
@@ -0,0 +1,67 @@
+package labelguard
+
+import "sync"
+
+// Other replaces the label values past the guard limit.
+const Other = "other"
+
+// Guard bounds the distinct values of a metric label, like the event types
+// sent by the clients. The first max values are kept, and the next ones are
+// reported as "other". Values sent by unauthenticated clients should only be
+// looked up with Known, and admitted once authenticated.
+type Guard struct {
+	mu   sync.Mutex
+	max  int
+	seen map[string]bool
+}
+
+func New(max int) *Guard {
+	return &Guard{
+		max:  max,
+		seen: map[string]bool{},
+	}
+}
+
+// Value returns the value, or "other" past the limit. A nil guard reports all
+// the values as "other".
+func (g *Guard) Value(value string) string {
+	if g == nil {
+		return Other
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.seen[value] {
+		return value
+	}
+
+	if len(g.seen) >= g.max {
+		return Other
+	}
+
+	g.seen[value] = true
+	return value
+}
+
+// Admit keeps the value, if there's room for it.
+func (g *Guard) Admit(value string) {
+	g.Value(value)
+}
+
+// Known returns the value if it was already admitted, or "other", without
+// admitting it.
+func (g *Guard) Known(value string) string {
+	if g == nil {
+		return Other
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.seen[value] {
+		return value
+	}
+
+	return Other
+}
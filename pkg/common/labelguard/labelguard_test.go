@@ -0,0 +1,49 @@
+package labelguard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuard_Value(t *testing.T) {
+	guard := New(2)
+
+	got := []string{}
+	for _, value := range []string{"a", "b", "c", "a", "d", "b"} {
+		got = append(got, guard.Value(value))
+	}
+
+	want := []string{"a", "b", "other", "a", "other", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Value() = %v, want %v", got, want)
+	}
+}
+
+func TestGuard_Known(t *testing.T) {
+	guard := New(2)
+
+	if got := guard.Known("a"); got != Other {
+		t.Errorf("Known() before Admit() = %v, want %v", got, Other)
+	}
+
+	guard.Admit("a")
+	guard.Admit("b")
+	guard.Admit("c")
+
+	got := []string{}
+	for _, value := range []string{"a", "b", "c"} {
+		got = append(got, guard.Known(value))
+	}
+
+	want := []string{"a", "b", "other"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Known() = %v, want %v", got, want)
+	}
+}
+
+func TestGuard_Value_Nil(t *testing.T) {
+	var guard *Guard
+	if got := guard.Value("a"); got != Other {
+		t.Errorf("Value() = %v, want %v", got, Other)
+	}
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Mode is the maintenance toggle shared by the handlers. While enabled, new
+// notifications are rejected, so they are retried later by Stone.
+type Mode struct {
+	enabled    int32
+	retryAfter time.Duration
+}
+
+func New(enabled bool, retryAfter time.Duration) *Mode {
+	m := &Mode{retryAfter: retryAfter}
+	m.Set(enabled)
+	return m
+}
+
+// Enabled reports if the maintenance is on. A nil mode is never enabled.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	return atomic.LoadInt32(&m.enabled) == 1
+}
+
+func (m *Mode) Set(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&m.enabled, value)
+}
+
+// RetryAfter is the delay suggested to the clients, in whole seconds.
+func (m *Mode) RetryAfter() int {
+	if m == nil || m.retryAfter <= 0 {
+		return 0
+	}
+	return int(m.retryAfter.Round(time.Second) / time.Second)
+}
@@ -0,0 +1,128 @@
+package quarantine
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// Tracker counts the failures of each event id, quarantining it after the
+// threshold. Only the last MaxEvents event ids are kept in memory, and the
+// entries not updated within the TTL are forgotten. A nil tracker never
+// quarantines.
+type Tracker struct {
+	threshold int
+	maxEvents int
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order has the most recently updated event ids first.
+	order *list.List
+}
+
+type entry struct {
+	eventID     string
+	failures    int
+	quarantined bool
+	updatedAt   time.Time
+}
+
+// New returns nil when the quarantine is disabled.
+func New(cfg configuration.QuarantineConfig) *Tracker {
+	if cfg.Threshold <= 0 {
+		return nil
+	}
+
+	return &Tracker{
+		threshold: cfg.Threshold,
+		maxEvents: cfg.MaxEvents,
+		ttl:       cfg.TTL,
+		now:       time.Now,
+		entries:   map[string]*list.Element{},
+		order:     list.New(),
+	}
+}
+
+// Quarantined checks if the event id is quarantined.
+func (t *Tracker) Quarantined(eventID string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.get(eventID)
+	return e != nil && e.quarantined
+}
+
+// Failed counts a failure of the event id, returning true when it was just
+// quarantined.
+func (t *Tracker) Failed(eventID string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e := t.get(eventID)
+	if e == nil {
+		e = &entry{eventID: eventID}
+		t.entries[eventID] = t.order.PushFront(e)
+	}
+	t.order.MoveToFront(t.entries[eventID])
+
+	e.failures++
+	e.updatedAt = t.now()
+
+	for t.maxEvents > 0 && t.order.Len() > t.maxEvents {
+		t.remove(t.order.Back())
+	}
+
+	if e.quarantined || e.failures < t.threshold {
+		return false
+	}
+
+	e.quarantined = true
+	return true
+}
+
+// Succeeded forgets the failures of the event id.
+func (t *Tracker) Succeeded(eventID string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if element, ok := t.entries[eventID]; ok {
+		t.remove(element)
+	}
+}
+
+// get returns the entry of the event id, forgetting it when expired.
+func (t *Tracker) get(eventID string) *entry {
+	element, ok := t.entries[eventID]
+	if !ok {
+		return nil
+	}
+
+	e := element.Value.(*entry)
+	if t.ttl > 0 && t.now().Sub(e.updatedAt) > t.ttl {
+		t.remove(element)
+		return nil
+	}
+
+	return e
+}
+
+func (t *Tracker) remove(element *list.Element) {
+	t.order.Remove(element)
+	delete(t.entries, element.Value.(*entry).eventID)
+}
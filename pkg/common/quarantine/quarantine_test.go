@@ -0,0 +1,89 @@
+package quarantine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestTracker_Failed(t *testing.T) {
+	tracker := New(configuration.QuarantineConfig{Threshold: 3})
+
+	for i := 1; i <= 2; i++ {
+		if tracker.Failed("930bbd6d") {
+			t.Fatalf("Failed() quarantined after %d failures", i)
+		}
+	}
+	if tracker.Quarantined("930bbd6d") {
+		t.Fatal("Quarantined() before the threshold")
+	}
+
+	if !tracker.Failed("930bbd6d") {
+		t.Fatal("Failed() must quarantine at the threshold")
+	}
+	if !tracker.Quarantined("930bbd6d") {
+		t.Error("Quarantined() = false after the threshold")
+	}
+	if tracker.Failed("930bbd6d") {
+		t.Error("Failed() must quarantine only once")
+	}
+	if tracker.Quarantined("other") {
+		t.Error("Quarantined() other event id")
+	}
+}
+
+func TestTracker_Succeeded(t *testing.T) {
+	tracker := New(configuration.QuarantineConfig{Threshold: 2})
+
+	tracker.Failed("930bbd6d")
+	tracker.Succeeded("930bbd6d")
+
+	if tracker.Failed("930bbd6d") {
+		t.Error("Failed() must restart the count after a success")
+	}
+}
+
+func TestTracker_TTL(t *testing.T) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	tracker := New(configuration.QuarantineConfig{Threshold: 1, TTL: time.Hour})
+	tracker.now = func() time.Time { return now }
+
+	tracker.Failed("930bbd6d")
+	now = now.Add(59 * time.Minute)
+	if !tracker.Quarantined("930bbd6d") {
+		t.Fatal("Quarantined() = false within the TTL")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if tracker.Quarantined("930bbd6d") {
+		t.Error("Quarantined() = true after the TTL")
+	}
+}
+
+func TestTracker_MaxEvents(t *testing.T) {
+	tracker := New(configuration.QuarantineConfig{Threshold: 1, MaxEvents: 2})
+
+	tracker.Failed("1")
+	tracker.Failed("2")
+	tracker.Failed("3")
+
+	if tracker.Quarantined("1") {
+		t.Error("Quarantined() keeps the least recently failed event id")
+	}
+	if !tracker.Quarantined("2") || !tracker.Quarantined("3") {
+		t.Error("Quarantined() forgot the recently failed event ids")
+	}
+}
+
+func TestTracker_Disabled(t *testing.T) {
+	tracker := New(configuration.QuarantineConfig{})
+	if tracker != nil {
+		t.Fatalf("New() = %v, want nil", tracker)
+	}
+
+	if tracker.Failed("930bbd6d") || tracker.Quarantined("930bbd6d") {
+		t.Error("a nil tracker must never quarantine")
+	}
+	tracker.Succeeded("930bbd6d")
+}
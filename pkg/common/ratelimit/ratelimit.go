@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+const (
+	// ScopeSource limits the requests of a single client IP.
+	ScopeSource = "source"
+	// ScopeGlobal limits the requests of all the clients.
+	ScopeGlobal = "global"
+)
+
+// Limiter has a token bucket for each source, and a global one. A request
+// takes a token of both, and is limited when any of them is empty. Only the
+// last MaxSources sources are kept in memory. A nil limiter never limits.
+type Limiter struct {
+	rate       float64
+	burst      int
+	maxSources int
+	now        func() time.Time
+
+	mu     sync.Mutex
+	global *bucket
+	// entries is nil when the sources aren't limited.
+	entries map[string]*list.Element
+	// order has the most recently seen sources first.
+	order *list.List
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+type entry struct {
+	source string
+	bucket *bucket
+}
+
+// New returns nil when both limits are disabled.
+func New(cfg configuration.RateLimitConfig) (*Limiter, error) {
+	if cfg.Rate <= 0 && cfg.GlobalRate <= 0 {
+		return nil, nil
+	}
+	if cfg.Rate < 0 || cfg.GlobalRate < 0 || (cfg.Rate > 0 && cfg.Burst < 1) || (cfg.GlobalRate > 0 && cfg.GlobalBurst < 1) {
+		return nil, fmt.Errorf("the rate limits must be positive, with a positive burst")
+	}
+
+	l := &Limiter{
+		rate:       cfg.Rate,
+		burst:      cfg.Burst,
+		maxSources: cfg.MaxSources,
+		now:        time.Now,
+	}
+	if cfg.GlobalRate > 0 {
+		l.global = &bucket{rate: cfg.GlobalRate, burst: float64(cfg.GlobalBurst), tokens: float64(cfg.GlobalBurst)}
+	}
+	if cfg.Rate > 0 {
+		l.entries = map[string]*list.Element{}
+		l.order = list.New()
+	}
+
+	return l, nil
+}
+
+// Allow takes a token for the request of the source. When it's limited, it
+// returns the scope and the time until the next token, and no token is taken.
+func (l *Limiter) Allow(source string) (string, time.Duration) {
+	if l == nil {
+		return "", 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	var sourceBucket *bucket
+	if l.entries != nil {
+		sourceBucket = l.source(source, now)
+		if wait := sourceBucket.wait(now); wait > 0 {
+			return ScopeSource, wait
+		}
+	}
+
+	if l.global != nil {
+		if wait := l.global.wait(now); wait > 0 {
+			return ScopeGlobal, wait
+		}
+		l.global.tokens--
+	}
+
+	if sourceBucket != nil {
+		sourceBucket.tokens--
+	}
+
+	return "", 0
+}
+
+// source returns the bucket of the source, starting it full.
+func (l *Limiter) source(source string, now time.Time) *bucket {
+	if element, ok := l.entries[source]; ok {
+		l.order.MoveToFront(element)
+		return element.Value.(*entry).bucket
+	}
+
+	b := &bucket{rate: l.rate, burst: float64(l.burst), tokens: float64(l.burst), last: now}
+	l.entries[source] = l.order.PushFront(&entry{source: source, bucket: b})
+
+	for l.maxSources > 0 && l.order.Len() > l.maxSources {
+		element := l.order.Back()
+		l.order.Remove(element)
+		delete(l.entries, element.Value.(*entry).source)
+	}
+
+	return b
+}
+
+// wait refills the bucket, returning the time until it has a token.
+func (b *bucket) wait(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
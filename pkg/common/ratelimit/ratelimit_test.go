@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func newLimiter(t *testing.T, cfg configuration.RateLimitConfig) (*Limiter, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	limiter, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      configuration.RateLimitConfig
+		disabled bool
+		wantErr  bool
+	}{
+		{name: "Disabled", disabled: true},
+		{name: "Source limit", cfg: configuration.RateLimitConfig{Rate: 1, Burst: 1}},
+		{name: "Global limit", cfg: configuration.RateLimitConfig{GlobalRate: 10, GlobalBurst: 10}},
+		{name: "Without the burst", cfg: configuration.RateLimitConfig{Rate: 1}, wantErr: true},
+		{name: "Without the global burst", cfg: configuration.RateLimitConfig{GlobalRate: 1}, wantErr: true},
+		{name: "Negative rate", cfg: configuration.RateLimitConfig{Rate: -1, GlobalRate: 1, GlobalBurst: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.disabled {
+				t.Errorf("New() = %v, want disabled %v", got, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow_Source(t *testing.T) {
+	limiter, now := newLimiter(t, configuration.RateLimitConfig{Rate: 2, Burst: 3})
+
+	for i := 1; i <= 3; i++ {
+		if scope, _ := limiter.Allow("10.0.0.1"); scope != "" {
+			t.Fatalf("Allow() limited the request %d of the burst", i)
+		}
+	}
+	if scope, wait := limiter.Allow("10.0.0.1"); scope != ScopeSource || wait != 500*time.Millisecond {
+		t.Errorf("Allow() = %q, %s, want %q, 500ms", scope, wait, ScopeSource)
+	}
+	if scope, _ := limiter.Allow("10.0.0.2"); scope != "" {
+		t.Error("Allow() limited other source")
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if scope, _ := limiter.Allow("10.0.0.1"); scope != "" {
+		t.Error("Allow() limited after the refill")
+	}
+	if scope, _ := limiter.Allow("10.0.0.1"); scope != ScopeSource {
+		t.Error("Allow() didn't limit after taking the refilled token")
+	}
+
+	// The bucket is never refilled over the burst.
+	*now = now.Add(time.Hour)
+	for i := 1; i <= 3; i++ {
+		if scope, _ := limiter.Allow("10.0.0.1"); scope != "" {
+			t.Fatalf("Allow() limited the request %d of the burst", i)
+		}
+	}
+	if scope, _ := limiter.Allow("10.0.0.1"); scope != ScopeSource {
+		t.Error("Allow() over the burst")
+	}
+}
+
+func TestLimiter_Allow_Global(t *testing.T) {
+	limiter, now := newLimiter(t, configuration.RateLimitConfig{Rate: 1, Burst: 2, GlobalRate: 1, GlobalBurst: 3})
+
+	for _, source := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if scope, _ := limiter.Allow(source); scope != "" {
+			t.Fatalf("Allow() limited %s", source)
+		}
+	}
+	if scope, wait := limiter.Allow("10.0.0.4"); scope != ScopeGlobal || wait != time.Second {
+		t.Errorf("Allow() = %q, %s, want %q, 1s", scope, wait, ScopeGlobal)
+	}
+
+	// A request limited by the global bucket doesn't take a source token.
+	*now = now.Add(2 * time.Second)
+	for i := 1; i <= 2; i++ {
+		if scope, _ := limiter.Allow("10.0.0.4"); scope != "" {
+			t.Fatalf("Allow() limited the request %d", i)
+		}
+	}
+}
+
+func TestLimiter_Allow_MaxSources(t *testing.T) {
+	limiter, _ := newLimiter(t, configuration.RateLimitConfig{Rate: 1, Burst: 1, MaxSources: 2})
+
+	limiter.Allow("10.0.0.1")
+	limiter.Allow("10.0.0.2")
+	limiter.Allow("10.0.0.3")
+
+	if len(limiter.entries) != 2 {
+		t.Fatalf("Allow() tracks %d sources, want 2", len(limiter.entries))
+	}
+	// The least recently seen source is forgotten, so its bucket is full again.
+	if scope, _ := limiter.Allow("10.0.0.1"); scope != "" {
+		t.Error("Allow() limited a forgotten source")
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var limiter *Limiter
+	if scope, _ := limiter.Allow("10.0.0.1"); scope != "" {
+		t.Error("Allow() limited with a nil limiter")
+	}
+}
@@ -0,0 +1,150 @@
+package shedding
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+const (
+	// ScopeSource sheds the requests of a single client IP.
+	ScopeSource = "source"
+	// ScopeGlobal sheds the requests of all the clients.
+	ScopeGlobal = "global"
+)
+
+// Tracker counts the consecutive verification failures of each source, and of
+// all of them, shedding the requests for a while after the thresholds. Only
+// the last MaxSources sources are kept in memory. A nil tracker never sheds.
+type Tracker struct {
+	threshold       int
+	globalThreshold int
+	duration        time.Duration
+	maxSources      int
+	now             func() time.Time
+
+	mu             sync.Mutex
+	globalFailures int
+	globalUntil    time.Time
+	entries        map[string]*list.Element
+	// order has the most recently failed sources first.
+	order *list.List
+}
+
+type entry struct {
+	source   string
+	failures int
+	until    time.Time
+}
+
+// New returns nil when the shedding is disabled.
+func New(cfg configuration.SheddingConfig) *Tracker {
+	if cfg.Threshold <= 0 && cfg.GlobalThreshold <= 0 {
+		return nil
+	}
+
+	return &Tracker{
+		threshold:       cfg.Threshold,
+		globalThreshold: cfg.GlobalThreshold,
+		duration:        cfg.Duration,
+		maxSources:      cfg.MaxSources,
+		now:             time.Now,
+		entries:         map[string]*list.Element{},
+		order:           list.New(),
+	}
+}
+
+// Shed checks if the requests of the source are shed, returning the scope and
+// the time left.
+func (t *Tracker) Shed(source string) (string, time.Duration) {
+	if t == nil {
+		return "", 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if left := t.globalUntil.Sub(now); left > 0 {
+		return ScopeGlobal, left
+	}
+
+	if element, ok := t.entries[source]; ok {
+		if left := element.Value.(*entry).until.Sub(now); left > 0 {
+			return ScopeSource, left
+		}
+	}
+
+	return "", 0
+}
+
+// Failed counts a verification failure of the source, returning the scope
+// when it just started the shedding.
+func (t *Tracker) Failed(source string) string {
+	if t == nil {
+		return ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until := t.now().Add(t.duration)
+
+	if t.globalThreshold > 0 {
+		t.globalFailures++
+		if t.globalFailures >= t.globalThreshold {
+			t.globalFailures = 0
+			t.globalUntil = until
+			return ScopeGlobal
+		}
+	}
+
+	if t.threshold <= 0 {
+		return ""
+	}
+
+	element, ok := t.entries[source]
+	if !ok {
+		element = t.order.PushFront(&entry{source: source})
+		t.entries[source] = element
+	}
+	t.order.MoveToFront(element)
+
+	for t.maxSources > 0 && t.order.Len() > t.maxSources {
+		t.remove(t.order.Back())
+	}
+
+	e := element.Value.(*entry)
+	e.failures++
+	if e.failures < t.threshold {
+		return ""
+	}
+
+	// The failures are counted again once the shedding is over.
+	e.failures = 0
+	e.until = until
+	return ScopeSource
+}
+
+// Succeeded forgets the consecutive failures of the source, and the global
+// ones.
+func (t *Tracker) Succeeded(source string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.globalFailures = 0
+	if element, ok := t.entries[source]; ok {
+		t.remove(element)
+	}
+}
+
+func (t *Tracker) remove(element *list.Element) {
+	t.order.Remove(element)
+	delete(t.entries, element.Value.(*entry).source)
+}
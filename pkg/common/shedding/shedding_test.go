@@ -0,0 +1,101 @@
+package shedding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func newTracker(cfg configuration.SheddingConfig) (*Tracker, *time.Time) {
+	now := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	tracker := New(cfg)
+	tracker.now = func() time.Time { return now }
+
+	return tracker, &now
+}
+
+func TestTracker_Failed(t *testing.T) {
+	tracker, now := newTracker(configuration.SheddingConfig{Threshold: 3, Duration: time.Minute})
+
+	for i := 1; i <= 2; i++ {
+		if scope := tracker.Failed("10.0.0.1"); scope != "" {
+			t.Fatalf("Failed() shed after %d failures", i)
+		}
+	}
+	if scope, _ := tracker.Shed("10.0.0.1"); scope != "" {
+		t.Fatal("Shed() before the threshold")
+	}
+
+	if scope := tracker.Failed("10.0.0.1"); scope != ScopeSource {
+		t.Fatalf("Failed() = %q at the threshold, want %q", scope, ScopeSource)
+	}
+	if scope, left := tracker.Shed("10.0.0.1"); scope != ScopeSource || left != time.Minute {
+		t.Errorf("Shed() = %q, %s, want %q, 1m", scope, left, ScopeSource)
+	}
+	if scope, _ := tracker.Shed("10.0.0.2"); scope != "" {
+		t.Error("Shed() other source")
+	}
+
+	*now = now.Add(time.Minute)
+	if scope, _ := tracker.Shed("10.0.0.1"); scope != "" {
+		t.Error("Shed() after the duration")
+	}
+}
+
+func TestTracker_Succeeded(t *testing.T) {
+	tracker, _ := newTracker(configuration.SheddingConfig{Threshold: 2, GlobalThreshold: 2, Duration: time.Minute})
+
+	tracker.Failed("10.0.0.1")
+	tracker.Succeeded("10.0.0.1")
+
+	if scope := tracker.Failed("10.0.0.1"); scope != "" {
+		t.Errorf("Failed() = %q, must restart the count after a success", scope)
+	}
+}
+
+func TestTracker_Global(t *testing.T) {
+	tracker, _ := newTracker(configuration.SheddingConfig{GlobalThreshold: 3, Duration: time.Minute})
+
+	tracker.Failed("10.0.0.1")
+	tracker.Failed("10.0.0.2")
+	if scope, _ := tracker.Shed("10.0.0.3"); scope != "" {
+		t.Fatal("Shed() before the global threshold")
+	}
+
+	if scope := tracker.Failed("10.0.0.3"); scope != ScopeGlobal {
+		t.Fatalf("Failed() = %q at the global threshold, want %q", scope, ScopeGlobal)
+	}
+	if scope, _ := tracker.Shed("10.0.0.4"); scope != ScopeGlobal {
+		t.Errorf("Shed() = %q, want all the sources shed", scope)
+	}
+}
+
+func TestTracker_MaxSources(t *testing.T) {
+	tracker, _ := newTracker(configuration.SheddingConfig{Threshold: 2, MaxSources: 2, Duration: time.Minute})
+
+	tracker.Failed("10.0.0.1")
+	tracker.Failed("10.0.0.2")
+	tracker.Failed("10.0.0.3")
+
+	// The least recently failed source was forgotten.
+	if scope := tracker.Failed("10.0.0.1"); scope != "" {
+		t.Errorf("Failed() = %q, want the count restarted", scope)
+	}
+	if scope := tracker.Failed("10.0.0.3"); scope != ScopeSource {
+		t.Errorf("Failed() = %q, want %q", scope, ScopeSource)
+	}
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+
+	tracker.Failed("10.0.0.1")
+	tracker.Succeeded("10.0.0.1")
+	if scope, _ := tracker.Shed("10.0.0.1"); scope != "" {
+		t.Error("Shed() of a nil tracker")
+	}
+	if New(configuration.SheddingConfig{}) != nil {
+		t.Error("New() must be nil when disabled")
+	}
+}
@@ -0,0 +1,105 @@
+package tail
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeSuccess   = "success"
+	OutcomeDeferred  = "deferred"
+	OutcomeFailure   = "failure"
+	OutcomeDuplicate = "duplicate"
+)
+
+// subscriberBuffer is the number of events kept for a slow subscriber, the
+// newer events are dropped for it.
+const subscriberBuffer = 64
+
+// Event has only the processed notification metadata, never its payload.
+type Event struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	Outcome   string    `json:"outcome"`
+	Status    int       `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Buffer keeps the last events in a ring, and publishes the new ones to the
+// subscribers. A nil buffer records nothing.
+type Buffer struct {
+	mu          sync.Mutex
+	events      []Event
+	next        int
+	full        bool
+	subscribers map[chan Event]struct{}
+}
+
+func New(size int) *Buffer {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Buffer{
+		events:      make([]Event, size),
+		subscribers: map[chan Event]struct{}{},
+	}
+}
+
+// Add records the event, replacing the oldest one when the ring is full.
+func (b *Buffer) Add(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events[b.next] = event
+	b.next = (b.next + 1) % len(b.events)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Recent returns the recorded events, from the oldest to the newest.
+func (b *Buffer) Recent() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.recent()
+}
+
+func (b *Buffer) recent() []Event {
+	if !b.full {
+		return append([]Event{}, b.events[:b.next]...)
+	}
+
+	return append(append([]Event{}, b.events[b.next:]...), b.events[:b.next]...)
+}
+
+// Subscribe returns the recorded events and a channel with the events added
+// after them. The returned function must be called to unsubscribe.
+func (b *Buffer) Subscribe() ([]Event, <-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscriber := make(chan Event, subscriberBuffer)
+	b.subscribers[subscriber] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers, subscriber)
+	}
+
+	return b.recent(), subscriber, unsubscribe
+}
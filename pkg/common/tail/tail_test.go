@@ -0,0 +1,89 @@
+package tail
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func eventIDs(events []Event) []string {
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.EventID)
+	}
+	return ids
+}
+
+func TestBuffer_Recent(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		events []string
+		want   []string
+	}{
+		{
+			name: "Empty",
+			size: 3,
+			want: []string{},
+		},
+		{
+			name:   "Not full",
+			size:   3,
+			events: []string{"1", "2"},
+			want:   []string{"1", "2"},
+		},
+		{
+			name:   "Full",
+			size:   3,
+			events: []string{"1", "2", "3"},
+			want:   []string{"1", "2", "3"},
+		},
+		{
+			name:   "Rollover",
+			size:   3,
+			events: []string{"1", "2", "3", "4", "5"},
+			want:   []string{"3", "4", "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.size)
+			for _, id := range tt.events {
+				b.Add(Event{EventID: id})
+			}
+
+			if got := eventIDs(b.Recent()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Recent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuffer_Subscribe(t *testing.T) {
+	b := New(2)
+	b.Add(Event{EventID: "1"})
+
+	recent, events, unsubscribe := b.Subscribe()
+	if got := eventIDs(recent); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("Subscribe() recent = %v", got)
+	}
+
+	b.Add(Event{EventID: "2"})
+	select {
+	case event := <-events:
+		if event.EventID != "2" {
+			t.Errorf("Subscribe() event = %v, want 2", event.EventID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Subscribe() new event not published")
+	}
+
+	unsubscribe()
+	b.Add(Event{EventID: "3"})
+	select {
+	case event := <-events:
+		t.Errorf("Subscribe() event %v published after unsubscribe", event.EventID)
+	default:
+	}
+}
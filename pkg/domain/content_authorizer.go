@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// ContentAuthorizer checks the decrypted notification, like rejecting the
+// entities of other tenants. It returns an error wrapping
+// ErrUnauthorizedContent to reject the notification.
+type ContentAuthorizer interface {
+	Authorize(ctx context.Context, notification Notification) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeadLetter is a notification whose publish failed after all the attempts,
+// with the envelope as received, so it can be replayed.
+type DeadLetter struct {
+	Input        NotificationInput
+	Notification Notification
+	Reason       string
+	FailedAt     time.Time
+}
+
+// DeadLetterStore keeps the notifications that couldn't be published, to be
+// investigated and replayed.
+type DeadLetterStore interface {
+	Configure(log *logrus.Logger) error
+	Store(ctx context.Context, deadLetter DeadLetter) error
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrArchiveFailed   = errors.New("unable to archive raw notification")
+	ErrPayloadMismatch = errors.New("notification headers don't match the payload")
+	// ErrEmptyPayload is returned when the decrypted body is empty, and its
+	// event type isn't allowed to be empty.
+	ErrEmptyPayload = errors.New("empty payload")
+	// ErrUntrustedCertificate is returned when the x5c certificate chain
+	// isn't trusted.
+	ErrUntrustedCertificate = errors.New("untrusted certificate chain")
+	// ErrUnsupportedCritical is returned when a JOSE header has a critical
+	// parameter that isn't understood.
+	ErrUnsupportedCritical = errors.New("unsupported critical header parameter")
+	// ErrTooManyIterations is returned when the PBES2 iteration count is
+	// greater than the configured maximum.
+	ErrTooManyIterations = errors.New("PBES2 iteration count exceeds the maximum")
+	// ErrDecryptLimit is returned when the encrypted or decrypted payload is
+	// larger than the configured limits.
+	ErrDecryptLimit = errors.New("decryption limit exceeded")
+	// ErrVerificationFailed is returned when the signature verification, or
+	// the decryption, fails.
+	ErrVerificationFailed = errors.New("verification failed")
+	// ErrUnauthorizedContent is returned when the content authorizer rejects
+	// the notification.
+	ErrUnauthorizedContent = errors.New("notification content not authorized")
+	// ErrOrderingBusy is returned when the notification can't wait, or be
+	// queued, behind the others with the same ordering key. It's meant to be
+	// redelivered.
+	ErrOrderingBusy = errors.New("notifications with the same ordering key are still being sent")
+	// ErrFieldTooLong is returned when a string in the payload is longer than
+	// the maximum length of its field.
+	ErrFieldTooLong = errors.New("payload field too long")
+	// ErrTransformFailed is the dead letter reason when the transformation
+	// template of the event type fails on the payload.
+	ErrTransformFailed = errors.New("unable to transform the payload")
+	// ErrInvalidSchema is returned when the payload doesn't match the JSON
+	// Schema of its event type.
+	ErrInvalidSchema = errors.New("payload doesn't match its schema")
+	// ErrNotificationNotFound is returned when the event id isn't in the
+	// notification store.
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// VerificationError wraps the failure of the signature verification, or of
+// the decryption, keeping its cause.
+type VerificationError struct {
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *VerificationError) Is(target error) bool {
+	return target == ErrVerificationFailed
+}
+
+// FieldTooLongError names the field longer than its maximum length.
+type FieldTooLongError struct {
+	Path      string
+	MaxLength int
+	Length    int
+}
+
+func (e *FieldTooLongError) Error() string {
+	return fmt.Sprintf("field %s has %d characters, more than the maximum of %d", e.Path, e.Length, e.MaxLength)
+}
+
+func (e *FieldTooLongError) Is(target error) bool {
+	return target == ErrFieldTooLong
+}
+
+// SchemaError has the failed keywords of the payload, by path, without their
+// values.
+type SchemaError struct {
+	Schema string
+	Errors []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("payload doesn't match the schema of %s: %s", e.Schema, strings.Join(e.Errors, "; "))
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrInvalidSchema
+}
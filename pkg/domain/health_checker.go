@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// HealthChecker is implemented by the notifiers and stores whose backend can
+// be reached, to check the readiness.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
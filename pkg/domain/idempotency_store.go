@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// IdempotencyStore remembers the event ids already sent, so their
+// redeliveries aren't sent again.
+type IdempotencyStore interface {
+	Configure(log *logrus.Logger) error
+	// Seen checks if the event id was remembered, and hasn't expired yet.
+	Seen(ctx context.Context, eventID string) (bool, error)
+	// Remember keeps the event id until the window expires.
+	Remember(ctx context.Context, eventID string, window time.Duration) error
+}
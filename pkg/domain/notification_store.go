@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StoredNotification is the delivery record of a verified notification, with
+// the envelope as received, so it can be inspected and replayed.
+type StoredNotification struct {
+	Input NotificationInput
+	// Status is the outcome of the last processing, like sent or
+	// delivery_failed.
+	Status string
+	// Error is the reason of the last failure. It's empty on success.
+	Error string
+	// Attempts counts the receptions and replays of the event id.
+	Attempts   int
+	ReceivedAt time.Time
+	UpdatedAt  time.Time
+}
+
+// NotificationFilter selects the stored notifications, its empty fields match
+// all of them.
+type NotificationFilter struct {
+	EventType string
+	Status    string
+	// Since and Until bound the last update of the notifications.
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Match checks if the stored notification is selected by the filter, without
+// its limit.
+func (f NotificationFilter) Match(stored StoredNotification) bool {
+	switch {
+	case f.EventType != "" && stored.Input.Header.EventType != f.EventType:
+		return false
+	case f.Status != "" && stored.Status != f.Status:
+		return false
+	case !f.Since.IsZero() && stored.UpdatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && stored.UpdatedAt.After(f.Until):
+		return false
+	}
+
+	return true
+}
+
+// NotificationStore records the delivery of the verified notifications, to be
+// listed and replayed by the admin API.
+type NotificationStore interface {
+	Configure(log *logrus.Logger) error
+	// Save replaces the record of the event id, keeping its first ReceivedAt
+	// and counting its attempts, until the retention expires.
+	Save(ctx context.Context, stored StoredNotification, retention time.Duration) error
+	// Get fails with ErrNotificationNotFound when the event id isn't stored.
+	Get(ctx context.Context, eventID string) (StoredNotification, error)
+	// List returns the notifications matching the filter, the most recently
+	// updated first.
+	List(ctx context.Context, filter NotificationFilter) ([]StoredNotification, error)
+}
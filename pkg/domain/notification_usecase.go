@@ -2,18 +2,95 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type NotificationInput struct {
 	Header        HeaderNotification
 	EncryptedBody string
+	// RawBody is the request body byte for byte, before decoding the
+	// envelope. It's nil when the input isn't from a request, like the
+	// imported ones.
+	RawBody []byte
+	// Unsigned marks an encrypted body that is a JWE without the outer JWS,
+	// received on the unsigned route. Its signature isn't verified.
+	Unsigned bool
+	// Replay marks a stored notification replayed by the admin API. It's sent
+	// even if its event id was already sent, or is quarantined.
+	Replay bool
 }
 
 type HeaderNotification struct {
 	EventID   string
 	EventType string
+	// CreatedAt is the event creation timestamp header, as received. It's
+	// empty when its header isn't configured or sent.
+	CreatedAt string
+	// RawEventType is the event type as received, when the normalization
+	// changed it.
+	RawEventType string
+}
+
+// ReceivedEventType returns the event type as received, before the
+// normalization.
+func (h HeaderNotification) ReceivedEventType() string {
+	if h.RawEventType != "" {
+		return h.RawEventType
+	}
+
+	return h.EventType
+}
+
+// Notification is the verified and decrypted notification sent to the
+// notifiers.
+type Notification struct {
+	Header HeaderNotification
+	// Body is the decrypted payload, as received.
+	Body   string
+	Fields NotificationFields
+	// Ciphertext is true when Body is the inner JWE, not decrypted in the
+	// verify-only mode.
+	Ciphertext bool
+	Processing ProcessingMetadata
+}
+
+// ProcessingMetadata describes how the notification was processed, for the
+// metadata added to the published messages.
+type ProcessingMetadata struct {
+	// ReceivedAt is when the usecase received the notification.
+	ReceivedAt time.Time
+	// KeyID is the kid of the key that verified the signature. It's empty
+	// when the key has none, or the notification is unsigned.
+	KeyID string
+}
+
+// NotificationFields are the well-known fields extracted from the body, so
+// the notifiers don't need to parse it. A field is empty when its extraction
+// is disabled or it's not in the body.
+type NotificationFields struct {
+	Timestamp  time.Time
+	EntityType string
+	Version    string
+	// PartitionKey is the ordering, or partition, key of the publishers. It's
+	// the event id when its extraction is disabled or it's not in the body.
+	PartitionKey string
+}
+
+// NotificationOutput describes how the notification was handled.
+type NotificationOutput struct {
+	// Deferred is true when the notification was accepted to be sent later.
+	Deferred bool
+	// Duplicate is true when the event id was already sent, so the
+	// notification was acked without being sent again.
+	Duplicate bool
+	// Receipt is a signed token proving the notification was accepted. It's
+	// empty when the receipts are disabled.
+	Receipt string
+	// Verified is true once the notification is authenticated, by its
+	// signature or, when unsigned, its decryption, even if it fails later.
+	Verified bool
 }
 
 type NotificationUsecase interface {
-	SendNotification(ctx context.Context, input NotificationInput) error
+	SendNotification(ctx context.Context, input NotificationInput) (NotificationOutput, error)
 }
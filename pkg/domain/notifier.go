@@ -8,5 +8,39 @@ import (
 
 type Notifier interface {
 	Configure(log *logrus.Logger) error
-	Send(ctx context.Context, eventTypeHeader, eventIDHeader, body string) error
+	Send(ctx context.Context, notification Notification) error
+}
+
+// DeferredNotifier accepts the notifications to send them later. Shutdown
+// stops accepting new notifications and waits for the pending ones until the
+// context is done.
+type DeferredNotifier interface {
+	Notifier
+	Shutdown(ctx context.Context) error
+}
+
+// NamedNotifier is named in the publish errors, by its name in the notifier
+// list.
+type NamedNotifier interface {
+	Notifier
+	Name() string
+}
+
+// BatchNotifier sends many notifications at once, returning the error of each
+// notification, in the same order.
+type BatchNotifier interface {
+	Notifier
+	SendBatch(ctx context.Context, notifications []Notification) []error
+}
+
+// MessageSerializer builds the message published by the notifiers, returning
+// its body and headers (or attributes).
+type MessageSerializer interface {
+	Serialize(notification Notification) ([]byte, map[string]string, error)
+}
+
+// SerializedNotifier publishes the messages built by a serializer.
+type SerializedNotifier interface {
+	Notifier
+	SetSerializer(serializer MessageSerializer)
 }
@@ -0,0 +1,8 @@
+package domain
+
+// ProcessingConfirmer confirms to the provider that a notification was
+// processed, like by calling back a provider endpoint, besides the HTTP
+// response. Confirm must not block, the confirmation is sent later.
+type ProcessingConfirmer interface {
+	Confirm(header HeaderNotification)
+}
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RawArchiver stores the notification exactly as it was received, before any
+// verification or decryption, so the signed artifact can be audited later.
+type RawArchiver interface {
+	Configure(log *logrus.Logger) error
+	Archive(ctx context.Context, input NotificationInput) error
+}
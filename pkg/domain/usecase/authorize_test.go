@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// accountAuthorizer allows only the account in the payload.
+type accountAuthorizer struct {
+	account string
+}
+
+func (a accountAuthorizer) Authorize(ctx context.Context, notification domain.Notification) error {
+	if account, _ := jsonpath.LookupString([]byte(notification.Body), "account_id"); account != a.account {
+		return fmt.Errorf("%w: account %s", domain.ErrUnauthorizedContent, account)
+	}
+
+	return nil
+}
+
+func TestNotificationUsecase_SendNotification_Authorizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer domain.ContentAuthorizer
+		payload    string
+		wantErr    error
+	}{
+		{
+			name:    "Allow all by default",
+			payload: `{"account_id":"acc-2"}`,
+		},
+		{
+			name:       "Authorized",
+			authorizer: accountAuthorizer{account: "acc-1"},
+			payload:    `{"account_id":"acc-1"}`,
+		},
+		{
+			name:       "Unauthorized",
+			authorizer: accountAuthorizer{account: "acc-1"},
+			payload:    `{"account_id":"acc-2"}`,
+			wantErr:    domain.ErrUnauthorizedContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+
+			log := logrus.New()
+			log.SetOutput(ioutil.Discard)
+			uc := NewNotificationUsecase(configuration.Config{}, log, keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+			if tt.authorizer != nil {
+				uc.SetAuthorizer(tt.authorizer)
+			}
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: signAndEncrypt(t, tt.payload),
+			}
+			_, err := uc.SendNotification(context.Background(), input)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("SendNotification() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantSent := 1
+			if tt.wantErr != nil {
+				wantSent = 0
+			}
+			if len(notifier.bodies) != wantSent {
+				t.Errorf("SendNotification() sent %d, want %d", len(notifier.bodies), wantSent)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// hasCertificateChain reports if the protected header of the compact signed
+// body has a x5c certificate chain.
+func hasCertificateChain(signedBody string) bool {
+	segments := strings.SplitN(strings.TrimSpace(signedBody), ".", 2)
+	protected, err := base64.RawURLEncoding.DecodeString(segments[0])
+	if err != nil {
+		return false
+	}
+
+	var header struct {
+		X5C []string `json:"x5c"`
+	}
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return false
+	}
+
+	return len(header.X5C) > 0
+}
+
+// certificateChainKey returns the leaf certificate public key, once its chain
+// is verified against the trusted CAs, its names are allowed and its key is
+// strong enough.
+func (uc NotificationUsecase) certificateChainKey(keyConfig *keys.Config, header jose.Header) (interface{}, error) {
+	chains, err := header.Certificates(x509.VerifyOptions{
+		Roots:     keyConfig.CertificateRoots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUntrustedCertificate, err)
+	}
+
+	leaf := chains[0][0]
+	if !allowedCertificateName(leaf, keyConfig.CertificateNames) {
+		return nil, fmt.Errorf("%w: name not allowed: %s", domain.ErrUntrustedCertificate, leaf.Subject.CommonName)
+	}
+
+	if err := keyConfig.KeyStrength.Check(leaf.PublicKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUntrustedCertificate, err)
+	}
+
+	return leaf.PublicKey, nil
+}
+
+func allowedCertificateName(cert *x509.Certificate, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, name := range names {
+		if cert.Subject.CommonName == name {
+			return true
+		}
+
+		for _, dnsName := range cert.DNSNames {
+			if dnsName == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCertificate creates a certificate signed by the parent, or a self
+// signed CA when the parent is nil.
+func newTestCertificate(t *testing.T, name string, parent *testCertificate, notAfter time.Time) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+func signWithCertificate(t *testing.T, leaf *testCertificate, payload string) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{}).WithHeader("x5c", []string{base64.StdEncoding.EncodeToString(leaf.cert.Raw)})
+	return signWithOptions(t, jose.SigningKey{Algorithm: jose.ES256, Key: leaf.key}, opts, payload)
+}
+
+func TestNotificationUsecase_verify_CertificateChain(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+	trustedCA := newTestCertificate(t, "Trusted CA", nil, validUntil)
+	untrustedCA := newTestCertificate(t, "Untrusted CA", nil, validUntil)
+	payload := encrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name        string
+		signedBody  func(t *testing.T) string
+		allowedName []string
+		curves      []string
+		wantErr     error
+		wantInvalid bool
+	}{
+		{
+			name: "Trusted chain",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "webhook.stone.com.br", trustedCA, validUntil), payload)
+			},
+		},
+		{
+			name: "Trusted chain with an allowed name",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "webhook.stone.com.br", trustedCA, validUntil), payload)
+			},
+			allowedName: []string{"webhook.stone.com.br"},
+		},
+		{
+			name: "Trusted chain with a name not allowed",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "other.example.com", trustedCA, validUntil), payload)
+			},
+			allowedName: []string{"webhook.stone.com.br"},
+			wantErr:     domain.ErrUntrustedCertificate,
+		},
+		{
+			name: "Trusted chain with a curve not allowed",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "webhook.stone.com.br", trustedCA, validUntil), payload)
+			},
+			curves:  []string{"P-384"},
+			wantErr: domain.ErrUntrustedCertificate,
+		},
+		{
+			name: "Untrusted chain",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "webhook.stone.com.br", untrustedCA, validUntil), payload)
+			},
+			wantErr: domain.ErrUntrustedCertificate,
+		},
+		{
+			name: "Expired certificate",
+			signedBody: func(t *testing.T) string {
+				return signWithCertificate(t, newTestCertificate(t, "webhook.stone.com.br", trustedCA, time.Now().Add(-time.Hour)), payload)
+			},
+			wantErr: domain.ErrUntrustedCertificate,
+		},
+		{
+			name: "Signed by another key than the certificate",
+			signedBody: func(t *testing.T) string {
+				leaf := newTestCertificate(t, "webhook.stone.com.br", trustedCA, validUntil)
+				leaf.key = newTestCertificate(t, "webhook.stone.com.br", trustedCA, validUntil).key
+				return signWithCertificate(t, leaf, payload)
+			},
+			wantInvalid: true,
+		},
+		{
+			name: "Without x5c uses the public keys",
+			signedBody: func(t *testing.T) string {
+				return sign(t, stoneSigningKey(t), payload)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testKeys := loadTestKeys(t)
+			testKeys.SignatureAlgorithms = append(testKeys.SignatureAlgorithms, jose.ES256)
+			testKeys.CertificateRoots = x509.NewCertPool()
+			testKeys.CertificateRoots.AddCert(trustedCA.cert)
+			testKeys.CertificateNames = tt.allowedName
+			testKeys.KeyStrength.Curves = tt.curves
+
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			got, err := uc.verify(testKeys, tt.signedBody(t))
+			if tt.wantInvalid {
+				if err == nil || errors.Is(err, domain.ErrUntrustedCertificate) {
+					t.Errorf("verify() error = %v, want an invalid signature", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != payload {
+				t.Errorf("verify() = %v, want the signed payload", got)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_verify_CertificateChainDisabled(t *testing.T) {
+	ca := newTestCertificate(t, "Trusted CA", nil, time.Now().Add(time.Hour))
+	leaf := newTestCertificate(t, "webhook.stone.com.br", ca, time.Now().Add(time.Hour))
+
+	testKeys := loadTestKeys(t)
+	testKeys.SignatureAlgorithms = append(testKeys.SignatureAlgorithms, jose.ES256)
+	uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+	// Without trusted CAs, the chain is ignored and the public keys don't
+	// verify the signature.
+	if _, err := uc.verify(testKeys, signWithCertificate(t, leaf, encrypt(t, "{}"))); err == nil {
+		t.Errorf("verify() must not trust the x5c chain")
+	}
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// checkPayload fails when the decrypted payload has a value that doesn't
+// match the notification headers.
+func (uc NotificationUsecase) checkPayload(header domain.HeaderNotification, payload string) error {
+	// An empty payload is only expected in some event types, like heartbeats,
+	// and has nothing else to check.
+	if strings.TrimSpace(payload) == "" {
+		if uc.emptyPayloadTypes[header.EventType] {
+			return nil
+		}
+		return fmt.Errorf("%w: event type %s", domain.ErrEmptyPayload, header.EventType)
+	}
+
+	if uc.payloadCheck.EventIDCheck {
+		eventID, ok := jsonpath.LookupString([]byte(payload), uc.payloadCheck.EventIDPath)
+		if ok && eventID != header.EventID {
+			return fmt.Errorf("%w: event id header [%s], payload [%s]", domain.ErrPayloadMismatch, header.EventID, eventID)
+		}
+	}
+
+	if uc.payloadCheck.EventTypeCheck {
+		eventType, ok := jsonpath.LookupString([]byte(payload), uc.payloadCheck.EventTypePath)
+		// The payload has the event type as sent, not normalized.
+		if ok && eventType != header.ReceivedEventType() {
+			return fmt.Errorf("%w: event type header [%s], payload [%s]", domain.ErrPayloadMismatch, header.ReceivedEventType(), eventType)
+		}
+	}
+
+	return uc.checkFieldLengths(payload)
+}
+
+// fieldLimit is the maximum length of the string in a JSON path.
+type fieldLimit struct {
+	path      string
+	maxLength int
+}
+
+// CheckPayloadConfig validates the maximum lengths of the fields.
+func CheckPayloadConfig(cfg configuration.PayloadCheckConfig) error {
+	_, err := parseFieldLimits(cfg.FieldMaxLengths)
+	return err
+}
+
+// parseFieldLimits parses the "path=length" items, in order.
+func parseFieldLimits(value string) ([]fieldLimit, error) {
+	limits := []fieldLimit{}
+	for _, item := range configuration.SplitList(value) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid field max length, expected path=length: %v", item)
+		}
+
+		maxLength, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || maxLength < 1 {
+			return nil, fmt.Errorf("invalid max length of field %s: %v", parts[0], parts[1])
+		}
+
+		limits = append(limits, fieldLimit{path: strings.TrimSpace(parts[0]), maxLength: maxLength})
+	}
+
+	return limits, nil
+}
+
+// checkFieldLengths fails on the first string longer than its field limit.
+// The fields that are missing, or aren't strings, have nothing to check.
+func (uc NotificationUsecase) checkFieldLengths(payload string) error {
+	for _, limit := range uc.fieldLimits {
+		value, ok := jsonpath.Lookup([]byte(payload), limit.path)
+		if !ok {
+			continue
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if length := utf8.RuneCountInString(text); length > limit.maxLength {
+			return &domain.FieldTooLongError{Path: limit.path, MaxLength: limit.maxLength, Length: length}
+		}
+	}
+
+	return nil
+}
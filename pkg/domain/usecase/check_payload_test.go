@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_checkPayload(t *testing.T) {
+	header := domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"}
+
+	tests := []struct {
+		name    string
+		cfg     configuration.PayloadCheckConfig
+		payload string
+		wantErr error
+	}{
+		{
+			name:    "Matching event id",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+			payload: `{"id":"930bbd6d"}`,
+		},
+		{
+			name:    "Mismatching event id",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+			payload: `{"id":"other-event"}`,
+			wantErr: domain.ErrPayloadMismatch,
+		},
+		{
+			name:    "Nested event id path",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "event.id"},
+			payload: `{"event":{"id":"other-event"}}`,
+			wantErr: domain.ErrPayloadMismatch,
+		},
+		{
+			name:    "Payload without event id",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+			payload: `{"target_data":{}}`,
+		},
+		{
+			name:    "Matching event type",
+			cfg:     configuration.PayloadCheckConfig{EventTypeCheck: true, EventTypePath: "type"},
+			payload: `{"type":"cash_in_internal_transfer"}`,
+		},
+		{
+			name:    "Mismatching event type",
+			cfg:     configuration.PayloadCheckConfig{EventTypeCheck: true, EventTypePath: "type"},
+			payload: `{"type":"cash_out_internal_transfer"}`,
+			wantErr: domain.ErrPayloadMismatch,
+		},
+		{
+			name:    "Nested event type path",
+			cfg:     configuration.PayloadCheckConfig{EventTypeCheck: true, EventTypePath: "event.type"},
+			payload: `{"event":{"type":"cash_out_internal_transfer"}}`,
+			wantErr: domain.ErrPayloadMismatch,
+		},
+		{
+			name:    "Payload without event type",
+			cfg:     configuration.PayloadCheckConfig{EventTypeCheck: true, EventTypePath: "type"},
+			payload: `{"target_data":{}}`,
+		},
+		{
+			name:    "Matching event id, mismatching event type",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id", EventTypeCheck: true, EventTypePath: "type"},
+			payload: `{"id":"930bbd6d","type":"cash_out_internal_transfer"}`,
+			wantErr: domain.ErrPayloadMismatch,
+		},
+		{
+			name:    "Event type check disabled",
+			cfg:     configuration.PayloadCheckConfig{EventTypeCheck: false, EventTypePath: "type"},
+			payload: `{"type":"cash_out_internal_transfer"}`,
+		},
+		{
+			name:    "Empty payload",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+			payload: "",
+			wantErr: domain.ErrEmptyPayload,
+		},
+		{
+			name:    "Blank payload",
+			cfg:     configuration.PayloadCheckConfig{},
+			payload: " \n",
+			wantErr: domain.ErrEmptyPayload,
+		},
+		{
+			name:    "Empty payload allowed to the event type",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id", EmptyPayloadEventTypes: "heartbeat;cash_in_internal_transfer"},
+			payload: "",
+		},
+		{
+			name:    "Empty payload allowed to other event types",
+			cfg:     configuration.PayloadCheckConfig{EmptyPayloadEventTypes: "heartbeat"},
+			payload: "",
+			wantErr: domain.ErrEmptyPayload,
+		},
+		{
+			name:    "Check disabled",
+			cfg:     configuration.PayloadCheckConfig{EventIDCheck: false, EventIDPath: "id"},
+			payload: `{"id":"other-event"}`,
+		},
+		{
+			name:    "Field within its max length",
+			cfg:     configuration.PayloadCheckConfig{FieldMaxLengths: "description=5;target_data.name=3"},
+			payload: `{"description":"short","target_data":{"name":"Zoë"}}`,
+		},
+		{
+			name:    "Field longer than its max length",
+			cfg:     configuration.PayloadCheckConfig{FieldMaxLengths: "description=5"},
+			payload: `{"description":"too long"}`,
+			wantErr: domain.ErrFieldTooLong,
+		},
+		{
+			name:    "Nested field longer than its max length",
+			cfg:     configuration.PayloadCheckConfig{FieldMaxLengths: "description=100;target_data.name=3"},
+			payload: `{"description":"short","target_data":{"name":"Zoey"}}`,
+			wantErr: domain.ErrFieldTooLong,
+		},
+		{
+			name:    "Fields without a string",
+			cfg:     configuration.PayloadCheckConfig{FieldMaxLengths: "amount=1;target_data=1;description=1"},
+			payload: `{"amount":123456,"target_data":{"name":"account"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewNotificationUsecase(configuration.Config{PayloadCheckConfig: tt.cfg}, logrus.New(), nil, nil, nil)
+
+			if err := uc.checkPayload(header, tt.payload); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_checkPayload_NormalizedEventType(t *testing.T) {
+	// The payload has the event type as sent, before the normalization.
+	header := domain.HeaderNotification{EventID: "930bbd6d", EventType: "payment.created", RawEventType: "Payment-Created"}
+	cfg := configuration.PayloadCheckConfig{EventTypeCheck: true, EventTypePath: "type"}
+	uc := NewNotificationUsecase(configuration.Config{PayloadCheckConfig: cfg}, logrus.New(), nil, nil, nil)
+
+	if err := uc.checkPayload(header, `{"type":"Payment-Created"}`); err != nil {
+		t.Errorf("checkPayload() error = %v, want the received event type matched", err)
+	}
+	if err := uc.checkPayload(header, `{"type":"payment.created"}`); !errors.Is(err, domain.ErrPayloadMismatch) {
+		t.Errorf("checkPayload() error = %v, want %v", err, domain.ErrPayloadMismatch)
+	}
+}
+
+func TestCheckPayloadConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		fieldMaxLengths string
+		wantErr         bool
+	}{
+		{name: "Without limits"},
+		{name: "Limits", fieldMaxLengths: "description=1024; target_data.name = 256;"},
+		{name: "Without length", fieldMaxLengths: "description", wantErr: true},
+		{name: "Invalid length", fieldMaxLengths: "description=long", wantErr: true},
+		{name: "Zero length", fieldMaxLengths: "description=0", wantErr: true},
+		{name: "Without path", fieldMaxLengths: "=10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPayloadConfig(configuration.PayloadCheckConfig{FieldMaxLengths: tt.fieldMaxLengths})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPayloadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFieldTooLongError(t *testing.T) {
+	cfg := configuration.PayloadCheckConfig{FieldMaxLengths: "description=5"}
+	uc := NewNotificationUsecase(configuration.Config{PayloadCheckConfig: cfg}, logrus.New(), nil, nil, nil)
+
+	err := uc.checkPayload(domain.HeaderNotification{}, `{"description":"too long"}`)
+
+	var fieldErr *domain.FieldTooLongError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("checkPayload() error = %v, want a field error", err)
+	}
+	if *fieldErr != (domain.FieldTooLongError{Path: "description", MaxLength: 5, Length: 8}) {
+		t.Errorf("checkPayload() error = %+v", *fieldErr)
+	}
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeConfirmer struct {
+	confirmed chan domain.HeaderNotification
+}
+
+func (f *fakeConfirmer) Confirm(header domain.HeaderNotification) {
+	f.confirmed <- header
+}
+
+func TestNotificationUsecase_SendNotification_Confirm(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name          string
+		delay         time.Duration
+		err           error
+		wantConfirmed bool
+	}{
+		{
+			name:          "Sent notification is confirmed",
+			wantConfirmed: true,
+		},
+		{
+			name:  "Failed notification isn't confirmed",
+			err:   errors.New("unavailable"),
+			delay: 0,
+		},
+		{
+			name:          "Detached notification is confirmed once sent",
+			delay:         100 * time.Millisecond,
+			wantConfirmed: true,
+		},
+		{
+			name:  "Failed detached notification isn't confirmed",
+			delay: 100 * time.Millisecond,
+			err:   errors.New("unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &slowNotifier{delay: tt.delay, err: tt.err, sent: make(chan error, 1)}
+			cfg := configuration.Config{PublishConfig: configuration.PublishConfig{SoftDeadline: 20 * time.Millisecond}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+			confirmer := &fakeConfirmer{confirmed: make(chan domain.HeaderNotification, 1)}
+			uc.SetConfirmer(confirmer)
+
+			header := domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"}
+			_, _ = uc.SendNotification(context.Background(), domain.NotificationInput{Header: header, EncryptedBody: encryptedBody})
+			<-notifier.sent
+
+			select {
+			case got := <-confirmer.confirmed:
+				if !tt.wantConfirmed {
+					t.Fatalf("SendNotification() confirmed %v", got)
+				}
+				if got != header {
+					t.Errorf("SendNotification() confirmed %v, want %v", got, header)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantConfirmed {
+					t.Fatal("SendNotification() never confirmed")
+				}
+			}
+		})
+	}
+}
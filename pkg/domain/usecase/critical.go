@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const headerCritical = jose.HeaderKey("crit")
+
+var (
+	// jwsCriticalParams are the critical JWS parameters understood. go-jose
+	// implements the unencoded payload option (RFC 7797).
+	jwsCriticalParams = map[string]bool{
+		"b64": true,
+	}
+	// jweCriticalParams is empty, no JWE extension is implemented.
+	jweCriticalParams = map[string]bool{}
+)
+
+// checkCritical rejects the headers requiring a parameter not understood, as
+// required by RFC 7515 section 4.1.11. The listed parameters must also be in
+// the header.
+func checkCritical(header jose.Header, understood map[string]bool) error {
+	value, ok := header.ExtraHeaders[headerCritical]
+	if !ok {
+		return nil
+	}
+
+	names, ok := value.([]interface{})
+	if !ok || len(names) == 0 {
+		return fmt.Errorf("%w: crit must be a non-empty list", domain.ErrUnsupportedCritical)
+	}
+
+	for _, item := range names {
+		name, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("%w: crit must be a list of names", domain.ErrUnsupportedCritical)
+		}
+
+		if !understood[name] {
+			return fmt.Errorf("%w: %s", domain.ErrUnsupportedCritical, name)
+		}
+
+		if _, ok := header.ExtraHeaders[jose.HeaderKey(name)]; !ok {
+			return fmt.Errorf("%w: %s is not in the header", domain.ErrUnsupportedCritical, name)
+		}
+	}
+
+	return nil
+}
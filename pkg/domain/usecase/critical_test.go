@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_SendNotification_Critical(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	body := `{"event_type":"cash_in_internal_transfer"}`
+
+	tests := []struct {
+		name          string
+		encryptedBody func(t *testing.T) string
+		wantErr       error
+	}{
+		{
+			name: "Without crit",
+			encryptedBody: func(t *testing.T) string {
+				return signAndEncrypt(t, body)
+			},
+		},
+		{
+			name: "JWS with a recognized crit parameter",
+			encryptedBody: func(t *testing.T) string {
+				opts := (&jose.SignerOptions{}).WithBase64(true)
+				return signWithOptions(t, stoneSigningKey(t), opts, encrypt(t, body))
+			},
+		},
+		{
+			name: "JWS with an unrecognized crit parameter",
+			encryptedBody: func(t *testing.T) string {
+				opts := (&jose.SignerOptions{}).WithHeader("exp", 1606780800).WithCritical("exp")
+				return signWithOptions(t, stoneSigningKey(t), opts, encrypt(t, body))
+			},
+			wantErr: domain.ErrUnsupportedCritical,
+		},
+		{
+			name: "JWS with a crit parameter missing in the header",
+			encryptedBody: func(t *testing.T) string {
+				opts := (&jose.SignerOptions{}).WithCritical("b64")
+				return signWithOptions(t, stoneSigningKey(t), opts, encrypt(t, body))
+			},
+			wantErr: domain.ErrUnsupportedCritical,
+		},
+		{
+			name: "JWE with an unrecognized crit parameter",
+			encryptedBody: func(t *testing.T) string {
+				opts := (&jose.EncrypterOptions{}).WithHeader("exp", 1606780800).WithHeader("crit", []string{"exp"})
+				return sign(t, stoneSigningKey(t), encryptWithOptions(t, opts, body))
+			},
+			wantErr: domain.ErrUnsupportedCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: tt.encryptedBody(t),
+			}
+
+			_, err := uc.SendNotification(context.Background(), input)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(notifier.bodies) != 0 {
+				t.Errorf("SendNotification() must not send: %v", notifier.bodies)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// deadLetter stores the notification whose publish failed. It runs when the
+// publish is done, possibly after the request, so it has its own context.
+func (uc NotificationUsecase) deadLetter(input domain.NotificationInput, notification domain.Notification, err error) {
+	if uc.deadLetters == nil {
+		return
+	}
+
+	deadLetter := domain.DeadLetter{
+		Input:        input,
+		Notification: notification,
+		Reason:       err.Error(),
+		FailedAt:     time.Now(),
+	}
+
+	if storeErr := uc.deadLetters.Store(context.Background(), deadLetter); storeErr != nil {
+		deadLettersTotal.WithLabelValues(deadLetterFailed).Inc()
+		uc.log.WithError(storeErr).Errorf("unable to store the dead letter of notification %s, publish failed: %v", input.Header.EventID, err)
+		return
+	}
+
+	deadLettersTotal.WithLabelValues(deadLetterStored).Inc()
+	uc.log.Warnf("notification %s stored as a dead letter, publish failed: %v", input.Header.EventID, err)
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_decode_DecryptLimits(t *testing.T) {
+	body := `{"padding":"` + strings.Repeat("a", 4096) + `"}`
+	encrypted := encrypt(t, body)
+	compressed := encryptWithOptions(t, &jose.EncrypterOptions{Compression: jose.DEFLATE}, body)
+	pbes2 := encryptWithPassphrase(t, "passphrase", 2001, body)
+
+	limits := configuration.DecryptLimits{
+		MaxCiphertextSize:   len(encrypted),
+		MaxDecompressedSize: len(body),
+		MaxPBES2Iterations:  2001,
+		AllowCompression:    true,
+	}
+
+	tests := []struct {
+		name      string
+		limits    func(l *configuration.DecryptLimits)
+		encrypted string
+		wantErr   error
+	}{
+		{
+			name:      "Within the limits",
+			encrypted: encrypted,
+		},
+		{
+			name:      "Compressed within the limits",
+			encrypted: compressed,
+		},
+		{
+			name:      "PBES2 within the limits",
+			encrypted: pbes2,
+		},
+		{
+			name:      "Ciphertext too large",
+			limits:    func(l *configuration.DecryptLimits) { l.MaxCiphertextSize = len(encrypted) - 1 },
+			encrypted: encrypted,
+			wantErr:   domain.ErrDecryptLimit,
+		},
+		{
+			name:      "Decompressed payload too large",
+			limits:    func(l *configuration.DecryptLimits) { l.MaxDecompressedSize = len(body) - 1 },
+			encrypted: compressed,
+			wantErr:   domain.ErrDecryptLimit,
+		},
+		{
+			name:      "Compression not allowed",
+			limits:    func(l *configuration.DecryptLimits) { l.AllowCompression = false },
+			encrypted: compressed,
+			wantErr:   domain.ErrDecryptLimit,
+		},
+		{
+			name:      "Too many PBES2 iterations",
+			limits:    func(l *configuration.DecryptLimits) { l.MaxPBES2Iterations = 2000 },
+			encrypted: pbes2,
+			wantErr:   domain.ErrTooManyIterations,
+		},
+		{
+			name: "Disabled limits",
+			limits: func(l *configuration.DecryptLimits) {
+				*l = configuration.DecryptLimits{AllowCompression: true}
+			},
+			encrypted: compressed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configuration.Config{DecryptLimits: limits}
+			if tt.limits != nil {
+				tt.limits(&cfg.DecryptLimits)
+			}
+
+			testKeys := loadTestKeys(t)
+			testKeys.KeyAlgorithms = []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.PBES2_HS256_A128KW}
+			testKeys.Passphrase = []byte("passphrase")
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			got, err := uc.decode(testKeys, tt.encrypted, false)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != body {
+				t.Errorf("decode() = %v, want the body", got)
+			}
+		})
+	}
+}
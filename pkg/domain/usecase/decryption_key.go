@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"fmt"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const headerP2C = "p2c"
+
+// decryptionKeys returns the keys to try with the JWE key management
+// algorithm, only if the algorithm is allowed: the private key with the kid of
+// the header, or all of them without a kid. PBES2 payloads are decrypted with
+// the passphrase, after checking the iteration count. An unsigned JWE is
+// checked against the unsigned allowlist instead.
+func decryptionKeys(keyConfig *keys.Config, limits configuration.DecryptLimits, header jose.Header, unsigned bool) ([]interface{}, error) {
+	alg := jose.KeyAlgorithm(header.Algorithm)
+	allowed := keyConfig.AllowsKeyAlgorithm(alg)
+	if unsigned {
+		allowed = keyConfig.AllowsUnsignedKeyAlgorithm(alg)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("key algorithm %s is not allowed", alg)
+	}
+
+	if !keys.IsPBES2Algorithm(alg) {
+		keyList := keyConfig.DecryptionKeys(header.KeyID)
+		if len(keyList) == 0 {
+			return nil, fmt.Errorf("no decryption key with kid %q", header.KeyID)
+		}
+
+		result := make([]interface{}, len(keyList))
+		for i, key := range keyList {
+			result[i] = key
+		}
+		return result, nil
+	}
+
+	// The numbers are decoded as float64 in the extra headers.
+	p2c, ok := header.ExtraHeaders[headerP2C].(float64)
+	if !ok {
+		return nil, fmt.Errorf("invalid PBES2 iteration count: %v", header.ExtraHeaders[headerP2C])
+	}
+	if limits.MaxPBES2Iterations > 0 && p2c > float64(limits.MaxPBES2Iterations) {
+		return nil, fmt.Errorf("%w: %.0f > %d", domain.ErrTooManyIterations, p2c, limits.MaxPBES2Iterations)
+	}
+
+	return []interface{}{keyConfig.Passphrase}, nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func encryptWithPassphrase(t *testing.T, passphrase string, iterations int, body string) string {
+	t.Helper()
+
+	recipient := jose.Recipient{Algorithm: jose.PBES2_HS256_A128KW, Key: passphrase, PBES2Count: iterations}
+	crypter, err := jose.NewEncrypter(jose.A128GCM, recipient, nil)
+	if err != nil {
+		t.Fatalf("creating encrypter: %v", err)
+	}
+
+	object, err := crypter.Encrypt([]byte(body))
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+
+	serialized, err := object.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serializing: %v", err)
+	}
+
+	return serialized
+}
+
+func TestNotificationUsecase_decode_PBES2(t *testing.T) {
+	const body = `{"event_type":"cash_in_internal_transfer"}`
+
+	tests := []struct {
+		name       string
+		algorithms []jose.KeyAlgorithm
+		encrypted  string
+		wantErr    bool
+	}{
+		{
+			name:       "Valid PBES2 payload",
+			algorithms: []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.PBES2_HS256_A128KW},
+			encrypted:  encryptWithPassphrase(t, "passphrase", 1000, body),
+		},
+		{
+			name:       "Wrong passphrase",
+			algorithms: []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.PBES2_HS256_A128KW},
+			encrypted:  encryptWithPassphrase(t, "other", 1000, body),
+			wantErr:    true,
+		},
+		{
+			name:       "PBES2 not allowed",
+			algorithms: []jose.KeyAlgorithm{jose.RSA_OAEP_256},
+			encrypted:  encryptWithPassphrase(t, "passphrase", 1000, body),
+			wantErr:    true,
+		},
+		{
+			name:       "Private key still decrypts",
+			algorithms: []jose.KeyAlgorithm{jose.RSA_OAEP_256, jose.PBES2_HS256_A128KW},
+			encrypted:  encrypt(t, body),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testKeys := loadTestKeys(t)
+			testKeys.KeyAlgorithms = tt.algorithms
+			testKeys.Passphrase = []byte("passphrase")
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			got, err := uc.decode(testKeys, tt.encrypted, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != body {
+				t.Errorf("decode() = %v, want %v", got, body)
+			}
+		})
+	}
+}
+
+func encryptTo(t *testing.T, publicKeyPath, kid, body string) string {
+	t.Helper()
+
+	keyBytes, err := ioutil.ReadFile(testsPath + publicKeyPath)
+	if err != nil {
+		t.Fatalf("reading public key: %v", err)
+	}
+	pub, err := keys.LoadPublicKey(keyBytes)
+	if err != nil {
+		t.Fatalf("loading public key: %v", err)
+	}
+
+	recipient := jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: pub, KeyID: kid}
+	crypter, err := jose.NewEncrypter(jose.A256GCM, recipient, nil)
+	if err != nil {
+		t.Fatalf("creating encrypter: %v", err)
+	}
+
+	object, err := crypter.Encrypt([]byte(body))
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+
+	serialized, err := object.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serializing: %v", err)
+	}
+
+	return serialized
+}
+
+func TestNotificationUsecase_decode_RotatedKeys(t *testing.T) {
+	const body = `{"event_type":"cash_in_internal_transfer"}`
+
+	testKeys, err := keys.LoadKeys(configuration.KeysConfig{
+		PrivateKeyPath:      testsPath + "stone/fakekey1.pem.jwt;" + testsPath + "partner/fakekey.pem",
+		PublicKeyLocation:   "file://" + testsPath + "stone/fakekey1.pub.jwt",
+		SignatureAlgorithms: "PS256",
+		KeyAlgorithms:       "RSA-OAEP-256",
+	})
+	if err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+	uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+	tests := []struct {
+		name      string
+		encrypted string
+		wantErr   bool
+	}{
+		{
+			name:      "Current key by kid",
+			encrypted: encryptTo(t, "stone/fakekey1.pub.jwt", "fake-stone-1", body),
+		},
+		{
+			name:      "Previous key by kid",
+			encrypted: encryptTo(t, "partner/fakekey.pub", testKeys.DecryptionKeyList[1].KeyID, body),
+		},
+		{
+			name:      "Previous key without kid",
+			encrypted: encryptTo(t, "partner/fakekey.pub", "", body),
+		},
+		{
+			name:      "Unknown kid",
+			encrypted: encryptTo(t, "partner/fakekey.pub", "unknown", body),
+			wantErr:   true,
+		},
+		{
+			name:      "Kid of another key",
+			encrypted: encryptTo(t, "partner/fakekey.pub", "fake-stone-1", body),
+			wantErr:   true,
+		},
+		{
+			name:      "Key not configured",
+			encrypted: encryptTo(t, "stone/fakekey2.pub.jwt", "", body),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := uc.decode(testKeys, tt.encrypted, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != body {
+				t.Errorf("decode() = %v, want %v", got, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// inflight tracks the publishes that outlive their requests, the detached and
+// the queued ones, so they're drained on shutdown. Their contexts are derived
+// from ctx, cancelled when the drain times out.
+type inflight struct {
+	publishes sync.WaitGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func newInflight() *inflight {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &inflight{ctx: ctx, cancel: cancel}
+}
+
+// Drain waits for the publishes that outlive their requests: the ones queued
+// behind their ordering keys, and the detached ones. When the context is done
+// first, they're cancelled, so they fail and are stored as dead letters, as
+// any failed publish, instead of being lost. It must only be called after the
+// API stopped receiving notifications.
+func (uc NotificationUsecase) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		uc.inflight.publishes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	uc.inflight.cancel()
+	<-done
+
+	return fmt.Errorf("the publishes still running were cancelled: %w", ctx.Err())
+}
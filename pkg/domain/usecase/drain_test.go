@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_Drain(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name           string
+		delay          time.Duration
+		drainTimeout   time.Duration
+		wantErr        bool
+		wantDeadLetter bool
+	}{
+		{
+			name:         "Detached publish finishes within the drain",
+			delay:        50 * time.Millisecond,
+			drainTimeout: time.Second,
+		},
+		{
+			name:           "Detached publish cancelled by the drain timeout",
+			delay:          time.Hour,
+			drainTimeout:   50 * time.Millisecond,
+			wantErr:        true,
+			wantDeadLetter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &slowNotifier{delay: tt.delay, sent: make(chan error, 1)}
+			cfg := configuration.Config{PublishConfig: configuration.PublishConfig{SoftDeadline: 10 * time.Millisecond}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+			deadLetters := &fakeDeadLetterStore{}
+			uc.SetDeadLetterStore(deadLetters)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+			output, err := uc.SendNotification(context.Background(), input)
+			if err != nil || !output.Deferred {
+				t.Fatalf("SendNotification() = %+v, %v, want detached", output, err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.drainTimeout)
+			defer cancel()
+
+			err = uc.Drain(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Drain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Drain() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+
+			// The drain returns only once the publish is finished.
+			select {
+			case <-notifier.sent:
+			default:
+				t.Errorf("Drain() returned before the publish finished")
+			}
+			if got := len(deadLetters.deadLetters) == 1; got != tt.wantDeadLetter {
+				t.Errorf("Drain() dead letters = %d, want dead letter %v", len(deadLetters.deadLetters), tt.wantDeadLetter)
+			}
+		})
+	}
+}
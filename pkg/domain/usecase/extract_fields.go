@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// extractFields reads the configured well-known fields from the payload. The
+// missing or invalid fields are left empty, without failing the notification.
+func (uc NotificationUsecase) extractFields(header domain.HeaderNotification, payload string) domain.NotificationFields {
+	var fields domain.NotificationFields
+
+	if path := uc.extraction.TimestampPath; path != "" {
+		if value, ok := jsonpath.LookupString([]byte(payload), path); ok {
+			timestamp, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				uc.log.WithError(err).Warnf("invalid timestamp in notification %s", header.EventID)
+			} else {
+				fields.Timestamp = timestamp
+			}
+		}
+	}
+
+	if path := uc.extraction.EntityTypePath; path != "" {
+		fields.EntityType, _ = jsonpath.LookupString([]byte(payload), path)
+	}
+
+	if path := uc.extraction.VersionPath; path != "" {
+		fields.Version, _ = jsonpath.LookupString([]byte(payload), path)
+	}
+
+	fields.PartitionKey = header.EventID
+	if path := uc.extraction.PartitionKeyPath; path != "" {
+		if value, ok := jsonpath.LookupString([]byte(payload), path); ok && value != "" {
+			fields.PartitionKey = value
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_extractFields(t *testing.T) {
+	header := domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"}
+	allFields := configuration.ExtractionConfig{
+		TimestampPath:    "created_at",
+		EntityTypePath:   "target_data.type",
+		VersionPath:      "version",
+		PartitionKeyPath: "target_data.account_id",
+	}
+
+	tests := []struct {
+		name    string
+		cfg     configuration.ExtractionConfig
+		payload string
+		want    domain.NotificationFields
+	}{
+		{
+			name:    "All fields",
+			cfg:     allFields,
+			payload: `{"created_at":"2020-11-20T10:30:00Z","target_data":{"type":"account","account_id":"acc-1"},"version":2}`,
+			want: domain.NotificationFields{
+				Timestamp:    time.Date(2020, 11, 20, 10, 30, 0, 0, time.UTC),
+				EntityType:   "account",
+				Version:      "2",
+				PartitionKey: "acc-1",
+			},
+		},
+		{
+			name:    "Missing fields",
+			cfg:     allFields,
+			payload: `{"target_data":{}}`,
+			want:    domain.NotificationFields{PartitionKey: header.EventID},
+		},
+		{
+			name:    "Invalid timestamp",
+			cfg:     allFields,
+			payload: `{"created_at":"yesterday","version":"v1"}`,
+			want:    domain.NotificationFields{Version: "v1", PartitionKey: header.EventID},
+		},
+		{
+			name:    "Extraction disabled",
+			cfg:     configuration.ExtractionConfig{},
+			payload: `{"created_at":"2020-11-20T10:30:00Z","target_data":{"type":"account","account_id":"acc-1"},"version":2}`,
+			want:    domain.NotificationFields{PartitionKey: header.EventID},
+		},
+		{
+			name:    "Payload isn't JSON",
+			cfg:     allFields,
+			payload: `not json`,
+			want:    domain.NotificationFields{PartitionKey: header.EventID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewNotificationUsecase(configuration.Config{ExtractionConfig: tt.cfg}, logrus.New(), nil, nil, nil)
+
+			got := uc.extractFields(header, tt.payload)
+			if !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("extractFields() timestamp = %v, want %v", got.Timestamp, tt.want.Timestamp)
+			}
+
+			got.Timestamp, tt.want.Timestamp = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_extractFields_PartitionKey(t *testing.T) {
+	cfg := configuration.Config{ExtractionConfig: configuration.ExtractionConfig{PartitionKeyPath: "target_data.account_id"}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), nil, nil, nil)
+
+	first := uc.extractFields(domain.HeaderNotification{EventID: "1"}, `{"target_data":{"account_id":42}}`)
+	second := uc.extractFields(domain.HeaderNotification{EventID: "2"}, `{"target_data":{"account_id":42},"amount":10}`)
+	other := uc.extractFields(domain.HeaderNotification{EventID: "3"}, `{"target_data":{"account_id":43}}`)
+
+	if first.PartitionKey != "42" || first.PartitionKey != second.PartitionKey {
+		t.Errorf("extractFields() partition keys = %q and %q, want both 42", first.PartitionKey, second.PartitionKey)
+	}
+	if other.PartitionKey == first.PartitionKey {
+		t.Errorf("extractFields() partition key of another account = %q, want distinct", other.PartitionKey)
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func signingKeyFromFile(t *testing.T, path string) jose.SigningKey {
+	t.Helper()
+
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading signing key: %v", err)
+	}
+	signingKey, err := keys.LoadPrivateKey(keyBytes)
+	if err != nil {
+		t.Fatalf("loading signing key: %v", err)
+	}
+
+	return jose.SigningKey{Algorithm: jose.PS256, Key: signingKey}
+}
+
+func TestNotificationUsecase_verify_FallbackKey(t *testing.T) {
+	payload := encrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+	fallbackSigningKey := signingKeyFromFile(t, testsPath+"stone/fakekey2.pem.jwt")
+	otherSigningKey := signingKeyFromFile(t, testsPath+"stone/fakekey3.pem.jwt")
+
+	tests := []struct {
+		name         string
+		fallback     bool
+		signingKey   jose.SigningKey
+		wantErr      bool
+		wantFallback bool
+	}{
+		{
+			name:       "Primary key, fallback not tried",
+			fallback:   true,
+			signingKey: stoneSigningKey(t),
+		},
+		{
+			name:         "Fallback key after the primary fails",
+			fallback:     true,
+			signingKey:   fallbackSigningKey,
+			wantFallback: true,
+		},
+		{
+			name:       "Fallback disabled",
+			signingKey: fallbackSigningKey,
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown key",
+			fallback:   true,
+			signingKey: otherSigningKey,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keysConfig := configuration.KeysConfig{
+				PrivateKeyPath:      testsPath + "partner/fakekey.pem",
+				PublicKeyLocation:   "file://" + testsPath + "stone/fakekey1.pub.jwt",
+				SignatureAlgorithms: "PS256",
+				KeyAlgorithms:       "RSA-OAEP-256",
+			}
+			if tt.fallback {
+				keysConfig.FallbackPublicKeyLocation = "file://" + testsPath + "stone/fakekey2.pub.jwt"
+			}
+			testKeys, err := keys.LoadKeys(keysConfig)
+			if err != nil {
+				t.Fatalf("unable to load keys: %v", err)
+			}
+
+			log, hook := test.NewNullLogger()
+			uc := NewNotificationUsecase(configuration.Config{}, log, keys.NewStore(testKeys, nil), nil, nil)
+			before := testutil.ToFloat64(fallbackKeyUsed)
+
+			got, err := uc.verify(testKeys, sign(t, tt.signingKey, payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != payload {
+				t.Errorf("verify() = %v, want the signed payload", got)
+			}
+
+			used := testutil.ToFloat64(fallbackKeyUsed) - before
+			warned := false
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "FALLBACK KEY USED") {
+					warned = true
+				}
+			}
+
+			if tt.wantFallback && (used != 1 || !warned) {
+				t.Errorf("verify() fallback metric = %v, warned = %v, want both", used, warned)
+			}
+			if !tt.wantFallback && (used != 0 || warned) {
+				t.Errorf("verify() fallback metric = %v, warned = %v, want none", used, warned)
+			}
+		})
+	}
+}
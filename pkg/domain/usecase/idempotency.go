@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// CheckIdempotencyConfig validates the window of the idempotency store.
+func CheckIdempotencyConfig(cfg configuration.IdempotencyConfig) error {
+	if strings.TrimSpace(cfg.Store) != "" && cfg.Window <= 0 {
+		return fmt.Errorf("the idempotency window must be positive")
+	}
+
+	return nil
+}
+
+// duplicate checks if the event id was already sent within the window. The
+// store failures are only logged, so the notification is sent again rather
+// than lost.
+func (uc NotificationUsecase) duplicate(ctx context.Context, eventID string) bool {
+	if uc.idempotency == nil {
+		return false
+	}
+
+	seen, err := uc.idempotency.Seen(ctx, eventID)
+	if err != nil {
+		idempotencyErrors.WithLabelValues(idempotencySeen).Inc()
+		uc.log.WithError(err).Warnf("unable to check if notification %s is a duplicate, sending it", eventID)
+		return false
+	}
+
+	return seen
+}
+
+// remember keeps the event id of the sent notification. It runs when the
+// publish is done, possibly after the request, so it has its own context.
+func (uc NotificationUsecase) remember(eventID string) {
+	if uc.idempotency == nil {
+		return
+	}
+
+	if err := uc.idempotency.Remember(context.Background(), eventID, uc.idempotencyWindow); err != nil {
+		idempotencyErrors.WithLabelValues(idempotencyRemember).Inc()
+		uc.log.WithError(err).Warnf("unable to remember notification %s, its redeliveries will be sent again", eventID)
+	}
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeIdempotencyStore struct {
+	remembered map[string]time.Duration
+	err        error
+}
+
+func (f *fakeIdempotencyStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Seen(ctx context.Context, eventID string) (bool, error) {
+	_, ok := f.remembered[eventID]
+	return ok, f.err
+}
+
+func (f *fakeIdempotencyStore) Remember(ctx context.Context, eventID string, window time.Duration) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.remembered[eventID] = window
+	return nil
+}
+
+func TestNotificationUsecase_SendNotification_Idempotency(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{err: errors.New("unavailable")}
+	store := &fakeIdempotencyStore{remembered: map[string]time.Duration{}}
+	cfg := configuration.Config{IdempotencyConfig: configuration.IdempotencyConfig{Store: "memory", Window: time.Hour}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+	uc.SetIdempotencyStore(store)
+	duplicatesBefore := testutil.ToFloat64(duplicateNotifications)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+	}
+
+	// A failed publish isn't remembered, so its redelivery is sent again.
+	if _, err := uc.SendNotification(context.Background(), input); err == nil {
+		t.Fatal("SendNotification() must fail")
+	}
+	if len(store.remembered) != 0 {
+		t.Errorf("failed notification remembered: %v", store.remembered)
+	}
+
+	notifier.err = nil
+	output, err := uc.SendNotification(context.Background(), input)
+	if err != nil || output.Duplicate {
+		t.Fatalf("SendNotification() = %+v, %v, want sent", output, err)
+	}
+	if store.remembered["930bbd6d"] != time.Hour {
+		t.Errorf("remembered window = %v, want %v", store.remembered["930bbd6d"], time.Hour)
+	}
+
+	output, err = uc.SendNotification(context.Background(), input)
+	if err != nil || !output.Duplicate {
+		t.Fatalf("SendNotification() = %+v, %v, want duplicate", output, err)
+	}
+	if len(notifier.bodies) != 2 {
+		t.Errorf("SendNotification() notified %d times, want 2", len(notifier.bodies))
+	}
+	if got := testutil.ToFloat64(duplicateNotifications) - duplicatesBefore; got != 1 {
+		t.Errorf("duplicate notifications = %v, want 1", got)
+	}
+}
+
+func TestNotificationUsecase_SendNotification_IdempotencyStoreFailure(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{}
+	store := &fakeIdempotencyStore{remembered: map[string]time.Duration{"930bbd6d": time.Hour}, err: errors.New("connection refused")}
+	uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+	uc.SetIdempotencyStore(store)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+	}
+
+	// The notification is sent when the store can't tell it's a duplicate.
+	output, err := uc.SendNotification(context.Background(), input)
+	if err != nil || output.Duplicate {
+		t.Fatalf("SendNotification() = %+v, %v, want sent", output, err)
+	}
+	if len(notifier.bodies) != 1 {
+		t.Errorf("SendNotification() notified %d times, want 1", len(notifier.bodies))
+	}
+}
+
+func TestCheckIdempotencyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.IdempotencyConfig
+		wantErr bool
+	}{
+		{name: "Disabled", cfg: configuration.IdempotencyConfig{}},
+		{name: "Window", cfg: configuration.IdempotencyConfig{Store: "memory", Window: time.Hour}},
+		{name: "Missing window", cfg: configuration.IdempotencyConfig{Store: "redis"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckIdempotencyConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckIdempotencyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"fmt"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// Inspection is what the verification and the decryption of an encrypted
+// body found, filled up to the step that failed.
+type Inspection struct {
+	// SignatureAlgorithm and SignatureKeyID are the alg and kid of the JWS
+	// header, and VerificationKeyID is the kid of the key that verified it.
+	SignatureAlgorithm string
+	SignatureKeyID     string
+	Verified           bool
+	VerificationKeyID  string
+	// EncryptionHeader has the JWE header, like alg, enc, kid and zip.
+	EncryptionHeader map[string]interface{}
+	Payload          string
+}
+
+// Inspect verifies and decodes the encrypted body with the current keys, the
+// same way the notifications are, describing each step. It's used to debug
+// the failed notifications offline.
+func (uc NotificationUsecase) Inspect(encryptedBody string) (Inspection, error) {
+	var inspection Inspection
+	keyConfig := uc.keys.Get()
+
+	if obj, err := jose.ParseSigned(encryptedBody); err == nil && len(obj.Signatures) > 0 {
+		inspection.SignatureAlgorithm = obj.Signatures[0].Header.Algorithm
+		inspection.SignatureKeyID = obj.Signatures[0].Header.KeyID
+	}
+
+	encryptedPayload, keyID, err := uc.verifyKey(keyConfig, encryptedBody)
+	if err != nil {
+		return inspection, fmt.Errorf("unable to verify signature: %w", err)
+	}
+	inspection.Verified = true
+	inspection.VerificationKeyID = keyID
+
+	if object, err := jose.ParseEncrypted(encryptedPayload); err == nil {
+		inspection.EncryptionHeader = encryptionHeader(object.Header)
+	}
+
+	payload, err := uc.decode(keyConfig, encryptedPayload, false)
+	if err != nil {
+		return inspection, fmt.Errorf("unable to decode payload: %w", err)
+	}
+	inspection.Payload = payload
+
+	return inspection, nil
+}
+
+func encryptionHeader(header jose.Header) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for name, value := range header.ExtraHeaders {
+		fields[string(name)] = value
+	}
+	if header.Algorithm != "" {
+		fields["alg"] = header.Algorithm
+	}
+	if header.KeyID != "" {
+		fields["kid"] = header.KeyID
+	}
+
+	return fields
+}
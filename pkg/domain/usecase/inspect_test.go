@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func TestNotificationUsecase_Inspect(t *testing.T) {
+	testKeys := loadTestKeys(t)
+
+	tests := []struct {
+		name          string
+		encryptedBody func(t *testing.T) string
+		wantVerified  bool
+		wantPayload   string
+		wantErr       bool
+	}{
+		{
+			name: "Valid body",
+			encryptedBody: func(t *testing.T) string {
+				return signAndEncrypt(t, `{"id":"930bbd6d"}`)
+			},
+			wantVerified: true,
+			wantPayload:  `{"id":"930bbd6d"}`,
+		},
+		{
+			name: "Signed by an unknown key",
+			encryptedBody: func(t *testing.T) string {
+				return sign(t, signingKeyFromFile(t, testsPath+"stone/fakekey3.pem.jwt"), encrypt(t, "{}"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Encrypted to an unknown key",
+			encryptedBody: func(t *testing.T) string {
+				return sign(t, stoneSigningKey(t), encryptTo(t, "stone/fakekey2.pub.jwt", "other", "{}"))
+			},
+			wantVerified: true,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			got, err := uc.Inspect(tt.encryptedBody(t))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Inspect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.SignatureAlgorithm == "" {
+				t.Error("Inspect() has no signature algorithm")
+			}
+			if got.Verified != tt.wantVerified {
+				t.Errorf("Inspect() verified = %t, want %t", got.Verified, tt.wantVerified)
+			}
+			if tt.wantVerified && got.EncryptionHeader["enc"] != "A256GCM" {
+				t.Errorf("Inspect() encryption header = %v, want enc A256GCM", got.EncryptionHeader)
+			}
+			if got.Payload != tt.wantPayload {
+				t.Errorf("Inspect() payload = %q, want %q", got.Payload, tt.wantPayload)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// jwksServer serves the current key set, which can be replaced.
+type jwksServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []string
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	t.Helper()
+
+	srv := &jwksServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		fmt.Fprintf(w, `{"keys":[%s]}`, strings.Join(srv.keys, ","))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func (s *jwksServer) SetKeys(t *testing.T, files ...string) {
+	t.Helper()
+
+	keys := []string{}
+	for _, file := range files {
+		key, err := ioutil.ReadFile(testsPath + file)
+		if err != nil {
+			t.Fatalf("reading key: %v", err)
+		}
+		keys = append(keys, string(key))
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func TestNotificationUsecase_SendNotification_KeySetRollback(t *testing.T) {
+	srv := newJWKSServer(t)
+	srv.SetKeys(t, "stone/fakekey1.pub.jwt", "stone/fakekey2.pub.jwt")
+
+	load := func() (*keys.Config, error) {
+		return keys.LoadKeys(configuration.KeysConfig{
+			PrivateKeyPath:      testsPath + "partner/fakekey.pem",
+			PublicKeyLocation:   "url://" + srv.URL,
+			SignatureAlgorithms: "PS256",
+			KeyAlgorithms:       "RSA-OAEP-256",
+		})
+	}
+	keyConfig, err := load()
+	if err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+	store := keys.NewStore(keyConfig, load)
+
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+	uc := NewNotificationUsecase(configuration.Config{}, log, store, nil, nil)
+
+	payload := encrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+	firstKey := sign(t, stoneSigningKey(t), payload)
+	secondKey := sign(t, signingKeyFromFile(t, testsPath+"stone/fakekey2.pem.jwt"), payload)
+
+	// The key set is replaced, and reloaded, in each step after the first.
+	steps := []struct {
+		name       string
+		keys       []string
+		wantFirst  bool
+		wantSecond bool
+	}{
+		{
+			name:       "Both keys",
+			wantFirst:  true,
+			wantSecond: true,
+		},
+		{
+			name:       "First key removed",
+			keys:       []string{"stone/fakekey2.pub.jwt"},
+			wantSecond: true,
+		},
+		{
+			name:       "First key back",
+			keys:       []string{"stone/fakekey1.pub.jwt", "stone/fakekey2.pub.jwt"},
+			wantFirst:  true,
+			wantSecond: true,
+		},
+		{
+			name:      "Second key removed",
+			keys:      []string{"stone/fakekey1.pub.jwt"},
+			wantFirst: true,
+		},
+	}
+
+	for _, step := range steps {
+		if step.keys != nil {
+			srv.SetKeys(t, step.keys...)
+			if err := store.Reload(); err != nil {
+				t.Fatalf("%s: Reload() error = %v", step.name, err)
+			}
+		}
+
+		for _, body := range []struct {
+			name    string
+			body    string
+			wantErr bool
+		}{
+			{name: "first key", body: firstKey, wantErr: !step.wantFirst},
+			{name: "second key", body: secondKey, wantErr: !step.wantSecond},
+		} {
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: body.body,
+			}
+			if _, err := uc.SendNotification(context.Background(), input); (err != nil) != body.wantErr {
+				t.Errorf("%s: SendNotification() with the %s error = %v, wantErr %v", step.name, body.name, err, body.wantErr)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// eventCreatedAt returns the event creation timestamp, from the header or
+// else from the extracted field.
+func (uc NotificationUsecase) eventCreatedAt(notification domain.Notification) (time.Time, bool) {
+	if value := notification.Header.CreatedAt; value != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, value)
+		if err == nil {
+			return createdAt, true
+		}
+		uc.log.WithError(err).Warnf("invalid timestamp header in notification %s", notification.Header.EventID)
+	}
+
+	if !notification.Fields.Timestamp.IsZero() {
+		return notification.Fields.Timestamp, true
+	}
+
+	return time.Time{}, false
+}
+
+// observeLag records the delivery lag, and warns when it's over the
+// threshold. The notifications without a timestamp are skipped.
+func (uc NotificationUsecase) observeLag(notification domain.Notification, now time.Time) (time.Duration, bool) {
+	createdAt, ok := uc.eventCreatedAt(notification)
+	if !ok {
+		return 0, false
+	}
+
+	// A timestamp in the future is a clock skew, not a negative lag.
+	lag := now.Sub(createdAt)
+	if lag < 0 {
+		lag = 0
+	}
+
+	deliveryLag.WithLabelValues(uc.eventTypeLabels.Known(notification.Header.EventType)).Observe(lag.Seconds())
+
+	if threshold := uc.lagConfig.WarnThreshold; threshold > 0 && lag > threshold {
+		uc.log.Warnf("notification %s delivered %s after its creation, over the %s threshold", notification.Header.EventID, lag, threshold)
+	}
+
+	return lag, true
+}
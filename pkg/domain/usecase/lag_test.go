@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_observeLag(t *testing.T) {
+	now := time.Date(2020, 11, 20, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		notification domain.Notification
+		threshold    time.Duration
+		wantLag      time.Duration
+		wantOK       bool
+		wantWarnings int
+	}{
+		{
+			name: "Timestamp header",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", CreatedAt: "2020-11-20T10:29:58.5Z"},
+			},
+			wantLag: 1500 * time.Millisecond,
+			wantOK:  true,
+		},
+		{
+			name: "Timestamp field",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(-time.Minute)},
+			},
+			wantLag: time.Minute,
+			wantOK:  true,
+		},
+		{
+			name: "Header before the field",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", CreatedAt: "2020-11-20T10:29:50Z"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(-time.Minute)},
+			},
+			wantLag: 10 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name: "Invalid header falls back to the field",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", CreatedAt: "yesterday"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(-time.Minute)},
+			},
+			wantLag:      time.Minute,
+			wantOK:       true,
+			wantWarnings: 1,
+		},
+		{
+			name:         "Without timestamp",
+			notification: domain.Notification{Header: domain.HeaderNotification{EventID: "1"}},
+			threshold:    time.Second,
+		},
+		{
+			name: "Timestamp in the future",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(time.Minute)},
+			},
+			threshold: time.Second,
+			wantOK:    true,
+		},
+		{
+			name: "Under the threshold",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(-time.Minute)},
+			},
+			threshold: time.Minute,
+			wantLag:   time.Minute,
+			wantOK:    true,
+		},
+		{
+			name: "Over the threshold",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1"},
+				Fields: domain.NotificationFields{Timestamp: now.Add(-time.Minute - time.Millisecond)},
+			},
+			threshold:    time.Minute,
+			wantLag:      time.Minute + time.Millisecond,
+			wantOK:       true,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			cfg := configuration.Config{LagConfig: configuration.LagConfig{WarnThreshold: tt.threshold}}
+			uc := NewNotificationUsecase(cfg, log, nil, nil, nil)
+
+			lag, ok := uc.observeLag(tt.notification, now)
+			if ok != tt.wantOK || lag != tt.wantLag {
+				t.Errorf("observeLag() = %v, %v, want %v, %v", lag, ok, tt.wantLag, tt.wantOK)
+			}
+
+			warnings := 0
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings++
+				}
+			}
+			if warnings != tt.wantWarnings {
+				t.Errorf("observeLag() warnings = %d, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
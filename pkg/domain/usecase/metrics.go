@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	phaseVerify  = "verify"
+	phaseDecode  = "decode"
+	phasePublish = "publish"
+)
+
+const (
+	testModeDropped = "dropped"
+	testModeRouted  = "routed"
+)
+
+const (
+	routeSent    = "sent"
+	routeDropped = "dropped"
+)
+
+const (
+	transformApplied = "applied"
+	transformFailed  = "failed"
+)
+
+const (
+	schemaValid   = "valid"
+	schemaInvalid = "invalid"
+)
+
+const (
+	deadLetterStored = "stored"
+	deadLetterFailed = "failed"
+)
+
+// The outcomes of the notifications.
+const (
+	outcomeSent               = "sent"
+	outcomeDeferred           = "deferred"
+	outcomeDuplicate          = "duplicate"
+	outcomeQuarantined        = "quarantined"
+	outcomeDropped            = "dropped"
+	outcomeArchiveFailed      = "archive_failed"
+	outcomeVerificationFailed = "verification_failed"
+	outcomeDecryptionFailed   = "decryption_failed"
+	outcomeRejected           = "rejected"
+	outcomeTransformFailed    = "transform_failed"
+	outcomeInvalidSchema      = "invalid_schema"
+	outcomeBusy               = "busy"
+	outcomeDeliveryFailed     = "delivery_failed"
+)
+
+const (
+	idempotencySeen     = "seen"
+	idempotencyRemember = "remember"
+)
+
+var (
+	notificationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_notifications_total",
+		Help: "Number of notifications processed, by event type and outcome.",
+	}, []string{"event_type", "outcome"})
+
+	fallbackKeyUsed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_fallback_key_used_total",
+		Help: "Number of signatures verified by the fallback key, after the public keys failed.",
+	})
+
+	payloadSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_consumer_payload_size_bytes",
+		Help:    "Size of the decrypted payloads, by event type.",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 8),
+	}, []string{"event_type"})
+
+	publishDetached = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_publish_detached_total",
+		Help: "Number of publishes detached after the soft deadline, answered with 202.",
+	})
+
+	detachedPublishFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_detached_publish_failures_total",
+		Help: "Number of detached publishes that failed.",
+	})
+
+	notifierPublishFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_notifier_publish_failures_total",
+		Help: "Number of publishes failed by each notifier, after the retries.",
+	}, []string{"notifier"})
+
+	deliveryLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_consumer_delivery_lag_seconds",
+		Help:    "Time from the event creation to its processing, by event type.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 4, 10),
+	}, []string{"event_type"})
+
+	testModeNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_test_mode_notifications_total",
+		Help: "Number of test-mode notifications, dropped or routed to the test notifiers.",
+	}, []string{"action"})
+
+	eventsQuarantined = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_quarantined_events_total",
+		Help: "Number of event ids quarantined after repeated failures.",
+	})
+
+	quarantineAcked = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_quarantine_acked_total",
+		Help: "Number of redeliveries of quarantined event ids, acked without being sent.",
+	})
+
+	orderingRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_ordering_rejected_total",
+		Help: "Number of notifications rejected for redelivery behind a busy ordering key, by policy.",
+	}, []string{"policy"})
+
+	orderingQueued = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_ordering_queued_total",
+		Help: "Number of notifications queued behind their ordering key and answered as deferred.",
+	})
+
+	orderingQueuedFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_ordering_queued_failures_total",
+		Help: "Number of queued notifications whose publish failed.",
+	})
+
+	publishRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_publish_retries_total",
+		Help: "Number of sends to a notifier retried after a failure.",
+	})
+
+	deadLettersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_dead_letters_total",
+		Help: "Number of failed publishes stored as dead letters, by result.",
+	}, []string{"result"})
+
+	duplicateNotifications = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_duplicate_notifications_total",
+		Help: "Number of redeliveries of event ids already sent, acked without being sent.",
+	})
+
+	idempotencyErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_idempotency_errors_total",
+		Help: "Number of failures of the idempotency store, by operation.",
+	}, []string{"operation"})
+
+	notificationStoreErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_notification_store_errors_total",
+		Help: "Number of notifications that couldn't be stored.",
+	})
+
+	routedNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_routed_notifications_total",
+		Help: "Number of notifications sent or dropped by a routing rule, by rule pattern.",
+	}, []string{"route", "result"})
+
+	transformedNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_transformed_notifications_total",
+		Help: "Number of payloads transformed, or failing the transformation, by template pattern.",
+	}, []string{"template", "result"})
+
+	schemaValidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_schema_validations_total",
+		Help: "Number of payloads validated by their JSON Schema, by schema pattern and result.",
+	}, []string{"schema", "result"})
+
+	phaseDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_consumer_phase_duration_seconds",
+		Help:    "Duration of the notification processing phases, by event type.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"event_type", "phase"})
+)
+
+// observePhase records the phase duration since start.
+func (uc NotificationUsecase) observePhase(eventType, phase string, start time.Time) {
+	phaseDuration.WithLabelValues(uc.eventTypeLabels.Known(eventType), phase).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func histogram(t *testing.T, observer prometheus.Observer) *dto.Histogram {
+	t.Helper()
+
+	var metric dto.Metric
+	if err := observer.(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("reading metric: %v", err)
+	}
+
+	return metric.GetHistogram()
+}
+
+func TestNotificationUsecase_SendNotification_Metrics(t *testing.T) {
+	const eventType = "metrics_test_transfer"
+	body := `{"id":"930bbd6d","amount":100}`
+
+	cfg := configuration.Config{MetricsConfig: configuration.MetricsConfig{MaxEventTypes: 10}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{&fakeNotifier{}}, nil)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: eventType},
+		EncryptedBody: signAndEncrypt(t, body),
+	}
+	if _, err := uc.SendNotification(context.Background(), input); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	size := histogram(t, payloadSize.WithLabelValues(eventType))
+	if size.GetSampleCount() != 1 || size.GetSampleSum() != float64(len(body)) {
+		t.Errorf("payload size count = %v, sum = %v, want 1 and %d", size.GetSampleCount(), size.GetSampleSum(), len(body))
+	}
+
+	for _, phase := range []string{phaseVerify, phaseDecode, phasePublish} {
+		duration := histogram(t, phaseDuration.WithLabelValues(eventType, phase))
+		if duration.GetSampleCount() != 1 {
+			t.Errorf("%s duration count = %v, want 1", phase, duration.GetSampleCount())
+		}
+	}
+}
+
+func TestNotificationUsecase_SendNotification_OutcomeMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     string
+		encryptedBody string
+		notifierErr   error
+		want          string
+		// wantOther reports the event type as other, as it's unverified.
+		wantOther bool
+	}{
+		{
+			name:          "Sent",
+			eventType:     "outcome_test_sent",
+			encryptedBody: signAndEncrypt(t, `{}`),
+			want:          outcomeSent,
+		},
+		{
+			name:          "Verification failed",
+			eventType:     "outcome_test_verification",
+			encryptedBody: "header.payload.signature",
+			want:          outcomeVerificationFailed,
+			wantOther:     true,
+		},
+		{
+			name:          "Decryption failed",
+			eventType:     "outcome_test_decryption",
+			encryptedBody: sign(t, stoneSigningKey(t), "not a jwe"),
+			want:          outcomeDecryptionFailed,
+		},
+		{
+			name:          "Delivery failed",
+			eventType:     "outcome_test_delivery",
+			encryptedBody: signAndEncrypt(t, `{}`),
+			notifierErr:   errors.New("unavailable"),
+			want:          outcomeDeliveryFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configuration.Config{MetricsConfig: configuration.MetricsConfig{MaxEventTypes: 10}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{&fakeNotifier{err: tt.notifierErr}}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: tt.eventType},
+				EncryptedBody: tt.encryptedBody,
+			}
+			label := tt.eventType
+			if tt.wantOther {
+				label = labelguard.Other
+			}
+			before := testutil.ToFloat64(notificationsTotal.WithLabelValues(label, tt.want))
+
+			_, _ = uc.SendNotification(context.Background(), input)
+
+			if got := testutil.ToFloat64(notificationsTotal.WithLabelValues(label, tt.want)) - before; got != 1 {
+				t.Errorf("notifications with outcome %s = %v, want 1", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_ForgedEventTypes(t *testing.T) {
+	cfg := configuration.Config{MetricsConfig: configuration.MetricsConfig{MaxEventTypes: 1}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{&fakeNotifier{}}, nil)
+
+	// The forged notification fails the verification, so its event type
+	// doesn't take the only label.
+	forged := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "1", EventType: "forged_event_type_test"},
+		EncryptedBody: "header.payload.signature",
+	}
+	_, _ = uc.SendNotification(context.Background(), forged)
+
+	verified := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "2", EventType: "verified_event_type_test"},
+		EncryptedBody: signAndEncrypt(t, `{}`),
+	}
+	if _, err := uc.SendNotification(context.Background(), verified); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	if got := testutil.ToFloat64(notificationsTotal.WithLabelValues("verified_event_type_test", outcomeSent)); got != 1 {
+		t.Errorf("verified notifications = %v, want 1", got)
+	}
+	if got := uc.eventTypeLabels.Known("forged_event_type_test"); got != labelguard.Other {
+		t.Errorf("forged event type label = %v, want %v", got, labelguard.Other)
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// CheckNotificationStoreConfig validates the retention of the notification
+// store.
+func CheckNotificationStoreConfig(cfg configuration.NotificationStoreConfig) error {
+	if strings.TrimSpace(cfg.Store) != "" && cfg.Retention <= 0 {
+		return fmt.Errorf("the notification store retention must be positive")
+	}
+
+	return nil
+}
+
+// published checks if the outcome is from a publish, stored when it's done.
+func published(outcome string) bool {
+	return outcome == outcomeSent || outcome == outcomeDeferred || outcome == outcomeDeliveryFailed
+}
+
+// storeNotification records the outcome of the notification, without its raw
+// body. It runs when the publish is done, possibly after the request, so it
+// has its own context. The store failures are only logged.
+func (uc NotificationUsecase) storeNotification(input domain.NotificationInput, status string, err error) {
+	if uc.notificationStore == nil {
+		return
+	}
+
+	now := time.Now()
+	stored := domain.StoredNotification{
+		Input:      input,
+		Status:     status,
+		ReceivedAt: now,
+		UpdatedAt:  now,
+	}
+	stored.Input.RawBody = nil
+	stored.Input.Replay = false
+	if err != nil {
+		stored.Error = err.Error()
+	}
+
+	if err := uc.notificationStore.Save(context.Background(), stored, uc.notificationRetention); err != nil {
+		notificationStoreErrors.Inc()
+		uc.log.WithError(err).Warnf("unable to store notification %s", input.Header.EventID)
+	}
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeNotificationStore struct {
+	mu    sync.Mutex
+	saved []domain.StoredNotification
+}
+
+func (f *fakeNotificationStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeNotificationStore) Save(ctx context.Context, stored domain.StoredNotification, retention time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.saved = append(f.saved, stored)
+	return nil
+}
+
+func (f *fakeNotificationStore) Get(ctx context.Context, eventID string) (domain.StoredNotification, error) {
+	return domain.StoredNotification{}, domain.ErrNotificationNotFound
+}
+
+func (f *fakeNotificationStore) List(ctx context.Context, filter domain.NotificationFilter) ([]domain.StoredNotification, error) {
+	return nil, nil
+}
+
+func (f *fakeNotificationStore) statuses() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := []string{}
+	for _, stored := range f.saved {
+		result = append(result, stored.Status)
+	}
+	return result
+}
+
+func TestNotificationUsecase_SendNotification_NotificationStore(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{err: errors.New("unavailable")}
+	store := &fakeNotificationStore{}
+	cfg := configuration.Config{
+		IdempotencyConfig:       configuration.IdempotencyConfig{Store: "memory", Window: time.Hour},
+		NotificationStoreConfig: configuration.NotificationStoreConfig{Store: "memory", Retention: time.Hour},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+	uc.SetIdempotencyStore(&fakeIdempotencyStore{remembered: map[string]time.Duration{}})
+	uc.SetNotificationStore(store)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+		RawBody:       []byte(`{"encrypted_body":"..."}`),
+	}
+
+	if _, err := uc.SendNotification(context.Background(), input); err == nil {
+		t.Fatal("SendNotification() must fail")
+	}
+
+	notifier.err = nil
+	if _, err := uc.SendNotification(context.Background(), input); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	// The duplicates keep the record of the sent notification.
+	output, err := uc.SendNotification(context.Background(), input)
+	if err != nil || !output.Duplicate {
+		t.Fatalf("SendNotification() = %+v, %v, want duplicate", output, err)
+	}
+
+	// But a replay is sent again.
+	replay := input
+	replay.Replay = true
+	output, err = uc.SendNotification(context.Background(), replay)
+	if err != nil || output.Duplicate {
+		t.Fatalf("SendNotification() of a replay = %+v, %v, want sent", output, err)
+	}
+	if len(notifier.bodies) != 3 {
+		t.Errorf("SendNotification() notified %d times, want 3", len(notifier.bodies))
+	}
+
+	// The forged notifications aren't stored.
+	forged := input
+	forged.EncryptedBody = "forged"
+	if _, err := uc.SendNotification(context.Background(), forged); err == nil {
+		t.Fatal("SendNotification() of a forged notification must fail")
+	}
+
+	want := []string{outcomeDeliveryFailed, outcomeSent, outcomeSent}
+	got := store.statuses()
+	if len(got) != len(want) {
+		t.Fatalf("stored statuses = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stored statuses = %v, want %v", got, want)
+		}
+	}
+
+	failed := store.saved[0]
+	if failed.Error == "" || failed.Input.EncryptedBody != encryptedBody || failed.Input.RawBody != nil || failed.Input.Replay {
+		t.Errorf("stored failure = %+v, want the envelope with the error, without the raw body", failed)
+	}
+	if store.saved[2].Input.Replay {
+		t.Error("stored replay must not be marked as a replay")
+	}
+}
+
+type deferredNotifier struct {
+	fakeNotifier
+}
+
+func (d *deferredNotifier) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestNotificationUsecase_SendNotification_NotificationStoreDeferred(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	tests := []struct {
+		name     string
+		notifier domain.Notifier
+		want     string
+	}{
+		{
+			name:     "Sent",
+			notifier: &fakeNotifier{},
+			want:     outcomeSent,
+		},
+		{
+			name:     "Deferred",
+			notifier: &deferredNotifier{},
+			want:     outcomeDeferred,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeNotificationStore{}
+			cfg := configuration.Config{
+				NotificationStoreConfig: configuration.NotificationStoreConfig{Store: "memory", Retention: time.Hour},
+			}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{tt.notifier}, nil)
+			uc.SetNotificationStore(store)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+			if _, err := uc.SendNotification(context.Background(), input); err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			if got := store.statuses(); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("stored statuses = %v, want [%s]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNotificationStoreConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.NotificationStoreConfig
+		wantErr bool
+	}{
+		{
+			name: "Disabled",
+		},
+		{
+			name: "Valid",
+			cfg:  configuration.NotificationStoreConfig{Store: "memory", Retention: time.Hour},
+		},
+		{
+			name:    "Without retention",
+			cfg:     configuration.NotificationStoreConfig{Store: "redis"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckNotificationStoreConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckNotificationStoreConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
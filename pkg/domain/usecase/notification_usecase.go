@@ -1,9 +1,15 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/sirupsen/logrus"
 
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keylock"
 	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/common/quarantine"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
 )
 
@@ -11,14 +17,153 @@ var _ domain.NotificationUsecase = &NotificationUsecase{}
 
 type NotificationUsecase struct {
 	log       *logrus.Logger
-	keys      *keys.Config
+	keys      *keys.Store
 	notifiers []domain.Notifier
+	// testNotifiers receive only the test-mode notifications, which are
+	// dropped when there's none.
+	testNotifiers []domain.Notifier
+	// archiver is optional, and when archiveFatal is false its failures are
+	// only logged.
+	archiver     domain.RawArchiver
+	archiveFatal bool
+	// authorizer is optional, all the notifications are allowed without it.
+	authorizer domain.ContentAuthorizer
+	// confirmer is optional, it confirms the processed notifications to the
+	// provider.
+	confirmer domain.ProcessingConfirmer
+	// routes is nil when all the notifications go to all the notifiers.
+	routes *Routes
+	// transforms is nil when the payloads are sent as decrypted.
+	transforms *Transforms
+	// schemas is nil when the payloads aren't validated.
+	schemas *Schemas
+	// deadLetters is optional, it stores the notifications whose publish
+	// failed.
+	deadLetters domain.DeadLetterStore
+	// idempotency is optional, it acks the redeliveries of the event ids
+	// already sent within the window.
+	idempotency       domain.IdempotencyStore
+	idempotencyWindow time.Duration
+	// notificationStore is optional, it records the delivery of the verified
+	// notifications.
+	notificationStore     domain.NotificationStore
+	notificationRetention time.Duration
+	// orderingKeyPath is empty when the notifications aren't ordered.
+	orderingKeyPath    string
+	orderingEventTypes map[string]bool
+	orderingLock       *keylock.KeyedLock
+	orderingMaxWait    time.Duration
+	// orderingQueue is nil unless the ordering policy is buffer.
+	orderingQueue     *keylock.Queue
+	payloadCheck      configuration.PayloadCheckConfig
+	emptyPayloadTypes map[string]bool
+	fieldLimits       []fieldLimit
+	extraction        configuration.ExtractionConfig
+	decryptLimits     configuration.DecryptLimits
+	verifyParallelism int
+	publishConfig     configuration.PublishConfig
+	retryConfig       configuration.RetryConfig
+	lagConfig         configuration.LagConfig
+	testMode          configuration.TestModeConfig
+	// verifyOnly sends the inner JWE to the notifiers, without decrypting it.
+	verifyOnly bool
+	// quarantine is nil when the failing event ids aren't quarantined.
+	quarantine *quarantine.Tracker
+	// eventTypeLabels bounds the event types in the metric labels.
+	eventTypeLabels *labelguard.Guard
+	inflight        *inflight
 }
 
-func NewNotificationUsecase(log *logrus.Logger, keys *keys.Config, notifiers []domain.Notifier) *NotificationUsecase {
+func NewNotificationUsecase(config configuration.Config, log *logrus.Logger, keys *keys.Store, notifiers []domain.Notifier, archiver domain.RawArchiver) *NotificationUsecase {
+	orderingEventTypes := map[string]bool{}
+	for _, eventType := range configuration.SplitList(config.OrderingConfig.EventTypes) {
+		orderingEventTypes[eventType] = true
+	}
+
+	emptyPayloadTypes := map[string]bool{}
+	for _, eventType := range configuration.SplitList(config.PayloadCheckConfig.EmptyPayloadEventTypes) {
+		emptyPayloadTypes[eventType] = true
+	}
+
+	// The invalid limits are rejected by CheckPayloadConfig.
+	fieldLimits, _ := parseFieldLimits(config.PayloadCheckConfig.FieldMaxLengths)
+
+	var orderingQueue *keylock.Queue
+	if config.OrderingConfig.Policy == OrderingPolicyBuffer {
+		orderingQueue = keylock.NewQueue(config.OrderingConfig.BufferSize)
+	}
+
 	return &NotificationUsecase{
-		log:       log,
-		keys:      keys,
-		notifiers: notifiers,
+		log:                   log,
+		keys:                  keys,
+		notifiers:             notifiers,
+		archiver:              archiver,
+		archiveFatal:          config.ArchiverConfig.Fatal,
+		orderingKeyPath:       config.OrderingConfig.KeyPath,
+		orderingEventTypes:    orderingEventTypes,
+		orderingLock:          keylock.New(),
+		orderingMaxWait:       config.OrderingConfig.MaxWait,
+		orderingQueue:         orderingQueue,
+		payloadCheck:          config.PayloadCheckConfig,
+		emptyPayloadTypes:     emptyPayloadTypes,
+		fieldLimits:           fieldLimits,
+		extraction:            config.ExtractionConfig,
+		decryptLimits:         config.DecryptLimits,
+		verifyParallelism:     config.KeysConfig.VerifyParallelism,
+		publishConfig:         config.PublishConfig,
+		retryConfig:           config.RetryConfig,
+		idempotencyWindow:     config.IdempotencyConfig.Window,
+		notificationRetention: config.NotificationStoreConfig.Retention,
+		lagConfig:             config.LagConfig,
+		testMode:              config.TestModeConfig,
+		verifyOnly:            config.RelayConfig.VerifyOnly,
+		quarantine:            quarantine.New(config.QuarantineConfig),
+		eventTypeLabels:       labelguard.New(config.MetricsConfig.MaxEventTypes),
+		inflight:              newInflight(),
 	}
 }
+
+// SetTestNotifiers sets the notifiers of the test-mode notifications.
+func (uc *NotificationUsecase) SetTestNotifiers(notifiers []domain.Notifier) {
+	uc.testNotifiers = notifiers
+}
+
+// SetAuthorizer checks the decrypted notifications before sending them.
+func (uc *NotificationUsecase) SetAuthorizer(authorizer domain.ContentAuthorizer) {
+	uc.authorizer = authorizer
+}
+
+// SetConfirmer confirms the processed notifications to the provider.
+func (uc *NotificationUsecase) SetConfirmer(confirmer domain.ProcessingConfirmer) {
+	uc.confirmer = confirmer
+}
+
+// SetDeadLetterStore stores the notifications whose publish failed.
+func (uc *NotificationUsecase) SetDeadLetterStore(store domain.DeadLetterStore) {
+	uc.deadLetters = store
+}
+
+// SetIdempotencyStore acks the redeliveries of the event ids already sent.
+func (uc *NotificationUsecase) SetIdempotencyStore(store domain.IdempotencyStore) {
+	uc.idempotency = store
+}
+
+// SetNotificationStore records the delivery of the verified notifications.
+func (uc *NotificationUsecase) SetNotificationStore(store domain.NotificationStore) {
+	uc.notificationStore = store
+}
+
+// SetRoutes sends the notifications to the notifiers of their event type.
+func (uc *NotificationUsecase) SetRoutes(routes *Routes) {
+	uc.routes = routes
+}
+
+// SetSchemas validates the payloads of their event types.
+func (uc *NotificationUsecase) SetSchemas(schemas *Schemas) {
+	uc.schemas = schemas
+}
+
+// SetTransforms reshapes the payloads of their event types before sending.
+func (uc *NotificationUsecase) SetTransforms(transforms *Transforms) {
+	uc.transforms = transforms
+}
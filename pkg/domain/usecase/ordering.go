@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const (
+	// OrderingPolicyWait holds the request until the ordering key is free.
+	OrderingPolicyWait = "wait"
+	// OrderingPolicyBuffer queues the notification behind the ordering key,
+	// answering it as deferred.
+	OrderingPolicyBuffer = "buffer"
+)
+
+// CheckOrderingConfig validates the ordering policy.
+func CheckOrderingConfig(cfg configuration.OrderingConfig) error {
+	switch cfg.Policy {
+	case OrderingPolicyWait:
+		if cfg.MaxWait < 0 {
+			return fmt.Errorf("invalid ordering max wait %s", cfg.MaxWait)
+		}
+	case OrderingPolicyBuffer:
+		if cfg.BufferSize < 1 {
+			return fmt.Errorf("the ordering buffer size must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid ordering policy %q, must be %s or %s", cfg.Policy, OrderingPolicyWait, OrderingPolicyBuffer)
+	}
+
+	return nil
+}
+
+// orderingKey returns the ordering key of the notification. Notifications
+// without the key aren't ordered.
+func (uc NotificationUsecase) orderingKey(eventType, payload string) (string, bool) {
+	if uc.orderingKeyPath == "" {
+		return "", false
+	}
+
+	if len(uc.orderingEventTypes) > 0 && !uc.orderingEventTypes[eventType] {
+		return "", false
+	}
+
+	return jsonpath.LookupString([]byte(payload), uc.orderingKeyPath)
+}
+
+// lockOrderingKey waits until no other notification with the same ordering
+// key is being sent, at most for the max wait.
+func (uc NotificationUsecase) lockOrderingKey(ctx context.Context, key string) (func(), error) {
+	if uc.orderingMaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, uc.orderingMaxWait)
+		defer cancel()
+	}
+
+	unlock, err := uc.orderingLock.Lock(ctx, key)
+	if err != nil {
+		orderingRejected.WithLabelValues(OrderingPolicyWait).Inc()
+		return nil, fmt.Errorf("%w: %v", domain.ErrOrderingBusy, err)
+	}
+
+	return unlock, nil
+}
+
+// publishQueued publishes the notification right away when its ordering key
+// is free, or queues it behind the key and reports it as deferred. The queued
+// publishes outlive the request, so they have their own context, bounded by
+// the hard timeout, and are waited by Drain. Answered as deferred already, a
+// failed queued publish is stored as a dead letter by done.
+func (uc NotificationUsecase) publishQueued(ctx context.Context, key string, notifiers []domain.Notifier, notification domain.Notification, done func(bool, error)) (bool, error) {
+	var deferred bool
+	var err error
+
+	uc.inflight.publishes.Add(1)
+	queued, queueErr := uc.orderingQueue.Run(key, func(queued bool, release func()) {
+		finish := func(deferred bool, err error) {
+			done(deferred, err)
+			release()
+			uc.inflight.publishes.Done()
+		}
+
+		if !queued {
+			deferred, err = uc.publish(ctx, notifiers, notification, finish)
+			return
+		}
+
+		publishCtx, cancel := uc.publishContext(uc.inflight.ctx)
+		defer cancel()
+
+		if _, err := uc.publish(publishCtx, notifiers, notification, finish); err != nil {
+			orderingQueuedFailed.Inc()
+			uc.log.WithError(err).Errorf("queued publish of notification %s failed, stored as a dead letter", notification.Header.EventID)
+		}
+	})
+	if queueErr != nil {
+		uc.inflight.publishes.Done()
+		orderingRejected.WithLabelValues(OrderingPolicyBuffer).Inc()
+		return false, fmt.Errorf("%w: %v", domain.ErrOrderingBusy, queueErr)
+	}
+
+	if queued {
+		orderingQueued.Inc()
+		uc.log.Infof("notification %s queued behind its ordering key", notification.Header.EventID)
+		return true, nil
+	}
+
+	return deferred, err
+}
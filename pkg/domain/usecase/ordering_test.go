@@ -0,0 +1,370 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// orderingNotifier tracks how many notifications for each account are being
+// sent at the same time.
+type orderingNotifier struct {
+	mu        sync.Mutex
+	active    map[string]int
+	maxActive map[string]int
+	entered   chan string
+	release   chan struct{}
+	// hold is how long each send takes, unless released before.
+	hold time.Duration
+}
+
+func newOrderingNotifier(hold time.Duration) *orderingNotifier {
+	return &orderingNotifier{
+		active:    map[string]int{},
+		maxActive: map[string]int{},
+		entered:   make(chan string, 10),
+		release:   make(chan struct{}),
+		hold:      hold,
+	}
+}
+
+func (n *orderingNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (n *orderingNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	account, _ := jsonpath.LookupString([]byte(notification.Body), "target_data.account_id")
+
+	n.mu.Lock()
+	n.active[account]++
+	if n.active[account] > n.maxActive[account] {
+		n.maxActive[account] = n.active[account]
+	}
+	n.mu.Unlock()
+
+	n.entered <- account
+	select {
+	case <-n.release:
+	case <-time.After(n.hold):
+	}
+
+	n.mu.Lock()
+	n.active[account]--
+	n.mu.Unlock()
+
+	return nil
+}
+
+func newOrderingUsecase(t *testing.T, notifier domain.Notifier) *NotificationUsecase {
+	cfg := configuration.Config{
+		OrderingConfig: configuration.OrderingConfig{
+			KeyPath:    "target_data.account_id",
+			EventTypes: "cash_in_internal_transfer",
+		},
+	}
+
+	return NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+}
+
+func accountInput(t *testing.T, eventType, account string) domain.NotificationInput {
+	body := fmt.Sprintf(`{"target_data":{"account_id":%q}}`, account)
+
+	return domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: account, EventType: eventType},
+		EncryptedBody: signAndEncrypt(t, body),
+	}
+}
+
+func TestNotificationUsecase_SendNotification_SameKeyIsSerialized(t *testing.T) {
+	notifier := newOrderingNotifier(20 * time.Millisecond)
+	uc := newOrderingUsecase(t, notifier)
+
+	inputs := []domain.NotificationInput{}
+	for i := 0; i < 4; i++ {
+		inputs = append(inputs, accountInput(t, "cash_in_internal_transfer", "account-1"))
+	}
+
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(input domain.NotificationInput) {
+			defer wg.Done()
+			if _, err := uc.SendNotification(context.Background(), input); err != nil {
+				t.Errorf("SendNotification() error = %v", err)
+			}
+		}(input)
+	}
+	wg.Wait()
+
+	if notifier.maxActive["account-1"] != 1 {
+		t.Errorf("SendNotification() concurrent sends for the same key = %v, want 1", notifier.maxActive["account-1"])
+	}
+}
+
+func TestNotificationUsecase_SendNotification_DistinctKeysRunConcurrently(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs func(t *testing.T) []domain.NotificationInput
+	}{
+		{
+			name: "Distinct keys",
+			inputs: func(t *testing.T) []domain.NotificationInput {
+				return []domain.NotificationInput{
+					accountInput(t, "cash_in_internal_transfer", "account-1"),
+					accountInput(t, "cash_in_internal_transfer", "account-2"),
+				}
+			},
+		},
+		{
+			name: "Event types without ordering",
+			inputs: func(t *testing.T) []domain.NotificationInput {
+				return []domain.NotificationInput{
+					accountInput(t, "cash_out_internal_transfer", "account-1"),
+					accountInput(t, "cash_out_internal_transfer", "account-1"),
+				}
+			},
+		},
+		{
+			name: "Notifications without the key",
+			inputs: func(t *testing.T) []domain.NotificationInput {
+				body := signAndEncrypt(t, `{"target_data":{}}`)
+				input := domain.NotificationInput{
+					Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+					EncryptedBody: body,
+				}
+				return []domain.NotificationInput{input, input}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := newOrderingNotifier(time.Minute)
+			uc := newOrderingUsecase(t, notifier)
+
+			var wg sync.WaitGroup
+			for _, input := range tt.inputs(t) {
+				wg.Add(1)
+				go func(input domain.NotificationInput) {
+					defer wg.Done()
+					_, _ = uc.SendNotification(context.Background(), input)
+				}(input)
+			}
+
+			// Both notifications must be inside the notifier at the same time.
+			timeout := time.After(2 * time.Second)
+			for i := 0; i < 2; i++ {
+				select {
+				case <-notifier.entered:
+				case <-timeout:
+					t.Fatalf("SendNotification() notifications were not sent concurrently")
+				}
+			}
+			close(notifier.release)
+			wg.Wait()
+		})
+	}
+}
+
+// sequenceNotifier records the event ids in the order they're sent, blocking
+// until released.
+type sequenceNotifier struct {
+	mu      sync.Mutex
+	sent    []string
+	entered chan string
+	release chan struct{}
+	// failing is the event id whose sends fail.
+	failing string
+}
+
+func (n *sequenceNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (n *sequenceNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	n.entered <- notification.Header.EventID
+	<-n.release
+
+	if notification.Header.EventID == n.failing {
+		return errors.New("send failed")
+	}
+
+	n.mu.Lock()
+	n.sent = append(n.sent, notification.Header.EventID)
+	n.mu.Unlock()
+
+	return nil
+}
+
+func sequenceInput(t *testing.T, eventID string) domain.NotificationInput {
+	return domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: eventID, EventType: "cash_in_internal_transfer"},
+		EncryptedBody: signAndEncrypt(t, `{"target_data":{"account_id":"account-1"}}`),
+	}
+}
+
+func TestCheckOrderingConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.OrderingConfig
+		wantErr bool
+	}{
+		{name: "Wait", cfg: configuration.OrderingConfig{Policy: OrderingPolicyWait}},
+		{name: "Bounded wait", cfg: configuration.OrderingConfig{Policy: OrderingPolicyWait, MaxWait: time.Second}},
+		{name: "Buffer", cfg: configuration.OrderingConfig{Policy: OrderingPolicyBuffer, BufferSize: 10}},
+		{name: "Buffer without size", cfg: configuration.OrderingConfig{Policy: OrderingPolicyBuffer}, wantErr: true},
+		{name: "Unknown policy", cfg: configuration.OrderingConfig{Policy: "drop"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckOrderingConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckOrderingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_OrderingWaitTimeout(t *testing.T) {
+	notifier := &sequenceNotifier{entered: make(chan string, 10), release: make(chan struct{})}
+	cfg := configuration.Config{
+		OrderingConfig: configuration.OrderingConfig{
+			KeyPath: "target_data.account_id",
+			Policy:  OrderingPolicyWait,
+			MaxWait: 50 * time.Millisecond,
+		},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := uc.SendNotification(context.Background(), sequenceInput(t, "1"))
+		first <- err
+	}()
+	<-notifier.entered
+
+	// The deadline of the request is longer than the max wait.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, err := uc.SendNotification(ctx, sequenceInput(t, "2"))
+	if !errors.Is(err, domain.ErrOrderingBusy) {
+		t.Errorf("SendNotification() error = %v, want %v", err, domain.ErrOrderingBusy)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendNotification() waited %s, want about the max wait", elapsed)
+	}
+
+	close(notifier.release)
+	if err := <-first; err != nil {
+		t.Errorf("SendNotification() first error = %v", err)
+	}
+	if !reflect.DeepEqual(notifier.sent, []string{"1"}) {
+		t.Errorf("SendNotification() sent = %v, want [1]", notifier.sent)
+	}
+}
+
+func TestNotificationUsecase_SendNotification_OrderingBuffer(t *testing.T) {
+	notifier := &sequenceNotifier{entered: make(chan string, 10), release: make(chan struct{})}
+	cfg := configuration.Config{
+		OrderingConfig: configuration.OrderingConfig{
+			KeyPath:    "target_data.account_id",
+			Policy:     OrderingPolicyBuffer,
+			BufferSize: 2,
+		},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+	first := make(chan domain.NotificationOutput, 1)
+	go func() {
+		output, err := uc.SendNotification(context.Background(), sequenceInput(t, "1"))
+		if err != nil {
+			t.Errorf("SendNotification() first error = %v", err)
+		}
+		first <- output
+	}()
+	<-notifier.entered
+
+	// The key is busy, so the next ones are queued and answered right away.
+	for _, eventID := range []string{"2", "3"} {
+		output, err := uc.SendNotification(context.Background(), sequenceInput(t, eventID))
+		if err != nil || !output.Deferred {
+			t.Fatalf("SendNotification(%s) = %+v, %v, want deferred", eventID, output, err)
+		}
+	}
+
+	// The buffer of the key is full.
+	if _, err := uc.SendNotification(context.Background(), sequenceInput(t, "4")); !errors.Is(err, domain.ErrOrderingBusy) {
+		t.Errorf("SendNotification() error = %v, want %v", err, domain.ErrOrderingBusy)
+	}
+
+	close(notifier.release)
+	if output := <-first; output.Deferred {
+		t.Errorf("SendNotification() first = %+v, want sent right away", output)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := uc.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(notifier.sent, []string{"1", "2", "3"}) {
+		t.Errorf("SendNotification() sent = %v, want in order", notifier.sent)
+	}
+}
+
+func TestNotificationUsecase_SendNotification_OrderingBufferFailed(t *testing.T) {
+	notifier := &sequenceNotifier{entered: make(chan string, 10), release: make(chan struct{}), failing: "2"}
+	cfg := configuration.Config{
+		OrderingConfig: configuration.OrderingConfig{
+			KeyPath:    "target_data.account_id",
+			Policy:     OrderingPolicyBuffer,
+			BufferSize: 1,
+		},
+	}
+	deadLetters := &fakeDeadLetterStore{}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+	uc.SetDeadLetterStore(deadLetters)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := uc.SendNotification(context.Background(), sequenceInput(t, "1"))
+		first <- err
+	}()
+	<-notifier.entered
+
+	output, err := uc.SendNotification(context.Background(), sequenceInput(t, "2"))
+	if err != nil || !output.Deferred {
+		t.Fatalf("SendNotification() = %+v, %v, want deferred", output, err)
+	}
+
+	close(notifier.release)
+	if err := <-first; err != nil {
+		t.Fatalf("SendNotification() first error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := uc.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	// The queued notification was answered as deferred, so its failure is
+	// only kept as a dead letter.
+	if len(deadLetters.deadLetters) != 1 || deadLetters.deadLetters[0].Input.Header.EventID != "2" {
+		t.Fatalf("SendNotification() dead letters = %+v, want the queued one", deadLetters.deadLetters)
+	}
+}
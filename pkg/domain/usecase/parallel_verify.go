@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"sync"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// verifyInParallel verifies the signature with a bounded pool of workers. The
+// keys not tried yet are skipped once one succeeds, failing with the last key
+// error like the sequential verification.
+func verifyInParallel(obj *jose.JSONWebSignature, keyList []*jose.JSONWebKey, parallelism int) ([]byte, *jose.JSONWebKey, error) {
+	workers := parallelism
+	if workers > len(keyList) {
+		workers = len(keyList)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		found    = make(chan int, 1)
+		texts    = make([][]byte, len(keyList))
+		verified = make(chan struct{})
+		indexes  = make(chan int)
+		errs     = make([]error, len(keyList))
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				plainText, err := obj.Verify(keyList[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				texts[i] = plainText
+				once.Do(func() {
+					found <- i
+					close(verified)
+				})
+			}
+		}()
+	}
+
+feed:
+	for i := range keyList {
+		select {
+		case indexes <- i:
+		case <-verified:
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	select {
+	case i := <-found:
+		return texts[i], keyList[i], nil
+	default:
+		return nil, nil, errs[len(errs)-1]
+	}
+}
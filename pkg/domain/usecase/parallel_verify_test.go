@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// ecdsaKeys generates the private keys and their public JWKs.
+func ecdsaKeys(tb testing.TB, n int) ([]*ecdsa.PrivateKey, []*jose.JSONWebKey) {
+	tb.Helper()
+
+	privateKeys := make([]*ecdsa.PrivateKey, n)
+	publicKeys := make([]*jose.JSONWebKey, n)
+	for i := range privateKeys {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			tb.Fatalf("generating key: %v", err)
+		}
+		privateKeys[i] = key
+		publicKeys[i] = &jose.JSONWebKey{Key: &key.PublicKey, Algorithm: string(jose.ES256)}
+	}
+
+	return privateKeys, publicKeys
+}
+
+func signedWith(tb testing.TB, key *ecdsa.PrivateKey, payload string) *jose.JSONWebSignature {
+	tb.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+	if err != nil {
+		tb.Fatalf("creating signer: %v", err)
+	}
+
+	signed, err := signer.Sign([]byte(payload))
+	if err != nil {
+		tb.Fatalf("signing: %v", err)
+	}
+
+	return signed
+}
+
+func Test_verifyWithKeys_Parallel(t *testing.T) {
+	privateKeys, publicKeys := ecdsaKeys(t, 33)
+	other, _ := ecdsaKeys(t, 1)
+
+	tests := []struct {
+		name        string
+		signingKey  *ecdsa.PrivateKey
+		parallelism int
+		wantErr     bool
+	}{
+		{name: "Sequential", signingKey: privateKeys[20], parallelism: 1},
+		{name: "First key", signingKey: privateKeys[0], parallelism: 4},
+		{name: "Middle key", signingKey: privateKeys[16], parallelism: 4},
+		{name: "Last key", signingKey: privateKeys[32], parallelism: 4},
+		{name: "More workers than keys", signingKey: privateKeys[7], parallelism: 100},
+		{name: "No matching key", signingKey: other[0], parallelism: 4, wantErr: true},
+		{name: "No matching key in sequence", signingKey: other[0], parallelism: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := signedWith(t, tt.signingKey, "payload")
+
+			plainText, key, err := verifyWithKeys(obj, publicKeys, tt.parallelism)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyWithKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(plainText) != "payload" {
+				t.Errorf("verifyWithKeys() = %q, want %q", plainText, "payload")
+			}
+			if key == nil || key.Key != &tt.signingKey.PublicKey {
+				t.Errorf("verifyWithKeys() key = %v, want the matching key", key)
+			}
+		})
+	}
+}
+
+func Benchmark_verifyWithKeys(b *testing.B) {
+	privateKeys, publicKeys := ecdsaKeys(b, 64)
+	obj := signedWith(b, privateKeys[len(privateKeys)-1], "payload")
+
+	for _, parallelism := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := verifyWithKeys(obj, publicKeys, parallelism); err != nil {
+					b.Fatalf("verifyWithKeys() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func Test_keysByID(t *testing.T) {
+	first := &jose.JSONWebKey{KeyID: "2020"}
+	second := &jose.JSONWebKey{KeyID: "2021"}
+	third := &jose.JSONWebKey{KeyID: "2021"}
+	noKid := &jose.JSONWebKey{}
+	keyList := []*jose.JSONWebKey{first, noKid, second, third}
+
+	tests := []struct {
+		name string
+		kid  string
+		want []*jose.JSONWebKey
+	}{
+		{name: "Without a kid", want: keyList},
+		{name: "Unknown kid", kid: "2022", want: keyList},
+		{name: "Known kid first", kid: "2021", want: []*jose.JSONWebKey{second, third, first, noKid}},
+		{name: "Already first", kid: "2020", want: keyList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keysByID(keyList, tt.kid)
+			if len(got) != len(tt.want) {
+				t.Fatalf("keysByID() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("keysByID()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
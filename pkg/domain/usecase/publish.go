@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type publishResult struct {
+	deferred bool
+	err      error
+}
+
+// publish sends the notification to the notifiers, and calls done with
+// whether a deferred notifier took it and the publish error once finished. With a soft deadline, a publish still running
+// when it elapses is detached and reported as deferred, and goes on until the
+// hard timeout.
+func (uc NotificationUsecase) publish(ctx context.Context, notifiers []domain.Notifier, notification domain.Notification, done func(bool, error)) (bool, error) {
+	if uc.publishConfig.SoftDeadline <= 0 {
+		deferred, err := uc.sendToNotifiers(ctx, notifiers, notification)
+		done(deferred, err)
+		return deferred, err
+	}
+
+	// The publish can outlive the request, so it has its own context, only
+	// keeping the span to continue the trace, and is waited by Drain.
+	detached := trace.ContextWithSpan(uc.inflight.ctx, trace.SpanFromContext(ctx))
+	publishCtx, cancel := uc.publishContext(detached)
+
+	results := make(chan publishResult, 1)
+	uc.inflight.publishes.Add(1)
+	go func() {
+		defer uc.inflight.publishes.Done()
+		defer cancel()
+
+		deferred, err := uc.sendToNotifiers(publishCtx, notifiers, notification)
+		done(deferred, err)
+		results <- publishResult{deferred: deferred, err: err}
+	}()
+
+	timer := time.NewTimer(uc.publishConfig.SoftDeadline)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		return result.deferred, result.err
+	case <-timer.C:
+	}
+
+	eventID := notification.Header.EventID
+	uc.log.Warnf("publish of notification %s exceeded the soft deadline, detached", eventID)
+	publishDetached.Inc()
+
+	go func() {
+		if result := <-results; result.err != nil {
+			uc.log.WithError(result.err).Errorf("detached publish of notification %s failed", eventID)
+			detachedPublishFailed.Inc()
+		}
+	}()
+
+	return true, nil
+}
+
+// sendToNotifiers sends the notification to all the notifiers, in order,
+// retrying each one. A failed notifier doesn't stop the others, and the error
+// names each failed one.
+func (uc NotificationUsecase) sendToNotifiers(ctx context.Context, notifiers []domain.Notifier, notification domain.Notification) (bool, error) {
+	deferred := false
+	failures := []string{}
+	for _, notifier := range notifiers {
+		if err := uc.sendWithRetries(ctx, notifier, notification); err != nil {
+			name := notifierName(notifier)
+			notifierPublishFailed.WithLabelValues(name).Inc()
+			failures = append(failures, fmt.Sprintf("notifier [%s]: %v", name, err))
+			continue
+		}
+
+		if _, ok := notifier.(domain.DeferredNotifier); ok {
+			deferred = true
+		}
+	}
+
+	if len(failures) > 0 {
+		return deferred, fmt.Errorf("%d of %d notifiers failed: %s", len(failures), len(notifiers), strings.Join(failures, "; "))
+	}
+
+	return deferred, nil
+}
+
+// notifierName is the name of the notifier, or its type when it isn't named.
+func notifierName(notifier domain.Notifier) string {
+	if named, ok := notifier.(domain.NamedNotifier); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", notifier)
+}
+
+// publishContext bounds a detached publish by the hard timeout, if any.
+func (uc NotificationUsecase) publishContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if uc.publishConfig.HardTimeout > 0 {
+		return context.WithTimeout(parent, uc.publishConfig.HardTimeout)
+	}
+
+	return context.WithCancel(parent)
+}
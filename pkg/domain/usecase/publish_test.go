@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// slowNotifier waits the delay, or the context, before sending.
+type slowNotifier struct {
+	delay time.Duration
+	err   error
+	sent  chan error
+}
+
+func (s *slowNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (s *slowNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	err := s.err
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.sent <- err
+	return err
+}
+
+func TestNotificationUsecase_SendNotification_SoftDeadline(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name         string
+		delay        time.Duration
+		err          error
+		wantDeferred bool
+		wantErr      bool
+		wantSentErr  error
+		wantFailed   float64
+	}{
+		{
+			name:  "Fast publish",
+			delay: 0,
+		},
+		{
+			name:        "Fast publish failure",
+			delay:       0,
+			err:         errors.New("unavailable"),
+			wantErr:     true,
+			wantSentErr: errors.New("unavailable"),
+		},
+		{
+			name:         "Slow publish is detached",
+			delay:        100 * time.Millisecond,
+			wantDeferred: true,
+		},
+		{
+			name:         "Detached publish failure",
+			delay:        100 * time.Millisecond,
+			err:          errors.New("unavailable"),
+			wantDeferred: true,
+			wantSentErr:  errors.New("unavailable"),
+			wantFailed:   1,
+		},
+		{
+			name:         "Detached publish respects the hard timeout",
+			delay:        time.Hour,
+			wantDeferred: true,
+			wantSentErr:  context.DeadlineExceeded,
+			wantFailed:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &slowNotifier{delay: tt.delay, err: tt.err, sent: make(chan error, 1)}
+			cfg := configuration.Config{PublishConfig: configuration.PublishConfig{
+				SoftDeadline: 20 * time.Millisecond,
+				HardTimeout:  200 * time.Millisecond,
+			}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+			detachedBefore := testutil.ToFloat64(publishDetached)
+			failedBefore := testutil.ToFloat64(detachedPublishFailed)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+
+			start := time.Now()
+			output, err := uc.SendNotification(context.Background(), input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.Deferred != tt.wantDeferred {
+				t.Errorf("SendNotification() deferred = %v, want %v", output.Deferred, tt.wantDeferred)
+			}
+			if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+				t.Errorf("SendNotification() answered after %s", elapsed)
+			}
+
+			// The detached publish still finishes.
+			select {
+			case sentErr := <-notifier.sent:
+				if (sentErr == nil) != (tt.wantSentErr == nil) || (sentErr != nil && sentErr.Error() != tt.wantSentErr.Error()) {
+					t.Errorf("publish error = %v, want %v", sentErr, tt.wantSentErr)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("the notification was never sent")
+			}
+
+			wantDetached := 0.0
+			if tt.wantDeferred {
+				wantDetached = 1
+			}
+			if got := testutil.ToFloat64(publishDetached) - detachedBefore; got != wantDetached {
+				t.Errorf("detached publishes = %v, want %v", got, wantDetached)
+			}
+
+			// The failure is counted once the detached publish returns.
+			deadline := time.Now().Add(time.Second)
+			for testutil.ToFloat64(detachedPublishFailed)-failedBefore != tt.wantFailed && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := testutil.ToFloat64(detachedPublishFailed) - failedBefore; got != tt.wantFailed {
+				t.Errorf("detached publish failures = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+// namedNotifier is a fakeNotifier named in the publish errors.
+type namedNotifier struct {
+	fakeNotifier
+	name string
+}
+
+func (n *namedNotifier) Name() string {
+	return n.name
+}
+
+func TestNotificationUsecase_SendNotification_FailedNotifiers(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name       string
+		errs       []error
+		wantReason string
+	}{
+		{
+			name: "All sent",
+			errs: []error{nil, nil, nil},
+		},
+		{
+			name:       "One failed",
+			errs:       []error{nil, errors.New("unavailable"), nil},
+			wantReason: "1 of 3 notifiers failed: notifier [redis]: unavailable",
+		},
+		{
+			name:       "Many failed",
+			errs:       []error{errors.New("timeout"), nil, errors.New("unavailable")},
+			wantReason: "2 of 3 notifiers failed: notifier [proxy]: timeout; notifier [sqs]: unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{"proxy", "redis", "sqs"}
+			named := []*namedNotifier{}
+			notifiers := []domain.Notifier{}
+			failedBefore := map[string]float64{}
+			for i, name := range names {
+				notifier := &namedNotifier{fakeNotifier: fakeNotifier{err: tt.errs[i]}, name: name}
+				named = append(named, notifier)
+				notifiers = append(notifiers, notifier)
+				failedBefore[name] = testutil.ToFloat64(notifierPublishFailed.WithLabelValues(name))
+			}
+			deadLetters := &fakeDeadLetterStore{}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(loadTestKeys(t), nil), notifiers, nil)
+			uc.SetDeadLetterStore(deadLetters)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+			_, err := uc.SendNotification(context.Background(), input)
+			if (err != nil) != (tt.wantReason != "") {
+				t.Fatalf("SendNotification() error = %v, want %q", err, tt.wantReason)
+			}
+
+			// Every notifier is tried, even after a failure.
+			for i, notifier := range named {
+				if len(notifier.bodies) != 1 {
+					t.Errorf("notifier %s sent %d times, want 1", names[i], len(notifier.bodies))
+				}
+
+				wantFailed := 0.0
+				if tt.errs[i] != nil {
+					wantFailed = 1
+				}
+				if got := testutil.ToFloat64(notifierPublishFailed.WithLabelValues(names[i])) - failedBefore[names[i]]; got != wantFailed {
+					t.Errorf("notifier %s publish failures = %v, want %v", names[i], got, wantFailed)
+				}
+			}
+
+			if tt.wantReason == "" {
+				if len(deadLetters.deadLetters) != 0 {
+					t.Errorf("SendNotification() dead letters = %v, want none", deadLetters.deadLetters)
+				}
+				return
+			}
+			if len(deadLetters.deadLetters) != 1 || deadLetters.deadLetters[0].Reason != tt.wantReason {
+				t.Errorf("SendNotification() dead letters = %v, want the reason %q", deadLetters.deadLetters, tt.wantReason)
+			}
+		})
+	}
+}
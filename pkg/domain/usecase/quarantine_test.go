@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_SendNotification_Quarantine(t *testing.T) {
+	// The payload is rejected by the payload checks at every redelivery.
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{}
+	store := &fakeDeadLetterStore{}
+	cfg := configuration.Config{
+		PayloadCheckConfig: configuration.PayloadCheckConfig{FieldMaxLengths: "id=3"},
+		QuarantineConfig:   configuration.QuarantineConfig{Threshold: 3},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+	uc.SetDeadLetterStore(store)
+	quarantinedBefore := testutil.ToFloat64(eventsQuarantined)
+	ackedBefore := testutil.ToFloat64(quarantineAcked)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+	}
+
+	for i := 1; i <= 3; i++ {
+		if _, err := uc.SendNotification(context.Background(), input); !errors.Is(err, domain.ErrFieldTooLong) {
+			t.Fatalf("SendNotification() attempt %d error = %v, want %v", i, err, domain.ErrFieldTooLong)
+		}
+	}
+	if got := testutil.ToFloat64(eventsQuarantined) - quarantinedBefore; got != 1 {
+		t.Errorf("quarantined events = %v, want 1", got)
+	}
+	if len(store.deadLetters) != 1 || !strings.HasPrefix(store.deadLetters[0].Reason, "QUARANTINED: ") {
+		t.Errorf("SendNotification() dead letters = %+v, want the quarantined one", store.deadLetters)
+	}
+
+	// The redeliveries are acked, without reaching the notifier.
+	for i := 0; i < 2; i++ {
+		if _, err := uc.SendNotification(context.Background(), input); err != nil {
+			t.Fatalf("SendNotification() redelivery error = %v", err)
+		}
+	}
+	if len(notifier.bodies) != 0 {
+		t.Errorf("SendNotification() notified %d times, want none", len(notifier.bodies))
+	}
+	if got := testutil.ToFloat64(quarantineAcked) - ackedBefore; got != 2 {
+		t.Errorf("acked redeliveries = %v, want 2", got)
+	}
+
+	// Other event ids are still checked.
+	other := input
+	other.Header.EventID = "other"
+	if _, err := uc.SendNotification(context.Background(), other); err == nil {
+		t.Error("SendNotification() other event id must still fail")
+	}
+}
+
+func TestNotificationUsecase_SendNotification_QuarantineIgnoresDeliveryFailures(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{err: errors.New("downstream unavailable")}
+	cfg := configuration.Config{QuarantineConfig: configuration.QuarantineConfig{Threshold: 1}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := uc.SendNotification(context.Background(), input); err == nil {
+			t.Fatalf("SendNotification() attempt %d must fail", i)
+		}
+	}
+
+	if uc.quarantine.Quarantined("930bbd6d") {
+		t.Error("a downstream outage must not quarantine the event id")
+	}
+}
+
+func TestNotificationUsecase_SendNotification_QuarantineIgnoresSplicedHeaders(t *testing.T) {
+	encryptedBody := signAndEncrypt(t, `{"id":"930bbd6d"}`)
+	notifier := &fakeNotifier{}
+	cfg := configuration.Config{
+		PayloadCheckConfig: configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+		QuarantineConfig:   configuration.QuarantineConfig{Threshold: 1},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+	// A valid payload with the event id header of another event.
+	spliced := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "victim", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: encryptedBody,
+	}
+	if _, err := uc.SendNotification(context.Background(), spliced); !errors.Is(err, domain.ErrPayloadMismatch) {
+		t.Fatalf("SendNotification() error = %v, want %v", err, domain.ErrPayloadMismatch)
+	}
+
+	if uc.quarantine.Quarantined("victim") {
+		t.Error("a spliced header must not quarantine its event id")
+	}
+}
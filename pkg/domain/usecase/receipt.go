@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Receipt has the claims of the processing receipt.
+type Receipt struct {
+	EventID     string    `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// receipt signs the receipt of the accepted notification. The notification
+// was already sent, so a signing failure only omits the receipt.
+func (uc NotificationUsecase) receipt(keyConfig *keys.Config, header domain.HeaderNotification) string {
+	if keyConfig == nil || keyConfig.ReceiptSigner == nil {
+		return ""
+	}
+
+	claims, err := json.Marshal(Receipt{
+		EventID:     header.EventID,
+		EventType:   header.EventType,
+		ProcessedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		uc.log.WithError(err).Errorf("unable to encode the receipt of notification %s", header.EventID)
+		return ""
+	}
+
+	signed, err := keyConfig.ReceiptSigner.Sign(claims)
+	if err != nil {
+		uc.log.WithError(err).Errorf("unable to sign the receipt of notification %s", header.EventID)
+		return ""
+	}
+
+	receipt, err := signed.CompactSerialize()
+	if err != nil {
+		uc.log.WithError(err).Errorf("unable to serialize the receipt of notification %s", header.EventID)
+		return ""
+	}
+
+	return receipt
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_SendNotification_Receipt(t *testing.T) {
+	receiptKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		withReceipt bool
+	}{
+		{
+			name: "Receipts disabled",
+		},
+		{
+			name:        "Receipts enabled",
+			withReceipt: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testKeys := loadTestKeys(t)
+			if tt.withReceipt {
+				testKeys.ReceiptSigner, err = keys.NewReceiptSigner(receiptKey)
+				if err != nil {
+					t.Fatalf("creating receipt signer: %v", err)
+				}
+			}
+
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{&fakeNotifier{}}, nil)
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: signAndEncrypt(t, `{"id":"930bbd6d"}`),
+			}
+
+			before := time.Now().Add(-time.Second)
+			output, err := uc.SendNotification(context.Background(), input)
+			if err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			if !tt.withReceipt {
+				if output.Receipt != "" {
+					t.Errorf("SendNotification() receipt = %v, want none", output.Receipt)
+				}
+				return
+			}
+
+			signed, err := jose.ParseSigned(output.Receipt)
+			if err != nil {
+				t.Fatalf("parsing receipt: %v", err)
+			}
+			if alg := signed.Signatures[0].Header.Algorithm; alg != string(jose.ES256) {
+				t.Errorf("receipt algorithm = %v, want %v", alg, jose.ES256)
+			}
+
+			claims, err := signed.Verify(&receiptKey.PublicKey)
+			if err != nil {
+				t.Fatalf("receipt doesn't verify with the receipt public key: %v", err)
+			}
+
+			var receipt Receipt
+			if err := json.Unmarshal(claims, &receipt); err != nil {
+				t.Fatalf("decoding receipt: %v", err)
+			}
+			if receipt.EventID != input.Header.EventID || receipt.EventType != input.Header.EventType {
+				t.Errorf("receipt = %+v, want the notification headers", receipt)
+			}
+			if receipt.ProcessedAt.Before(before) || receipt.ProcessedAt.After(time.Now()) {
+				t.Errorf("receipt processed at = %v", receipt.ProcessedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// CheckRelayConfig rejects the verify-only mode together with the features
+// reading the plaintext. Out of this mode, the private key is required.
+func CheckRelayConfig(cfg configuration.Config) error {
+	if !cfg.RelayConfig.VerifyOnly {
+		if cfg.KeysConfig.PrivateKeyPath == "" {
+			return fmt.Errorf("the private key path is only optional in the verify-only mode")
+		}
+		return nil
+	}
+
+	conflicts := []string{}
+	add := func(enabled bool, name string) {
+		if enabled {
+			conflicts = append(conflicts, name)
+		}
+	}
+	add(cfg.PayloadCheckConfig.EventIDCheck, "EVENT_ID_CHECK")
+	add(cfg.PayloadCheckConfig.EventTypeCheck, "EVENT_TYPE_CHECK")
+	add(cfg.PayloadCheckConfig.FieldMaxLengths != "", "FIELD_MAX_LENGTHS")
+	add(cfg.ExtractionConfig.TimestampPath != "", "EXTRACT_TIMESTAMP_PATH")
+	add(cfg.ExtractionConfig.EntityTypePath != "", "EXTRACT_ENTITY_TYPE_PATH")
+	add(cfg.ExtractionConfig.VersionPath != "", "EXTRACT_VERSION_PATH")
+	add(cfg.ExtractionConfig.PartitionKeyPath != "", "EXTRACT_PARTITION_KEY_PATH")
+	add(cfg.OrderingConfig.KeyPath != "", "ORDERING_KEY_PATH")
+	add(cfg.AuthorizerConfig.TenantPath != "", "AUTHORIZER_TENANT_PATH")
+	add(cfg.TransformConfig.Templates != "", "TRANSFORM_TEMPLATES")
+	add(cfg.SchemaConfig.Schemas != "", "PAYLOAD_SCHEMAS")
+	add(cfg.TestModeConfig.Path != "", "TEST_MODE_PATH")
+	add(cfg.SelfTestConfig.Enabled, "SELF_TEST_ENABLED")
+	add(cfg.HTTPConfig.UnsignedPath != "", "API_UNSIGNED_PATH")
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the verify-only mode doesn't decrypt the payload, which is required by %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// forward checks the inner JWE of the verify-only mode, and returns it as is.
+// The decryption, and its checks, are left to the downstream.
+func (uc NotificationUsecase) forward(encryptedBody string) (string, error) {
+	if err := uc.checkCiphertextSize(encryptedBody); err != nil {
+		return "", err
+	}
+
+	if _, err := jose.ParseEncrypted(encryptedBody); err != nil {
+		return "", fmt.Errorf("parsing encrypted: %v", err)
+	}
+
+	return encryptedBody, nil
+}
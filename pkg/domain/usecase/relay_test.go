@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// ciphertextNotifier keeps the notifications it receives.
+type ciphertextNotifier struct {
+	notifications []domain.Notification
+}
+
+func (n *ciphertextNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (n *ciphertextNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestCheckRelayConfig(t *testing.T) {
+	verifyOnly := configuration.RelayConfig{VerifyOnly: true}
+
+	tests := []struct {
+		name    string
+		cfg     configuration.Config
+		wantErr bool
+	}{
+		{
+			name: "Decrypting",
+			cfg:  configuration.Config{KeysConfig: configuration.KeysConfig{PrivateKeyPath: "key.pem"}},
+		},
+		{
+			name:    "Decrypting without the private key",
+			cfg:     configuration.Config{},
+			wantErr: true,
+		},
+		{
+			name: "Verify-only without the private key",
+			cfg:  configuration.Config{RelayConfig: verifyOnly},
+		},
+		{
+			name:    "Verify-only with a payload check",
+			cfg:     configuration.Config{RelayConfig: verifyOnly, PayloadCheckConfig: configuration.PayloadCheckConfig{EventIDCheck: true}},
+			wantErr: true,
+		},
+		{
+			name:    "Verify-only with a field max length",
+			cfg:     configuration.Config{RelayConfig: verifyOnly, PayloadCheckConfig: configuration.PayloadCheckConfig{FieldMaxLengths: "description=10"}},
+			wantErr: true,
+		},
+		{
+			name:    "Verify-only with an extracted field",
+			cfg:     configuration.Config{RelayConfig: verifyOnly, ExtractionConfig: configuration.ExtractionConfig{PartitionKeyPath: "target_data.account_id"}},
+			wantErr: true,
+		},
+		{
+			name:    "Verify-only with the tenant authorizer",
+			cfg:     configuration.Config{RelayConfig: verifyOnly, AuthorizerConfig: configuration.AuthorizerConfig{TenantPath: "account_id"}},
+			wantErr: true,
+		},
+		{
+			name:    "Verify-only with the unsigned route",
+			cfg:     configuration.Config{RelayConfig: verifyOnly, HTTPConfig: configuration.HTTPConfig{UnsignedPath: "/unsigned"}},
+			wantErr: true,
+		},
+		{
+			name: "Verify-only with the test-mode event type suffix",
+			cfg:  configuration.Config{RelayConfig: verifyOnly, TestModeConfig: configuration.TestModeConfig{EventTypeSuffix: ".test"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRelayConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRelayConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_VerifyOnly(t *testing.T) {
+	// The relay has no private key, only the public keys to verify.
+	testKeys := loadTestKeys(t)
+	testKeys.PrivateKey = nil
+	testKeys.DecryptionKeyList = nil
+
+	ciphertext := encrypt(t, `{"account_id":"acc-1"}`)
+
+	tests := []struct {
+		name          string
+		encryptedBody string
+		wantErr       bool
+	}{
+		{
+			name:          "Signed ciphertext",
+			encryptedBody: sign(t, stoneSigningKey(t), ciphertext),
+		},
+		{
+			name:          "Tampered signature",
+			encryptedBody: sign(t, stoneSigningKey(t), ciphertext) + "x",
+			wantErr:       true,
+		},
+		{
+			name:          "Signed payload that isn't a JWE",
+			encryptedBody: sign(t, stoneSigningKey(t), `{"account_id":"acc-1"}`),
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &ciphertextNotifier{}
+			cfg := configuration.Config{RelayConfig: configuration.RelayConfig{VerifyOnly: true}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: tt.encryptedBody,
+			}
+			_, err := uc.SendNotification(context.Background(), input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if len(notifier.notifications) != 0 {
+					t.Errorf("SendNotification() sent %d notifications, want none", len(notifier.notifications))
+				}
+				return
+			}
+
+			if len(notifier.notifications) != 1 {
+				t.Fatalf("SendNotification() sent %d notifications, want 1", len(notifier.notifications))
+			}
+			got := notifier.notifications[0]
+			if got.Body != ciphertext || !got.Ciphertext {
+				t.Errorf("SendNotification() body = %q, ciphertext %t, want the undecrypted JWE", got.Body, got.Ciphertext)
+			}
+		})
+	}
+}
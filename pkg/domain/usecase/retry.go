@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// CheckRetryConfig validates the retries of the notifiers.
+func CheckRetryConfig(cfg configuration.RetryConfig) error {
+	if cfg.MaxAttempts < 1 {
+		return fmt.Errorf("the retry max attempts must be positive")
+	}
+	if cfg.Backoff < 0 || cfg.MaxBackoff < 0 {
+		return fmt.Errorf("invalid retry backoff %s or max backoff %s", cfg.Backoff, cfg.MaxBackoff)
+	}
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		return fmt.Errorf("invalid retry jitter %v, must be from 0 to 1", cfg.Jitter)
+	}
+
+	return nil
+}
+
+// sendWithRetries sends the notification to the notifier, retrying the
+// failures until the max attempts or the context is done.
+func (uc NotificationUsecase) sendWithRetries(ctx context.Context, notifier domain.Notifier, notification domain.Notification) error {
+	// The notifier is always tried once, even without a retry config.
+	maxAttempts := uc.retryConfig.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			publishRetries.Inc()
+
+			timer := time.NewTimer(retryDelay(uc.retryConfig, attempt, rand.Float64()))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%v, retries stopped: %w", err, ctx.Err())
+			}
+		}
+
+		err = notifier.Send(ctx, notification)
+		if err == nil {
+			return nil
+		}
+
+		if attempt < maxAttempts {
+			uc.log.WithError(err).Warnf("publish attempt %d of %d of notification %s failed", attempt, maxAttempts, notification.Header.EventID)
+		}
+	}
+
+	return err
+}
+
+// retryDelay is the backoff before the attempt, doubled on each retry up to
+// the max backoff, and moved by up to the jitter fraction. The random value
+// is from 0 to 1.
+func retryDelay(cfg configuration.RetryConfig, attempt int, random float64) time.Duration {
+	delay := cfg.Backoff
+	for i := 2; i < attempt && (cfg.MaxBackoff <= 0 || delay < cfg.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if cfg.MaxBackoff > 0 && delay > cfg.MaxBackoff {
+		delay = cfg.MaxBackoff
+	}
+
+	return delay + time.Duration(float64(delay)*cfg.Jitter*(2*random-1))
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// flakyNotifier fails the first sends.
+type flakyNotifier struct {
+	failures int
+	attempts int
+}
+
+func (f *flakyNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *flakyNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("downstream unavailable")
+	}
+	return nil
+}
+
+type fakeDeadLetterStore struct {
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func TestNotificationUsecase_SendNotification_Retry(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name           string
+		maxAttempts    int
+		failures       int
+		wantAttempts   int
+		wantErr        bool
+		wantDeadLetter bool
+	}{
+		{
+			name:         "Sent at the first attempt",
+			maxAttempts:  3,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Sent after retries",
+			maxAttempts:  3,
+			failures:     2,
+			wantAttempts: 3,
+		},
+		{
+			name:           "Dead letter after the max attempts",
+			maxAttempts:    3,
+			failures:       5,
+			wantAttempts:   3,
+			wantErr:        true,
+			wantDeadLetter: true,
+		},
+		{
+			name:           "No retries without a retry config",
+			failures:       1,
+			wantAttempts:   1,
+			wantErr:        true,
+			wantDeadLetter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &flakyNotifier{failures: tt.failures}
+			deadLetters := &fakeDeadLetterStore{}
+			cfg := configuration.Config{
+				RetryConfig: configuration.RetryConfig{MaxAttempts: tt.maxAttempts, Backoff: time.Millisecond, Jitter: 0.5},
+			}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+			uc.SetDeadLetterStore(deadLetters)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+
+			_, err := uc.SendNotification(context.Background(), input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if notifier.attempts != tt.wantAttempts {
+				t.Errorf("SendNotification() attempts = %d, want %d", notifier.attempts, tt.wantAttempts)
+			}
+
+			if (len(deadLetters.deadLetters) == 1) != tt.wantDeadLetter {
+				t.Fatalf("SendNotification() dead letters = %v, want %v", deadLetters.deadLetters, tt.wantDeadLetter)
+			}
+			if !tt.wantDeadLetter {
+				return
+			}
+
+			deadLetter := deadLetters.deadLetters[0]
+			if deadLetter.Input.Header != input.Header || deadLetter.Input.EncryptedBody != input.EncryptedBody {
+				t.Errorf("SendNotification() dead letter input = %v, want %v", deadLetter.Input, input)
+			}
+			if deadLetter.Notification.Body != `{"event_type":"cash_in_internal_transfer"}` {
+				t.Errorf("SendNotification() dead letter body = %s, want the decrypted payload", deadLetter.Notification.Body)
+			}
+			if deadLetter.Reason != "1 of 1 notifiers failed: notifier [*usecase.flakyNotifier]: downstream unavailable" {
+				t.Errorf("SendNotification() dead letter reason = %q, want the publish error", deadLetter.Reason)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_sendWithRetries_ContextDone(t *testing.T) {
+	notifier := &flakyNotifier{failures: 5}
+	cfg := configuration.Config{
+		RetryConfig: configuration.RetryConfig{MaxAttempts: 5, Backoff: time.Hour},
+	}
+	uc := NewNotificationUsecase(cfg, logrus.New(), nil, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := uc.sendWithRetries(ctx, notifier, domain.Notification{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sendWithRetries() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if notifier.attempts != 1 {
+		t.Errorf("sendWithRetries() attempts = %d, want 1", notifier.attempts)
+	}
+}
+
+func Test_retryDelay(t *testing.T) {
+	cfg := configuration.RetryConfig{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		name    string
+		attempt int
+		jitter  float64
+		random  float64
+		want    time.Duration
+	}{
+		{name: "First retry", attempt: 2, want: 100 * time.Millisecond},
+		{name: "Doubled", attempt: 3, want: 200 * time.Millisecond},
+		{name: "Doubled again", attempt: 5, want: 800 * time.Millisecond},
+		{name: "Up to the max backoff", attempt: 6, want: time.Second},
+		{name: "Many attempts", attempt: 100, want: time.Second},
+		{name: "Jitter down", attempt: 3, jitter: 0.5, random: 0, want: 100 * time.Millisecond},
+		{name: "Jitter up", attempt: 3, jitter: 0.5, random: 1, want: 300 * time.Millisecond},
+		{name: "Jitter in the middle", attempt: 3, jitter: 0.5, random: 0.5, want: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.Jitter = tt.jitter
+
+			if got := retryDelay(cfg, tt.attempt, tt.random); got != tt.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRetryConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.RetryConfig
+		wantErr bool
+	}{
+		{name: "Valid", cfg: configuration.RetryConfig{MaxAttempts: 3, Backoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.2}},
+		{name: "Without retries", cfg: configuration.RetryConfig{MaxAttempts: 1}},
+		{name: "No attempts", cfg: configuration.RetryConfig{}, wantErr: true},
+		{name: "Negative backoff", cfg: configuration.RetryConfig{MaxAttempts: 3, Backoff: -time.Second}, wantErr: true},
+		{name: "Jitter above 1", cfg: configuration.RetryConfig{MaxAttempts: 3, Jitter: 1.5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRetryConfig(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRetryConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
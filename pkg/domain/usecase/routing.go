@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// RouteDrop is the target of the routes acking the notifications without
+// sending them.
+const RouteDrop = "drop"
+
+// routeDefault labels the notifications matching no rule.
+const routeDefault = "default"
+
+// Routes selects the notifiers of each event type. The first matching rule
+// wins, and the notifications matching no rule take the default route.
+type Routes struct {
+	rules []route
+	// fallback is nil when the notifications matching no rule go to all
+	// the notifiers.
+	fallback *route
+}
+
+type route struct {
+	pattern   string
+	notifiers []domain.Notifier
+	drop      bool
+}
+
+// NewRoutes resolves the targets of the routing rules by the notifier names.
+// It returns nil when the routing is disabled.
+func NewRoutes(cfg configuration.RoutingConfig, notifiers map[string]domain.Notifier) (*Routes, error) {
+	if strings.TrimSpace(cfg.Rules) == "" && strings.TrimSpace(cfg.Default) == "" {
+		return nil, nil
+	}
+
+	routes := &Routes{}
+	for _, item := range configuration.SplitList(cfg.Rules) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid routing rule, expected pattern=targets: %v", item)
+		}
+
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid routing pattern %q: %v", pattern, err)
+		}
+
+		r, err := newRoute(pattern, parts[1], notifiers)
+		if err != nil {
+			return nil, err
+		}
+		routes.rules = append(routes.rules, r)
+	}
+
+	if strings.TrimSpace(cfg.Default) != "" {
+		r, err := newRoute(routeDefault, cfg.Default, notifiers)
+		if err != nil {
+			return nil, err
+		}
+		routes.fallback = &r
+	}
+
+	return routes, nil
+}
+
+// newRoute resolves the targets, notifiers separated by ',' or drop.
+func newRoute(pattern, targets string, notifiers map[string]domain.Notifier) (route, error) {
+	r := route{pattern: pattern}
+	for _, target := range strings.Split(targets, ",") {
+		target = strings.ToLower(strings.TrimSpace(target))
+		if target == "" {
+			continue
+		}
+
+		if target == RouteDrop {
+			r.drop = true
+			continue
+		}
+
+		notifier, ok := notifiers[target]
+		if !ok {
+			return route{}, fmt.Errorf("routing target of %s is not in the notifier list: %v", pattern, target)
+		}
+
+		// The members of a tee or a failover share the same notifier.
+		if !containsNotifierImpl(r.notifiers, notifier) {
+			r.notifiers = append(r.notifiers, notifier)
+		}
+	}
+
+	switch {
+	case r.drop && len(r.notifiers) > 0:
+		return route{}, fmt.Errorf("routing targets of %s can't mix drop and notifiers: %v", pattern, targets)
+	case !r.drop && len(r.notifiers) == 0:
+		return route{}, fmt.Errorf("routing rule %s without targets", pattern)
+	}
+
+	return r, nil
+}
+
+func containsNotifierImpl(notifiers []domain.Notifier, notifier domain.Notifier) bool {
+	for _, n := range notifiers {
+		if n == notifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match returns the route of the event type, or nil for all the notifiers.
+func (r *Routes) match(eventType string) *route {
+	if r == nil {
+		return nil
+	}
+
+	for i := range r.rules {
+		// The patterns are validated by NewRoutes.
+		if ok, _ := path.Match(r.rules[i].pattern, eventType); ok {
+			return &r.rules[i]
+		}
+	}
+
+	return r.fallback
+}
+
+// route returns the notifiers of the notification, and false when it's
+// dropped.
+func (uc NotificationUsecase) route(eventType string, notifiers []domain.Notifier) ([]domain.Notifier, bool) {
+	r := uc.routes.match(eventType)
+	if r == nil {
+		return notifiers, true
+	}
+
+	if r.drop {
+		routedNotifications.WithLabelValues(r.pattern, routeDropped).Inc()
+		return nil, false
+	}
+
+	routedNotifications.WithLabelValues(r.pattern, routeSent).Inc()
+	return r.notifiers, true
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNewRoutes(t *testing.T) {
+	notifiers := map[string]domain.Notifier{
+		"proxy": &fakeNotifier{},
+		"redis": &fakeNotifier{},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      configuration.RoutingConfig
+		disabled bool
+		wantErr  bool
+	}{
+		{name: "Disabled", disabled: true},
+		{name: "Rules", cfg: configuration.RoutingConfig{Rules: "cash_in_*=proxy;payment.*=Proxy, redis;*.test=drop"}},
+		{name: "Only the default route", cfg: configuration.RoutingConfig{Default: "drop"}},
+		{name: "Rule without targets", cfg: configuration.RoutingConfig{Rules: "cash_in_*="}, wantErr: true},
+		{name: "Rule without pattern", cfg: configuration.RoutingConfig{Rules: "=proxy"}, wantErr: true},
+		{name: "Invalid pattern", cfg: configuration.RoutingConfig{Rules: "cash_in_[=proxy"}, wantErr: true},
+		{name: "Target not in the notifier list", cfg: configuration.RoutingConfig{Rules: "cash_in_*=stdout"}, wantErr: true},
+		{name: "Drop mixed with notifiers", cfg: configuration.RoutingConfig{Rules: "cash_in_*=proxy,drop"}, wantErr: true},
+		{name: "Invalid default route", cfg: configuration.RoutingConfig{Default: "stdout"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRoutes(tt.cfg, notifiers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRoutes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.disabled {
+				t.Errorf("NewRoutes() = %v, want disabled %v", got, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_Routing(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name      string
+		cfg       configuration.RoutingConfig
+		eventType string
+		wantProxy bool
+		wantRedis bool
+	}{
+		{
+			name:      "Without routes to all the notifiers",
+			eventType: "cash_in_internal_transfer",
+			wantProxy: true,
+			wantRedis: true,
+		},
+		{
+			name:      "First matching rule",
+			cfg:       configuration.RoutingConfig{Rules: "cash_in_*=redis;*=proxy"},
+			eventType: "cash_in_internal_transfer",
+			wantRedis: true,
+		},
+		{
+			name:      "Next matching rule",
+			cfg:       configuration.RoutingConfig{Rules: "cash_in_*=redis;*=proxy"},
+			eventType: "cash_out_pix",
+			wantProxy: true,
+		},
+		{
+			name:      "No matching rule to all the notifiers",
+			cfg:       configuration.RoutingConfig{Rules: "cash_in_*=redis"},
+			eventType: "cash_out_pix",
+			wantProxy: true,
+			wantRedis: true,
+		},
+		{
+			name:      "No matching rule to the default route",
+			cfg:       configuration.RoutingConfig{Rules: "cash_in_*=redis", Default: "proxy"},
+			eventType: "cash_out_pix",
+			wantProxy: true,
+		},
+		{
+			name:      "Dropped by a rule",
+			cfg:       configuration.RoutingConfig{Rules: "*_test=drop;*=proxy,redis"},
+			eventType: "cash_in_test",
+		},
+		{
+			name:      "Dropped by the default route",
+			cfg:       configuration.RoutingConfig{Rules: "cash_in_*=redis", Default: "drop"},
+			eventType: "cash_out_pix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy, redis := &fakeNotifier{}, &fakeNotifier{}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{proxy, redis}, nil)
+
+			routes, err := NewRoutes(tt.cfg, map[string]domain.Notifier{"proxy": proxy, "redis": redis})
+			if err != nil {
+				t.Fatalf("NewRoutes() error = %v", err)
+			}
+			uc.SetRoutes(routes)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: tt.eventType},
+				EncryptedBody: encryptedBody,
+			}
+			if _, err := uc.SendNotification(context.Background(), input); err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			if got := len(proxy.bodies) == 1; got != tt.wantProxy {
+				t.Errorf("SendNotification() sent to proxy = %v, want %v", got, tt.wantProxy)
+			}
+			if got := len(redis.bodies) == 1; got != tt.wantRedis {
+				t.Errorf("SendNotification() sent to redis = %v, want %v", got, tt.wantRedis)
+			}
+		})
+	}
+}
+
+func TestNewRoutes_SharedNotifier(t *testing.T) {
+	// The members of a tee are the same notifier, sent only once.
+	tee := &fakeNotifier{}
+	routes, err := NewRoutes(configuration.RoutingConfig{Rules: "*=proxy,redis"}, map[string]domain.Notifier{"proxy": tee, "redis": tee})
+	if err != nil {
+		t.Fatalf("NewRoutes() error = %v", err)
+	}
+
+	if got := routes.match("cash_in_internal_transfer"); got == nil || len(got.notifiers) != 1 {
+		t.Errorf("match() = %v, want the shared notifier once", got)
+	}
+}
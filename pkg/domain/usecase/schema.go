@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonschema"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const (
+	// SchemaActionReject fails the invalid payloads.
+	SchemaActionReject = "reject"
+	// SchemaActionDeadLetter stores the invalid payloads as dead letters,
+	// acking them.
+	SchemaActionDeadLetter = "dead_letter"
+)
+
+// Schemas validates the payloads of each event type with its JSON Schema.
+// The first matching schema wins, and the payloads matching none aren't
+// validated.
+type Schemas struct {
+	rules      []schemaRule
+	deadLetter bool
+}
+
+type schemaRule struct {
+	pattern string
+	schema  *jsonschema.Schema
+}
+
+// NewSchemas compiles the schemas of the validation rules, so the invalid
+// ones fail at startup. It returns nil when the validation is disabled.
+func NewSchemas(cfg configuration.Config) (*Schemas, error) {
+	if strings.TrimSpace(cfg.SchemaConfig.Schemas) == "" {
+		return nil, nil
+	}
+
+	schemas := &Schemas{}
+	switch cfg.SchemaConfig.Action {
+	case SchemaActionReject:
+	case SchemaActionDeadLetter:
+		if cfg.DeadLetterConfig.Store == "" {
+			return nil, fmt.Errorf("the payload schema action %s requires a dead letter store", SchemaActionDeadLetter)
+		}
+		schemas.deadLetter = true
+	default:
+		return nil, fmt.Errorf("invalid payload schema action %q, must be %s or %s", cfg.SchemaConfig.Action, SchemaActionReject, SchemaActionDeadLetter)
+	}
+
+	for _, item := range configuration.SplitList(cfg.SchemaConfig.Schemas) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid payload schema, expected pattern=path: %v", item)
+		}
+
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid payload schema pattern %q: %v", pattern, err)
+		}
+
+		data, err := ioutil.ReadFile(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("reading the payload schema of %s: %v", pattern, err)
+		}
+
+		schema, err := jsonschema.Compile(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payload schema of %s: %v", pattern, err)
+		}
+
+		schemas.rules = append(schemas.rules, schemaRule{pattern: pattern, schema: schema})
+	}
+
+	return schemas, nil
+}
+
+// match returns the schema of the event type, or nil.
+func (s *Schemas) match(eventType string) *schemaRule {
+	if s == nil {
+		return nil
+	}
+
+	for i := range s.rules {
+		// The patterns are validated by NewSchemas.
+		if ok, _ := path.Match(s.rules[i].pattern, eventType); ok {
+			return &s.rules[i]
+		}
+	}
+
+	return nil
+}
+
+// validate checks the payload with the schema of its event type, logging the
+// failed keywords. The empty payloads were already checked by checkPayload.
+func (s *Schemas) validate(log *logrus.Logger, header domain.HeaderNotification, payload string) error {
+	rule := s.match(header.EventType)
+	if rule == nil || strings.TrimSpace(payload) == "" {
+		return nil
+	}
+
+	var messages []string
+	errs, err := rule.schema.Validate([]byte(payload))
+	if err != nil {
+		messages = []string{"$: invalid JSON"}
+	}
+	for _, e := range errs {
+		messages = append(messages, e.String())
+	}
+
+	if len(messages) == 0 {
+		schemaValidations.WithLabelValues(rule.pattern, schemaValid).Inc()
+		return nil
+	}
+
+	schemaValidations.WithLabelValues(rule.pattern, schemaInvalid).Inc()
+	log.WithFields(logrus.Fields{
+		"event_id":   header.EventID,
+		"event_type": header.EventType,
+		"schema":     rule.pattern,
+		"errors":     messages,
+	}).Warn("payload doesn't match its schema")
+
+	return &domain.SchemaError{Schema: rule.pattern, Errors: messages}
+}
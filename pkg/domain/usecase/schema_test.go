@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const testSchema = `{"type":"object","required":["event_type","amount"],"properties":{"amount":{"type":"integer","minimum":0}}}`
+
+func schemaConfig(schemas, action, deadLetterStore string) configuration.Config {
+	return configuration.Config{
+		SchemaConfig:     configuration.SchemaConfig{Schemas: schemas, Action: action},
+		DeadLetterConfig: configuration.DeadLetterConfig{Store: deadLetterStore},
+	}
+}
+
+func TestNewSchemas(t *testing.T) {
+	valid := writeTemplate(t, testSchema)
+	unsupported := writeTemplate(t, `{"$ref":"#/definitions/amount"}`)
+
+	tests := []struct {
+		name     string
+		cfg      configuration.Config
+		disabled bool
+		wantErr  bool
+	}{
+		{name: "Disabled", cfg: schemaConfig("", SchemaActionReject, ""), disabled: true},
+		{name: "Rejected", cfg: schemaConfig("cash_in_*="+valid+";*="+valid, SchemaActionReject, "")},
+		{name: "To the dead letters", cfg: schemaConfig("*="+valid, SchemaActionDeadLetter, "file")},
+		{name: "Dead letters without the store", cfg: schemaConfig("*="+valid, SchemaActionDeadLetter, ""), wantErr: true},
+		{name: "Invalid action", cfg: schemaConfig("*="+valid, "drop", ""), wantErr: true},
+		{name: "Without the path", cfg: schemaConfig("cash_in_*=", SchemaActionReject, ""), wantErr: true},
+		{name: "Invalid pattern", cfg: schemaConfig("cash_in_[="+valid, SchemaActionReject, ""), wantErr: true},
+		{name: "Missing file", cfg: schemaConfig("*="+valid+".missing", SchemaActionReject, ""), wantErr: true},
+		{name: "Unsupported keyword", cfg: schemaConfig("*="+unsupported, SchemaActionReject, ""), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSchemas(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSchemas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.disabled {
+				t.Errorf("NewSchemas() = %v, want disabled %v", got, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_Schema(t *testing.T) {
+	testKeys := loadTestKeys(t)
+
+	tests := []struct {
+		name           string
+		action         string
+		payload        string
+		wantErr        error
+		wantErrors     []string
+		wantSent       bool
+		wantDeadLetter bool
+	}{
+		{
+			name:     "Valid payload",
+			action:   SchemaActionReject,
+			payload:  `{"event_type":"cash_in_internal_transfer","amount":10}`,
+			wantSent: true,
+		},
+		{
+			name:       "Invalid payload rejected",
+			action:     SchemaActionReject,
+			payload:    `{"event_type":"cash_in_internal_transfer","amount":-1.5}`,
+			wantErr:    domain.ErrInvalidSchema,
+			wantErrors: []string{"$.amount: must be of type integer"},
+		},
+		{
+			name:           "Invalid payload to the dead letters",
+			action:         SchemaActionDeadLetter,
+			payload:        `{"event_type":"cash_in_internal_transfer"}`,
+			wantDeadLetter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			deadLetters := &fakeDeadLetterStore{}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+			uc.SetDeadLetterStore(deadLetters)
+
+			schemas, err := NewSchemas(schemaConfig("cash_in_*="+writeTemplate(t, testSchema), tt.action, "file"))
+			if err != nil {
+				t.Fatalf("NewSchemas() error = %v", err)
+			}
+			uc.SetSchemas(schemas)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: signAndEncrypt(t, tt.payload),
+			}
+			_, err = uc.SendNotification(context.Background(), input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendNotification() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var schemaErr *domain.SchemaError
+			if tt.wantErrors != nil && (!errors.As(err, &schemaErr) || !reflect.DeepEqual(schemaErr.Errors, tt.wantErrors)) {
+				t.Errorf("SendNotification() error = %v, want the errors %v", err, tt.wantErrors)
+			}
+
+			if got := len(notifier.bodies) == 1; got != tt.wantSent {
+				t.Errorf("SendNotification() sent %v, want sent %v", notifier.bodies, tt.wantSent)
+			}
+			if got := len(deadLetters.deadLetters) == 1; got != tt.wantDeadLetter {
+				t.Fatalf("SendNotification() dead letters = %v, want %v", deadLetters.deadLetters, tt.wantDeadLetter)
+			}
+			if tt.wantDeadLetter && deadLetters.deadLetters[0].Notification.Body != tt.payload {
+				t.Errorf("SendNotification() dead letter body = %s, want %s", deadLetters.deadLetters[0].Notification.Body, tt.payload)
+			}
+		})
+	}
+}
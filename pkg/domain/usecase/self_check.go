@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"fmt"
+)
+
+// SelfTest verifies and decodes the encrypted body with the current keys, to
+// detect misconfigured keys before the notifications arrive.
+func (uc NotificationUsecase) SelfTest(encryptedBody string) error {
+	keyConfig := uc.keys.Get()
+
+	encryptedPayload, err := uc.verify(keyConfig, encryptedBody)
+	if err != nil {
+		return fmt.Errorf("unable to verify signature: %w", err)
+	}
+
+	if _, err := uc.decode(keyConfig, encryptedPayload, false); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func TestNotificationUsecase_SelfTest(t *testing.T) {
+	testKeys := loadTestKeys(t)
+
+	tests := []struct {
+		name          string
+		encryptedBody func(t *testing.T) string
+		wantErr       bool
+	}{
+		{
+			name: "Sample body",
+			encryptedBody: func(t *testing.T) string {
+				return signAndEncrypt(t, `{"id":"930bbd6d"}`)
+			},
+		},
+		{
+			name: "Generated sample body",
+			encryptedBody: func(t *testing.T) string {
+				signingKey, err := keys.LoadSampleSigningKey(testsPath + "stone/fakekey1.pem.jwt")
+				if err != nil {
+					t.Fatalf("loading signing key: %v", err)
+				}
+				body, err := keys.NewSampleBody(testKeys, signingKey)
+				if err != nil {
+					t.Fatalf("NewSampleBody() error = %v", err)
+				}
+				return body
+			},
+		},
+		{
+			name: "Signed by an unknown key",
+			encryptedBody: func(t *testing.T) string {
+				return sign(t, signingKeyFromFile(t, testsPath+"stone/fakekey3.pem.jwt"), encrypt(t, "{}"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid body",
+			encryptedBody: func(t *testing.T) string {
+				return "not.a.token"
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			if err := uc.SelfTest(tt.encryptedBody(t)); (err != nil) != tt.wantErr {
+				t.Errorf("SelfTest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
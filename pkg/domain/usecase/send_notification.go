@@ -2,60 +2,427 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
 )
 
-func (uc NotificationUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) error {
-	encryptedPayload, err := uc.verify(input.EncryptedBody)
+func (uc NotificationUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, error) {
+	ctx, span := tracer.Start(ctx, "SendNotification", trace.WithAttributes(
+		attribute.String("webhook.event_id", input.Header.EventID),
+		attribute.String("webhook.event_type", input.Header.EventType),
+	))
+
+	output, outcome, err := uc.sendNotification(ctx, input)
+	notificationsTotal.WithLabelValues(uc.eventTypeLabels.Known(input.Header.EventType), outcome).Inc()
+
+	span.SetAttributes(attribute.String("webhook.outcome", outcome))
+	endSpan(span, err)
+	return output, err
+}
+
+// sendNotification returns the outcome of the notification, for the metrics.
+func (uc NotificationUsecase) sendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, string, error) {
+	var output domain.NotificationOutput
+	processing := domain.ProcessingMetadata{ReceivedAt: time.Now()}
+
+	if err := uc.archive(ctx, input); err != nil {
+		return output, outcomeArchiveFailed, err
+	}
+
+	// The same keys are used in the whole request, even if they're reloaded.
+	keyConfig := uc.keys.Get()
+
+	// The unsigned notifications are only authenticated by the decryption,
+	// with a key shared by both sides.
+	var err error
+	encryptedPayload := input.EncryptedBody
+	if !input.Unsigned {
+		start := time.Now()
+		_, span := tracer.Start(ctx, "verify JWS")
+		encryptedPayload, processing.KeyID, err = uc.verifyKey(keyConfig, input.EncryptedBody)
+		span.SetAttributes(attribute.String("webhook.kid", processing.KeyID))
+		endSpan(span, err)
+		if err == nil {
+			uc.authenticated(&output, input.Header.EventType)
+		}
+		uc.observePhase(input.Header.EventType, phaseVerify, start)
+		if err != nil {
+			return output, outcomeVerificationFailed, fmt.Errorf("unable to verify signature: %w", &domain.VerificationError{Err: err})
+		}
+	}
+
+	var payload string
+	switch {
+	case uc.verifyOnly && input.Unsigned:
+		// Nothing would be verified at all.
+		return output, outcomeRejected, fmt.Errorf("unsigned notifications can't be forwarded undecrypted")
+	case uc.verifyOnly:
+		payload, err = uc.forward(encryptedPayload)
+		if err != nil {
+			return output, outcomeDecryptionFailed, fmt.Errorf("unable to forward payload: %w", &domain.VerificationError{Err: err})
+		}
+	default:
+		start := time.Now()
+		_, span := tracer.Start(ctx, "decrypt JWE")
+		payload, err = uc.decode(keyConfig, encryptedPayload, input.Unsigned)
+		endSpan(span, err)
+		if err == nil && input.Unsigned {
+			uc.authenticated(&output, input.Header.EventType)
+		}
+		uc.observePhase(input.Header.EventType, phaseDecode, start)
+		if err != nil {
+			return output, outcomeDecryptionFailed, fmt.Errorf("unable to decode payload: %w", &domain.VerificationError{Err: err})
+		}
+	}
+
+	payloadSize.WithLabelValues(uc.eventTypeLabels.Known(input.Header.EventType)).Observe(float64(len(payload)))
+
+	// Only the verified notifications are tracked, so a forged one can't
+	// quarantine an event id, or be acked as a duplicate. A replay is sent
+	// anyway.
+	eventID := input.Header.EventID
+	if !input.Replay && uc.duplicate(ctx, eventID) {
+		duplicateNotifications.Inc()
+		uc.log.Infof("notification %s already sent, acked without being sent again", eventID)
+		output.Duplicate = true
+		return output, outcomeDuplicate, nil
+	}
+
+	if !input.Replay && uc.quarantine.Quarantined(eventID) {
+		quarantineAcked.Inc()
+		uc.log.Warnf("notification %s is quarantined, acked without being sent", eventID)
+		uc.storeNotification(input, outcomeQuarantined, nil)
+		return output, outcomeQuarantined, nil
+	}
+
+	output, outcome, err := uc.process(ctx, keyConfig, input, payload, processing)
+	output.Verified = true
+	// The published notifications are stored when the publish is done, and
+	// a header spliced onto another payload isn't stored at all.
+	if !published(outcome) && !errors.Is(err, domain.ErrPayloadMismatch) {
+		uc.storeNotification(input, outcome, err)
+	}
 	if err != nil {
-		return fmt.Errorf("unable to verify signature: %v", err)
+		// Only the payload fails every redelivery, a downstream outage
+		// doesn't, and a header spliced onto another payload isn't a failure
+		// of its event.
+		if deterministic(err) && uc.quarantine.Failed(eventID) {
+			eventsQuarantined.Inc()
+			uc.log.WithError(err).Errorf("QUARANTINED: notification %s reached the failure threshold, its redeliveries will be acked without being sent", eventID)
+			uc.deadLetter(input, domain.Notification{Header: input.Header, Body: payload, Processing: processing}, fmt.Errorf("QUARANTINED: %w", err))
+		}
+		return output, outcome, err
 	}
 
-	payload, err := uc.decode(encryptedPayload)
+	uc.quarantine.Succeeded(eventID)
+	return output, outcome, nil
+}
+
+// deterministic reports the rejections of the payload itself, by the payload
+// checks or its schema, which fail all the redeliveries the same way.
+func deterministic(err error) bool {
+	return errors.Is(err, domain.ErrEmptyPayload) || errors.Is(err, domain.ErrFieldTooLong) || errors.Is(err, domain.ErrInvalidSchema)
+}
+
+// authenticated marks the notification as verified, only then admitting its
+// event type in the metric labels, so forged ones can't exhaust them.
+func (uc NotificationUsecase) authenticated(output *domain.NotificationOutput, eventType string) {
+	output.Verified = true
+	uc.eventTypeLabels.Admit(eventType)
+}
+
+// process checks and publishes the verified notification, returning its
+// outcome.
+func (uc NotificationUsecase) process(ctx context.Context, keyConfig *keys.Config, input domain.NotificationInput, payload string, processing domain.ProcessingMetadata) (domain.NotificationOutput, string, error) {
+	var output domain.NotificationOutput
+
+	if err := uc.checkPayload(input.Header, payload); err != nil {
+		return output, outcomeRejected, err
+	}
+
+	// An invalid payload is rejected, or stored as a dead letter and acked.
+	if err := uc.schemas.validate(uc.log, input.Header, payload); err != nil {
+		if !uc.schemas.deadLetter {
+			return output, outcomeRejected, err
+		}
+		uc.deadLetter(input, domain.Notification{Header: input.Header, Body: payload, Processing: processing}, err)
+		return output, outcomeInvalidSchema, nil
+	}
+
+	if err := uc.authorize(ctx, domain.Notification{Header: input.Header, Body: payload}); err != nil {
+		return output, outcomeRejected, err
+	}
+
+	notifiers := uc.notifiers
+	if uc.isTestMode(input.Header, payload) {
+		if len(uc.testNotifiers) == 0 {
+			testModeNotifications.WithLabelValues(testModeDropped).Inc()
+			uc.log.Infof("test-mode notification %s dropped", input.Header.EventID)
+			return output, outcomeDropped, nil
+		}
+
+		testModeNotifications.WithLabelValues(testModeRouted).Inc()
+		notifiers = uc.testNotifiers
+	} else {
+		var routed bool
+		notifiers, routed = uc.route(input.Header.EventType, notifiers)
+		if !routed {
+			uc.log.Infof("notification %s dropped by its route, type[%s]", input.Header.EventID, input.Header.EventType)
+			return output, outcomeDropped, nil
+		}
+	}
+
+	notification := domain.Notification{
+		Header:     input.Header,
+		Body:       payload,
+		Fields:     uc.extractFields(input.Header, payload),
+		Ciphertext: uc.verifyOnly,
+		Processing: processing,
+	}
+
+	// The fields and the ordering key are still taken from the payload as
+	// received. A failed transformation is stored as a dead letter, with the
+	// payload as received, and acked, since every redelivery fails the same way.
+	body, err := uc.transforms.apply(input.Header, payload)
 	if err != nil {
-		return fmt.Errorf("unable to decode payload: %v", err)
+		uc.deadLetter(input, notification, fmt.Errorf("%w: %v", domain.ErrTransformFailed, err))
+		return output, outcomeTransformFailed, nil
 	}
+	notification.Body = body
 
-	for _, notifier := range uc.notifiers {
-		err := notifier.Send(ctx, input.Header.EventType, input.Header.EventID, payload)
-		if err != nil {
-			return err
+	uc.observeLag(notification, time.Now())
+
+	// The ordering key is kept until the publish is done, even if it's
+	// detached or queued, and the provider is only confirmed once it
+	// succeeded, when its event id is also remembered. A failed publish is
+	// stored as a dead letter, and one taken by a deferred notifier is stored
+	// as deferred.
+	start := time.Now()
+	done := func(deferred bool, err error) {
+		uc.observePhase(input.Header.EventType, phasePublish, start)
+		switch {
+		case err != nil:
+			uc.deadLetter(input, notification, err)
+			uc.storeNotification(input, outcomeDeliveryFailed, err)
+		case deferred:
+			uc.remember(input.Header.EventID)
+			uc.storeNotification(input, outcomeDeferred, nil)
+		default:
+			uc.remember(input.Header.EventID)
+			uc.storeNotification(input, outcomeSent, nil)
+		}
+		if err == nil && uc.confirmer != nil {
+			uc.confirmer.Confirm(input.Header)
+		}
+	}
+
+	key, ordered := uc.orderingKey(input.Header.EventType, payload)
+	switch {
+	case ordered && uc.orderingQueue != nil:
+		output.Deferred, err = uc.publishQueued(ctx, key, notifiers, notification, done)
+	case ordered:
+		unlock, lockErr := uc.lockOrderingKey(ctx, key)
+		if lockErr != nil {
+			return output, outcomeBusy, lockErr
+		}
+		output.Deferred, err = uc.publish(ctx, notifiers, notification, func(deferred bool, err error) {
+			done(deferred, err)
+			unlock()
+		})
+	default:
+		output.Deferred, err = uc.publish(ctx, notifiers, notification, done)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderingBusy) {
+			return output, outcomeBusy, err
 		}
+		return output, outcomeDeliveryFailed, err
+	}
+
+	output.Receipt = uc.receipt(keyConfig, input.Header)
+
+	if output.Deferred {
+		return output, outcomeDeferred, nil
+	}
+	return output, outcomeSent, nil
+}
+
+// authorize allows all the notifications when there's no authorizer.
+func (uc NotificationUsecase) authorize(ctx context.Context, notification domain.Notification) error {
+	if uc.authorizer == nil {
+		return nil
+	}
+
+	if err := uc.authorizer.Authorize(ctx, notification); err != nil {
+		return fmt.Errorf("authorizing notification: %w", err)
 	}
 
 	return nil
 }
 
-func (uc NotificationUsecase) verify(signedBody string) (string, error) {
+func (uc NotificationUsecase) archive(ctx context.Context, input domain.NotificationInput) error {
+	if uc.archiver == nil {
+		return nil
+	}
+
+	err := uc.archiver.Archive(ctx, input)
+	if err == nil {
+		return nil
+	}
+
+	if uc.archiveFatal {
+		return fmt.Errorf("%w: %v", domain.ErrArchiveFailed, err)
+	}
+
+	uc.log.WithError(err).Warnf("unable to archive notification %s, proceeding anyway", input.Header.EventID)
+	return nil
+}
+
+func (uc NotificationUsecase) verify(keyConfig *keys.Config, signedBody string) (string, error) {
+	payload, _, err := uc.verifyKey(keyConfig, signedBody)
+	return payload, err
+}
+
+// verifyKey verifies the signature, returning the payload and the kid of the
+// matching key. The kid of a x5c certificate chain is the one in the header.
+func (uc NotificationUsecase) verifyKey(keyConfig *keys.Config, signedBody string) (string, string, error) {
 	obj, err := jose.ParseSigned(signedBody)
 	if err != nil {
-		return "", fmt.Errorf("unable to parse message: %v", err)
+		return "", "", fmt.Errorf("unable to parse message: %v", err)
 	}
 
 	if len(obj.Signatures) != 1 {
-		return "", fmt.Errorf("multi signature not supported")
+		return "", "", fmt.Errorf("multi signature not supported")
+	}
+
+	alg := jose.SignatureAlgorithm(obj.Signatures[0].Header.Algorithm)
+	if !keyConfig.AllowsSignatureAlgorithm(alg) {
+		return "", "", fmt.Errorf("signature algorithm not allowed: %s", alg)
+	}
+
+	if err := checkCritical(obj.Signatures[0].Protected, jwsCriticalParams); err != nil {
+		return "", "", err
 	}
 
-	// Verify will all keys.
-	var plainText []byte
-	for _, verificationKey := range uc.keys.VerificationKeyList {
+	// A trusted x5c certificate chain replaces the public keys.
+	if keyConfig.CertificateRoots != nil && !keys.IsSymmetricAlgorithm(alg) && hasCertificateChain(signedBody) {
+		leafKey, err := uc.certificateChainKey(keyConfig, obj.Signatures[0].Protected)
+		if err != nil {
+			return "", "", err
+		}
+
+		plainText, err := obj.Verify(leafKey)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid signature: %v", err)
+		}
+
+		return string(plainText), obj.Signatures[0].Header.KeyID, nil
+	}
+
+	// HMAC signatures are never verified with the public keys, and vice versa,
+	// so a public key can't be used as a shared secret.
+	verificationKeyList := keyConfig.VerificationKeyList
+	if keys.IsSymmetricAlgorithm(alg) {
+		verificationKeyList = keyConfig.SymmetricKeyList
+	}
+
+	if len(verificationKeyList) == 0 {
+		return "", "", fmt.Errorf("no verification keys to algorithm %s", alg)
+	}
+
+	// The keys with the kid of the signature are tried first, the others are
+	// still tried during a rotation, when the kid may be unknown yet.
+	verificationKeyList = keysByID(verificationKeyList, obj.Signatures[0].Header.KeyID)
+
+	plainText, key, err := verifyWithKeys(obj, verificationKeyList, uc.verifyParallelism)
+	if err == nil {
+		return string(plainText), key.KeyID, nil
+	}
+
+	// The break-glass keys are tried last, and their use means the
+	// rotation of the public keys is broken.
+	if !keys.IsSymmetricAlgorithm(alg) && len(keyConfig.FallbackKeyList) > 0 {
+		plainText, key, fallbackErr := verifyWithKeys(obj, keyConfig.FallbackKeyList, uc.verifyParallelism)
+		if fallbackErr == nil {
+			fallbackKeyUsed.Inc()
+			uc.log.Warnf("FALLBACK KEY USED: signature verified by the fallback key, the public keys failed: %v", err)
+			return string(plainText), key.KeyID, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("invalid signature: %v", err)
+}
+
+// keysByID returns the keys with the kid first, keeping the order of the
+// others. The list is returned as is when no key has the kid.
+func keysByID(keyList []*jose.JSONWebKey, kid string) []*jose.JSONWebKey {
+	if kid == "" {
+		return keyList
+	}
+
+	matching := make([]*jose.JSONWebKey, 0, len(keyList))
+	var others []*jose.JSONWebKey
+	for _, key := range keyList {
+		if key.KeyID == kid {
+			matching = append(matching, key)
+		} else {
+			others = append(others, key)
+		}
+	}
+	if len(matching) == 0 {
+		return keyList
+	}
+
+	return append(matching, others...)
+}
+
+// verifyWithKeys verifies the signature with all keys, returning the matching
+// key or failing with the last key error. With parallelism, up to that number
+// of keys are tried at a time.
+func verifyWithKeys(obj *jose.JSONWebSignature, keyList []*jose.JSONWebKey, parallelism int) ([]byte, *jose.JSONWebKey, error) {
+	if parallelism > 1 && len(keyList) > 1 {
+		return verifyInParallel(obj, keyList, parallelism)
+	}
+
+	var err error
+	for _, verificationKey := range keyList {
+		var plainText []byte
 		plainText, err = obj.Verify(verificationKey)
 		if err == nil {
-			break
+			return plainText, verificationKey, nil
 		}
 	}
 
-	if err != nil {
-		return "", fmt.Errorf("invalid signature: %v", err)
+	return nil, nil, err
+}
+
+// checkCiphertextSize bounds the encrypted payload, before it's parsed,
+// whether it's decrypted or forwarded.
+func (uc NotificationUsecase) checkCiphertextSize(encryptedBody string) error {
+	limit := uc.decryptLimits.MaxCiphertextSize
+	if limit > 0 && len(encryptedBody) > limit {
+		return fmt.Errorf("%w: ciphertext with %d bytes, the maximum is %d", domain.ErrDecryptLimit, len(encryptedBody), limit)
 	}
 
-	return string(plainText), nil
+	return nil
 }
 
-func (uc NotificationUsecase) decode(encryptedBody string) (string, error) {
+// decode decrypts the JWE. An unsigned one is only decrypted with the unsigned
+// key algorithms, whose key isn't public.
+func (uc NotificationUsecase) decode(keyConfig *keys.Config, encryptedBody string, unsigned bool) (string, error) {
+	if err := uc.checkCiphertextSize(encryptedBody); err != nil {
+		return "", err
+	}
+	limits := uc.decryptLimits
+
 	// Parse the serialized, encrypted JWE object. An error would indicate that
 	// the given input did not represent a valid message.
 	object, err := jose.ParseEncrypted(encryptedBody)
@@ -63,13 +430,40 @@ func (uc NotificationUsecase) decode(encryptedBody string) (string, error) {
 		return "", fmt.Errorf("parsing encrypted: %v", err)
 	}
 
+	if err := checkCritical(object.Header, jweCriticalParams); err != nil {
+		return "", err
+	}
+
+	// go-jose inflates the whole payload while decrypting, so a compressed
+	// payload can only be bounded by rejecting it.
+	if _, ok := object.Header.ExtraHeaders["zip"]; ok && !limits.AllowCompression {
+		return "", fmt.Errorf("%w: compressed payloads aren't allowed", domain.ErrDecryptLimit)
+	}
+
+	keyList, err := decryptionKeys(keyConfig, limits, object.Header, unsigned)
+	if err != nil {
+		return "", err
+	}
+
 	// Now we can decrypt and get back our original plaintext. An error here
 	// would indicate the the message failed to decrypt, e.g. because the auth
 	// tag was broken or the message was tampered with.
-	decrypted, err := object.Decrypt(uc.keys.PrivateKey)
+	var decrypted []byte
+	for _, key := range keyList {
+		decrypted, err = object.Decrypt(key)
+		if err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("decrypting: %v", err)
 	}
 
+	// An allowed compressed payload is only measured once inflated, and its
+	// memory is bounded by the ciphertext limit.
+	if limits.MaxDecompressedSize > 0 && len(decrypted) > limits.MaxDecompressedSize {
+		return "", fmt.Errorf("%w: payload with %d bytes, the maximum is %d", domain.ErrDecryptLimit, len(decrypted), limits.MaxDecompressedSize)
+	}
+
 	return string(decrypted), nil
 }
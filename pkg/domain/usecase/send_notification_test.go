@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const testsPath = "../../../tests/"
+
+type fakeNotifier struct {
+	bodies        []string
+	notifications []domain.Notification
+	err           error
+}
+
+func (f *fakeNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	f.bodies = append(f.bodies, notification.Body)
+	f.notifications = append(f.notifications, notification)
+	return f.err
+}
+
+type fakeArchiver struct {
+	archived []domain.NotificationInput
+	err      error
+}
+
+func (f *fakeArchiver) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeArchiver) Archive(ctx context.Context, input domain.NotificationInput) error {
+	f.archived = append(f.archived, input)
+	return f.err
+}
+
+func loadTestKeys(t *testing.T) *keys.Config {
+	t.Helper()
+
+	cfg, err := keys.LoadKeys(configuration.KeysConfig{
+		PrivateKeyPath:      testsPath + "partner/fakekey.pem",
+		PublicKeyLocation:   "file://" + testsPath + "stone/fakekey1.pub.jwt",
+		SignatureAlgorithms: "PS256;RS256",
+		KeyAlgorithms:       "RSA-OAEP-256",
+	})
+	if err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+
+	return cfg
+}
+
+// encrypt builds the inner JWE the same way Stone does.
+func encrypt(t *testing.T, body string) string {
+	t.Helper()
+
+	return encryptWithOptions(t, nil, body)
+}
+
+func encryptWithOptions(t *testing.T, opts *jose.EncrypterOptions, body string) string {
+	t.Helper()
+
+	keyBytes, err := ioutil.ReadFile(testsPath + "partner/fakekey.pub")
+	if err != nil {
+		t.Fatalf("reading public key: %v", err)
+	}
+	pub, err := keys.LoadPublicKey(keyBytes)
+	if err != nil {
+		t.Fatalf("loading public key: %v", err)
+	}
+
+	crypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: pub}, opts)
+	if err != nil {
+		t.Fatalf("creating encrypter: %v", err)
+	}
+	encrypted, err := crypter.Encrypt([]byte(body))
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	encryptedBody, err := encrypted.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serializing encrypted: %v", err)
+	}
+
+	return encryptedBody
+}
+
+func stoneSigningKey(t *testing.T) jose.SigningKey {
+	t.Helper()
+
+	keyBytes, err := ioutil.ReadFile(testsPath + "stone/fakekey1.pem.jwt")
+	if err != nil {
+		t.Fatalf("reading signing key: %v", err)
+	}
+	signingKey, err := keys.LoadPrivateKey(keyBytes)
+	if err != nil {
+		t.Fatalf("loading signing key: %v", err)
+	}
+
+	return jose.SigningKey{Algorithm: jose.PS256, Key: signingKey}
+}
+
+func sign(t *testing.T, signingKey jose.SigningKey, payload string) string {
+	t.Helper()
+
+	return signWithOptions(t, signingKey, nil, payload)
+}
+
+func signWithOptions(t *testing.T, signingKey jose.SigningKey, opts *jose.SignerOptions, payload string) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(signingKey, opts)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+	signed, err := signer.Sign([]byte(payload))
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	signedBody, err := signed.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serializing signed: %v", err)
+	}
+
+	return signedBody
+}
+
+// signAndEncrypt builds an encrypted body the same way Stone does.
+func signAndEncrypt(t *testing.T, body string) string {
+	t.Helper()
+
+	return sign(t, stoneSigningKey(t), encrypt(t, body))
+}
+
+func TestNotificationUsecase_SendNotification_Archive(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	encryptedBody := signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	tests := []struct {
+		name         string
+		archiveErr   error
+		archiveFatal bool
+		wantErr      error
+		wantNotified bool
+	}{
+		{
+			name:         "Archived notifications are sent",
+			wantNotified: true,
+		},
+		{
+			name:         "Best-effort archive failure still sends the notification",
+			archiveErr:   errors.New("bucket unavailable"),
+			wantNotified: true,
+		},
+		{
+			name:         "Fatal archive failure rejects the notification",
+			archiveErr:   errors.New("bucket unavailable"),
+			archiveFatal: true,
+			wantErr:      domain.ErrArchiveFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			archiver := &fakeArchiver{err: tt.archiveErr}
+			cfg := configuration.Config{ArchiverConfig: configuration.ArchiverConfig{Fatal: tt.archiveFatal}}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, archiver)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+
+			_, err := uc.SendNotification(context.Background(), input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(archiver.archived) != 1 || archiver.archived[0].EncryptedBody != encryptedBody {
+				t.Errorf("SendNotification() archived = %v", archiver.archived)
+			}
+			if (len(notifier.bodies) == 1) != tt.wantNotified {
+				t.Errorf("SendNotification() notified = %v, want %v", notifier.bodies, tt.wantNotified)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_PreservesNumbers(t *testing.T) {
+	payload := `{"id":9007199254740993,"target_data":{"amount":1234567890.123456789012345678}}`
+	encryptedBody := signAndEncrypt(t, payload)
+
+	tests := []struct {
+		name    string
+		eventID string
+		wantErr error
+	}{
+		{
+			name:    "64-bit event id matches the header",
+			eventID: "9007199254740993",
+		},
+		{
+			// Both are the same float64.
+			name:    "64-bit event id off by one mismatches the header",
+			eventID: "9007199254740992",
+			wantErr: domain.ErrPayloadMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			cfg := configuration.Config{
+				PayloadCheckConfig: configuration.PayloadCheckConfig{EventIDCheck: true, EventIDPath: "id"},
+			}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: tt.eventID, EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+
+			_, err := uc.SendNotification(context.Background(), input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The notifiers receive the payload as decrypted, digit by digit.
+			if len(notifier.bodies) != 1 || notifier.bodies[0] != payload {
+				t.Errorf("SendNotification() notified = %v, want %s", notifier.bodies, payload)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_ProcessingMetadata(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	notifier := &fakeNotifier{}
+	uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+
+	before := time.Now()
+	input := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: signAndEncrypt(t, `{"event_type":"cash_in_internal_transfer"}`),
+	}
+	if _, err := uc.SendNotification(context.Background(), input); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("SendNotification() notified = %v, want 1 notification", notifier.bodies)
+	}
+	processing := notifier.notifications[0].Processing
+	if processing.ReceivedAt.Before(before) || processing.ReceivedAt.After(time.Now()) {
+		t.Errorf("SendNotification() received at = %v, want the processing start", processing.ReceivedAt)
+	}
+	if want := testKeys.VerificationKeyList[0].KeyID; processing.KeyID != want {
+		t.Errorf("SendNotification() key id = %q, want %q", processing.KeyID, want)
+	}
+}
+
+func TestNotificationUsecase_extractFields_PreservesNumbers(t *testing.T) {
+	cfg := configuration.Config{ExtractionConfig: configuration.ExtractionConfig{VersionPath: "amount", EntityTypePath: "account_id"}}
+	uc := NewNotificationUsecase(cfg, logrus.New(), nil, nil, nil)
+
+	fields := uc.extractFields(domain.HeaderNotification{}, `{"account_id":9007199254740993,"amount":1234567890.123456789012345678}`)
+	if fields.EntityType != "9007199254740993" || fields.Version != "1234567890.123456789012345678" {
+		t.Errorf("extractFields() = %+v", fields)
+	}
+}
+
+func TestNotificationUsecase_verify(t *testing.T) {
+	payload := encrypt(t, `{"event_type":"cash_in_internal_transfer"}`)
+
+	oldSecret := &jose.JSONWebKey{Key: []byte("old-shared-secret-with-32-bytes!"), KeyID: "old"}
+	newSecret := &jose.JSONWebKey{Key: []byte("new-shared-secret-with-32-bytes!"), KeyID: "new"}
+
+	// An attacker who knows the public key could use it as a HMAC secret.
+	publicKey, err := ioutil.ReadFile(testsPath + "stone/fakekey1.pub.jwt")
+	if err != nil {
+		t.Fatalf("reading public key: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		algorithms []jose.SignatureAlgorithm
+		signingKey jose.SigningKey
+		wantErr    bool
+	}{
+		{
+			name:       "Asymmetric signature is verified",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256},
+			signingKey: stoneSigningKey(t),
+		},
+		{
+			name:       "Signature with the current shared secret is verified",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256, jose.HS256},
+			signingKey: jose.SigningKey{Algorithm: jose.HS256, Key: newSecret.Key},
+		},
+		{
+			name:       "Signature with the rotated shared secret is verified",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256, jose.HS256},
+			signingKey: jose.SigningKey{Algorithm: jose.HS256, Key: oldSecret.Key},
+		},
+		{
+			name:       "Unknown shared secret must fail",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256, jose.HS256},
+			signingKey: jose.SigningKey{Algorithm: jose.HS256, Key: []byte("unknown-secret-with-32-bytes-len")},
+			wantErr:    true,
+		},
+		{
+			name:       "HMAC signature must fail when not allowed",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256},
+			signingKey: jose.SigningKey{Algorithm: jose.HS256, Key: newSecret.Key},
+			wantErr:    true,
+		},
+		{
+			name:       "Public key used as HMAC secret must fail",
+			algorithms: []jose.SignatureAlgorithm{jose.PS256, jose.HS256},
+			signingKey: jose.SigningKey{Algorithm: jose.HS256, Key: publicKey},
+			wantErr:    true,
+		},
+		{
+			name:       "Asymmetric algorithm not allowed must fail",
+			algorithms: []jose.SignatureAlgorithm{jose.HS256},
+			signingKey: stoneSigningKey(t),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testKeys := loadTestKeys(t)
+			testKeys.SymmetricKeyList = []*jose.JSONWebKey{newSecret, oldSecret}
+			testKeys.SignatureAlgorithms = tt.algorithms
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), nil, nil)
+
+			got, err := uc.verify(testKeys, sign(t, tt.signingKey, payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != payload {
+				t.Errorf("verify() = %v, want %v", got, payload)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_VerificationError(t *testing.T) {
+	ciphertext := encrypt(t, `{"account_id":"acc-1"}`)
+
+	tests := []struct {
+		name          string
+		encryptedBody string
+		notifierErr   error
+		wantVerify    bool
+	}{
+		{name: "Invalid signature", encryptedBody: sign(t, stoneSigningKey(t), ciphertext) + "x", wantVerify: true},
+		{name: "Invalid ciphertext", encryptedBody: sign(t, stoneSigningKey(t), "not a jwe"), wantVerify: true},
+		{name: "Notifier failure", encryptedBody: sign(t, stoneSigningKey(t), ciphertext), notifierErr: errors.New("down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{err: tt.notifierErr}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: tt.encryptedBody,
+			}
+			_, err := uc.SendNotification(context.Background(), input)
+			if err == nil {
+				t.Fatal("SendNotification() must fail")
+			}
+			if got := errors.Is(err, domain.ErrVerificationFailed); got != tt.wantVerify {
+				t.Errorf("SendNotification() error = %v, is verification failure %t, want %t", err, got, tt.wantVerify)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// isTestMode detects the test-mode notifications, by the event type suffix or
+// a true boolean in the payload.
+func (uc NotificationUsecase) isTestMode(header domain.HeaderNotification, payload string) bool {
+	if suffix := uc.testMode.EventTypeSuffix; suffix != "" && strings.HasSuffix(header.EventType, suffix) {
+		return true
+	}
+
+	if path := uc.testMode.Path; path != "" {
+		value, ok := jsonpath.Lookup([]byte(payload), path)
+		if testMode, isBool := value.(bool); ok && isBool && testMode {
+			return true
+		}
+	}
+
+	return false
+}
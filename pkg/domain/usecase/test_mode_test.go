@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_SendNotification_TestMode(t *testing.T) {
+	testMode := configuration.TestModeConfig{Path: "livemode_test", EventTypeSuffix: ".test"}
+
+	tests := []struct {
+		name          string
+		eventType     string
+		payload       string
+		withTestRoute bool
+		wantSent      int
+		wantTestSent  int
+	}{
+		{
+			name:      "Production event",
+			eventType: "cash_in_internal_transfer",
+			payload:   `{"livemode_test":false}`,
+			wantSent:  1,
+		},
+		{
+			name:      "Not a boolean flag",
+			eventType: "cash_in_internal_transfer",
+			payload:   `{"livemode_test":"true"}`,
+			wantSent:  1,
+		},
+		{
+			name:      "Test flag is dropped",
+			eventType: "cash_in_internal_transfer",
+			payload:   `{"livemode_test":true}`,
+		},
+		{
+			name:      "Test suffix is dropped",
+			eventType: "cash_in_internal_transfer.test",
+			payload:   `{}`,
+		},
+		{
+			name:          "Test event is diverted",
+			eventType:     "cash_in_internal_transfer",
+			payload:       `{"livemode_test":true}`,
+			withTestRoute: true,
+			wantTestSent:  1,
+		},
+		{
+			name:          "Production event with test notifiers",
+			eventType:     "cash_in_internal_transfer",
+			payload:       `{}`,
+			withTestRoute: true,
+			wantSent:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, testNotifier := &fakeNotifier{}, &fakeNotifier{}
+
+			log := logrus.New()
+			log.SetOutput(ioutil.Discard)
+			uc := NewNotificationUsecase(configuration.Config{TestModeConfig: testMode}, log, keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{notifier}, nil)
+			if tt.withTestRoute {
+				uc.SetTestNotifiers([]domain.Notifier{testNotifier})
+			}
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "1", EventType: tt.eventType},
+				EncryptedBody: signAndEncrypt(t, tt.payload),
+			}
+			if _, err := uc.SendNotification(context.Background(), input); err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			if len(notifier.bodies) != tt.wantSent || len(testNotifier.bodies) != tt.wantTestSent {
+				t.Errorf("SendNotification() sent %d, test sent %d, want %d and %d", len(notifier.bodies), len(testNotifier.bodies), tt.wantSent, tt.wantTestSent)
+			}
+		})
+	}
+}
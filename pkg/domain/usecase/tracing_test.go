@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNotificationUsecase_SendNotification_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(loadTestKeys(t), nil), []domain.Notifier{&fakeNotifier{}}, nil)
+
+	sent := domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		EncryptedBody: signAndEncrypt(t, `{}`),
+	}
+	if _, err := uc.SendNotification(context.Background(), sent); err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+
+	forged := sent
+	forged.EncryptedBody = "header.payload.signature"
+	if _, err := uc.SendNotification(context.Background(), forged); err == nil {
+		t.Fatal("SendNotification() must fail with a forged signature")
+	}
+
+	type result struct {
+		name   string
+		failed bool
+	}
+	var got []result
+	for _, span := range recorder.Ended() {
+		got = append(got, result{name: span.Name(), failed: span.Status().Code == codes.Error})
+	}
+
+	want := []result{
+		{name: "verify JWS"},
+		{name: "decrypt JWE"},
+		{name: "SendNotification"},
+		{name: "verify JWS", failed: true},
+		{name: "SendNotification", failed: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SendNotification() spans = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SendNotification() span %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// TransformData is the data of the transformation templates.
+type TransformData struct {
+	EventID   string
+	EventType string
+	CreatedAt string
+	// Payload is the decoded JSON payload, with its numbers kept as written.
+	Payload interface{}
+}
+
+// Transforms renders the payloads of each event type with its template. The
+// first matching template wins, and the payloads matching none are sent as
+// decrypted.
+type Transforms struct {
+	rules []transform
+}
+
+type transform struct {
+	pattern  string
+	template *template.Template
+}
+
+// transformFuncs are available to the templates, besides the builtin ones.
+var transformFuncs = template.FuncMap{
+	// json renders a value as JSON, like a string with its quotes.
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewTransforms parses the templates of the transformation rules, so the
+// invalid ones fail at startup. It returns nil when the transformation is
+// disabled.
+func NewTransforms(cfg configuration.TransformConfig) (*Transforms, error) {
+	if strings.TrimSpace(cfg.Templates) == "" {
+		return nil, nil
+	}
+
+	transforms := &Transforms{}
+	for _, item := range configuration.SplitList(cfg.Templates) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid transformation template, expected pattern=path: %v", item)
+		}
+
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid transformation pattern %q: %v", pattern, err)
+		}
+
+		text, err := ioutil.ReadFile(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("reading the transformation template of %s: %v", pattern, err)
+		}
+
+		// A missing field is an error, instead of rendering "<no value>".
+		tmpl, err := template.New(pattern).Funcs(transformFuncs).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid transformation template of %s: %v", pattern, err)
+		}
+
+		transforms.rules = append(transforms.rules, transform{pattern: pattern, template: tmpl})
+	}
+
+	return transforms, nil
+}
+
+// match returns the transformation of the event type, or nil.
+func (t *Transforms) match(eventType string) *transform {
+	if t == nil {
+		return nil
+	}
+
+	for i := range t.rules {
+		// The patterns are validated by NewTransforms.
+		if ok, _ := path.Match(t.rules[i].pattern, eventType); ok {
+			return &t.rules[i]
+		}
+	}
+
+	return nil
+}
+
+// apply renders the payload with the template of its event type, which must
+// result in valid JSON. The payload is returned as is without a template.
+func (t *Transforms) apply(header domain.HeaderNotification, payload string) (string, error) {
+	rule := t.match(header.EventType)
+	if rule == nil {
+		return payload, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+
+	data := TransformData{EventID: header.EventID, EventType: header.EventType, CreatedAt: header.CreatedAt}
+	if err := decoder.Decode(&data.Payload); err != nil {
+		transformedNotifications.WithLabelValues(rule.pattern, transformFailed).Inc()
+		return "", fmt.Errorf("decoding the payload of template %s: %v", rule.pattern, err)
+	}
+
+	var body bytes.Buffer
+	if err := rule.template.Execute(&body, data); err != nil {
+		transformedNotifications.WithLabelValues(rule.pattern, transformFailed).Inc()
+		return "", fmt.Errorf("rendering template %s: %v", rule.pattern, err)
+	}
+
+	if !json.Valid(body.Bytes()) {
+		transformedNotifications.WithLabelValues(rule.pattern, transformFailed).Inc()
+		return "", fmt.Errorf("template %s didn't render valid JSON", rule.pattern)
+	}
+
+	transformedNotifications.WithLabelValues(rule.pattern, transformApplied).Inc()
+	return body.String(), nil
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// writeTemplate writes the template to a temp file, returning its path.
+func writeTemplate(t *testing.T, text string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "template.tmpl")
+	if err := ioutil.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+
+	return path
+}
+
+func TestNewTransforms(t *testing.T) {
+	valid := writeTemplate(t, `{"id":{{json .EventID}}}`)
+	invalid := writeTemplate(t, `{"id":{{json .EventID}`)
+
+	tests := []struct {
+		name     string
+		cfg      configuration.TransformConfig
+		disabled bool
+		wantErr  bool
+	}{
+		{name: "Disabled", disabled: true},
+		{name: "Templates", cfg: configuration.TransformConfig{Templates: "cash_in_*=" + valid + ";*=" + valid}},
+		{name: "Without the path", cfg: configuration.TransformConfig{Templates: "cash_in_*="}, wantErr: true},
+		{name: "Without the pattern", cfg: configuration.TransformConfig{Templates: "=" + valid}, wantErr: true},
+		{name: "Invalid pattern", cfg: configuration.TransformConfig{Templates: "cash_in_[=" + valid}, wantErr: true},
+		{name: "Missing file", cfg: configuration.TransformConfig{Templates: "*=" + valid + ".missing"}, wantErr: true},
+		{name: "Invalid template", cfg: configuration.TransformConfig{Templates: "*=" + invalid}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTransforms(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTransforms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.disabled {
+				t.Errorf("NewTransforms() = %v, want disabled %v", got, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestTransforms_apply(t *testing.T) {
+	transforms, err := NewTransforms(configuration.TransformConfig{Templates: "cash_in_*=" + writeTemplate(t,
+		`{"id":{{json .EventID}},"type":{{json .EventType}},"amount":{{.Payload.amount}},"account":{{json .Payload.account.id}}}`,
+	) + ";broken=" + writeTemplate(t, `{"missing":{{.Payload.missing}}}`) + ";not_json=" + writeTemplate(t, `amount {{.Payload.amount}}`)})
+	if err != nil {
+		t.Fatalf("NewTransforms() error = %v", err)
+	}
+
+	payload := `{"amount":12345678901234567890,"account":{"id":"a\"1"}}`
+
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Rendered",
+			eventType: "cash_in_pix",
+			payload:   payload,
+			want:      `{"id":"930bbd6d","type":"cash_in_pix","amount":12345678901234567890,"account":"a\"1"}`,
+		},
+		{
+			name:      "No matching template",
+			eventType: "cash_out_pix",
+			payload:   payload,
+			want:      payload,
+		},
+		{
+			name:      "Missing field",
+			eventType: "broken",
+			payload:   payload,
+			wantErr:   true,
+		},
+		{
+			name:      "Not JSON output",
+			eventType: "not_json",
+			payload:   payload,
+			wantErr:   true,
+		},
+		{
+			name:      "Not JSON payload",
+			eventType: "cash_in_pix",
+			payload:   `amount`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transforms.apply(domain.HeaderNotification{EventID: "930bbd6d", EventType: tt.eventType}, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationUsecase_SendNotification_Transform(t *testing.T) {
+	testKeys := loadTestKeys(t)
+	payload := `{"event_type":"cash_in_internal_transfer"}`
+	encryptedBody := signAndEncrypt(t, payload)
+
+	tests := []struct {
+		name           string
+		template       string
+		wantBody       string
+		wantDeadLetter bool
+	}{
+		{
+			name:     "Transformed",
+			template: `{"type":{{json .Payload.event_type}}}`,
+			wantBody: `{"type":"cash_in_internal_transfer"}`,
+		},
+		{
+			name:           "Failed to the dead letters and acked",
+			template:       `{"type":{{json .Payload.missing}}}`,
+			wantDeadLetter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			deadLetters := &fakeDeadLetterStore{}
+			uc := NewNotificationUsecase(configuration.Config{}, logrus.New(), keys.NewStore(testKeys, nil), []domain.Notifier{notifier}, nil)
+			uc.SetDeadLetterStore(deadLetters)
+
+			transforms, err := NewTransforms(configuration.TransformConfig{Templates: "cash_in_*=" + writeTemplate(t, tt.template)})
+			if err != nil {
+				t.Fatalf("NewTransforms() error = %v", err)
+			}
+			uc.SetTransforms(transforms)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: encryptedBody,
+			}
+			if _, err := uc.SendNotification(context.Background(), input); err != nil {
+				t.Fatalf("SendNotification() error = %v", err)
+			}
+
+			if tt.wantBody != "" && (len(notifier.bodies) != 1 || notifier.bodies[0] != tt.wantBody) {
+				t.Errorf("SendNotification() sent %v, want %s", notifier.bodies, tt.wantBody)
+			}
+			if tt.wantDeadLetter && len(notifier.bodies) != 0 {
+				t.Errorf("SendNotification() sent %v, want nothing", notifier.bodies)
+			}
+
+			if got := len(deadLetters.deadLetters) == 1; got != tt.wantDeadLetter {
+				t.Fatalf("SendNotification() dead letters = %v, want %v", deadLetters.deadLetters, tt.wantDeadLetter)
+			}
+			if tt.wantDeadLetter && deadLetters.deadLetters[0].Notification.Body != payload {
+				t.Errorf("SendNotification() dead letter body = %s, want %s", deadLetters.deadLetters[0].Notification.Body, payload)
+			}
+			if tt.wantDeadLetter && !strings.Contains(deadLetters.deadLetters[0].Reason, domain.ErrTransformFailed.Error()) {
+				t.Errorf("SendNotification() dead letter reason = %s, want %v", deadLetters.deadLetters[0].Reason, domain.ErrTransformFailed)
+			}
+		})
+	}
+}
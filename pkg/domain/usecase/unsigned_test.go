@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// encryptWithAlgorithm builds a JWE with the key management algorithm.
+func encryptWithAlgorithm(t *testing.T, alg jose.KeyAlgorithm, body string) string {
+	t.Helper()
+
+	keyBytes, err := ioutil.ReadFile(testsPath + "partner/fakekey.pub")
+	if err != nil {
+		t.Fatalf("reading public key: %v", err)
+	}
+	pub, err := keys.LoadPublicKey(keyBytes)
+	if err != nil {
+		t.Fatalf("loading public key: %v", err)
+	}
+
+	crypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: alg, Key: pub}, nil)
+	if err != nil {
+		t.Fatalf("creating encrypter: %v", err)
+	}
+	encrypted, err := crypter.Encrypt([]byte(body))
+	if err != nil {
+		t.Fatalf("encrypting: %v", err)
+	}
+	encryptedBody, err := encrypted.CompactSerialize()
+	if err != nil {
+		t.Fatalf("serializing encrypted: %v", err)
+	}
+
+	return encryptedBody
+}
+
+func TestNotificationUsecase_SendNotification_Unsigned(t *testing.T) {
+	body := `{"account_id":"acc-1"}`
+	ciphertext := encryptWithPassphrase(t, "passphrase", 1000, body)
+	signedCiphertext := encrypt(t, body)
+
+	tests := []struct {
+		name          string
+		encryptedBody string
+		unsigned      bool
+		verifyOnly    bool
+		wantErr       bool
+	}{
+		{
+			name:          "Unsigned JWE is decrypted",
+			encryptedBody: ciphertext,
+			unsigned:      true,
+		},
+		{
+			name:          "Unsigned JWE with a wrong passphrase",
+			encryptedBody: encryptWithPassphrase(t, "other", 1000, body),
+			unsigned:      true,
+			wantErr:       true,
+		},
+		{
+			name:          "Unsigned JWE encrypted with the public key",
+			encryptedBody: signedCiphertext,
+			unsigned:      true,
+			wantErr:       true,
+		},
+		{
+			name:          "Unsigned JWE with a key algorithm not allowed",
+			encryptedBody: encryptWithAlgorithm(t, jose.RSA_OAEP, body),
+			unsigned:      true,
+			wantErr:       true,
+		},
+		{
+			name:          "Signed JWE on the unsigned route isn't a JWE",
+			encryptedBody: sign(t, stoneSigningKey(t), signedCiphertext),
+			unsigned:      true,
+			wantErr:       true,
+		},
+		{
+			name:          "Signed mode still verifies",
+			encryptedBody: sign(t, stoneSigningKey(t), signedCiphertext),
+		},
+		{
+			name:          "Signed mode rejects the unsigned JWE",
+			encryptedBody: signedCiphertext,
+			wantErr:       true,
+		},
+		{
+			name:          "Signed mode rejects a tampered signature",
+			encryptedBody: sign(t, stoneSigningKey(t), signedCiphertext) + "x",
+			wantErr:       true,
+		},
+		{
+			name:          "Unsigned JWE isn't forwarded by the verify-only mode",
+			encryptedBody: ciphertext,
+			unsigned:      true,
+			verifyOnly:    true,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &ciphertextNotifier{}
+			cfg := configuration.Config{RelayConfig: configuration.RelayConfig{VerifyOnly: tt.verifyOnly}}
+			keyConfig := loadTestKeys(t)
+			keyConfig.Passphrase = []byte("passphrase")
+			keyConfig.UnsignedKeyAlgorithms = []jose.KeyAlgorithm{jose.PBES2_HS256_A128KW}
+			uc := NewNotificationUsecase(cfg, logrus.New(), keys.NewStore(keyConfig, nil), []domain.Notifier{notifier}, nil)
+
+			input := domain.NotificationInput{
+				Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				EncryptedBody: tt.encryptedBody,
+				Unsigned:      tt.unsigned,
+			}
+			_, err := uc.SendNotification(context.Background(), input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendNotification() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if len(notifier.notifications) != 0 {
+					t.Errorf("SendNotification() sent %d notifications, want none", len(notifier.notifications))
+				}
+				return
+			}
+
+			if len(notifier.notifications) != 1 || notifier.notifications[0].Body != body {
+				t.Errorf("SendNotification() sent %v, want the decrypted body", notifier.notifications)
+			}
+		})
+	}
+}
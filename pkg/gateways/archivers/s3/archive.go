@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const (
+	EventIDMetadata   = "Event-Id"
+	EventTypeMetadata = "Event-Type"
+)
+
+// Archive stores the encrypted body as the object content, without any
+// change, and the notification headers as the object metadata.
+func (a S3Archiver) Archive(ctx context.Context, input domain.NotificationInput) error {
+	log := a.log.WithField("archiver", "s3")
+
+	_, err := a.client.PutObjectWithContext(ctx, &awss3.PutObjectInput{
+		Bucket:      aws.String(a.bucket),
+		Key:         aws.String(a.prefix + input.Header.EventID),
+		Body:        strings.NewReader(input.EncryptedBody),
+		ContentType: aws.String("application/jose"),
+		Metadata: map[string]*string{
+			EventIDMetadata:   aws.String(input.Header.EventID),
+			EventTypeMetadata: aws.String(input.Header.EventType),
+		},
+	})
+	if err != nil {
+		log.WithError(err).Info("unable to archive the notification")
+		return fmt.Errorf("unable to archive the notification: %w", err)
+	}
+
+	return nil
+}
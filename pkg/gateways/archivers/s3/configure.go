@@ -0,0 +1,47 @@
+package s3
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	Bucket string `envconfig:"S3_ARCHIVER_BUCKET" required:"true"`
+	Prefix string `envconfig:"S3_ARCHIVER_PREFIX"`
+	Region string `envconfig:"S3_ARCHIVER_REGION" default:"us-east-1"`
+	// Endpoint allows to use a S3 compatible object store, like the GCS
+	// interoperability API: "https://storage.googleapis.com".
+	Endpoint string `envconfig:"S3_ARCHIVER_ENDPOINT"`
+}
+
+func (a *S3Archiver) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	a.log = log
+	log.WithField("archiver", "s3").Infof("config:[%+v]", config)
+
+	awsConfig := aws.NewConfig().WithRegion(config.Region)
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create aws session: %w", err)
+	}
+
+	a.client = awss3.New(sess)
+	a.bucket = config.Bucket
+	a.prefix = config.Prefix
+
+	return nil
+}
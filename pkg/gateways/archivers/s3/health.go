@@ -0,0 +1,22 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.HealthChecker = &S3Archiver{}
+
+// CheckHealth checks if the bucket exists and can be accessed.
+func (a *S3Archiver) CheckHealth(ctx context.Context) error {
+	if _, err := a.client.HeadBucketWithContext(ctx, &awss3.HeadBucketInput{Bucket: aws.String(a.bucket)}); err != nil {
+		return fmt.Errorf("unable to access the bucket %s: %w", a.bucket, err)
+	}
+
+	return nil
+}
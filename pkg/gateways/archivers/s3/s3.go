@@ -0,0 +1,20 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.RawArchiver = &S3Archiver{}
+
+type S3Archiver struct {
+	log    *logrus.Logger
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+func New() *S3Archiver {
+	return &S3Archiver{}
+}
@@ -0,0 +1,53 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/jsonpath"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.ContentAuthorizer = &Authorizer{}
+
+// Authorizer accepts the notifications whose tenant, read from the payload,
+// is allowed.
+type Authorizer struct {
+	path    string
+	allowed map[string]bool
+}
+
+// New returns nil when the authorizer is disabled.
+func New(cfg configuration.AuthorizerConfig) (*Authorizer, error) {
+	if cfg.TenantPath == "" {
+		return nil, nil
+	}
+
+	allowed := map[string]bool{}
+	for _, tenant := range configuration.SplitList(cfg.AllowedTenants) {
+		allowed[tenant] = true
+	}
+
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("the tenant authorizer requires the allowed tenants")
+	}
+
+	return &Authorizer{
+		path:    cfg.TenantPath,
+		allowed: allowed,
+	}, nil
+}
+
+func (a *Authorizer) Authorize(ctx context.Context, notification domain.Notification) error {
+	tenant, ok := jsonpath.LookupString([]byte(notification.Body), a.path)
+	if !ok {
+		return fmt.Errorf("%w: no tenant in %s", domain.ErrUnauthorizedContent, a.path)
+	}
+
+	if !a.allowed[tenant] {
+		return fmt.Errorf("%w: tenant %s isn't allowed", domain.ErrUnauthorizedContent, tenant)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            configuration.AuthorizerConfig
+		wantAuthorizer bool
+		wantErr        bool
+	}{
+		{
+			name: "Disabled",
+			cfg:  configuration.AuthorizerConfig{AllowedTenants: "a"},
+		},
+		{
+			name:           "Enabled",
+			cfg:            configuration.AuthorizerConfig{TenantPath: "account_id", AllowedTenants: "a;b"},
+			wantAuthorizer: true,
+		},
+		{
+			name:    "Without allowed tenants",
+			cfg:     configuration.AuthorizerConfig{TenantPath: "account_id"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got != nil) != tt.wantAuthorizer {
+				t.Errorf("New() = %v, wantAuthorizer %v", got, tt.wantAuthorizer)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	authorizer, err := New(configuration.AuthorizerConfig{TenantPath: "target_data.account_id", AllowedTenants: "acc-1;acc-2"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "Authorized tenant",
+			body: `{"target_data":{"account_id":"acc-2"}}`,
+		},
+		{
+			name:    "Unauthorized tenant",
+			body:    `{"target_data":{"account_id":"acc-3"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Without tenant",
+			body:    `{"target_data":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Payload isn't JSON",
+			body:    `acc-1`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.Authorize(context.Background(), domain.Notification{Body: tt.body})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrUnauthorizedContent) {
+				t.Errorf("Authorize() error = %v, want %v", err, domain.ErrUnauthorizedContent)
+			}
+		})
+	}
+}
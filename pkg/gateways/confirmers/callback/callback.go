@@ -0,0 +1,162 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/checkpoint"
+)
+
+var _ domain.ProcessingConfirmer = &Confirmer{}
+
+// StatusProcessed is the status confirmed to the provider.
+const StatusProcessed = "processed"
+
+// ConfirmationRequest is the body posted to the provider callback.
+type ConfirmationRequest struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Status    string `json:"status"`
+}
+
+// Confirmer posts the confirmations of the processed notifications to the
+// provider callback URL. The confirmations are queued and sent by workers,
+// retried with an exponential backoff. The confirmations dropped or given up
+// are stored as dead letters when there's a dead letter store.
+type Confirmer struct {
+	log         *logrus.Logger
+	client      *http.Client
+	url         string
+	token       string
+	maxAttempts int
+	backoff     time.Duration
+	queue       chan domain.HeaderNotification
+	stop        chan struct{}
+	workers     sync.WaitGroup
+	dropped     int64
+	// ctx is the context of the posts, canceled when the shutdown deadline
+	// is reached.
+	ctx    context.Context
+	cancel context.CancelFunc
+	checkpoint.Checkpointer
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New starts the workers of the confirmer. It returns nil when the callback
+// is disabled.
+func New(cfg configuration.CallbackConfig, log *logrus.Logger) (*Confirmer, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+
+	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid provider callback url %q", cfg.URL)
+	}
+	if cfg.MaxAttempts < 1 || cfg.QueueSize < 1 || cfg.Concurrency < 1 {
+		return nil, fmt.Errorf("the provider callback max attempts, queue size and concurrency must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Confirmer{
+		log:         log,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		url:         cfg.URL,
+		token:       cfg.Token,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.Backoff,
+		queue:       make(chan domain.HeaderNotification, cfg.QueueSize),
+		stop:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	for i := 0; i < cfg.Concurrency; i++ {
+		c.workers.Add(1)
+		go c.run()
+	}
+
+	return c, nil
+}
+
+// Confirm queues the confirmation, dropping it when the queue is full.
+func (c *Confirmer) Confirm(header domain.HeaderNotification) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		c.drop(header, "the confirmer is shutting down")
+		return
+	}
+
+	select {
+	case c.queue <- header:
+	default:
+		c.drop(header, "the queue is full")
+	}
+}
+
+// Shutdown stops accepting confirmations and sends the queued ones until the
+// context is done, when the running posts are canceled. The confirmations not
+// sent by then are dropped.
+func (c *Confirmer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		close(c.stop)
+		c.cancel()
+		<-done
+	}
+	c.cancel()
+
+	if dropped := atomic.LoadInt64(&c.dropped); dropped > 0 {
+		return fmt.Errorf("%d provider confirmations dropped", dropped)
+	}
+
+	return nil
+}
+
+func (c *Confirmer) run() {
+	defer c.workers.Done()
+
+	for header := range c.queue {
+		select {
+		case <-c.stop:
+			c.drop(header, "the confirmer stopped")
+			continue
+		default:
+		}
+
+		c.deliver(header)
+	}
+}
+
+func (c *Confirmer) drop(header domain.HeaderNotification, reason string) {
+	atomic.AddInt64(&c.dropped, 1)
+	confirmationsTotal.WithLabelValues(resultDropped).Inc()
+	c.log.Errorf("provider confirmation of notification %s dropped, %s", header.EventID, reason)
+	c.Checkpoint(c.log, domain.Notification{Header: header}, "provider confirmation dropped, "+reason)
+}
@@ -0,0 +1,254 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// providerServer answers the confirmations with the statuses, in order, and
+// then with 200.
+type providerServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []ConfirmationRequest
+	tokens   []string
+}
+
+func newProviderServer(t *testing.T, statuses ...int) *providerServer {
+	t.Helper()
+
+	p := &providerServer{statuses: statuses}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ConfirmationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding the confirmation: %v", err)
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.requests = append(p.requests, req)
+		p.tokens = append(p.tokens, r.Header.Get("Authorization"))
+
+		status := http.StatusOK
+		if len(p.statuses) > 0 {
+			status, p.statuses = p.statuses[0], p.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func (p *providerServer) received() []ConfirmationRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]ConfirmationRequest(nil), p.requests...)
+}
+
+func testConfig(url string) configuration.CallbackConfig {
+	return configuration.CallbackConfig{
+		URL:         url,
+		Token:       "secret",
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		QueueSize:   10,
+		Concurrency: 1,
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.CallbackConfig
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "Disabled", cfg: configuration.CallbackConfig{}, wantNil: true},
+		{name: "Enabled", cfg: testConfig("https://provider.example/confirmations")},
+		{name: "Invalid url", cfg: testConfig("provider.example"), wantNil: true, wantErr: true},
+		{name: "No attempts", cfg: configuration.CallbackConfig{URL: "https://provider.example", QueueSize: 1, Concurrency: 1}, wantNil: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg, logrus.New())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("New() = %v, wantNil %v", got, tt.wantNil)
+			}
+			if got != nil {
+				_ = got.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestConfirmer_Confirm(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+	}{
+		{
+			name:         "Confirmed at once",
+			wantAttempts: 1,
+		},
+		{
+			name:         "Retried on transient failures",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Given up after the max attempts",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Permanent failure isn't retried",
+			statuses:     []int{http.StatusBadRequest},
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newProviderServer(t, tt.statuses...)
+			confirmer, err := New(testConfig(provider.URL), logrus.New())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			confirmer.Confirm(domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"})
+			if err := confirmer.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+
+			got := provider.received()
+			if len(got) != tt.wantAttempts {
+				t.Fatalf("Confirm() attempts = %d, want %d", len(got), tt.wantAttempts)
+			}
+			want := ConfirmationRequest{EventID: "930bbd6d", EventType: "cash_in_internal_transfer", Status: StatusProcessed}
+			for i, req := range got {
+				if req != want {
+					t.Errorf("Confirm() attempt %d = %+v, want %+v", i+1, req, want)
+				}
+				if provider.tokens[i] != "Bearer secret" {
+					t.Errorf("Confirm() attempt %d authorization = %q", i+1, provider.tokens[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmer_Shutdown_DropsRetries(t *testing.T) {
+	provider := newProviderServer(t, http.StatusServiceUnavailable)
+	cfg := testConfig(provider.URL)
+	cfg.Backoff = time.Hour
+	confirmer, err := New(cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	confirmer.Confirm(domain.HeaderNotification{EventID: "930bbd6d"})
+
+	// Wait for the first attempt, the retry waits for the backoff.
+	deadline := time.Now().Add(time.Second)
+	for len(provider.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := confirmer.Shutdown(ctx); err == nil {
+		t.Error("Shutdown() must report the dropped confirmation")
+	}
+
+	confirmer.Confirm(domain.HeaderNotification{EventID: "after-shutdown"})
+	if got := len(provider.received()); got != 1 {
+		t.Errorf("Confirm() attempts = %d, want 1", got)
+	}
+}
+
+type fakeDeadLetterStore struct {
+	mu          sync.Mutex
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func TestConfirmer_Shutdown_CancelsPost(t *testing.T) {
+	// The provider only answers once the post is canceled.
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer provider.Close()
+
+	cfg := testConfig(provider.URL)
+	cfg.Timeout = 10 * time.Second
+	confirmer, err := New(cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	store := &fakeDeadLetterStore{}
+	confirmer.SetDeadLetterStore(store)
+
+	// The second confirmation is still queued on shutdown.
+	confirmer.Confirm(domain.HeaderNotification{EventID: "930bbd6d"})
+	confirmer.Confirm(domain.HeaderNotification{EventID: "930bbd6e"})
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := confirmer.Shutdown(ctx); err == nil {
+		t.Error("Shutdown() must report the dropped confirmation")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %s, past its deadline", elapsed)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.deadLetters) != 2 {
+		t.Fatalf("Shutdown() stored %d dead letters, want 2", len(store.deadLetters))
+	}
+	for i, eventID := range []string{"930bbd6d", "930bbd6e"} {
+		if got := store.deadLetters[i].Input.Header.EventID; got != eventID {
+			t.Errorf("dead letter %d = %s, want %s", i, got, eventID)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// errPermanent is a failure that isn't retried, like a rejected confirmation.
+var errPermanent = errors.New("permanent failure")
+
+// deliver posts the confirmation, retrying the transient failures until the
+// max attempts. The confirmations given up are logged and counted, and stored
+// as dead letters.
+func (c *Confirmer) deliver(header domain.HeaderNotification) {
+	log := c.log.WithField("event_id", header.EventID)
+
+	var err error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if attempt > 1 {
+			retriesTotal.Inc()
+			select {
+			case <-time.After(c.backoff << (attempt - 2)):
+			case <-c.stop:
+				c.drop(header, "the confirmer stopped while retrying")
+				return
+			}
+		}
+
+		select {
+		case <-c.stop:
+			c.drop(header, "the confirmer stopped")
+			return
+		default:
+		}
+
+		err = c.post(header)
+		if err == nil {
+			confirmationsTotal.WithLabelValues(resultSuccess).Inc()
+			return
+		}
+		if errors.Is(err, errPermanent) {
+			break
+		}
+
+		log.WithError(err).Warnf("provider confirmation attempt %d of %d failed", attempt, c.maxAttempts)
+	}
+
+	confirmationsTotal.WithLabelValues(resultGivenUp).Inc()
+	log.WithError(err).Errorf("giving up the provider confirmation of notification %s, type[%s]", header.EventID, header.EventType)
+	c.Checkpoint(log, domain.Notification{Header: header}, fmt.Sprintf("provider confirmation given up: %v", err))
+}
+
+func (c *Confirmer) post(header domain.HeaderNotification) error {
+	body, err := json.Marshal(ConfirmationRequest{
+		EventID:   header.EventID,
+		EventType: header.EventType,
+		Status:    StatusProcessed,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("provider callback answered %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: provider callback answered %d", errPermanent, resp.StatusCode)
+	}
+}
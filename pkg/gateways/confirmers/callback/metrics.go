@@ -0,0 +1,24 @@
+package callback
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultSuccess = "success"
+	resultGivenUp = "given_up"
+	resultDropped = "dropped"
+)
+
+var (
+	confirmationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_provider_confirmations_total",
+		Help: "Number of confirmations to the provider callback, by result.",
+	}, []string{"result"})
+
+	retriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_provider_confirmation_retries_total",
+		Help: "Number of retried confirmations to the provider callback.",
+	})
+)
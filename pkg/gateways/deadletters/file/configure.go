@@ -0,0 +1,33 @@
+package file
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	Path string `envconfig:"DEAD_LETTER_FILE_PATH" required:"true"`
+}
+
+func (s *FileStore) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	s.log = log
+	log.WithField("dead_letter_store", "file").Infof("config:[%+v]", config)
+
+	// The dead letters have the decrypted payloads.
+	file, err := os.OpenFile(config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open the dead letter file: %w", err)
+	}
+
+	s.w = file
+	return nil
+}
@@ -0,0 +1,23 @@
+package file
+
+import (
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.DeadLetterStore = &FileStore{}
+
+// FileStore appends the dead letters to a file, a JSON object per line.
+type FileStore struct {
+	log *logrus.Logger
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func New() *FileStore {
+	return &FileStore{}
+}
@@ -0,0 +1,61 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Record is a line of the dead letter file. The event_id, event_type and
+// encrypted_body are the fields of a replay file, so the file can be sent
+// again by the import.
+type Record struct {
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	EncryptedBody string `json:"encrypted_body"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	RawEventType  string `json:"raw_event_type,omitempty"`
+	// Unsigned notifications can't be replayed by the import, which
+	// verifies the signatures.
+	Unsigned bool `json:"unsigned,omitempty"`
+	// Body is the decrypted payload, or the inner JWE in the verify-only
+	// mode.
+	Body       string    `json:"body"`
+	Ciphertext bool      `json:"ciphertext,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+	FailedAt   time.Time `json:"failed_at"`
+	Reason     string    `json:"reason"`
+}
+
+// Store appends the dead letter as a line. The lines of concurrent dead
+// letters are never interleaved.
+func (s *FileStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	line, err := json.Marshal(Record{
+		EventID:       deadLetter.Input.Header.EventID,
+		EventType:     deadLetter.Input.Header.EventType,
+		EncryptedBody: deadLetter.Input.EncryptedBody,
+		CreatedAt:     deadLetter.Input.Header.CreatedAt,
+		RawEventType:  deadLetter.Input.Header.RawEventType,
+		Unsigned:      deadLetter.Input.Unsigned,
+		Body:          deadLetter.Notification.Body,
+		Ciphertext:    deadLetter.Notification.Ciphertext,
+		ReceivedAt:    deadLetter.Notification.Processing.ReceivedAt,
+		FailedAt:      deadLetter.FailedAt,
+		Reason:        deadLetter.Reason,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding the dead letter: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing the dead letter: %w", err)
+	}
+
+	return nil
+}
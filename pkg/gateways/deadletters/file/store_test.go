@@ -0,0 +1,71 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestFileStore_Store(t *testing.T) {
+	var buf bytes.Buffer
+	store := &FileStore{log: logrus.New(), w: &buf}
+
+	failedAt := time.Date(2020, 9, 1, 12, 30, 0, 0, time.UTC)
+	deadLetter := domain.DeadLetter{
+		Input: domain.NotificationInput{
+			Header:        domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer", CreatedAt: "2020-09-01T12:29:59Z"},
+			EncryptedBody: "signed.and.encrypted",
+		},
+		Notification: domain.Notification{
+			Body:       `{"amount":100}`,
+			Processing: domain.ProcessingMetadata{ReceivedAt: failedAt.Add(-time.Second)},
+		},
+		Reason:   "downstream unavailable",
+		FailedAt: failedAt,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Store(context.Background(), deadLetter); err != nil {
+				t.Errorf("Store() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("Store() wrote %d lines, want 10", len(lines))
+	}
+
+	want := Record{
+		EventID:       "930bbd6d",
+		EventType:     "cash_in_internal_transfer",
+		EncryptedBody: "signed.and.encrypted",
+		CreatedAt:     "2020-09-01T12:29:59Z",
+		Body:          `{"amount":100}`,
+		ReceivedAt:    failedAt.Add(-time.Second),
+		FailedAt:      failedAt,
+		Reason:        "downstream unavailable",
+	}
+	for _, line := range lines {
+		var got Record
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Store() line %q error = %v", line, err)
+		}
+		if got != want {
+			t.Errorf("Store() = %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type Handler struct {
+	log         *logrus.Logger
+	config      configuration.Config
+	maintenance *maintenance.Mode
+	events      *tail.Buffer
+	keys        *keys.Store
+	// packer is nil when the pack endpoint is disabled.
+	packer *keys.Packer
+	// notifications is nil when the notifications aren't stored.
+	notifications domain.NotificationStore
+	usecase       domain.NotificationUsecase
+}
+
+func NewHandler(log *logrus.Logger, config configuration.Config, maintenance *maintenance.Mode, events *tail.Buffer, keys *keys.Store, packer *keys.Packer) *Handler {
+	return &Handler{
+		log:         log,
+		config:      config,
+		maintenance: maintenance,
+		events:      events,
+		keys:        keys,
+		packer:      packer,
+	}
+}
+
+// PackEnabled checks if the pack endpoint is available.
+func (h *Handler) PackEnabled() bool {
+	return h.packer != nil
+}
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// Authenticate only allows the requests with the admin token.
+func (h Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if h.config.AdminConfig.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.config.AdminConfig.Token)) != 1 {
+			h.log.Warnf("unauthorized admin request to %s", r.URL.Path)
+			_ = responses.SendError(w, r, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
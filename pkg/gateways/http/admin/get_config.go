@@ -0,0 +1,13 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// GetConfig returns the effective configuration, without the secrets.
+func (h Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	_ = responses.Send(w, configuration.Redacted(h.config), http.StatusOK)
+}
@@ -0,0 +1,88 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+)
+
+const testToken = "admin-secret-token"
+
+func newTestHandler(cfg configuration.Config) *Handler {
+	cfg.AdminConfig.Token = testToken
+	return NewHandler(logrus.New(), cfg, maintenance.New(false, time.Minute), tail.New(10), nil, nil)
+}
+
+func adminRequest(method, target, token string, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestHandler_GetConfig(t *testing.T) {
+	cfg := configuration.Config{
+		NotifierList: "stdout;proxy",
+	}
+	h := newTestHandler(cfg)
+	handler := h.Authenticate(http.HandlerFunc(h.GetConfig))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "Without token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "With an invalid token",
+			token:      "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "With the admin token",
+			token:      testToken,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/config", tt.token, ""))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetConfig() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if strings.Contains(w.Body.String(), testToken) {
+				t.Errorf("GetConfig() response has the admin token: %s", w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("GetConfig() invalid body: %v", err)
+			}
+			if got["NOTIFIER_LIST"] != "stdout;proxy" {
+				t.Errorf("GetConfig() NOTIFIER_LIST = %v", got["NOTIFIER_LIST"])
+			}
+			if got["ADMIN_TOKEN"] != configuration.RedactedValue {
+				t.Errorf("GetConfig() ADMIN_TOKEN = %v", got["ADMIN_TOKEN"])
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type MaintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetMaintenance turns the maintenance mode on or off.
+func (h Handler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		_ = responses.SendError(w, r, `body must be {"enabled": true|false}`, http.StatusBadRequest)
+		return
+	}
+
+	h.maintenance.Set(*req.Enabled)
+	h.log.Warnf("maintenance mode enabled: %t", *req.Enabled)
+
+	_ = responses.Send(w, MaintenanceResponse{Enabled: h.maintenance.Enabled()}, http.StatusOK)
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestHandler_SetMaintenance(t *testing.T) {
+	h := newTestHandler(configuration.Config{})
+	handler := h.Authenticate(http.HandlerFunc(h.SetMaintenance))
+
+	tests := []struct {
+		name        string
+		token       string
+		body        string
+		wantStatus  int
+		wantEnabled bool
+	}{
+		{
+			name:       "Without token",
+			body:       `{"enabled":true}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "Enabling",
+			token:       testToken,
+			body:        `{"enabled":true}`,
+			wantStatus:  http.StatusOK,
+			wantEnabled: true,
+		},
+		{
+			name:        "Invalid body keeps the mode",
+			token:       testToken,
+			body:        `{}`,
+			wantStatus:  http.StatusBadRequest,
+			wantEnabled: true,
+		},
+		{
+			name:        "Disabling",
+			token:       testToken,
+			body:        `{"enabled":false}`,
+			wantStatus:  http.StatusOK,
+			wantEnabled: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, adminRequest(http.MethodPost, "/admin/maintenance", tt.token, tt.body))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("SetMaintenance() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if h.maintenance.Enabled() != tt.wantEnabled {
+				t.Errorf("SetMaintenance() enabled = %v, want %v", h.maintenance.Enabled(), tt.wantEnabled)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got MaintenanceResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("SetMaintenance() invalid body: %v", err)
+			}
+			if got.Enabled != tt.wantEnabled {
+				t.Errorf("SetMaintenance() response = %v, want %v", got.Enabled, tt.wantEnabled)
+			}
+		})
+	}
+}
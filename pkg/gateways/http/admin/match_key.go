@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// maxPublicKeySize limits the key, or key set, to match.
+const maxPublicKeySize = 1 << 20
+
+type MatchKeyResponse struct {
+	Match bool `json:"match"`
+	// KeyID is the kid of the matching key, when a key set is sent.
+	KeyID string `json:"kid,omitempty"`
+	// PrivateKeyID is the kid of the matching private key.
+	PrivateKeyID string `json:"private_kid,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// MatchKey checks if the public key in the body, PEM, DER, JWK or a JWKS, is
+// the counterpart of a configured private key. A key set matches when any of
+// its keys does.
+func (h Handler) MatchKey(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxPublicKeySize+1))
+	if err != nil || len(body) == 0 || len(body) > maxPublicKeySize {
+		_ = responses.SendError(w, r, "body must be a public key, PEM, DER, JWK or JWKS", http.StatusBadRequest)
+		return
+	}
+
+	config := h.keys.Get()
+	if config == nil || len(config.DecryptionKeyList) == 0 {
+		_ = responses.SendError(w, r, "no private key configured", http.StatusInternalServerError)
+		return
+	}
+
+	keyList, err := parsePublicKeys(body)
+	if err != nil {
+		_ = responses.SendError(w, r, "invalid public key: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var response MatchKeyResponse
+	for _, key := range keyList {
+		privateKey, err := matchPrivateKey(config.DecryptionKeyList, key.Key)
+		if err == nil {
+			response = MatchKeyResponse{Match: true, KeyID: key.KeyID, PrivateKeyID: privateKey.KeyID}
+			break
+		}
+		if !errors.Is(err, keys.ErrKeyMismatch) && len(keyList) == 1 {
+			_ = responses.SendError(w, r, "invalid public key: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.Reason = err.Error()
+	}
+	if !response.Match && len(keyList) > 1 {
+		response.Reason = "no key in the set matches the private key"
+	}
+
+	h.log.Infof("public key matched against the private key: %t", response.Match)
+	_ = responses.Send(w, response, http.StatusOK)
+}
+
+// matchPrivateKey returns the private key of the public key. The error is the
+// mismatch with the first key, when none matches.
+func matchPrivateKey(keyList []*jose.JSONWebKey, publicKey interface{}) (*jose.JSONWebKey, error) {
+	var firstErr error
+	for _, privateKey := range keyList {
+		err := keys.MatchPublicKey(privateKey, publicKey)
+		if err == nil {
+			return privateKey, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return nil, firstErr
+}
+
+// parsePublicKeys parses a JWKS, or a single public key.
+func parsePublicKeys(data []byte) ([]jose.JSONWebKey, error) {
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keySet); err == nil && len(keySet.Keys) > 0 {
+		return keySet.Keys, nil
+	}
+
+	key, err := keys.LoadPublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if jwk, ok := key.(*jose.JSONWebKey); ok {
+		return []jose.JSONWebKey{*jwk}, nil
+	}
+
+	return []jose.JSONWebKey{{Key: key}}, nil
+}
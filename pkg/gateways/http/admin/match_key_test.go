@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func TestHandler_MatchKey(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodePEM := func(key *ecdsa.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	encodeJSON := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       MatchKeyResponse
+	}{
+		{
+			name:       "Matching PEM key",
+			body:       encodePEM(&privateKey.PublicKey),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: true, PrivateKeyID: "current"},
+		},
+		{
+			name:       "Matching the previous private key",
+			body:       encodePEM(&previousKey.PublicKey),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: true, PrivateKeyID: "previous"},
+		},
+		{
+			name:       "Non matching PEM key",
+			body:       encodePEM(&otherKey.PublicKey),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: false, Reason: "public key doesn't match the private key: different EC point"},
+		},
+		{
+			name:       "Matching JWK",
+			body:       encodeJSON(jose.JSONWebKey{Key: &privateKey.PublicKey, KeyID: "ours"}),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: true, KeyID: "ours", PrivateKeyID: "current"},
+		},
+		{
+			name: "Matching key in a JWKS",
+			body: encodeJSON(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &otherKey.PublicKey, KeyID: "other"},
+				{Key: &privateKey.PublicKey, KeyID: "ours"},
+			}}),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: true, KeyID: "ours", PrivateKeyID: "current"},
+		},
+		{
+			name: "No matching key in a JWKS",
+			body: encodeJSON(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+				{Key: &otherKey.PublicKey, KeyID: "other"},
+				{Key: &otherKey.PublicKey, KeyID: "another"},
+			}}),
+			wantStatus: http.StatusOK,
+			want:       MatchKeyResponse{Match: false, Reason: "no key in the set matches the private key"},
+		},
+		{
+			name:       "Invalid key",
+			body:       "not a key",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty body",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	current := &jose.JSONWebKey{Key: privateKey, KeyID: "current"}
+	store := keys.NewStore(&keys.Config{
+		PrivateKey: current,
+		DecryptionKeyList: []*jose.JSONWebKey{
+			current,
+			{Key: previousKey, KeyID: "previous"},
+		},
+	}, nil)
+	h := NewHandler(logrus.New(), configuration.Config{AdminConfig: configuration.AdminConfig{Token: testToken}}, nil, nil, store, nil)
+	handler := h.Authenticate(http.HandlerFunc(h.MatchKey))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, adminRequest(http.MethodPost, "/admin/keys/match", testToken, tt.body))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("MatchKey() status = %v, want %v: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got MatchKeyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("MatchKey() body %q: %v", w.Body, err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchKey() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_MatchKey_Unauthorized(t *testing.T) {
+	h := NewHandler(logrus.New(), configuration.Config{AdminConfig: configuration.AdminConfig{Token: testToken}}, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	h.Authenticate(http.HandlerFunc(h.MatchKey)).ServeHTTP(w, adminRequest(http.MethodPost, "/admin/keys/match", "wrong", "key"))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("MatchKey() status = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
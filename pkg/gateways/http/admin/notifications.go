@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// NotificationSummary is a stored notification, without its envelope.
+type NotificationSummary struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Attempts   int       `json:"attempts"`
+	ReceivedAt time.Time `json:"received_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ListNotificationsResponse struct {
+	Notifications []NotificationSummary `json:"notifications"`
+}
+
+// NotificationDetails is a stored notification with its envelope, as
+// received. The payload is still encrypted.
+type NotificationDetails struct {
+	NotificationSummary
+	RawEventType  string `json:"raw_event_type,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	Unsigned      bool   `json:"unsigned"`
+	EncryptedBody string `json:"encrypted_body"`
+}
+
+type ReplayNotificationResponse struct {
+	EventID  string `json:"event_id"`
+	Replayed bool   `json:"replayed"`
+	Deferred bool   `json:"deferred"`
+}
+
+// SetNotificationStore enables the stored notifications routes, replayed
+// through the usecase.
+func (h *Handler) SetNotificationStore(store domain.NotificationStore, usecase domain.NotificationUsecase) {
+	h.notifications = store
+	h.usecase = usecase
+}
+
+// NotificationsEnabled checks if the stored notifications routes are
+// available.
+func (h *Handler) NotificationsEnabled() bool {
+	return h.notifications != nil
+}
+
+// ListNotifications lists the stored notifications, the most recently updated
+// first, filtered by the event_type, status, since and until (RFC 3339)
+// parameters, up to limit.
+func (h Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseNotificationFilter(r)
+	if err != nil {
+		_ = responses.SendError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.notifications.List(r.Context(), filter)
+	if err != nil {
+		h.log.WithError(err).Error("unable to list the stored notifications")
+		_ = responses.SendError(w, r, "unable to list the notifications", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListNotificationsResponse{Notifications: make([]NotificationSummary, len(list))}
+	for i, stored := range list {
+		response.Notifications[i] = summary(stored)
+	}
+
+	_ = responses.Send(w, response, http.StatusOK)
+}
+
+// GetNotification returns the stored notification, with its envelope.
+func (h Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
+	stored, ok := h.storedNotification(w, r)
+	if !ok {
+		return
+	}
+
+	_ = responses.Send(w, NotificationDetails{
+		NotificationSummary: summary(stored),
+		RawEventType:        stored.Input.Header.RawEventType,
+		CreatedAt:           stored.Input.Header.CreatedAt,
+		Unsigned:            stored.Input.Unsigned,
+		EncryptedBody:       stored.Input.EncryptedBody,
+	}, http.StatusOK)
+}
+
+// ReplayNotification sends the stored notification again through the usecase,
+// verified and decrypted with the current keys, even if it was already sent.
+func (h Handler) ReplayNotification(w http.ResponseWriter, r *http.Request) {
+	stored, ok := h.storedNotification(w, r)
+	if !ok {
+		return
+	}
+
+	input := stored.Input
+	input.Replay = true
+
+	eventID := input.Header.EventID
+	h.log.Infof("replaying notification %s", eventID)
+	output, err := h.usecase.SendNotification(r.Context(), input)
+	if err != nil {
+		h.log.WithError(err).Errorf("replay of notification %s failed", eventID)
+		_ = responses.SendError(w, r, fmt.Sprintf("replay failed: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	_ = responses.Send(w, ReplayNotificationResponse{EventID: eventID, Replayed: true, Deferred: output.Deferred}, http.StatusOK)
+}
+
+// storedNotification answers 404 when the event id isn't stored.
+func (h Handler) storedNotification(w http.ResponseWriter, r *http.Request) (domain.StoredNotification, bool) {
+	eventID := mux.Vars(r)["event_id"]
+
+	stored, err := h.notifications.Get(r.Context(), eventID)
+	switch {
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		_ = responses.SendError(w, r, err.Error(), http.StatusNotFound)
+		return stored, false
+	case err != nil:
+		h.log.WithError(err).Errorf("unable to get the stored notification %s", eventID)
+		_ = responses.SendError(w, r, "unable to get the notification", http.StatusInternalServerError)
+		return stored, false
+	}
+
+	return stored, true
+}
+
+func parseNotificationFilter(r *http.Request) (domain.NotificationFilter, error) {
+	query := r.URL.Query()
+	filter := domain.NotificationFilter{
+		EventType: query.Get("event_type"),
+		Status:    query.Get("status"),
+		Limit:     defaultListLimit,
+	}
+
+	var err error
+	if value := query.Get("since"); value != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, fmt.Errorf("invalid since %q, must be RFC 3339", value)
+		}
+	}
+	if value := query.Get("until"); value != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, fmt.Errorf("invalid until %q, must be RFC 3339", value)
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 || filter.Limit > maxListLimit {
+			return filter, fmt.Errorf("invalid limit %q, must be from 1 to %d", value, maxListLimit)
+		}
+	}
+
+	return filter, nil
+}
+
+func summary(stored domain.StoredNotification) NotificationSummary {
+	return NotificationSummary{
+		EventID:    stored.Input.Header.EventID,
+		EventType:  stored.Input.Header.EventType,
+		Status:     stored.Status,
+		Error:      stored.Error,
+		Attempts:   stored.Attempts,
+		ReceivedAt: stored.ReceivedAt,
+		UpdatedAt:  stored.UpdatedAt,
+	}
+}
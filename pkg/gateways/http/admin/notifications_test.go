@@ -0,0 +1,208 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notificationstores/memory"
+)
+
+type replayUsecase struct {
+	inputs []domain.NotificationInput
+	err    error
+}
+
+func (u *replayUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, error) {
+	u.inputs = append(u.inputs, input)
+	return domain.NotificationOutput{}, u.err
+}
+
+func newNotificationsRouter(t *testing.T, usecase domain.NotificationUsecase) *mux.Router {
+	t.Helper()
+
+	store := memory.New()
+	if err := store.Configure(logrus.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, stored := range []domain.StoredNotification{
+		{Input: domain.NotificationInput{Header: domain.HeaderNotification{EventID: "1", EventType: "cash_in"}, EncryptedBody: "jws-1"}, Status: "delivery_failed", Error: "unavailable"},
+		{Input: domain.NotificationInput{Header: domain.HeaderNotification{EventID: "2", EventType: "cash_out"}, EncryptedBody: "jws-2"}, Status: "sent"},
+	} {
+		stored.ReceivedAt = now.Add(time.Duration(i) * time.Minute)
+		stored.UpdatedAt = stored.ReceivedAt
+		if err := store.Save(context.Background(), stored, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandler(logrus.New(), configuration.Config{AdminConfig: configuration.AdminConfig{Token: testToken}}, nil, nil, nil, nil)
+	h.SetNotificationStore(store, usecase)
+
+	r := mux.NewRouter()
+	r.Use(h.Authenticate)
+	r.HandleFunc("/admin/notifications", h.ListNotifications).Methods(http.MethodGet)
+	r.HandleFunc("/admin/notifications/{event_id}", h.GetNotification).Methods(http.MethodGet)
+	r.HandleFunc("/admin/notifications/{event_id}/replay", h.ReplayNotification).Methods(http.MethodPost)
+	return r
+}
+
+func TestHandler_ListNotifications(t *testing.T) {
+	router := newNotificationsRouter(t, &replayUsecase{})
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "All",
+			target:     "/admin/notifications",
+			wantStatus: http.StatusOK,
+			want:       []string{"2", "1"},
+		},
+		{
+			name:       "By status",
+			target:     "/admin/notifications?status=delivery_failed",
+			wantStatus: http.StatusOK,
+			want:       []string{"1"},
+		},
+		{
+			name:       "By event type and time",
+			target:     "/admin/notifications?event_type=cash_out&since=2021-03-01T10:00:30Z",
+			wantStatus: http.StatusOK,
+			want:       []string{"2"},
+		},
+		{
+			name:       "Limited",
+			target:     "/admin/notifications?limit=1",
+			wantStatus: http.StatusOK,
+			want:       []string{"2"},
+		},
+		{
+			name:       "Invalid limit",
+			target:     "/admin/notifications?limit=0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid since",
+			target:     "/admin/notifications?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, adminRequest(http.MethodGet, tt.target, testToken, ""))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ListNotifications() status = %v, want %v: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got ListNotificationsResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("ListNotifications() body %q: %v", w.Body, err)
+			}
+			if len(got.Notifications) != len(tt.want) {
+				t.Fatalf("ListNotifications() = %+v, want %v", got.Notifications, tt.want)
+			}
+			for i, eventID := range tt.want {
+				if got.Notifications[i].EventID != eventID {
+					t.Errorf("ListNotifications() = %+v, want %v", got.Notifications, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_GetNotification(t *testing.T) {
+	router := newNotificationsRouter(t, &replayUsecase{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/notifications/1", testToken, ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetNotification() status = %v, want %v: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var got NotificationDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GetNotification() body %q: %v", w.Body, err)
+	}
+	if got.EventID != "1" || got.Status != "delivery_failed" || got.Error != "unavailable" || got.EncryptedBody != "jws-1" || got.Attempts != 1 {
+		t.Errorf("GetNotification() = %+v", got)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/notifications/unknown", testToken, ""))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetNotification() of an unknown event id status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_ReplayNotification(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "Replayed",
+			target:     "/admin/notifications/1/replay",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Failed replay",
+			target:     "/admin/notifications/1/replay",
+			err:        errors.New("unavailable"),
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:       "Unknown event id",
+			target:     "/admin/notifications/unknown/replay",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &replayUsecase{err: tt.err}
+			router := newNotificationsRouter(t, usecase)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, adminRequest(http.MethodPost, tt.target, testToken, ""))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ReplayNotification() status = %v, want %v: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusNotFound {
+				if len(usecase.inputs) != 0 {
+					t.Errorf("ReplayNotification() sent %d notifications, want 0", len(usecase.inputs))
+				}
+				return
+			}
+
+			if len(usecase.inputs) != 1 {
+				t.Fatalf("ReplayNotification() sent %d notifications, want 1", len(usecase.inputs))
+			}
+			if input := usecase.inputs[0]; !input.Replay || input.EncryptedBody != "jws-1" || input.Header.EventID != "1" {
+				t.Errorf("ReplayNotification() input = %+v, want the stored envelope marked as a replay", input)
+			}
+		})
+	}
+}
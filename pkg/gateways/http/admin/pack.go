@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+type PackRequest struct {
+	// EventID is generated when empty.
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// PackResponse has the headers and the body to POST the notification to
+// another webhook consumer.
+type PackResponse struct {
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	EncryptedBody string `json:"encrypted_body"`
+}
+
+// Pack signs and encrypts a cleartext payload, returning the envelope ready to
+// be relayed. The body is bounded like the notifications.
+func (h Handler) Pack(w http.ResponseWriter, r *http.Request) {
+	var req PackRequest
+	body := http.MaxBytesReader(w, r.Body, h.config.HTTPConfig.MaxBodySize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil || req.EventType == "" || len(req.Payload) == 0 {
+		_ = responses.SendError(w, r, `body must be {"event_type": "...", "payload": {...}}`, http.StatusBadRequest)
+		return
+	}
+
+	if req.EventID == "" {
+		id, err := newEventID()
+		if err != nil {
+			h.log.WithError(err).Error("unable to generate the event id")
+			_ = responses.SendError(w, r, "unable to generate the event id", http.StatusInternalServerError)
+			return
+		}
+		req.EventID = id
+	}
+
+	encryptedBody, err := h.packer.Pack(req.Payload)
+	if err != nil {
+		h.log.WithError(err).Error("unable to pack the payload")
+		_ = responses.SendError(w, r, "unable to pack the payload", http.StatusInternalServerError)
+		return
+	}
+
+	_ = responses.Send(w, PackResponse{
+		EventID:       req.EventID,
+		EventType:     req.EventType,
+		EncryptedBody: encryptedBody,
+	}, http.StatusOK)
+}
+
+func newEventID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
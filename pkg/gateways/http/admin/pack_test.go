@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/domain/usecase"
+)
+
+const testsPath = "../../../../tests/"
+
+type recorderNotifier struct {
+	notifications []domain.Notification
+}
+
+func (r *recorderNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (r *recorderNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func newPackTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	packer, err := keys.LoadPacker(configuration.PackConfig{
+		Enabled:           true,
+		SigningKeyPath:    testsPath + "stone/fakekey1.pem.jwt",
+		EncryptionKeyPath: testsPath + "partner/fakekey.pub",
+	})
+	if err != nil {
+		t.Fatalf("LoadPacker() error = %v", err)
+	}
+
+	cfg := configuration.Config{
+		HTTPConfig:  configuration.HTTPConfig{MaxBodySize: 1024},
+		AdminConfig: configuration.AdminConfig{Token: testToken},
+	}
+	h := NewHandler(logrus.New(), cfg, nil, nil, nil, packer)
+	return h.Authenticate(http.HandlerFunc(h.Pack))
+}
+
+func TestHandler_Pack(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Packed",
+			token:      testToken,
+			body:       `{"event_id":"1","event_type":"cash_in_internal_transfer","payload":{"id":"1"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Without the event type",
+			token:      testToken,
+			body:       `{"payload":{"id":"1"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Without the payload",
+			token:      testToken,
+			body:       `{"event_type":"cash_in_internal_transfer"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Body too large",
+			token:      testToken,
+			body:       `{"event_type":"cash_in_internal_transfer","payload":{"padding":"` + strings.Repeat("a", 1024) + `"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid token",
+			token:      "invalid",
+			body:       `{"event_type":"cash_in_internal_transfer","payload":{}}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newPackTestHandler(t).ServeHTTP(w, adminRequest(http.MethodPost, "/internal/pack", tt.token, tt.body))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Pack() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_Pack_RoundTrip(t *testing.T) {
+	const payload = `{"id":"1","event_type":"cash_in_internal_transfer"}`
+
+	w := httptest.NewRecorder()
+	body := `{"event_type":"cash_in_internal_transfer","payload":` + payload + `}`
+	newPackTestHandler(t).ServeHTTP(w, adminRequest(http.MethodPost, "/internal/pack", testToken, body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Pack() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var packed PackResponse
+	if err := json.NewDecoder(w.Body).Decode(&packed); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if packed.EventID == "" || packed.EventType != "cash_in_internal_transfer" {
+		t.Errorf("Pack() headers = %v, %v", packed.EventID, packed.EventType)
+	}
+
+	// The envelope is accepted by our own usecase.
+	keyConfig, err := keys.LoadKeys(configuration.KeysConfig{
+		PrivateKeyPath:      testsPath + "partner/fakekey.pem",
+		PublicKeyLocation:   "file://" + testsPath + "stone/fakekey1.pub.jwt",
+		SignatureAlgorithms: "PS256",
+		KeyAlgorithms:       "RSA-OAEP-256",
+	})
+	if err != nil {
+		t.Fatalf("unable to load keys: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+	notifier := &recorderNotifier{}
+	uc := usecase.NewNotificationUsecase(configuration.Config{}, log, keys.NewStore(keyConfig, nil), []domain.Notifier{notifier}, nil)
+
+	_, err = uc.SendNotification(context.Background(), domain.NotificationInput{
+		Header:        domain.HeaderNotification{EventID: packed.EventID, EventType: packed.EventType},
+		EncryptedBody: packed.EncryptedBody,
+	})
+	if err != nil {
+		t.Fatalf("SendNotification() error = %v", err)
+	}
+	if len(notifier.notifications) != 1 || notifier.notifications[0].Body != payload {
+		t.Errorf("SendNotification() notified = %v, want %v", notifier.notifications, payload)
+	}
+}
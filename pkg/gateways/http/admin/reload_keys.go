@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+type ReloadKeysResponse struct {
+	Reloaded bool `json:"reloaded"`
+}
+
+// ReloadKeys loads the keys again and swaps them at once. A concurrent reload
+// is rejected with 409, and a failed one keeps the current keys.
+func (h Handler) ReloadKeys(w http.ResponseWriter, r *http.Request) {
+	err := h.keys.Reload()
+	switch {
+	case errors.Is(err, keys.ErrReloadInProgress):
+		_ = responses.SendError(w, r, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, keys.ErrReloadDisabled):
+		_ = responses.SendError(w, r, err.Error(), http.StatusNotImplemented)
+		return
+	case err != nil:
+		h.log.WithError(err).Error("unable to reload the keys, keeping the current ones")
+		_ = responses.SendError(w, r, "unable to reload the keys", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("keys reloaded")
+	_ = responses.Send(w, ReloadKeysResponse{Reloaded: true}, http.StatusOK)
+}
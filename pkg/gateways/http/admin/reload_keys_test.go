@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+)
+
+func newReloadTestHandler(store *keys.Store) http.Handler {
+	h := NewHandler(logrus.New(), configuration.Config{AdminConfig: configuration.AdminConfig{Token: testToken}}, nil, nil, store, nil)
+	return h.Authenticate(http.HandlerFunc(h.ReloadKeys))
+}
+
+func TestHandler_ReloadKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		store      *keys.Store
+		wantStatus int
+	}{
+		{
+			name: "Reloaded",
+			store: keys.NewStore(&keys.Config{}, func() (*keys.Config, error) {
+				return &keys.Config{}, nil
+			}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "Load failure",
+			store: keys.NewStore(&keys.Config{}, func() (*keys.Config, error) {
+				return nil, errors.New("unable to load")
+			}),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Reload disabled",
+			store:      keys.NewStore(&keys.Config{}, nil),
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newReloadTestHandler(tt.store).ServeHTTP(w, adminRequest(http.MethodPost, "/admin/keys/reload", testToken, ""))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ReloadKeys() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_ReloadKeys_Concurrent(t *testing.T) {
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	store := keys.NewStore(&keys.Config{}, func() (*keys.Config, error) {
+		close(loading)
+		<-release
+		return &keys.Config{}, nil
+	})
+	handler := newReloadTestHandler(store)
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(first, adminRequest(http.MethodPost, "/admin/keys/reload", testToken, ""))
+	}()
+	<-loading
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, adminRequest(http.MethodPost, "/admin/keys/reload", testToken, ""))
+			statuses[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+	close(release)
+	<-done
+
+	if first.Code != http.StatusOK {
+		t.Errorf("ReloadKeys() first status = %v, want %v", first.Code, http.StatusOK)
+	}
+	for _, status := range statuses {
+		if status != http.StatusConflict {
+			t.Errorf("ReloadKeys() concurrent status = %v, want %v", status, http.StatusConflict)
+		}
+	}
+}
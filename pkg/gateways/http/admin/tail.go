@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// Tail streams the processed notifications metadata as server-sent events,
+// starting with the recent ones. The stream ends with the request, or with
+// the server write timeout.
+func (h Handler) Tail(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		_ = responses.SendError(w, r, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	recent, events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range recent {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			if err := writeEvent(w, event); err != nil {
+				h.log.WithError(err).Info("tail stream closed")
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event tail.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
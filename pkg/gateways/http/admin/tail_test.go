@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+)
+
+func readEvent(t *testing.T, reader *bufio.Reader) tail.Event {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+
+		data := strings.TrimPrefix(strings.TrimSpace(line), "data: ")
+		if data == "" {
+			continue
+		}
+
+		var event tail.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", data, err)
+		}
+		return event
+	}
+}
+
+func TestHandler_Tail(t *testing.T) {
+	h := newTestHandler(configuration.Config{})
+	h.events.Add(tail.Event{EventID: "1", EventType: "cash_in_internal_transfer", Outcome: tail.OutcomeSuccess})
+
+	srv := httptest.NewServer(h.Authenticate(http.HandlerFunc(h.Tail)))
+	defer srv.Close()
+
+	req := adminRequest(http.MethodGet, srv.URL+"/admin/tail", testToken, "")
+	req.RequestURI = ""
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Tail() status = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Tail() content type = %v", got)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if event := readEvent(t, reader); event.EventID != "1" {
+		t.Errorf("Tail() recent event = %v, want 1", event.EventID)
+	}
+
+	h.events.Add(tail.Event{EventID: "2", EventType: "cash_out_internal_transfer", Outcome: tail.OutcomeFailure})
+	event := readEvent(t, reader)
+	if event.EventID != "2" || event.Outcome != tail.OutcomeFailure {
+		t.Errorf("Tail() new event = %+v", event)
+	}
+}
+
+func TestHandler_Tail_Unauthorized(t *testing.T) {
+	h := newTestHandler(configuration.Config{})
+
+	w := httptest.NewRecorder()
+	h.Authenticate(http.HandlerFunc(h.Tail)).ServeHTTP(w, adminRequest(http.MethodGet, "/admin/tail", "", ""))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Tail() status = %v, want %v", w.Code, http.StatusUnauthorized)
+	}
+}
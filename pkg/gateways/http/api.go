@@ -2,7 +2,11 @@ package http
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -10,53 +14,373 @@ import (
 	"github.com/urfave/negroni"
 
 	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventtype"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventversion"
+	"github.com/stone-co/webhook-consumer/pkg/common/keys"
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/ratelimit"
+	"github.com/stone-co/webhook-consumer/pkg/common/shedding"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
 	"github.com/stone-co/webhook-consumer/pkg/common/validator"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/admin"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/http/healthcheck"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/http/notifications"
 )
 
-func NewHttpServer(config configuration.Config, log *logrus.Logger, usecase domain.NotificationUsecase) *http.Server {
+func NewHttpServers(config configuration.Config, log *logrus.Logger, usecase domain.NotificationUsecase, keyStore *keys.Store, notificationStore domain.NotificationStore, readiness *healthcheck.Readiness) (*Servers, error) {
+	if err := notifications.CheckSuccessStatus(config.HTTPConfig.SuccessStatus); err != nil {
+		return nil, err
+	}
+
 	validator := validator.NewJSONValidator()
 
-	notificationsHandler := notifications.NewHandler(log, validator, usecase)
+	// The maintenance mode is shared, so the admin API can toggle it.
+	maintenanceMode := maintenance.New(config.MaintenanceConfig.Enabled, config.MaintenanceConfig.RetryAfter)
+
+	// The tail of processed notifications, for the admin API.
+	events := tail.New(config.AdminConfig.TailSize)
+
+	healthcheckHandler := healthcheck.NewHandler(maintenanceMode)
+	healthcheckHandler.SetReadiness(readiness)
+	notificationsHandler := notifications.NewHandler(config.HTTPConfig, log, validator, usecase, maintenanceMode, events)
+
+	if eventTypes := configuration.SplitList(config.HTTPConfig.AckEventTypes); len(eventTypes) > 0 {
+		ack, err := notifications.NewTemplateAckResponder(config.HTTPConfig.AckStatus, config.HTTPConfig.AckContentType, config.HTTPConfig.AckBody)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, eventType := range eventTypes {
+			notificationsHandler.SetAckResponder(eventType, ack)
+		}
+	}
+
+	versions, err := eventversion.New(config.EventVersionConfig)
+	if err != nil {
+		return nil, err
+	}
+	notificationsHandler.SetEventVersionPolicy(versions)
+
+	eventTypes, err := eventtype.New(config.EventTypeConfig)
+	if err != nil {
+		return nil, err
+	}
+	notificationsHandler.SetEventTypeNormalizer(eventTypes)
+	notificationsHandler.SetEventTypeLabels(labelguard.New(config.MetricsConfig.MaxEventTypes))
+
+	if config.HTTPConfig.ProbeHeader != "" {
+		name, value, err := notifications.ParseProbeHeader(config.HTTPConfig.ProbeHeader)
+		if err != nil {
+			return nil, err
+		}
+		notificationsHandler.SetProbeHeader(name, value)
+	}
+
+	bodySignature, err := notifications.NewBodySignature(config.HTTPConfig.BodySignatureHeader, config.HTTPConfig.BodySignatureSecret)
+	if err != nil {
+		return nil, err
+	}
+	notificationsHandler.SetBodySignature(bodySignature)
+
+	clientIP, err := middleware.NewClientIP(config.ClientIPConfig)
+	if err != nil {
+		return nil, err
+	}
+	notificationsHandler.SetClientIP(clientIP)
+
+	limiter, err := ratelimit.New(config.RateLimitConfig)
+	if err != nil {
+		return nil, err
+	}
+	notificationsHandler.SetRateLimiter(limiter)
+
+	if tracker := shedding.New(config.SheddingConfig); tracker != nil {
+		if config.SheddingConfig.Duration <= 0 {
+			return nil, fmt.Errorf("invalid shedding duration %s, must be positive", config.SheddingConfig.Duration)
+		}
+		notificationsHandler.SetShedding(tracker)
+	}
+
+	if probePath := config.HTTPConfig.ProbePath; probePath != "" && (!strings.HasPrefix(probePath, "/") || probePath == notificationsPath) {
+		return nil, fmt.Errorf("invalid probe path %q, must start with / and differ from %s", probePath, notificationsPath)
+	}
+
+	if unsignedPath := config.HTTPConfig.UnsignedPath; unsignedPath != "" && (!strings.HasPrefix(unsignedPath, "/") || unsignedPath == notificationsPath || unsignedPath == config.HTTPConfig.ProbePath) {
+		return nil, fmt.Errorf("invalid unsigned path %q, must start with / and differ from %s and the probe path", unsignedPath, notificationsPath)
+	}
+
+	// Nothing but the sources and the passphrase authenticate the unsigned
+	// notifications.
+	var unsignedAuth *middleware.InboundAuth
+	if config.HTTPConfig.UnsignedPath != "" {
+		if config.HTTPConfig.UnsignedAllowedCIDRs == "" {
+			return nil, fmt.Errorf("the unsigned path requires the allowed source networks")
+		}
+		if config.KeysConfig.PBES2Passphrase == "" {
+			return nil, fmt.Errorf("the unsigned path requires the PBES2 passphrase")
+		}
+
+		unsignedAuth, err = middleware.NewInboundAuth(configuration.InboundAuthConfig{AllowedCIDRs: config.HTTPConfig.UnsignedAllowedCIDRs}, clientIP, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if batchPath := config.HTTPConfig.BatchPath; batchPath != "" {
+		if !strings.HasPrefix(batchPath, "/") || batchPath == notificationsPath || batchPath == config.HTTPConfig.ProbePath || batchPath == config.HTTPConfig.UnsignedPath {
+			return nil, fmt.Errorf("invalid batch path %q, must start with / and differ from %s, the probe and the unsigned paths", batchPath, notificationsPath)
+		}
+		if config.HTTPConfig.BatchMaxItems < 1 {
+			return nil, fmt.Errorf("invalid batch max items %d, must be positive", config.HTTPConfig.BatchMaxItems)
+		}
+	}
+
+	// The pack endpoint is internal, so it's behind the admin authentication.
+	var packer *keys.Packer
+	if config.PackConfig.Enabled {
+		if config.AdminConfig.Token == "" {
+			return nil, fmt.Errorf("the pack endpoint requires the admin token")
+		}
+
+		packer, err = keys.LoadPacker(config.PackConfig)
+		if err != nil {
+			return nil, fmt.Errorf("loading the pack keys: %v", err)
+		}
+	}
+
+	// The admin API is only available with a token.
+	var adminHandler *admin.Handler
+	if config.AdminConfig.Token != "" {
+		adminHandler = admin.NewHandler(log, config, maintenanceMode, events, keyStore, packer)
+		if notificationStore != nil {
+			adminHandler.SetNotificationStore(notificationStore, usecase)
+		}
+	}
+
+	if config.HTTPConfig.PprofEnabled && config.HTTPConfig.AdminPort == 0 {
+		return nil, fmt.Errorf("pprof is only served on the admin listener, which requires the admin port")
+	}
+
+	inboundAuth, err := middleware.NewInboundAuth(config.InboundAuthConfig, clientIP, log)
+	if err != nil {
+		return nil, err
+	}
+
+	api := NewApi(log, healthcheckHandler, notificationsHandler, adminHandler)
+	api.SetInboundAuth(inboundAuth)
+	api.SetUnsignedAuth(unsignedAuth)
+
+	if config.HTTPConfig.AdminPort == 0 {
+		srv := api.NewServer("0.0.0.0", config.HTTPConfig)
+		if srv.TLSConfig, err = loadTLSConfig(config.HTTPConfig.TLSCertPath, config.HTTPConfig.TLSKeyPath); err != nil {
+			return nil, err
+		}
+		if err := requireClientCertificates(srv.TLSConfig, config.InboundAuthConfig.ClientCAPath); err != nil {
+			return nil, err
+		}
+
+		return &Servers{Public: srv}, nil
+	}
+
+	if config.HTTPConfig.AdminPort == config.HTTPConfig.Port && config.HTTPConfig.UnixSocket == "" {
+		return nil, fmt.Errorf("the admin port must differ from the public port %d", config.HTTPConfig.Port)
+	}
+
+	servers := &Servers{
+		Public: api.NewPublicServer("0.0.0.0", config.HTTPConfig),
+		Admin:  api.NewAdminServer(config.HTTPConfig),
+	}
+	if servers.Public.TLSConfig, err = loadTLSConfig(config.HTTPConfig.TLSCertPath, config.HTTPConfig.TLSKeyPath); err != nil {
+		return nil, err
+	}
+	if err := requireClientCertificates(servers.Public.TLSConfig, config.InboundAuthConfig.ClientCAPath); err != nil {
+		return nil, err
+	}
+	if servers.Admin.TLSConfig, err = loadTLSConfig(config.HTTPConfig.AdminTLSCertPath, config.HTTPConfig.AdminTLSKeyPath); err != nil {
+		return nil, fmt.Errorf("admin listener: %v", err)
+	}
+
+	return servers, nil
+}
 
-	api := NewApi(log, notificationsHandler)
-	return api.NewServer("0.0.0.0", config.HTTPConfig)
+// Servers has the public server, receiving the notifications, and the admin
+// server with the operational routes. Admin is nil when all the routes are
+// served by the public server.
+type Servers struct {
+	Public *http.Server
+	Admin  *http.Server
 }
 
+const notificationsPath = "/api/v0/notifications"
+
 type Api struct {
 	log           *logrus.Logger
-	Healthcheck   healthcheck.Handler
+	healthcheck   *healthcheck.Handler
 	notifications *notifications.Handler
+	admin         *admin.Handler
+	// inboundAuth is nil when the clients of the notification routes aren't
+	// authenticated.
+	inboundAuth *middleware.InboundAuth
+	// unsignedAuth only accepts the sources of the unsigned route.
+	unsignedAuth *middleware.InboundAuth
 }
 
-func NewApi(log *logrus.Logger, notifications *notifications.Handler) *Api {
+func NewApi(log *logrus.Logger, healthcheck *healthcheck.Handler, notifications *notifications.Handler, admin *admin.Handler) *Api {
 	return &Api{
 		log:           log,
+		healthcheck:   healthcheck,
 		notifications: notifications,
+		admin:         admin,
 	}
 }
 
+// SetInboundAuth authenticates the clients of the notification routes.
+func (a *Api) SetInboundAuth(auth *middleware.InboundAuth) {
+	a.inboundAuth = auth
+}
+
+// SetUnsignedAuth authenticates the clients of the unsigned route, after the
+// inbound authentication.
+func (a *Api) SetUnsignedAuth(auth *middleware.InboundAuth) {
+	a.unsignedAuth = auth
+}
+
+// NewServer serves all the routes on a single listener.
 func (a *Api) NewServer(host string, cfg configuration.HTTPConfig) *http.Server {
-	// Router
-	r := mux.NewRouter()
+	root := mux.NewRouter()
+
+	r := root
+	if basePath := cleanBasePath(cfg.BasePath); basePath != "" {
+		r = root.PathPrefix(basePath).Subrouter()
+	}
+
+	operational := r
+	if cfg.OperationalRoutesAtRoot {
+		operational = root
+	}
+
+	a.operationalRoutes(operational, a.healthcheck.Ready)
+	a.publicRoutes(r, cfg)
+	a.adminRoutes(r)
+
+	return a.newServer(middleware.Metrics(root), fmt.Sprintf("%s:%d", host, cfg.Port), cfg)
+}
+
+// NewPublicServer serves only the notifications, and the probe, routes.
+func (a *Api) NewPublicServer(host string, cfg configuration.HTTPConfig) *http.Server {
+	root := mux.NewRouter()
+
+	r := root
+	if basePath := cleanBasePath(cfg.BasePath); basePath != "" {
+		r = root.PathPrefix(basePath).Subrouter()
+	}
+
+	a.publicRoutes(r, cfg)
+
+	return a.newServer(middleware.Metrics(root), fmt.Sprintf("%s:%d", host, cfg.Port), cfg)
+}
+
+// NewAdminServer serves the healthcheck, metrics, pprof and admin routes at
+// the root of the admin host and port. The base path is only for the
+// ingresses of the public server.
+func (a *Api) NewAdminServer(cfg configuration.HTTPConfig) *http.Server {
+	root := mux.NewRouter()
 
-	// Handlers
-	r.HandleFunc("/healthcheck", a.Healthcheck.Get).Methods(http.MethodGet)
+	a.operationalRoutes(root, a.healthcheck.ReadyWithErrors)
+	a.adminRoutes(root)
+	if cfg.PprofEnabled {
+		pprofRoutes(root)
+	}
+
+	return a.newServer(root, net.JoinHostPort(cfg.AdminHost, strconv.Itoa(cfg.AdminPort)), cfg)
+}
+
+// operationalRoutes serve the readiness with ready, which only has the check
+// errors on the admin listener.
+func (a *Api) operationalRoutes(r *mux.Router, ready http.HandlerFunc) {
+	r.HandleFunc("/healthcheck", a.healthcheck.Get).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", a.healthcheck.Live).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", ready).Methods(http.MethodGet)
 	r.HandleFunc("/metrics", promhttp.Handler().ServeHTTP).Methods(http.MethodGet)
-	r.HandleFunc("/api/v0/notifications", a.notifications.New).Methods(http.MethodPost)
+}
+
+// publicRoutes start a span for each request, and authenticate the clients
+// before the handlers read the body.
+func (a *Api) publicRoutes(r *mux.Router, cfg configuration.HTTPConfig) {
+	public := func(handler http.HandlerFunc) http.Handler {
+		return middleware.Tracing(a.inboundAuth.Handler(handler))
+	}
+
+	r.Handle(notificationsPath, public(a.notifications.New)).Methods(http.MethodPost)
+	if cfg.ProbePath != "" {
+		r.Handle(cfg.ProbePath, public(a.notifications.Probe)).Methods(http.MethodGet, http.MethodHead, http.MethodPost)
+	}
+	if cfg.UnsignedPath != "" {
+		r.Handle(cfg.UnsignedPath, public(a.unsignedAuth.Handler(http.HandlerFunc(a.notifications.NewUnsigned)).ServeHTTP)).Methods(http.MethodPost)
+	}
+	if cfg.BatchPath != "" {
+		r.Handle(cfg.BatchPath, public(a.notifications.NewBatch)).Methods(http.MethodPost)
+	}
+}
 
+func (a *Api) adminRoutes(r *mux.Router) {
+	if a.admin == nil {
+		return
+	}
+
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(a.admin.Authenticate)
+	adminRouter.HandleFunc("/config", a.admin.GetConfig).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/maintenance", a.admin.SetMaintenance).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/tail", a.admin.Tail).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/keys/reload", a.admin.ReloadKeys).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/keys/match", a.admin.MatchKey).Methods(http.MethodPost)
+	if a.admin.NotificationsEnabled() {
+		adminRouter.HandleFunc("/notifications", a.admin.ListNotifications).Methods(http.MethodGet)
+		adminRouter.HandleFunc("/notifications/{event_id}", a.admin.GetNotification).Methods(http.MethodGet)
+		adminRouter.HandleFunc("/notifications/{event_id}/replay", a.admin.ReplayNotification).Methods(http.MethodPost)
+	}
+
+	if a.admin.PackEnabled() {
+		internalRouter := r.PathPrefix("/internal").Subrouter()
+		internalRouter.Use(a.admin.Authenticate)
+		internalRouter.HandleFunc("/pack", a.admin.Pack).Methods(http.MethodPost)
+	}
+}
+
+func pprofRoutes(r *mux.Router) {
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+}
+
+// newServer wraps the routes with the middlewares of each server.
+func (a *Api) newServer(handler http.Handler, addr string, cfg configuration.HTTPConfig) *http.Server {
 	n := negroni.New(negroni.NewRecovery(), negroni.NewLogger())
 
-	n.UseHandler(r)
+	n.UseHandler(handler)
 
-	endpoint := fmt.Sprintf("%s:%d", host, cfg.Port)
+	return &http.Server{
+		Handler:           n,
+		Addr:              addr,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
 
-	srv := &http.Server{
-		Handler: n,
-		Addr:    endpoint,
+// cleanBasePath returns the base path as "/prefix", or empty for the root.
+func cleanBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
 	}
 
-	return srv
+	return "/" + basePath
 }
@@ -0,0 +1,304 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/admin"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/healthcheck"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/notifications"
+)
+
+func TestApi_NewServer(t *testing.T) {
+	cfg := configuration.HTTPConfig{
+		Port:              3000,
+		ReadTimeout:       1 * time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+	}
+
+	log := logrus.New()
+	handler := notifications.NewHandler(cfg, log, validator.NewJSONValidator(), nil, nil, nil)
+	srv := NewApi(log, healthcheck.NewHandler(nil), handler, nil).NewServer("0.0.0.0", cfg)
+
+	if srv.Addr != "0.0.0.0:3000" {
+		t.Errorf("NewServer() addr = %v", srv.Addr)
+	}
+	if srv.ReadTimeout != cfg.ReadTimeout {
+		t.Errorf("NewServer() read timeout = %v, want %v", srv.ReadTimeout, cfg.ReadTimeout)
+	}
+	if srv.ReadHeaderTimeout != cfg.ReadHeaderTimeout {
+		t.Errorf("NewServer() read header timeout = %v, want %v", srv.ReadHeaderTimeout, cfg.ReadHeaderTimeout)
+	}
+	if srv.WriteTimeout != cfg.WriteTimeout {
+		t.Errorf("NewServer() write timeout = %v, want %v", srv.WriteTimeout, cfg.WriteTimeout)
+	}
+	if srv.IdleTimeout != cfg.IdleTimeout {
+		t.Errorf("NewServer() idle timeout = %v, want %v", srv.IdleTimeout, cfg.IdleTimeout)
+	}
+}
+
+func TestApi_NewServer_BasePath(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        configuration.HTTPConfig
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "Without base path",
+			path:       "/healthcheck",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Base path",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1/"},
+			path:       "/stone/v1/healthcheck",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Root route with base path",
+			cfg:        configuration.HTTPConfig{BasePath: "stone/v1"},
+			path:       "/healthcheck",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Admin route with base path",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1"},
+			path:       "/stone/v1/admin/config",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Admin route without base path",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1"},
+			path:       "/admin/config",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Pack route is disabled by default",
+			path:       "/internal/pack",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Probe route",
+			cfg:        configuration.HTTPConfig{ProbePath: "/webhooks/stone/ping"},
+			path:       "/webhooks/stone/ping",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Probe route with base path",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1", ProbePath: "/ping"},
+			path:       "/stone/v1/ping",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Probe route is disabled by default",
+			path:       "/webhooks/stone/ping",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Unsigned route only accepts posts",
+			cfg:        configuration.HTTPConfig{UnsignedPath: "/webhooks/stone/unsigned"},
+			path:       "/webhooks/stone/unsigned",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Unsigned route is disabled by default",
+			path:       "/webhooks/stone/unsigned",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Operational routes at root",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1", OperationalRoutesAtRoot: true},
+			path:       "/healthcheck",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Operational routes at root aren't prefixed",
+			cfg:        configuration.HTTPConfig{BasePath: "/stone/v1", OperationalRoutesAtRoot: true},
+			path:       "/stone/v1/healthcheck",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logrus.New()
+			log.SetOutput(ioutil.Discard)
+
+			config := configuration.Config{HTTPConfig: tt.cfg, AdminConfig: configuration.AdminConfig{Token: "token"}}
+			handler := notifications.NewHandler(tt.cfg, log, validator.NewJSONValidator(), nil, nil, nil)
+			adminHandler := admin.NewHandler(log, config, nil, nil, nil, nil)
+			srv := NewApi(log, healthcheck.NewHandler(nil), handler, adminHandler).NewServer("0.0.0.0", tt.cfg)
+
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("NewServer() %s status = %v, want %v", tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestApi_SeparateListeners(t *testing.T) {
+	tests := []struct {
+		name       string
+		admin      bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "Notifications on the public listener", method: http.MethodPost, path: "/api/v0/notifications", wantStatus: http.StatusBadRequest},
+		{name: "Probe on the public listener", method: http.MethodGet, path: "/ping", wantStatus: http.StatusOK},
+		{name: "Healthcheck isn't on the public listener", method: http.MethodGet, path: "/healthcheck", wantStatus: http.StatusNotFound},
+		{name: "Metrics aren't on the public listener", method: http.MethodGet, path: "/metrics", wantStatus: http.StatusNotFound},
+		{name: "Admin API isn't on the public listener", method: http.MethodGet, path: "/admin/config", wantStatus: http.StatusNotFound},
+		{name: "Pprof isn't on the public listener", method: http.MethodGet, path: "/debug/pprof/", wantStatus: http.StatusNotFound},
+		{name: "Healthcheck on the admin listener", admin: true, method: http.MethodGet, path: "/healthcheck", wantStatus: http.StatusOK},
+		{name: "Metrics on the admin listener", admin: true, method: http.MethodGet, path: "/metrics", wantStatus: http.StatusOK},
+		{name: "Admin API on the admin listener", admin: true, method: http.MethodGet, path: "/admin/config", wantStatus: http.StatusUnauthorized},
+		{name: "Pprof on the admin listener", admin: true, method: http.MethodGet, path: "/debug/pprof/", wantStatus: http.StatusOK},
+		{name: "Notifications aren't on the admin listener", admin: true, method: http.MethodPost, path: "/api/v0/notifications", wantStatus: http.StatusNotFound},
+		{name: "Probe isn't on the admin listener", admin: true, method: http.MethodGet, path: "/ping", wantStatus: http.StatusNotFound},
+	}
+
+	cfg := configuration.HTTPConfig{AdminPort: 3001, AdminHost: "127.0.0.1", PprofEnabled: true, ProbePath: "/ping"}
+
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+
+	config := configuration.Config{HTTPConfig: cfg, AdminConfig: configuration.AdminConfig{Token: "token"}}
+	handler := notifications.NewHandler(cfg, log, validator.NewJSONValidator(), nil, nil, nil)
+	api := NewApi(log, healthcheck.NewHandler(nil), handler, admin.NewHandler(log, config, nil, nil, nil, nil))
+
+	public := api.NewPublicServer("0.0.0.0", cfg)
+	adminServer := api.NewAdminServer(cfg)
+	if adminServer.Addr != "127.0.0.1:3001" {
+		t.Errorf("NewAdminServer() addr = %v", adminServer.Addr)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := public
+			if tt.admin {
+				srv = adminServer
+			}
+
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s status = %v, want %v", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewHttpServers(t *testing.T) {
+	certPath, keyPath := writeCertificate(t)
+
+	tests := []struct {
+		name      string
+		cfg       configuration.HTTPConfig
+		wantAdmin string
+		wantTLS   bool
+		wantErr   bool
+	}{
+		{
+			name: "Single listener",
+			cfg:  configuration.HTTPConfig{Port: 3000},
+		},
+		{
+			name:      "Admin listener",
+			cfg:       configuration.HTTPConfig{Port: 3000, AdminPort: 3001, AdminHost: "127.0.0.1"},
+			wantAdmin: "127.0.0.1:3001",
+		},
+		{
+			name:      "Admin listener with TLS",
+			cfg:       configuration.HTTPConfig{Port: 3000, AdminPort: 3001, AdminHost: "127.0.0.1", AdminTLSCertPath: certPath, AdminTLSKeyPath: keyPath},
+			wantAdmin: "127.0.0.1:3001",
+			wantTLS:   true,
+		},
+		{
+			name:    "Same admin and public ports",
+			cfg:     configuration.HTTPConfig{Port: 3000, AdminPort: 3000},
+			wantErr: true,
+		},
+		{
+			name:    "Pprof without the admin listener",
+			cfg:     configuration.HTTPConfig{Port: 3000, PprofEnabled: true},
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned path is the notifications path",
+			cfg:     configuration.HTTPConfig{Port: 3000, UnsignedPath: "/api/v0/notifications"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned path without the leading slash",
+			cfg:     configuration.HTTPConfig{Port: 3000, UnsignedPath: "unsigned"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsigned path without the allowed sources",
+			cfg:     configuration.HTTPConfig{Port: 3000, UnsignedPath: "/unsigned"},
+			wantErr: true,
+		},
+		{
+			name:    "Batch path is the unsigned path",
+			cfg:     configuration.HTTPConfig{Port: 3000, UnsignedPath: "/unsigned", UnsignedAllowedCIDRs: "10.0.0.0/8", BatchPath: "/unsigned", BatchMaxItems: 100},
+			wantErr: true,
+		},
+		{
+			name:    "Batch without items",
+			cfg:     configuration.HTTPConfig{Port: 3000, BatchPath: "/batch"},
+			wantErr: true,
+		},
+		{
+			name:    "TLS certificate without the key",
+			cfg:     configuration.HTTPConfig{Port: 3000, TLSCertPath: certPath},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logrus.New()
+			log.SetOutput(ioutil.Discard)
+
+			tt.cfg.SuccessStatus = http.StatusNoContent
+			servers, err := NewHttpServers(configuration.Config{HTTPConfig: tt.cfg}, log, nil, nil, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHttpServers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if servers.Public.TLSConfig != nil {
+				t.Errorf("NewHttpServers() public tls = %v, want disabled", servers.Public.TLSConfig)
+			}
+			if tt.wantAdmin == "" {
+				if servers.Admin != nil {
+					t.Errorf("NewHttpServers() admin = %v, want nil", servers.Admin.Addr)
+				}
+				return
+			}
+			if servers.Admin == nil || servers.Admin.Addr != tt.wantAdmin {
+				t.Fatalf("NewHttpServers() admin = %v, want %v", servers.Admin, tt.wantAdmin)
+			}
+			if (servers.Admin.TLSConfig != nil) != tt.wantTLS {
+				t.Errorf("NewHttpServers() admin tls = %v, want %v", servers.Admin.TLSConfig != nil, tt.wantTLS)
+			}
+		})
+	}
+}
@@ -1,13 +1,40 @@
 package healthcheck
 
-import "net/http"
+import (
+	"net/http"
 
-type Handler struct{}
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
 
-func NewHandler() *Handler {
-	return &Handler{}
+const (
+	StatusOK          = "ok"
+	StatusMaintenance = "maintenance"
+)
+
+type Response struct {
+	Status string `json:"status"`
+}
+
+type Handler struct {
+	maintenance *maintenance.Mode
+	// readiness is nil when there are no dependency checks.
+	readiness *Readiness
 }
 
+func NewHandler(maintenance *maintenance.Mode) *Handler {
+	return &Handler{
+		maintenance: maintenance,
+	}
+}
+
+// Get stays healthy during the maintenance, so the service isn't restarted,
+// but reports it in the status.
 func (h Handler) Get(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+	status := StatusOK
+	if h.maintenance.Enabled() {
+		status = StatusMaintenance
+	}
+
+	_ = responses.Send(w, Response{Status: status}, http.StatusOK)
 }
@@ -0,0 +1,135 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+const (
+	StatusUnavailable = "unavailable"
+	StatusError       = "error"
+)
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Readiness checks the dependencies, like the keys and the notifier backends.
+// A nil readiness has no checks, so it's always ready.
+type Readiness struct {
+	timeout time.Duration
+	checks  []check
+}
+
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{timeout: timeout}
+}
+
+// Add checks the dependency, ready when the check returns nil.
+func (r *Readiness) Add(name string, run func(ctx context.Context) error) {
+	r.checks = append(r.checks, check{name: name, run: run})
+}
+
+// Run runs all the checks at once, each bounded by the timeout, returning
+// their results and if all of them passed.
+func (r *Readiness) Run(ctx context.Context) (map[string]CheckResult, bool) {
+	results := map[string]CheckResult{}
+	if r == nil {
+		return results, true
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	ready := true
+	for _, c := range r.checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+
+			checkCtx, cancel := ctx, func() {}
+			if r.timeout > 0 {
+				checkCtx, cancel = context.WithTimeout(ctx, r.timeout)
+			}
+			defer cancel()
+
+			result := CheckResult{Status: StatusOK}
+			if err := c.run(checkCtx); err != nil {
+				result = CheckResult{Status: StatusError, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[c.name] = result
+			if result.Status != StatusOK {
+				ready = false
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return results, ready
+}
+
+// SetReadiness sets the dependency checks of the readiness.
+func (h *Handler) SetReadiness(readiness *Readiness) {
+	h.readiness = readiness
+}
+
+// Live answers while the process serves requests, without checking the
+// dependencies, so a failing backend doesn't restart the service.
+func (h Handler) Live(w http.ResponseWriter, r *http.Request) {
+	_ = responses.Send(w, Response{Status: StatusOK}, http.StatusOK)
+}
+
+// Ready answers 503 when a dependency check fails, with the status of each
+// check. The errors have hosts, buckets and ARNs, so they are left out of this
+// public answer. It stays ready during the maintenance, reported in the
+// status.
+func (h Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	h.ready(w, r, false)
+}
+
+// ReadyWithErrors is Ready with the error of each failed check, for the
+// admin listener.
+func (h Handler) ReadyWithErrors(w http.ResponseWriter, r *http.Request) {
+	h.ready(w, r, true)
+}
+
+func (h Handler) ready(w http.ResponseWriter, r *http.Request, withErrors bool) {
+	results, ready := h.readiness.Run(r.Context())
+	if !withErrors {
+		for name, result := range results {
+			result.Error = ""
+			results[name] = result
+		}
+	}
+
+	response := ReadinessResponse{Status: StatusOK, Checks: results}
+	statusCode := http.StatusOK
+	switch {
+	case !ready:
+		response.Status = StatusUnavailable
+		statusCode = http.StatusServiceUnavailable
+	case h.maintenance.Enabled():
+		response.Status = StatusMaintenance
+	}
+
+	_ = responses.Send(w, response, statusCode)
+}
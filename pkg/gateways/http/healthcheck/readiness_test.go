@@ -0,0 +1,141 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+)
+
+func TestHandler_Live(t *testing.T) {
+	h := NewHandler(maintenance.New(true, 0))
+	readiness := NewReadiness(time.Second)
+	readiness.Add("failing", func(ctx context.Context) error { return errors.New("down") })
+	h.SetReadiness(readiness)
+
+	w := httptest.NewRecorder()
+	h.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Live() status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_Ready(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name        string
+		checks      map[string]func(ctx context.Context) error
+		maintenance bool
+		wantStatus  int
+		wantBody    string
+		wantChecks  map[string]string
+	}{
+		{
+			name:       "Without checks",
+			wantStatus: http.StatusOK,
+			wantBody:   StatusOK,
+			wantChecks: map[string]string{},
+		},
+		{
+			name:       "All checks pass",
+			checks:     map[string]func(ctx context.Context) error{"keys": ok, "notifier:redis": ok},
+			wantStatus: http.StatusOK,
+			wantBody:   StatusOK,
+			wantChecks: map[string]string{"keys": StatusOK, "notifier:redis": StatusOK},
+		},
+		{
+			name:       "A check fails",
+			checks:     map[string]func(ctx context.Context) error{"keys": ok, "notifier:redis": failing},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   StatusUnavailable,
+			wantChecks: map[string]string{"keys": StatusOK, "notifier:redis": StatusError},
+		},
+		{
+			name:       "A check times out",
+			checks:     map[string]func(ctx context.Context) error{"archiver": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   StatusUnavailable,
+			wantChecks: map[string]string{"archiver": StatusError},
+		},
+		{
+			name:        "Maintenance",
+			checks:      map[string]func(ctx context.Context) error{"keys": ok},
+			maintenance: true,
+			wantStatus:  http.StatusOK,
+			wantBody:    StatusMaintenance,
+			wantChecks:  map[string]string{"keys": StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(maintenance.New(tt.maintenance, 0))
+			readiness := NewReadiness(50 * time.Millisecond)
+			for name, check := range tt.checks {
+				readiness.Add(name, check)
+			}
+			h.SetReadiness(readiness)
+
+			w := httptest.NewRecorder()
+			h.ReadyWithErrors(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Ready() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var response ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Ready() body = %s, err = %v", w.Body.String(), err)
+			}
+			if response.Status != tt.wantBody {
+				t.Errorf("Ready() status = %q, want %q", response.Status, tt.wantBody)
+			}
+			if len(response.Checks) != len(tt.wantChecks) {
+				t.Fatalf("Ready() checks = %v, want %v", response.Checks, tt.wantChecks)
+			}
+			for name, status := range tt.wantChecks {
+				result := response.Checks[name]
+				if result.Status != status {
+					t.Errorf("Ready() check %s = %q, want %q", name, result.Status, status)
+				}
+				if status == StatusError && result.Error == "" {
+					t.Errorf("Ready() check %s without the error", name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Ready_WithoutErrors(t *testing.T) {
+	h := NewHandler(maintenance.New(false, 0))
+	readiness := NewReadiness(time.Second)
+	readiness.Add("archiver", func(ctx context.Context) error { return errors.New("bucket private-bucket not found") })
+	h.SetReadiness(readiness)
+
+	w := httptest.NewRecorder()
+	h.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Ready() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	var response ReadinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Ready() body = %s, err = %v", w.Body.String(), err)
+	}
+	if result := response.Checks["archiver"]; result.Status != StatusError || result.Error != "" {
+		t.Errorf("Ready() check = %+v, want the status without the error", result)
+	}
+}
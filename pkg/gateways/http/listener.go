@@ -0,0 +1,67 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+const unixSocketMode = 0660
+
+// NewListener listens on the unix socket, when configured, or on the TCP
+// address. A stale socket file left by a previous run is removed, and the
+// socket file is removed again when the listener is closed.
+func NewListener(cfg configuration.HTTPConfig, addr string) (net.Listener, error) {
+	if cfg.UnixSocket == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	if err := removeStaleSocket(cfg.UnixSocket); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", cfg.UnixSocket)
+	if err != nil {
+		return nil, fmt.Errorf("listening on unix socket %s: %v", cfg.UnixSocket, err)
+	}
+
+	if err := os.Chmod(cfg.UnixSocket, unixSocketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("changing unix socket %s mode: %v", cfg.UnixSocket, err)
+	}
+
+	return listener, nil
+}
+
+// Serve serves with TLS when the server has a TLS config.
+func Serve(srv *http.Server, listener net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(listener, "", "")
+	}
+
+	return srv.Serve(listener)
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("checking unix socket %s: %v", path, err)
+	}
+
+	// Never remove a regular file by mistake.
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing stale unix socket %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestNewListener_UnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-consumer")
+	if err != nil {
+		t.Fatalf("creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "api.sock")
+
+	// A stale socket, left by a process that didn't clean up.
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("creating stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := NewListener(configuration.HTTPConfig{UnixSocket: socket}, "")
+	if err != nil {
+		t.Fatalf("NewListener() error = %v", err)
+	}
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("NewListener() socket not created: %v", err)
+	}
+	if info.Mode().Perm() != unixSocketMode {
+		t.Errorf("NewListener() socket mode = %v, want %v", info.Mode().Perm(), os.FileMode(unixSocketMode))
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})}
+	go func() { _ = srv.Serve(listener) }()
+
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/healthcheck")
+	if err != nil {
+		t.Fatalf("requesting through the socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("response status = %v, want %v", resp.StatusCode, http.StatusTeapot)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Shutdown() socket file not removed: %v", err)
+	}
+}
+
+func TestNewListener_NotASocket(t *testing.T) {
+	file, err := ioutil.TempFile("", "webhook-consumer")
+	if err != nil {
+		t.Fatalf("creating temp file: %v", err)
+	}
+	defer os.Remove(file.Name())
+	file.Close()
+
+	if _, err := NewListener(configuration.HTTPConfig{UnixSocket: file.Name()}, ""); err == nil {
+		t.Errorf("NewListener() must not replace a regular file")
+	}
+}
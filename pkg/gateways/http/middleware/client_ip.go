@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// ClientIP resolves the client address of the requests, shared by the inbound
+// authentication, the rate limits and the shedding. Behind the proxies, the
+// address is read from the right of the header, where each trusted proxy
+// appends the address it received from, so the values written by the client,
+// on the left, are never used.
+type ClientIP struct {
+	header      string
+	trustedHops int
+}
+
+func NewClientIP(cfg configuration.ClientIPConfig) (ClientIP, error) {
+	if cfg.Header != "" && cfg.TrustedHops < 1 {
+		return ClientIP{}, fmt.Errorf("invalid client IP trusted hops %d, must be positive with a header", cfg.TrustedHops)
+	}
+
+	return ClientIP{header: cfg.Header, trustedHops: cfg.TrustedHops}, nil
+}
+
+// Of returns the address appended by the outermost trusted proxy, or the peer
+// address without the header, or when it has fewer addresses than the trusted
+// hops, as the request didn't come through all of them.
+func (c ClientIP) Of(r *http.Request) string {
+	if c.header != "" {
+		var addresses []string
+		for _, value := range r.Header.Values(c.header) {
+			for _, address := range strings.Split(value, ",") {
+				addresses = append(addresses, strings.TrimSpace(address))
+			}
+		}
+		if len(addresses) >= c.trustedHops {
+			return addresses[len(addresses)-c.trustedHops]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestClientIP_Of(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configuration.ClientIPConfig
+		headers []string
+		want    string
+	}{
+		{
+			name:    "Peer address without a header",
+			headers: []string{"10.0.0.1"},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "Address appended by the proxy",
+			cfg:     configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1},
+			headers: []string{"10.0.0.1, 203.0.113.7"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "Address appended by the outermost of two proxies",
+			cfg:     configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 2},
+			headers: []string{"10.0.0.1, 203.0.113.7", "192.0.2.1"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "Fewer addresses than the trusted hops",
+			cfg:     configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 2},
+			headers: []string{"10.0.0.1"},
+			want:    "198.51.100.1",
+		},
+		{
+			name: "Without the header",
+			cfg:  configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1},
+			want: "198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientIP, err := NewClientIP(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewClientIP() error = %v", err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = "198.51.100.1:4321"
+			for _, value := range tt.headers {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := clientIP.Of(r); got != tt.want {
+				t.Errorf("Of() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIP(t *testing.T) {
+	if _, err := NewClientIP(configuration.ClientIPConfig{Header: "X-Forwarded-For"}); err == nil {
+		t.Error("NewClientIP() error = nil without trusted hops, want error")
+	}
+}
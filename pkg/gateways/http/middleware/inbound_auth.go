@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// The reasons of the rejected requests.
+const (
+	rejectedAddress         = "address"
+	rejectedCertificate     = "certificate"
+	rejectedCertificateName = "certificate_name"
+)
+
+var inboundAuthRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "webhook_consumer_inbound_auth_rejections_total",
+	Help: "Number of requests rejected by the inbound authentication, by reason.",
+}, []string{"reason"})
+
+// InboundAuth rejects the clients out of the allowed networks, or without a
+// verified and allowed client certificate. The certificates are verified by
+// the TLS handshake, which only asks for them, so the other routes, like the
+// healthcheck, don't require them. A nil inbound auth accepts all the
+// clients.
+type InboundAuth struct {
+	log               *logrus.Logger
+	networks          []*net.IPNet
+	clientIP          ClientIP
+	requireClientCert bool
+	allowedNames      []string
+}
+
+// NewInboundAuth returns nil when all the checks are disabled.
+func NewInboundAuth(cfg configuration.InboundAuthConfig, clientIP ClientIP, log *logrus.Logger) (*InboundAuth, error) {
+	if cfg.ClientCAPath == "" && cfg.ClientAllowedNames == "" && cfg.AllowedCIDRs == "" {
+		return nil, nil
+	}
+	if cfg.ClientAllowedNames != "" && cfg.ClientCAPath == "" {
+		return nil, fmt.Errorf("the client allowed names require the client CA path")
+	}
+
+	auth := &InboundAuth{
+		log:               log,
+		clientIP:          clientIP,
+		requireClientCert: cfg.ClientCAPath != "",
+		allowedNames:      configuration.SplitList(cfg.ClientAllowedNames),
+	}
+
+	for _, item := range configuration.SplitList(cfg.AllowedCIDRs) {
+		network, err := parseNetwork(item)
+		if err != nil {
+			return nil, err
+		}
+		auth.networks = append(auth.networks, network)
+	}
+
+	return auth, nil
+}
+
+// parseNetwork parses a CIDR, or a single address.
+func parseNetwork(item string) (*net.IPNet, error) {
+	if !strings.Contains(item, "/") {
+		ip := net.ParseIP(item)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid allowed address %q", item)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(item)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed network %q: %v", item, err)
+	}
+
+	return network, nil
+}
+
+// Handler checks the client before the next handler reads the body.
+func (a *InboundAuth) Handler(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(a.networks) > 0 {
+			ip := net.ParseIP(a.clientIP.Of(r))
+			if !a.allowedAddress(ip) {
+				a.reject(w, r, rejectedAddress, fmt.Sprintf("client address %s not allowed", ip), "client not allowed", http.StatusForbidden)
+				return
+			}
+		}
+
+		if a.requireClientCert {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+				a.reject(w, r, rejectedCertificate, "request without a verified client certificate", "client certificate required", http.StatusUnauthorized)
+				return
+			}
+
+			leaf := r.TLS.VerifiedChains[0][0]
+			if !allowedCertificateName(leaf, a.allowedNames) {
+				a.reject(w, r, rejectedCertificateName, fmt.Sprintf("client certificate name not allowed: %s", leaf.Subject.CommonName), "client not allowed", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *InboundAuth) reject(w http.ResponseWriter, r *http.Request, reason, logMessage, message string, status int) {
+	inboundAuthRejections.WithLabelValues(reason).Inc()
+	a.log.Warnf("request to %s rejected by the inbound authentication, %s", r.URL.Path, logMessage)
+	_ = responses.SendError(w, r, message, status)
+}
+
+// allowedAddress is false when the client IP isn't an address.
+func (a *InboundAuth) allowedAddress(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allowedCertificateName(cert *x509.Certificate, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+
+	for _, name := range names {
+		if cert.Subject.CommonName == name {
+			return true
+		}
+
+		for _, dnsName := range cert.DNSNames {
+			if dnsName == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestNewInboundAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      configuration.InboundAuthConfig
+		disabled bool
+		wantErr  bool
+	}{
+		{name: "Disabled", disabled: true},
+		{name: "Networks and addresses", cfg: configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8; 192.0.2.10;2001:db8::/32;2001:db8::1"}},
+		{name: "Client certificates", cfg: configuration.InboundAuthConfig{ClientCAPath: "ca.pem", ClientAllowedNames: "stone.com.br"}},
+		{name: "Invalid network", cfg: configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/33"}, wantErr: true},
+		{name: "Invalid address", cfg: configuration.InboundAuthConfig{AllowedCIDRs: "stone.com.br"}, wantErr: true},
+		{name: "Allowed names without the CAs", cfg: configuration.InboundAuthConfig{ClientAllowedNames: "stone.com.br"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewInboundAuth(tt.cfg, ClientIP{}, logrus.New())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewInboundAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != tt.disabled {
+				t.Errorf("NewInboundAuth() = %v, want disabled %v", got, tt.disabled)
+			}
+		})
+	}
+}
+
+func TestInboundAuth_Handler(t *testing.T) {
+	verified := func(commonName string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	tests := []struct {
+		name       string
+		cfg        configuration.InboundAuthConfig
+		clientIP   configuration.ClientIPConfig
+		remoteAddr string
+		header     string
+		tls        *tls.ConnectionState
+		wantStatus int
+		wantReason string
+	}{
+		{
+			name:       "Without checks",
+			remoteAddr: "198.51.100.1:4321",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Allowed network",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:4321",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Allowed address",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8;198.51.100.1"},
+			remoteAddr: "198.51.100.1:4321",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Address out of the networks",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8"},
+			remoteAddr: "198.51.100.1:4321",
+			wantStatus: http.StatusForbidden,
+			wantReason: rejectedAddress,
+		},
+		{
+			name:       "Client IP header",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8"},
+			clientIP:   configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1},
+			remoteAddr: "198.51.100.1:4321",
+			header:     "198.51.100.9, 10.1.2.3",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Client IP header forged by the client",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8"},
+			clientIP:   configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1},
+			remoteAddr: "10.1.2.3:4321",
+			header:     "10.0.0.1, 198.51.100.9",
+			wantStatus: http.StatusForbidden,
+			wantReason: rejectedAddress,
+		},
+		{
+			name:       "Invalid client IP header",
+			cfg:        configuration.InboundAuthConfig{AllowedCIDRs: "10.0.0.0/8"},
+			clientIP:   configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1},
+			remoteAddr: "10.1.2.3:4321",
+			header:     "unknown",
+			wantStatus: http.StatusForbidden,
+			wantReason: rejectedAddress,
+		},
+		{
+			name:       "Verified client certificate",
+			cfg:        configuration.InboundAuthConfig{ClientCAPath: "ca.pem"},
+			remoteAddr: "198.51.100.1:4321",
+			tls:        verified("webhooks.stone.com.br"),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Without TLS",
+			cfg:        configuration.InboundAuthConfig{ClientCAPath: "ca.pem"},
+			remoteAddr: "198.51.100.1:4321",
+			wantStatus: http.StatusUnauthorized,
+			wantReason: rejectedCertificate,
+		},
+		{
+			name:       "Without a client certificate",
+			cfg:        configuration.InboundAuthConfig{ClientCAPath: "ca.pem"},
+			remoteAddr: "198.51.100.1:4321",
+			tls:        &tls.ConnectionState{},
+			wantStatus: http.StatusUnauthorized,
+			wantReason: rejectedCertificate,
+		},
+		{
+			name:       "Allowed certificate name",
+			cfg:        configuration.InboundAuthConfig{ClientCAPath: "ca.pem", ClientAllowedNames: "webhooks.stone.com.br"},
+			remoteAddr: "198.51.100.1:4321",
+			tls:        verified("webhooks.stone.com.br"),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Certificate name not allowed",
+			cfg:        configuration.InboundAuthConfig{ClientCAPath: "ca.pem", ClientAllowedNames: "webhooks.stone.com.br"},
+			remoteAddr: "198.51.100.1:4321",
+			tls:        verified("other.example.com"),
+			wantStatus: http.StatusForbidden,
+			wantReason: rejectedCertificateName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientIP, err := NewClientIP(tt.clientIP)
+			if err != nil {
+				t.Fatalf("NewClientIP() error = %v", err)
+			}
+			auth, err := NewInboundAuth(tt.cfg, clientIP, logrus.New())
+			if err != nil {
+				t.Fatalf("NewInboundAuth() error = %v", err)
+			}
+
+			var rejected float64
+			if tt.wantReason != "" {
+				rejected = testutil.ToFloat64(inboundAuthRejections.WithLabelValues(tt.wantReason))
+			}
+
+			called := false
+			handler := auth.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v0/notifications", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r.TLS = tt.tls
+			if tt.header != "" {
+				r.Header.Set("X-Forwarded-For", tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Handler() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if called != (tt.wantStatus == http.StatusNoContent) {
+				t.Errorf("Handler() called the next handler = %v", called)
+			}
+			if tt.wantReason != "" {
+				if got := testutil.ToFloat64(inboundAuthRejections.WithLabelValues(tt.wantReason)) - rejected; got != 1 {
+					t.Errorf("Handler() rejections = %v, want 1", got)
+				}
+			}
+		})
+	}
+}
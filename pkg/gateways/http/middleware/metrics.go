@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_http_requests_total",
+		Help: "Number of HTTP requests, by route and status code class.",
+	}, []string{"route", "code"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_consumer_http_request_duration_seconds",
+		Help:    "Duration of the HTTP requests, by route and status code class.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "code"})
+)
+
+// Metrics wraps the router, counting and timing all the requests, including
+// the ones matching no route, answered with 404 or 405 and labeled
+// "unknown". The route is the path template, so the label cardinality is
+// bounded by the routes.
+func Metrics(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := newStatusWriter(w)
+
+		route := "unknown"
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.Route != nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		router.ServeHTTP(sw, r)
+
+		code := statusClass(sw.Status())
+		if sw.disconnected {
+			code = disconnectedCode
+		}
+		requestsTotal.WithLabelValues(route, code).Inc()
+		requestDuration.WithLabelValues(route, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+// disconnectedCode labels the requests dropped by the client.
+const disconnectedCode = "disconnected"
+
+func statusClass(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantCode string
+	}{
+		{
+			name: "No content is 2xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantCode: "2xx",
+		},
+		{
+			name: "Only Write is 2xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			},
+			wantCode: "2xx",
+		},
+		{
+			name: "Nothing written is 2xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+			},
+			wantCode: "2xx",
+		},
+		{
+			name: "Bad request is 4xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte("bad"))
+			},
+			wantCode: "4xx",
+		},
+		{
+			name: "Internal error is 5xx",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantCode: "5xx",
+		},
+		{
+			name: "Client disconnected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				ClientDisconnected(w)
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			wantCode: "disconnected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			r.HandleFunc("/items/{id}", tt.handler)
+
+			counter := requestsTotal.WithLabelValues("/items/{id}", tt.wantCode)
+			before := testutil.ToFloat64(counter)
+
+			Metrics(r).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/1", nil))
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("Metrics() counted %v requests with code %s, want 1", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMetrics_Unmatched(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodGet)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "Not found",
+			method:     http.MethodGet,
+			path:       "/other",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Method not allowed",
+			method:     http.MethodPost,
+			path:       "/items/1",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := requestsTotal.WithLabelValues("unknown", "4xx")
+			before := testutil.ToFloat64(counter)
+
+			w := httptest.NewRecorder()
+			Metrics(r).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Metrics() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("Metrics() counted %v unmatched requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestStatusWriter_Flush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	sw := newStatusWriter(recorder)
+
+	var w http.ResponseWriter = sw
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatalf("statusWriter must be a http.Flusher")
+	}
+	flusher.Flush()
+
+	if !recorder.Flushed {
+		t.Errorf("Flush() was not called on the wrapped writer")
+	}
+	if sw.Status() != http.StatusOK {
+		t.Errorf("Status() = %v, want %v", sw.Status(), http.StatusOK)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import "net/http"
+
+// statusWriter keeps the status code written by the handler. Handlers that
+// only call Write implicitly respond with 200.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	// disconnected is set when the client went away mid-request.
+	disconnected bool
+}
+
+func newStatusWriter(w http.ResponseWriter) *statusWriter {
+	return &statusWriter{ResponseWriter: w}
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps the streaming responses working through the wrapper.
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+// ClientDisconnected marks the request as dropped by the client, like a
+// connection reset while sending the body, so it isn't counted as an error
+// response. Writers not wrapped by Metrics are ignored.
+func ClientDisconnected(w http.ResponseWriter) {
+	if sw, ok := w.(*statusWriter); ok {
+		sw.disconnected = true
+	}
+}
+
+// Status returns the written status, 200 when nothing was written.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/stone-co/webhook-consumer/pkg/gateways/http"
+
+// Tracing starts a server span for the request, continuing the trace of the
+// caller. The span is named by the route template, and the 5xx responses are
+// errors.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethodKey.String(r.Method), semconv.HTTPRouteKey.String(route)))
+		defer span.End()
+
+		// The status writer of the metrics is kept, so the disconnections
+		// are still marked in it.
+		sw, ok := w.(*statusWriter)
+		if !ok {
+			sw = newStatusWriter(w)
+		}
+
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		status := sw.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if sw.disconnected {
+			span.SetAttributes(attribute.Bool("http.client_disconnected", true))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	r := mux.NewRouter()
+	r.Handle("/items/{id}", Tracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ClientDisconnected(w)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})))
+
+	counter := requestsTotal.WithLabelValues("/items/{id}", disconnectedCode)
+	before := testutil.ToFloat64(counter)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/items/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	Metrics(r).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Tracing() ended %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "POST /items/{id}" {
+		t.Errorf("Tracing() span name = %q, want %q", span.Name(), "POST /items/{id}")
+	}
+	if got := span.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("Tracing() trace id = %s, want the caller's %s", got, traceID)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Tracing() status = %v, want %v", span.Status().Code, codes.Error)
+	}
+
+	// The disconnection is still marked in the status writer of the metrics.
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("Metrics() counted %v disconnected requests, want 1", got)
+	}
+}
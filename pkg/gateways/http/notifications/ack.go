@@ -0,0 +1,130 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Ack is the response to a notification sent successfully.
+type Ack struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// AckResponder builds the success response of the event types whose
+// provider validates the acknowledgment shape, instead of the bare 204.
+type AckResponder interface {
+	Ack(header domain.HeaderNotification) (Ack, error)
+}
+
+// TemplateAckResponder renders the body from a text/template, with the
+// notification header (EventID and EventType) as data.
+type TemplateAckResponder struct {
+	status      int
+	contentType string
+	body        *template.Template
+}
+
+// ackFuncs are available to the ack templates, besides the builtin ones.
+var ackFuncs = template.FuncMap{
+	// json renders a value as JSON, like a string with its quotes.
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewTemplateAckResponder parses the body template. With a JSON content type,
+// the header fields are sent by the client, so they must be rendered with
+// json, like {{json .EventID}}, to keep them from injecting JSON.
+func NewTemplateAckResponder(status int, contentType, body string) (*TemplateAckResponder, error) {
+	tmpl, err := template.New("ack").Funcs(ackFuncs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ack body: %v", err)
+	}
+
+	if strings.Contains(strings.ToLower(contentType), "json") && tmpl.Tree != nil {
+		if err := checkEscaped(tmpl.Tree.Root); err != nil {
+			return nil, fmt.Errorf("ack body: %v", err)
+		}
+	}
+
+	return &TemplateAckResponder{
+		status:      status,
+		contentType: contentType,
+		body:        tmpl,
+	}, nil
+}
+
+func (r TemplateAckResponder) Ack(header domain.HeaderNotification) (Ack, error) {
+	var body bytes.Buffer
+	if err := r.body.Execute(&body, header); err != nil {
+		return Ack{}, fmt.Errorf("rendering ack body: %v", err)
+	}
+
+	return Ack{
+		Status:      r.status,
+		ContentType: r.contentType,
+		Body:        body.Bytes(),
+	}, nil
+}
+
+// checkEscaped requires the json func as the last command of every action
+// rendering a field.
+func checkEscaped(node parse.Node) error {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, n := range node.Nodes {
+			if err := checkEscaped(n); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		if len(node.Pipe.Decl) > 0 || !rendersField(node.Pipe) {
+			return nil
+		}
+		last := node.Pipe.Cmds[len(node.Pipe.Cmds)-1]
+		if ident, ok := last.Args[0].(*parse.IdentifierNode); !ok || ident.Ident != "json" {
+			return fmt.Errorf("%s must be rendered with json, like {{json .EventID}}", node)
+		}
+	case *parse.IfNode:
+		return checkBranch(&node.BranchNode)
+	case *parse.RangeNode:
+		return checkBranch(&node.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(&node.BranchNode)
+	}
+
+	return nil
+}
+
+func checkBranch(node *parse.BranchNode) error {
+	if err := checkEscaped(node.List); err != nil {
+		return err
+	}
+	return checkEscaped(node.ElseList)
+}
+
+// rendersField reports whether the pipeline uses a field of the data.
+func rendersField(pipe *parse.PipeNode) bool {
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch arg.(type) {
+			case *parse.FieldNode, *parse.DotNode, *parse.ChainNode, *parse.VariableNode:
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package notifications
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestHandler_New_AckResponder(t *testing.T) {
+	ack, err := NewTemplateAckResponder(http.StatusOK, "application/json", `{"status":"received","event_id":{{json .EventID}}}`)
+	if err != nil {
+		t.Fatalf("NewTemplateAckResponder() error = %v", err)
+	}
+
+	h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), &fakeUsecase{}, nil, nil)
+	h.SetAckResponder("cash_out_internal_transfer", ack)
+
+	tests := []struct {
+		name       string
+		eventType  string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Event type with ack",
+			eventType:  "cash_out_internal_transfer",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"received","event_id":"930bbd6d"}`,
+		},
+		{
+			name:       "Other event types",
+			eventType:  "cash_in_internal_transfer",
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, tt.eventType)
+			w := httptest.NewRecorder()
+			h.New(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("New() body = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNewTemplateAckResponder(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{
+			name:        "Invalid template",
+			contentType: "text/plain",
+			body:        "{{.EventID",
+			wantErr:     true,
+		},
+		{
+			name:        "Escaped fields",
+			contentType: "application/json",
+			body:        `{"id":{{json .EventID}},"type":{{.EventType | json}}}`,
+		},
+		{
+			name:        "Unescaped field",
+			contentType: "application/json",
+			body:        `{"id":"{{.EventID}}"}`,
+			wantErr:     true,
+		},
+		{
+			name:        "Field escaped by another func",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"id":"{{printf "%s" .EventID}}"}`,
+			wantErr:     true,
+		},
+		{
+			name:        "Unescaped field in a branch",
+			contentType: "application/json",
+			body:        `{{if .EventType}}{"type":"{{.EventType}}"}{{end}}`,
+			wantErr:     true,
+		},
+		{
+			name:        "Unescaped field of another content type",
+			contentType: "text/plain",
+			body:        `received {{.EventID}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTemplateAckResponder(http.StatusOK, tt.contentType, tt.body); (err != nil) != tt.wantErr {
+				t.Errorf("NewTemplateAckResponder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateAckResponder_Ack_Escaped(t *testing.T) {
+	ack, err := NewTemplateAckResponder(http.StatusOK, "application/json", `{"status":"received","event_id":{{json .EventID}}}`)
+	if err != nil {
+		t.Fatalf("NewTemplateAckResponder() error = %v", err)
+	}
+
+	got, err := ack.Ack(domain.HeaderNotification{EventID: `1","status":"forged`})
+	if err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+
+	want := `{"status":"received","event_id":"1\",\"status\":\"forged"}`
+	if string(got.Body) != want {
+		t.Errorf("Ack() body = %s, want %s", got.Body, want)
+	}
+}
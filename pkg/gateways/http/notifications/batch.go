@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// BatchRequest has the notifications, each one decoded on its own, so an
+// invalid item fails alone.
+type BatchRequest struct {
+	Notifications []json.RawMessage `json:"notifications"`
+}
+
+type BatchItemRequest struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// CreatedAt is the event creation timestamp, like the timestamp header.
+	CreatedAt     string `json:"created_at,omitempty"`
+	EncryptedBody string `json:"encrypted_body"`
+}
+
+// BatchResponse has the result of each notification, in the request order.
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult has the status the notification would be answered with
+// alone, and the error message of the failures.
+type BatchItemResult struct {
+	EventID string `json:"event_id,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Receipt string `json:"receipt,omitempty"`
+}
+
+// NewBatch receives several signed notifications at once. Each one is
+// verified, decrypted and sent on its own, in order, and the batch is
+// answered with 207 and the result of each one. The ack responders don't
+// apply to the batches.
+func (h Handler) NewBatch(w http.ResponseWriter, r *http.Request) {
+	// Reject while in maintenance, before any crypto work.
+	if h.maintenance.Enabled() {
+		h.log.Warn("notification batch rejected by the maintenance mode")
+		if retryAfter := h.maintenance.RetryAfter(); retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+		_ = responses.SendError(w, r, "service in maintenance", http.StatusServiceUnavailable)
+		return
+	}
+
+	if h.rateLimited(w, r) {
+		return
+	}
+
+	// Shed the sources failing the verification, before reading the body.
+	source := h.clientIP.Of(r)
+	if scope, left := h.shedding.Shed(source); scope != "" {
+		shedRequests.WithLabelValues(scope).Inc()
+		h.log.Warnf("notification batch from %s shed after repeated verification failures, scope %s", source, scope)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(left.Seconds()))))
+		_ = responses.SendError(w, r, "too many verification failures", http.StatusTooManyRequests)
+		return
+	}
+
+	body, err := readBody(r.Body, h.maxBodySize)
+	if errors.Is(err, ErrBodyTooLarge) {
+		h.log.WithError(err).Error("request body too large")
+		_ = responses.SendError(w, r, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		if isClientDisconnect(err) {
+			h.log.WithError(err).Warn("client disconnected while sending the request body")
+			middleware.ClientDisconnected(w)
+		} else {
+			h.log.WithError(err).Error("unable to read the request body")
+		}
+		_ = responses.SendError(w, r, h.errorMessage("body is incomplete", err), http.StatusBadRequest)
+		return
+	}
+
+	// The body signature covers the whole batch.
+	if err := h.bodySignature.Verify(r, body); err != nil {
+		h.log.WithError(err).Error("invalid body signature")
+		_ = responses.SendError(w, r, h.errorMessage("invalid body signature", err), http.StatusUnauthorized)
+		return
+	}
+
+	var batch BatchRequest
+	if err := decodeBody(body, h.strictJSON, &batch); err != nil {
+		h.log.WithError(err).Error("batch body is empty or has no valid fields")
+		_ = responses.SendError(w, r, h.errorMessage("body is empty or has no valid fields", err), http.StatusBadRequest)
+		return
+	}
+	if len(batch.Notifications) == 0 || len(batch.Notifications) > h.batchMaxItems {
+		h.log.Errorf("batch with %d notifications, must have from 1 to %d", len(batch.Notifications), h.batchMaxItems)
+		_ = responses.SendError(w, r, fmt.Sprintf("the batch must have from 1 to %d notifications", h.batchMaxItems), http.StatusBadRequest)
+		return
+	}
+
+	response := BatchResponse{Results: make([]BatchItemResult, 0, len(batch.Notifications))}
+	for _, item := range batch.Notifications {
+		response.Results = append(response.Results, h.receiveItem(r, source, item))
+	}
+
+	_ = responses.Send(w, response, http.StatusMultiStatus)
+}
+
+// receiveItem checks and sends a notification of the batch, like the
+// notifications route does with a request.
+func (h Handler) receiveItem(r *http.Request, source string, raw json.RawMessage) BatchItemResult {
+	var item BatchItemRequest
+	if err := decodeBody(raw, h.strictJSON, &item); err != nil {
+		h.log.WithError(err).Error("batch notification has no valid fields")
+		return BatchItemResult{Status: http.StatusBadRequest, Error: h.errorMessage("notification has no valid fields", err)}
+	}
+
+	result := BatchItemResult{EventID: item.EventID}
+
+	if err := checkCompactJWS(item.EncryptedBody); err != nil {
+		h.log.WithError(err).Errorf("invalid encrypted body of batch notification %s", item.EventID)
+		result.Status, result.Error = http.StatusBadRequest, h.errorMessage("invalid encrypted body", err)
+		return result
+	}
+
+	eventType := h.eventTypes.Normalize(item.EventType)
+	if item.EventID == "" || eventType == "" {
+		h.log.Error("event_id and event_type are mandatories in the batch notifications")
+		result.Status, result.Error = http.StatusBadRequest, "event_id and event_type are mandatories"
+		return result
+	}
+
+	if err := h.versions.Check(eventType); err != nil {
+		h.log.WithError(err).Errorf("unsupported event type version of batch notification %s", item.EventID)
+		result.Status, result.Error = http.StatusBadRequest, h.errorMessage("unsupported event type version", err)
+		return result
+	}
+
+	input := domain.NotificationInput{
+		Header: domain.HeaderNotification{
+			EventID:   item.EventID,
+			EventType: eventType,
+			CreatedAt: item.CreatedAt,
+		},
+		EncryptedBody: item.EncryptedBody,
+		RawBody:       raw,
+	}
+	if eventType != item.EventType {
+		input.Header.RawEventType = item.EventType
+	}
+
+	output, message, status, err := h.send(r, source, input)
+	if err != nil {
+		result.Status, result.Error = status, h.errorMessage(message, err)
+		return result
+	}
+
+	result.Receipt = output.Receipt
+	switch {
+	case output.Duplicate:
+		result.Status, result.Receipt = http.StatusNoContent, ""
+		h.record(input.Header, tail.OutcomeDuplicate, result.Status)
+	case output.Deferred:
+		result.Status = http.StatusAccepted
+		h.record(input.Header, tail.OutcomeDeferred, result.Status)
+	default:
+		result.Status = h.successStatus
+		if result.Receipt != "" {
+			result.Status = http.StatusOK
+		}
+		h.record(input.Header, tail.OutcomeSuccess, result.Status)
+	}
+
+	return result
+}
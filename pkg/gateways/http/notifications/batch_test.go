@@ -0,0 +1,146 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// eventUsecase answers each event id with its own output or error.
+type eventUsecase struct {
+	outputs map[string]domain.NotificationOutput
+	errs    map[string]error
+	inputs  []domain.NotificationInput
+}
+
+func (u *eventUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, error) {
+	u.inputs = append(u.inputs, input)
+	return u.outputs[input.Header.EventID], u.errs[input.Header.EventID]
+}
+
+func batchItem(eventID, eventType, encryptedBody string) string {
+	return fmt.Sprintf(`{"event_id":%q,"event_type":%q,"encrypted_body":%q}`, eventID, eventType, encryptedBody)
+}
+
+func TestHandler_NewBatch(t *testing.T) {
+	usecase := &eventUsecase{
+		outputs: map[string]domain.NotificationOutput{
+			"duplicate": {Duplicate: true},
+			"deferred":  {Deferred: true},
+			"receipt":   {Receipt: "signed-receipt"},
+		},
+		errs: map[string]error{
+			"forged": fmt.Errorf("verifying: %w", domain.ErrVerificationFailed),
+			"empty":  fmt.Errorf("checking: %w", domain.ErrEmptyPayload),
+		},
+	}
+	h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1 << 16, BatchMaxItems: 10}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+
+	body := `{"notifications":[` + strings.Join([]string{
+		batchItem("sent", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("duplicate", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("deferred", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("receipt", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("forged", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("empty", "cash_out_internal_transfer", "header.payload.signature"),
+		batchItem("not-jose", "cash_out_internal_transfer", "not a jws"),
+		batchItem("", "cash_out_internal_transfer", "header.payload.signature"),
+		`"not an object"`,
+	}, ",") + `]}`
+
+	w := httptest.NewRecorder()
+	h.NewBatch(w, httptest.NewRequest(http.MethodPost, "/notifications/batch", strings.NewReader(body)))
+
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("NewBatch() status = %d, want %d, body %s", w.Code, http.StatusMultiStatus, w.Body.String())
+	}
+
+	var response BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("NewBatch() body = %s, err = %v", w.Body.String(), err)
+	}
+
+	want := []BatchItemResult{
+		{EventID: "sent", Status: http.StatusNoContent},
+		{EventID: "duplicate", Status: http.StatusNoContent},
+		{EventID: "deferred", Status: http.StatusAccepted},
+		{EventID: "receipt", Status: http.StatusOK, Receipt: "signed-receipt"},
+		{EventID: "forged", Status: http.StatusForbidden, Error: "failed to send notification"},
+		{EventID: "empty", Status: http.StatusUnprocessableEntity, Error: "empty payload"},
+		{EventID: "not-jose", Status: http.StatusBadRequest, Error: "invalid encrypted body"},
+		{Status: http.StatusBadRequest, Error: "event_id and event_type are mandatories"},
+		{Status: http.StatusBadRequest, Error: "notification has no valid fields"},
+	}
+	if !reflect.DeepEqual(response.Results, want) {
+		t.Errorf("NewBatch() results = %+v, want %+v", response.Results, want)
+	}
+
+	if len(usecase.inputs) != 6 {
+		t.Fatalf("NewBatch() sent %d notifications, want 6", len(usecase.inputs))
+	}
+	if got := string(usecase.inputs[0].RawBody); got != batchItem("sent", "cash_out_internal_transfer", "header.payload.signature") {
+		t.Errorf("NewBatch() raw body = %s, want the item", got)
+	}
+}
+
+func TestHandler_NewBatch_InvalidBatch(t *testing.T) {
+	item := batchItem("930bbd6d-0c7a-4fe4-8b50-4b82a20cb847", "cash_out_internal_transfer", "header.payload.signature")
+
+	tests := []struct {
+		name        string
+		body        string
+		maintenance bool
+		wantStatus  int
+	}{
+		{
+			name:       "Not JSON",
+			body:       `notifications`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty batch",
+			body:       `{"notifications":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Too many notifications",
+			body:       `{"notifications":[` + item + `,` + item + `,` + item + `]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Maintenance",
+			body:        `{"notifications":[` + item + `]}`,
+			maintenance: true,
+			wantStatus:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1 << 16, BatchMaxItems: 2}, logrus.New(), validator.NewJSONValidator(), usecase, maintenance.New(tt.maintenance, 0), nil)
+
+			w := httptest.NewRecorder()
+			h.NewBatch(w, httptest.NewRequest(http.MethodPost, "/notifications/batch", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("NewBatch() status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if len(usecase.inputs) != 0 {
+				t.Errorf("NewBatch() sent %d notifications, want none", len(usecase.inputs))
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const bodySignaturePrefix = "sha256="
+
+var ErrInvalidBodySignature = errors.New("invalid body signature")
+
+// BodySignature verifies the HMAC-SHA256 of the raw request body, sent in a
+// header as "sha256=<hex>". It's computed over the bytes as received, so any
+// re-encoding of the JSON, like reordered keys or changed whitespace, fails.
+type BodySignature struct {
+	header string
+	secret []byte
+}
+
+// NewBodySignature returns nil when the body signature is disabled.
+func NewBodySignature(header, secret string) (*BodySignature, error) {
+	if header == "" && secret == "" {
+		return nil, nil
+	}
+	if header == "" || secret == "" {
+		return nil, fmt.Errorf("the body signature requires both the header and the secret")
+	}
+
+	return &BodySignature{header: header, secret: []byte(secret)}, nil
+}
+
+// Verify checks the signature of the raw body. A nil body signature accepts
+// all the bodies.
+func (s *BodySignature) Verify(r *http.Request, body []byte) error {
+	if s == nil {
+		return nil
+	}
+
+	value := r.Header.Get(s.header)
+	if !strings.HasPrefix(value, bodySignaturePrefix) {
+		return fmt.Errorf("%w: missing %s header", ErrInvalidBodySignature, s.header)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, bodySignaturePrefix))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBodySignature, err)
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrInvalidBodySignature
+	}
+
+	return nil
+}
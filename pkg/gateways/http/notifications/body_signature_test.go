@@ -0,0 +1,176 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+)
+
+const testBodySignatureHeader = "X-Body-Signature"
+
+func signBody(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewBodySignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		secret  string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "Disabled", wantNil: true},
+		{name: "Enabled", header: testBodySignatureHeader, secret: "secret"},
+		{name: "Missing secret", header: testBodySignatureHeader, wantNil: true, wantErr: true},
+		{name: "Missing header", secret: "secret", wantNil: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBodySignature(tt.header, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBodySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("NewBodySignature() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestBodySignature_Verify(t *testing.T) {
+	body := `{"encrypted_body": "header.payload.signature"}`
+
+	tests := []struct {
+		name      string
+		signature string
+		body      string
+		wantErr   bool
+	}{
+		{
+			name:      "Raw body signature",
+			signature: signBody("secret", body),
+			body:      body,
+		},
+		{
+			name:      "Re-encoded JSON",
+			signature: signBody("secret", body),
+			body:      `{"encrypted_body":"header.payload.signature"}`,
+			wantErr:   true,
+		},
+		{
+			name:      "Other secret",
+			signature: signBody("other", body),
+			body:      body,
+			wantErr:   true,
+		},
+		{
+			name:    "Missing signature",
+			body:    body,
+			wantErr: true,
+		},
+		{
+			name:      "Signature without prefix",
+			signature: strings.TrimPrefix(signBody("secret", body), "sha256="),
+			body:      body,
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid hex",
+			signature: "sha256=zz",
+			body:      body,
+			wantErr:   true,
+		},
+	}
+
+	signature, err := NewBodySignature(testBodySignatureHeader, "secret")
+	if err != nil {
+		t.Fatalf("NewBodySignature() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.signature != "" {
+				r.Header.Set(testBodySignatureHeader, tt.signature)
+			}
+
+			err := signature.Verify(r, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidBodySignature) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidBodySignature)
+			}
+		})
+	}
+}
+
+func TestHandler_New_RawBody(t *testing.T) {
+	// Unusual whitespace and key order, that a re-encoding would change.
+	body := "{ \"extra\" : [1,  2],\n\t\"encrypted_body\":\"header.payload.signature\" }"
+
+	tests := []struct {
+		name       string
+		signature  string
+		wantStatus int
+	}{
+		{
+			name:       "Signed raw body",
+			signature:  signBody("secret", body),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Signature of the re-encoded body",
+			signature:  signBody("secret", `{"encrypted_body":"header.payload.signature","extra":[1,2]}`),
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+			signature, err := NewBodySignature(testBodySignatureHeader, "secret")
+			if err != nil {
+				t.Fatalf("NewBodySignature() error = %v", err)
+			}
+			h.SetBodySignature(signature)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+			r.Header.Set(testBodySignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+			h.New(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("New() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				if len(usecase.inputs) != 0 {
+					t.Errorf("New() must not send an invalid body signature: %v", usecase.inputs)
+				}
+				return
+			}
+
+			// The usecase receives the raw bytes, besides the decoded envelope.
+			if len(usecase.inputs) != 1 || string(usecase.inputs[0].RawBody) != body || usecase.inputs[0].EncryptedBody != "header.payload.signature" {
+				t.Errorf("New() usecase inputs = %v", usecase.inputs)
+			}
+		})
+	}
+}
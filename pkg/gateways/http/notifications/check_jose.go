@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNotJOSE = errors.New("encrypted body isn't a JWS compact serialization")
+	ErrNotJWE  = errors.New("encrypted body isn't a JWE compact serialization")
+)
+
+// checkCompactJWS cheaply rejects the encrypted bodies that can't be a signed
+// JWS, before any crypto work: three non-empty base64url segments separated
+// by dots. A JSON serialization is left to the parser.
+func checkCompactJWS(body string) error {
+	if strings.HasPrefix(body, "{") {
+		return nil
+	}
+
+	segments := strings.Split(body, ".")
+	if len(segments) != 3 {
+		return ErrNotJOSE
+	}
+
+	for _, segment := range segments {
+		if segment == "" || !isBase64URL(segment) {
+			return ErrNotJOSE
+		}
+	}
+
+	return nil
+}
+
+// checkCompactJWE cheaply rejects the unsigned encrypted bodies that can't be
+// a JWE: five base64url segments separated by dots. The encrypted key is empty
+// with direct encryption, and the IV may be, but not the protected header, the
+// ciphertext and the tag. A JSON serialization is left to the parser.
+func checkCompactJWE(body string) error {
+	if strings.HasPrefix(body, "{") {
+		return nil
+	}
+
+	segments := strings.Split(body, ".")
+	if len(segments) != 5 || segments[0] == "" || segments[3] == "" || segments[4] == "" {
+		return ErrNotJWE
+	}
+
+	for _, segment := range segments {
+		if !isBase64URL(segment) {
+			return ErrNotJWE
+		}
+	}
+
+	return nil
+}
+
+// isBase64URL checks the unpadded base64url alphabet.
+func isBase64URL(segment string) bool {
+	for _, c := range segment {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"testing"
+)
+
+func Test_checkCompactJWS(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "Compact JWS",
+			body: "eyJhbGciOiJQUzI1NiJ9.ZXlKaGJHY2lPaUpTVTBFdFQwRkZVQzB5TlRZaWZR.c2lnbmF0dXJl-_",
+		},
+		{
+			name: "JSON serialization",
+			body: `{"payload":"cGF5bG9hZA","signatures":[]}`,
+		},
+		{
+			name:    "Empty",
+			body:    "",
+			wantErr: true,
+		},
+		{
+			name:    "Whitespace",
+			body:    "   ",
+			wantErr: true,
+		},
+		{
+			name:    "Not JOSE",
+			body:    "not a jose value",
+			wantErr: true,
+		},
+		{
+			name:    "Two segments",
+			body:    "header.payload",
+			wantErr: true,
+		},
+		{
+			name:    "JWE compact serialization",
+			body:    "header.key.iv.ciphertext.tag",
+			wantErr: true,
+		},
+		{
+			name:    "Empty signature",
+			body:    "header.payload.",
+			wantErr: true,
+		},
+		{
+			name:    "Padded segment",
+			body:    "header.payload==.signature",
+			wantErr: true,
+		},
+		{
+			name:    "Standard base64 alphabet",
+			body:    "header.pay+load/.signature",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkCompactJWS(tt.body); (err != nil) != tt.wantErr {
+				t.Errorf("checkCompactJWS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_checkCompactJWE(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "Compact JWE",
+			body: "eyJhbGciOiJSU0EtT0FFUC0yNTYifQ.a2V5.aXY.Y2lwaGVydGV4dA.dGFn",
+		},
+		{
+			name: "Direct encryption, without the encrypted key",
+			body: "eyJhbGciOiJkaXIifQ..aXY.Y2lwaGVydGV4dA.dGFn",
+		},
+		{
+			name: "JSON serialization",
+			body: `{"protected":"eyJhbGciOiJkaXIifQ","ciphertext":"Y2lwaGVydGV4dA"}`,
+		},
+		{
+			name:    "Empty",
+			body:    "",
+			wantErr: true,
+		},
+		{
+			name:    "JWS compact serialization",
+			body:    "header.payload.signature",
+			wantErr: true,
+		},
+		{
+			name:    "Empty ciphertext",
+			body:    "header.key.iv..tag",
+			wantErr: true,
+		},
+		{
+			name:    "Empty tag",
+			body:    "header.key.iv.ciphertext.",
+			wantErr: true,
+		},
+		{
+			name:    "Standard base64 alphabet",
+			body:    "header.key.iv.cipher+text/.tag",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkCompactJWE(tt.body); (err != nil) != tt.wantErr {
+				t.Errorf("checkCompactJWE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+)
+
+const formContentType = "application/x-www-form-urlencoded"
+
+var ErrTrailingData = errors.New("unexpected data after the JSON object")
+
+// isFormBody is true when the envelope is form encoded, like in the legacy
+// integrations. Any other content type is decoded as JSON.
+func isFormBody(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == formContentType
+}
+
+// decodeFormBody decodes the form encoded envelope. The strict mode also
+// rejects the unknown and repeated fields.
+func decodeFormBody(body []byte, strict bool, v *NotificationRequest) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+
+	if strict {
+		for field, fieldValues := range values {
+			if field != "encrypted_body" {
+				return fmt.Errorf("unknown form field %q", field)
+			}
+			if len(fieldValues) > 1 {
+				return fmt.Errorf("repeated form field %q", field)
+			}
+		}
+	}
+
+	v.EncryptedBody = values.Get("encrypted_body")
+	return nil
+}
+
+// decodeBody decodes the request envelope. The strict mode also rejects the
+// unknown fields and any data after the JSON object.
+func decodeBody(body []byte, strict bool, v interface{}) error {
+	if !strict {
+		return json.Unmarshal(body, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return ErrTrailingData
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package notifications
+
+import (
+	"testing"
+)
+
+func Test_decodeBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		strict  bool
+		wantErr bool
+	}{
+		{
+			name: "Valid body",
+			body: `{"encrypted_body":"header.payload.signature"}`,
+		},
+		{
+			name:   "Valid body in strict mode",
+			body:   `{"encrypted_body":"header.payload.signature"}` + "\n",
+			strict: true,
+		},
+		{
+			name: "Unknown fields",
+			body: `{"encrypted_body":"header.payload.signature","extra":1}`,
+		},
+		{
+			name:    "Unknown fields in strict mode",
+			body:    `{"encrypted_body":"header.payload.signature","extra":1}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing data",
+			body:    `{"encrypted_body":"header.payload.signature"}garbage`,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing object in strict mode",
+			body:    `{"encrypted_body":"header.payload.signature"}{}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing data in strict mode",
+			body:    `{"encrypted_body":"header.payload.signature"} garbage`,
+			strict:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request NotificationRequest
+			if err := decodeBody([]byte(tt.body), tt.strict, &request); (err != nil) != tt.wantErr {
+				t.Errorf("decodeBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_isFormBody(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "application/x-www-form-urlencoded", want: true},
+		{contentType: "application/x-www-form-urlencoded; charset=utf-8", want: true},
+		{contentType: "Application/X-WWW-Form-Urlencoded", want: true},
+		{contentType: "application/json", want: false},
+		{contentType: "", want: false},
+		{contentType: "invalid;;", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := isFormBody(tt.contentType); got != tt.want {
+				t.Errorf("isFormBody() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeFormBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		strict  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Valid body",
+			body: "encrypted_body=header.payload.signature",
+			want: "header.payload.signature",
+		},
+		{
+			name: "Escaped value",
+			body: "encrypted_body=header%2Epayload.signature",
+			want: "header.payload.signature",
+		},
+		{
+			name: "Unknown fields",
+			body: "encrypted_body=header.payload.signature&extra=1",
+			want: "header.payload.signature",
+		},
+		{
+			name:    "Unknown fields in strict mode",
+			body:    "encrypted_body=header.payload.signature&extra=1",
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "Repeated field in strict mode",
+			body:    "encrypted_body=header.payload.signature&encrypted_body=other",
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "Valid body in strict mode",
+			body:   "encrypted_body=header.payload.signature",
+			strict: true,
+			want:   "header.payload.signature",
+		},
+		{
+			name:    "Invalid escape",
+			body:    "encrypted_body=%zz",
+			wantErr: true,
+		},
+		{
+			name: "Missing field",
+			body: "other=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got NotificationRequest
+			err := decodeFormBody([]byte(tt.body), tt.strict, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeFormBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.EncryptedBody != tt.want {
+				t.Errorf("decodeFormBody() = %q, want %q", got.EncryptedBody, tt.want)
+			}
+		})
+	}
+}
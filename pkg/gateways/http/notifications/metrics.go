@@ -0,0 +1,28 @@
+package notifications
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	verificationFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_consumer_verification_failures_total",
+		Help: "Number of notifications failing the signature verification or the decryption.",
+	})
+
+	receivedNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_received_notifications_total",
+		Help: "Number of notifications answered after being processed, by event type, outcome and status code.",
+	}, []string{"event_type", "outcome", "code"})
+
+	shedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_shed_requests_total",
+		Help: "Number of requests shed after repeated verification failures, by scope.",
+	}, []string{"scope"})
+
+	rateLimitedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_rate_limited_requests_total",
+		Help: "Number of requests rejected by the rate limits, by scope.",
+	}, []string{"scope"})
+)
@@ -1,11 +1,16 @@
 package notifications
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
 	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
 )
 
@@ -18,43 +23,287 @@ type NotificationRequest struct {
 	EncryptedBody string `json:"encrypted_body" validate:"required"`
 }
 
+type ReceiptResponse struct {
+	Receipt string `json:"receipt"`
+}
+
+// New receives the notifications signed by the provider, whose encrypted body
+// is a JWS wrapping the JWE.
 func (h Handler) New(w http.ResponseWriter, r *http.Request) {
-	// Decode request body.
+	h.receive(w, r, false)
+}
+
+// NewUnsigned receives the notifications whose encrypted body is the JWE
+// alone. Only its route skips the signature verification.
+func (h Handler) NewUnsigned(w http.ResponseWriter, r *http.Request) {
+	h.receive(w, r, true)
+}
+
+func (h Handler) receive(w http.ResponseWriter, r *http.Request, unsigned bool) {
+	// Reject while in maintenance, before any crypto work.
+	if h.maintenance.Enabled() {
+		h.log.Warn("notification rejected by the maintenance mode")
+		if retryAfter := h.maintenance.RetryAfter(); retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+		_ = responses.SendError(w, r, "service in maintenance", http.StatusServiceUnavailable)
+		return
+	}
+
+	if h.rateLimited(w, r) {
+		return
+	}
+
+	// Shed the sources failing the verification, before reading the body.
+	source := h.clientIP.Of(r)
+	if scope, left := h.shedding.Shed(source); scope != "" {
+		shedRequests.WithLabelValues(scope).Inc()
+		h.log.Warnf("notification from %s shed after repeated verification failures, scope %s", source, scope)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(left.Seconds()))))
+		_ = responses.SendError(w, r, "too many verification failures", http.StatusTooManyRequests)
+		return
+	}
+
+	// Read the whole request body, chunked or not, up to the limit.
+	body, err := readBody(r.Body, h.maxBodySize)
+	if errors.Is(err, ErrBodyTooLarge) {
+		h.log.WithError(err).Error("request body too large")
+		_ = responses.SendError(w, r, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil && isClientDisconnect(err) {
+		// Not an application error, the client may not even read the response.
+		h.log.WithError(err).Warn("client disconnected while sending the request body")
+		middleware.ClientDisconnected(w)
+		_ = responses.SendError(w, r, h.errorMessage("body is incomplete", err), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.log.WithError(err).Error("unable to read the request body")
+		_ = responses.SendError(w, r, h.errorMessage("body is incomplete", err), http.StatusBadRequest)
+		return
+	}
+
+	// The health probes are unsigned, so they're answered before decoding.
+	if h.isProbe(r, body) {
+		h.Probe(w, r)
+		return
+	}
+
+	// The body signature covers the raw bytes, so it's checked before decoding.
+	if err := h.bodySignature.Verify(r, body); err != nil {
+		h.log.WithError(err).Error("invalid body signature")
+		_ = responses.SendError(w, r, h.errorMessage("invalid body signature", err), http.StatusUnauthorized)
+		return
+	}
+
+	// Decode request body, form encoded or JSON.
 	var encryptedBody NotificationRequest
-	if err := json.NewDecoder(r.Body).Decode(&encryptedBody); err != nil {
+	if isFormBody(r.Header.Get("Content-Type")) {
+		err = decodeFormBody(body, h.strictJSON, &encryptedBody)
+	} else {
+		err = decodeBody(body, h.strictJSON, &encryptedBody)
+	}
+	if err != nil {
 		h.log.WithError(err).Error("body is empty or has no valid fields")
-		_ = responses.SendError(w, "body is empty or has no valid fields", http.StatusBadRequest)
+		_ = responses.SendError(w, r, h.errorMessage("body is empty or has no valid fields", err), http.StatusBadRequest)
 		return
 	}
 
 	// Validate request body.
 	if err := h.Validate(encryptedBody); err != nil {
 		h.log.WithError(err).Error("invalid request body")
-		_ = responses.SendError(w, err.Error(), http.StatusBadRequest)
+		_ = responses.SendError(w, r, h.errorMessage("invalid request body", err), http.StatusBadRequest)
+		return
+	}
+
+	// Reject the values that can't be a JWS, or a JWE when unsigned, before
+	// any crypto work.
+	checkCompact := checkCompactJWS
+	if unsigned {
+		checkCompact = checkCompactJWE
+	}
+	if err := checkCompact(encryptedBody.EncryptedBody); err != nil {
+		h.log.WithError(err).Error("invalid encrypted body")
+		_ = responses.SendError(w, r, h.errorMessage("invalid encrypted body", err), http.StatusBadRequest)
 		return
 	}
 
+	// The event type is normalized before any use, the received one is kept.
+	rawEventType := r.Header.Get(EventTypeHeader)
+	eventType := h.eventTypes.Normalize(rawEventType)
+
 	// Check for mandatory headers.
-	if r.Header.Get(EventIDHeader) == "" || r.Header.Get(EventTypeHeader) == "" {
+	if r.Header.Get(EventIDHeader) == "" || eventType == "" {
 		h.log.Errorf("%s and %s headers are mandatories", EventIDHeader, EventTypeHeader)
-		_ = responses.SendError(w, fmt.Sprintf("%s and %s headers are mandatories", EventIDHeader, EventTypeHeader), http.StatusBadRequest)
+		_ = responses.SendError(w, r, fmt.Sprintf("%s and %s headers are mandatories", EventIDHeader, EventTypeHeader), http.StatusBadRequest)
+		return
+	}
+
+	// Reject the unsupported event versions, before any crypto work.
+	if err := h.versions.Check(eventType); err != nil {
+		h.log.WithError(err).Error("unsupported event type version")
+		_ = responses.SendError(w, r, h.errorMessage("unsupported event type version", err), http.StatusBadRequest)
 		return
 	}
 
 	input := domain.NotificationInput{
 		Header: domain.HeaderNotification{
 			EventID:   r.Header.Get(EventIDHeader),
-			EventType: r.Header.Get(EventTypeHeader),
+			EventType: eventType,
 		},
 		EncryptedBody: encryptedBody.EncryptedBody,
+		RawBody:       body,
+		Unsigned:      unsigned,
+	}
+	if eventType != rawEventType {
+		input.Header.RawEventType = rawEventType
+	}
+	if h.timestampHeader != "" {
+		input.Header.CreatedAt = r.Header.Get(h.timestampHeader)
 	}
 
 	// Call the usecase.
-	if err := h.usecase.SendNotification(r.Context(), input); err != nil {
+	output, message, status, err := h.send(r, source, input)
+	if err != nil {
+		_ = responses.SendError(w, r, h.errorMessage(message, err), status)
+		return
+	}
+
+	// The duplicates are always acked with an empty body, without a receipt.
+	if output.Duplicate {
+		h.record(input.Header, tail.OutcomeDuplicate, http.StatusNoContent)
+		_ = responses.Send(w, nil, http.StatusNoContent)
+		return
+	}
+
+	if responder, ok := h.acks[input.Header.EventType]; ok && !output.Deferred {
+		h.sendAck(w, r, responder, input.Header)
+		return
+	}
+
+	// The receipt, when enabled, is the response body.
+	var response interface{}
+	if output.Receipt != "" {
+		response = ReceiptResponse{Receipt: output.Receipt}
+	}
+
+	if output.Deferred {
+		h.record(input.Header, tail.OutcomeDeferred, http.StatusAccepted)
+		_ = responses.Send(w, response, http.StatusAccepted)
+		return
+	}
+
+	status = h.successStatus
+	if response != nil {
+		status = http.StatusOK
+	}
+
+	h.record(input.Header, tail.OutcomeSuccess, status)
+	_ = responses.Send(w, response, status)
+}
+
+// send calls the usecase, updating the shedding of the source. A failure is
+// recorded, and returned with the message and status of its category.
+func (h Handler) send(r *http.Request, source string, input domain.NotificationInput) (domain.NotificationOutput, string, int, error) {
+	output, err := h.usecase.SendNotification(r.Context(), input)
+	if output.Verified {
+		// A verified notification proves the source, even if it fails later,
+		// and only then is its event type admitted in the metric labels.
+		h.shedding.Succeeded(source)
+		h.eventTypeLabels.Admit(input.Header.EventType)
+	}
+	if errors.Is(err, domain.ErrVerificationFailed) {
+		verificationFailures.Inc()
+		if scope := h.shedding.Failed(source); scope != "" {
+			h.log.Errorf("shedding the notifications, scope %s, after repeated verification failures from %s", scope, source)
+		}
+	}
+	if err != nil {
 		h.log.WithError(err).Error("failed to send notification")
-		_ = responses.SendError(w, "failed to send notification", http.StatusForbidden)
+
+		message, status := "failed to send notification", http.StatusForbidden
+		switch {
+		case errors.Is(err, domain.ErrArchiveFailed):
+			message, status = "failed to archive notification", http.StatusInternalServerError
+		case errors.Is(err, domain.ErrPayloadMismatch):
+			message, status = "notification headers don't match the payload", http.StatusBadRequest
+		case errors.Is(err, domain.ErrEmptyPayload):
+			message, status = "empty payload", http.StatusUnprocessableEntity
+		case errors.Is(err, domain.ErrUntrustedCertificate):
+			message, status = "untrusted certificate chain", http.StatusUnauthorized
+		case errors.Is(err, domain.ErrUnsupportedCritical):
+			message, status = "unsupported critical header parameter", http.StatusBadRequest
+		case errors.Is(err, domain.ErrTooManyIterations):
+			message, status = "PBES2 iteration count too large", http.StatusBadRequest
+		case errors.Is(err, domain.ErrDecryptLimit):
+			message, status = "payload too large to decrypt", http.StatusBadRequest
+		case errors.Is(err, domain.ErrUnauthorizedContent):
+			message, status = "notification not authorized", http.StatusForbidden
+		case errors.Is(err, domain.ErrFieldTooLong):
+			// The field and its limit help the provider, and aren't secret.
+			message, status = "payload field too long", http.StatusUnprocessableEntity
+			var fieldErr *domain.FieldTooLongError
+			if errors.As(err, &fieldErr) {
+				message = fieldErr.Error()
+			}
+		case errors.Is(err, domain.ErrInvalidSchema):
+			// The failed keywords help the provider, and have no values.
+			message, status = "payload doesn't match its schema", http.StatusUnprocessableEntity
+			var schemaErr *domain.SchemaError
+			if errors.As(err, &schemaErr) {
+				message = schemaErr.Error()
+			}
+		case errors.Is(err, domain.ErrOrderingBusy):
+			message, status = "notifications with the same ordering key are still being sent", http.StatusServiceUnavailable
+		}
+
+		h.record(input.Header, tail.OutcomeFailure, status)
+		return output, message, status, err
+	}
+
+	return output, "", 0, nil
+}
+
+// errorMessage returns the stable message of the error category, with the
+// error details only when the internal errors are exposed.
+func (h Handler) errorMessage(message string, err error) string {
+	if !h.exposeInternalErrors {
+		return message
+	}
+
+	return fmt.Sprintf("%s: %v", message, err)
+}
+
+// sendAck writes the acknowledgment expected by the provider. The
+// notification was already sent, so a failure to build the ack is answered
+// with the default success status.
+func (h Handler) sendAck(w http.ResponseWriter, r *http.Request, responder AckResponder, header domain.HeaderNotification) {
+	ack, err := responder.Ack(header)
+	if err != nil {
+		h.log.WithError(err).Errorf("unable to build the ack of notification %s", header.EventID)
+		h.record(header, tail.OutcomeSuccess, h.successStatus)
+		_ = responses.Send(w, nil, h.successStatus)
 		return
 	}
 
-	_ = responses.Send(w, nil, http.StatusNoContent)
+	if ack.ContentType != "" {
+		w.Header().Set("Content-Type", ack.ContentType)
+	}
+	w.WriteHeader(ack.Status)
+	_, _ = w.Write(ack.Body)
+
+	h.record(header, tail.OutcomeSuccess, ack.Status)
+}
+
+// record adds the processed notification to the tail, without its payload.
+func (h Handler) record(header domain.HeaderNotification, outcome string, status int) {
+	receivedNotifications.WithLabelValues(h.eventTypeLabels.Known(header.EventType), outcome, strconv.Itoa(status)).Inc()
+	h.events.Add(tail.Event{
+		EventID:   header.EventID,
+		EventType: header.EventType,
+		Outcome:   outcome,
+		Status:    status,
+		Timestamp: time.Now(),
+	})
 }
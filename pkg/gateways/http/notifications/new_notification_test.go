@@ -0,0 +1,907 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventtype"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventversion"
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
+)
+
+type fakeUsecase struct {
+	mu     sync.Mutex
+	inputs []domain.NotificationInput
+	output domain.NotificationOutput
+	err    error
+}
+
+func (f *fakeUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.inputs = append(f.inputs, input)
+	return f.output, f.err
+}
+
+// testServer keeps the last request received, as seen by the server.
+type testServer struct {
+	*httptest.Server
+	handler     *Handler
+	lastRequest *http.Request
+}
+
+func newTestServer(t *testing.T, cfg configuration.HTTPConfig, usecase domain.NotificationUsecase, mode *maintenance.Mode) *testServer {
+	t.Helper()
+
+	if cfg.MaxBodySize == 0 {
+		cfg.MaxBodySize = 1024
+	}
+
+	h := NewHandler(cfg, logrus.New(), validator.NewJSONValidator(), usecase, mode, nil)
+	srv := &testServer{handler: h}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.lastRequest = r
+		h.New(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func postNotification(t *testing.T, url string, body io.Reader) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, "930bbd6d-0c7a-4fe4-8b50-4b82a20cb847")
+	req.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("sending request: %v", err)
+	}
+	resp.Body.Close()
+
+	return resp
+}
+
+// chunkedReader hides the body length, so the client uses chunked encoding.
+type chunkedReader struct {
+	io.Reader
+}
+
+func TestHandler_New_ChunkedBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Chunked body is read",
+			body:       `{"encrypted_body":"header.payload.signature"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Chunked body over the limit",
+			body:       `{"encrypted_body":"` + strings.Repeat("a", 2048) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+
+			resp := postNotification(t, srv.URL, chunkedReader{strings.NewReader(tt.body)})
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if srv.lastRequest.ContentLength != -1 || len(srv.lastRequest.TransferEncoding) != 1 {
+				t.Errorf("New() request was not chunked: %v", srv.lastRequest.TransferEncoding)
+			}
+			if tt.wantStatus == http.StatusNoContent && (len(usecase.inputs) != 1 || usecase.inputs[0].EncryptedBody != "header.payload.signature") {
+				t.Errorf("New() usecase inputs = %v", usecase.inputs)
+			}
+		})
+	}
+}
+
+func TestHandler_New_TruncatedChunkedBody(t *testing.T) {
+	usecase := &fakeUsecase{}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+	defer conn.Close()
+
+	// The chunk announces more bytes than sent, and the final chunk is missing.
+	body := `{"encrypted_body":"header.payload.signature"}`
+	fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: test\r\nTransfer-Encoding: chunked\r\n"+
+		"%s: 1\r\n%s: type\r\n\r\n%x\r\n%s", EventIDHeader, EventTypeHeader, len(body)+10, body)
+	if err := conn.(*net.TCPConn).CloseWrite(); err != nil {
+		t.Fatalf("closing write: %v", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusBadRequest)
+	}
+	if len(usecase.inputs) != 0 {
+		t.Errorf("New() incomplete body must not be sent: %v", usecase.inputs)
+	}
+}
+
+func TestHandler_New_DeferredNotification(t *testing.T) {
+	usecase := &fakeUsecase{output: domain.NotificationOutput{Deferred: true}}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+
+	resp := postNotification(t, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusAccepted)
+	}
+}
+
+func TestHandler_New_DuplicateNotification(t *testing.T) {
+	// The duplicates are acked with 204, even with a receipt or another
+	// success status.
+	usecase := &fakeUsecase{output: domain.NotificationOutput{Duplicate: true, Receipt: "receipt"}}
+	srv := newTestServer(t, configuration.HTTPConfig{SuccessStatus: http.StatusOK}, usecase, nil)
+
+	resp := postNotification(t, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestHandler_New_Maintenance(t *testing.T) {
+	usecase := &fakeUsecase{}
+	mode := maintenance.New(true, 2*time.Minute)
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, mode)
+	body := `{"encrypted_body":"header.payload.signature"}`
+
+	resp := postNotification(t, srv.URL, strings.NewReader(body))
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "120" {
+		t.Errorf("New() Retry-After = %v, want 120", got)
+	}
+	if len(usecase.inputs) != 0 {
+		t.Errorf("New() must not send in maintenance: %v", usecase.inputs)
+	}
+
+	mode.Set(false)
+
+	resp = postNotification(t, srv.URL, strings.NewReader(body))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+	if len(usecase.inputs) != 1 {
+		t.Errorf("New() must send after the maintenance: %v", usecase.inputs)
+	}
+}
+
+func TestHandler_New_NotJOSE(t *testing.T) {
+	for _, encryptedBody := range []string{" ", "not a jose value", "header.payload"} {
+		t.Run(encryptedBody, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+
+			body, err := json.Marshal(NotificationRequest{EncryptedBody: encryptedBody})
+			if err != nil {
+				t.Fatalf("marshaling body: %v", err)
+			}
+
+			resp := postNotification(t, srv.URL, strings.NewReader(string(body)))
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("New() status = %v, want %v", resp.StatusCode, http.StatusBadRequest)
+			}
+			if len(usecase.inputs) != 0 {
+				t.Errorf("New() must not send a non JOSE body: %v", usecase.inputs)
+			}
+		})
+	}
+}
+
+func TestHandler_NewUnsigned(t *testing.T) {
+	tests := []struct {
+		name          string
+		unsigned      bool
+		encryptedBody string
+		wantStatus    int
+	}{
+		{name: "Signed route with a JWS", encryptedBody: "header.payload.signature", wantStatus: http.StatusNoContent},
+		{name: "Signed route with a JWE", encryptedBody: "header.key.iv.ciphertext.tag", wantStatus: http.StatusBadRequest},
+		{name: "Unsigned route with a JWE", unsigned: true, encryptedBody: "header.key.iv.ciphertext.tag", wantStatus: http.StatusNoContent},
+		{name: "Unsigned route with a JWS", unsigned: true, encryptedBody: "header.payload.signature", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+			handler := h.New
+			if tt.unsigned {
+				handler = h.NewUnsigned
+			}
+			srv := httptest.NewServer(http.HandlerFunc(handler))
+			defer srv.Close()
+
+			body, err := json.Marshal(NotificationRequest{EncryptedBody: tt.encryptedBody})
+			if err != nil {
+				t.Fatalf("marshaling body: %v", err)
+			}
+
+			resp := postNotification(t, srv.URL, strings.NewReader(string(body)))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				return
+			}
+			if len(usecase.inputs) != 1 || usecase.inputs[0].Unsigned != tt.unsigned {
+				t.Errorf("inputs = %+v, want one with unsigned %t", usecase.inputs, tt.unsigned)
+			}
+		})
+	}
+}
+
+func TestHandler_New_NormalizedEventType(t *testing.T) {
+	usecase := &fakeUsecase{}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	normalizer, err := eventtype.New(configuration.EventTypeConfig{Lowercase: true, Trim: true, Separators: "-", Separator: "."})
+	if err != nil {
+		t.Fatalf("eventtype.New() error = %v", err)
+	}
+	srv.handler.SetEventTypeNormalizer(normalizer)
+
+	body, err := json.Marshal(NotificationRequest{EncryptedBody: "header.payload.signature"})
+	if err != nil {
+		t.Fatalf("marshaling body: %v", err)
+	}
+
+	eventTypes := []string{"payment.created", "Payment.Created", " PAYMENT-CREATED"}
+	for _, eventType := range eventTypes {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		req.Header.Set(EventIDHeader, "930bbd6d")
+		req.Header.Set(EventTypeHeader, eventType)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(usecase.inputs) != len(eventTypes) {
+		t.Fatalf("usecase called %d times, want %d", len(usecase.inputs), len(eventTypes))
+	}
+	// The raw event type is only kept when changed. The HTTP server already
+	// trims the header values.
+	wantRaw := []string{"", "Payment.Created", "PAYMENT-CREATED"}
+	for i, input := range usecase.inputs {
+		if input.Header.EventType != "payment.created" || input.Header.RawEventType != wantRaw[i] {
+			t.Errorf("event type = %q, raw %q, want payment.created, raw %q", input.Header.EventType, input.Header.RawEventType, wantRaw[i])
+		}
+	}
+}
+
+func TestHandler_New_UsecaseErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "Empty payload",
+			err:        fmt.Errorf("checking: %w", domain.ErrEmptyPayload),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "Payload mismatch",
+			err:        domain.ErrPayloadMismatch,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unsupported critical parameter",
+			err:        fmt.Errorf("verifying: %w", domain.ErrUnsupportedCritical),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Too many PBES2 iterations",
+			err:        fmt.Errorf("decoding: %w", domain.ErrTooManyIterations),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Decryption limit exceeded",
+			err:        fmt.Errorf("decoding: %w", domain.ErrDecryptLimit),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Untrusted certificate chain",
+			err:        fmt.Errorf("verifying: %w", domain.ErrUntrustedCertificate),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Unauthorized content",
+			err:        fmt.Errorf("authorizing: %w", domain.ErrUnauthorizedContent),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Payload field too long",
+			err:        fmt.Errorf("checking: %w", &domain.FieldTooLongError{Path: "description", MaxLength: 5, Length: 8}),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "Payload doesn't match its schema",
+			err:        fmt.Errorf("checking: %w", &domain.SchemaError{Schema: "cash_in_*", Errors: []string{"$: missing required property amount"}}),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "Ordering key busy",
+			err:        fmt.Errorf("ordering: %w", domain.ErrOrderingBusy),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "Archive failed",
+			err:        domain.ErrArchiveFailed,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "Other errors",
+			err:        fmt.Errorf("invalid signature"),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, configuration.HTTPConfig{}, &fakeUsecase{err: tt.err}, nil)
+
+			resp := postNotification(t, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_New_FieldTooLong(t *testing.T) {
+	err := fmt.Errorf("checking: %w", &domain.FieldTooLongError{Path: "description", MaxLength: 5, Length: 8})
+	h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), &fakeUsecase{err: err}, nil, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+	r.Header.Set(EventIDHeader, "930bbd6d")
+	r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+	r.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	h.New(w, r)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("New() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+	if want := "field description has 8 characters, more than the maximum of 5\n"; w.Body.String() != want {
+		t.Errorf("New() body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestHandler_New_RecordsTail(t *testing.T) {
+	events := tail.New(5)
+	usecase := &fakeUsecase{}
+	h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, events)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+	r.Header.Set(EventIDHeader, "930bbd6d")
+	r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+	h.New(httptest.NewRecorder(), r)
+
+	got := events.Recent()
+	if len(got) != 1 {
+		t.Fatalf("New() recorded %d events, want 1", len(got))
+	}
+	if got[0].EventID != "930bbd6d" || got[0].Outcome != tail.OutcomeSuccess || got[0].Status != http.StatusNoContent {
+		t.Errorf("New() recorded %+v", got[0])
+	}
+}
+
+func TestHandler_New_StrictJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Unknown fields",
+			body:       `{"encrypted_body":"header.payload.signature","extra":1}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Unknown fields in strict mode",
+			strict:     true,
+			body:       `{"encrypted_body":"header.payload.signature","extra":1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Trailing data in strict mode",
+			strict:     true,
+			body:       `{"encrypted_body":"header.payload.signature"}{"encrypted_body":"other"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Valid body in strict mode",
+			strict:     true,
+			body:       `{"encrypted_body":"header.payload.signature"}`,
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, configuration.HTTPConfig{StrictJSON: tt.strict}, &fakeUsecase{}, nil)
+
+			resp := postNotification(t, srv.URL, strings.NewReader(tt.body))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_New_Receipt(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     domain.NotificationOutput
+		wantStatus int
+	}{
+		{
+			name:       "Sent",
+			output:     domain.NotificationOutput{Receipt: "receipt.token.signature"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Deferred",
+			output:     domain.NotificationOutput{Deferred: true, Receipt: "receipt.token.signature"},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), &fakeUsecase{output: tt.output}, nil, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+			w := httptest.NewRecorder()
+			h.New(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			var got ReceiptResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("New() invalid body: %v", err)
+			}
+			if got.Receipt != tt.output.Receipt {
+				t.Errorf("New() receipt = %v, want %v", got.Receipt, tt.output.Receipt)
+			}
+		})
+	}
+}
+
+func TestHandler_New_ExposeInternalErrors(t *testing.T) {
+	const detail = "go-jose/go-jose: error in cryptographic primitive"
+
+	tests := []struct {
+		name       string
+		expose     bool
+		wantDetail bool
+	}{
+		{
+			name: "Internal errors are hidden by default",
+		},
+		{
+			name:       "Internal errors are exposed",
+			expose:     true,
+			wantDetail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			cfg := configuration.HTTPConfig{MaxBodySize: 1024, ExposeInternalErrors: tt.expose}
+			h := NewHandler(cfg, log, validator.NewJSONValidator(), &fakeUsecase{err: fmt.Errorf("invalid signature: %s", detail)}, nil, nil)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+			w := httptest.NewRecorder()
+			h.New(w, r)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("New() status = %v, want %v", w.Code, http.StatusForbidden)
+			}
+			if got := strings.Contains(w.Body.String(), detail); got != tt.wantDetail {
+				t.Errorf("New() body = %v, want detail %v", w.Body.String(), tt.wantDetail)
+			}
+
+			entry := hook.LastEntry()
+			if entry == nil || !strings.Contains(fmt.Sprint(entry.Data[logrus.ErrorKey]), detail) {
+				t.Errorf("New() must log the error detail: %v", entry)
+			}
+		})
+	}
+}
+
+func TestHandler_New_SuccessStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		successStatus int
+		wantStatus    int
+	}{
+		{
+			name:       "Default success status",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:          "No content",
+			successStatus: http.StatusNoContent,
+			wantStatus:    http.StatusNoContent,
+		},
+		{
+			name:          "OK with an empty body",
+			successStatus: http.StatusOK,
+			wantStatus:    http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, configuration.HTTPConfig{SuccessStatus: tt.successStatus}, &fakeUsecase{}, nil)
+
+			resp := postNotification(t, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("reading body: %v", err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if len(body) != 0 {
+				t.Errorf("New() body = %q, want empty", body)
+			}
+		})
+	}
+}
+
+func TestCheckSuccessStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr bool
+	}{
+		{status: http.StatusNoContent},
+		{status: http.StatusOK},
+		{status: http.StatusCreated, wantErr: true},
+		{status: http.StatusFound, wantErr: true},
+		{status: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if err := CheckSuccessStatus(tt.status); (err != nil) != tt.wantErr {
+				t.Errorf("CheckSuccessStatus(%d) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandler_New_EventVersion(t *testing.T) {
+	policy, err := eventversion.New(configuration.EventVersionConfig{Check: true, Pattern: "[.]v([0-9]+([.][0-9]+)?)$", MinVersion: "1", MaxVersion: "2"})
+	if err != nil {
+		t.Fatalf("eventversion.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		eventType  string
+		wantStatus int
+	}{
+		{
+			name:       "Supported version",
+			eventType:  "payment.created.v2",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Too new version",
+			eventType:  "payment.created.v3",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unparseable version",
+			eventType:  "payment.created",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{}
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+			h.SetEventVersionPolicy(policy)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v0/notifications", strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+			req.Header.Set(EventIDHeader, "930bbd6d-0c7a-4fe4-8b50-4b82a20cb847")
+			req.Header.Set(EventTypeHeader, tt.eventType)
+			w := httptest.NewRecorder()
+
+			h.New(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if sent := len(usecase.inputs) == 1; sent != (tt.wantStatus == http.StatusNoContent) {
+				t.Errorf("New() sent = %v, want only the supported versions", sent)
+			}
+		})
+	}
+}
+
+func TestHandler_New_FormBody(t *testing.T) {
+	send := func(t *testing.T, cfg configuration.HTTPConfig, contentType, body string) (int, []domain.NotificationInput) {
+		usecase := &fakeUsecase{}
+		srv := newTestServer(t, cfg, usecase, nil)
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(EventIDHeader, "930bbd6d-0c7a-4fe4-8b50-4b82a20cb847")
+		req.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+
+		return resp.StatusCode, usecase.inputs
+	}
+
+	jsonStatus, jsonInputs := send(t, configuration.HTTPConfig{}, "application/json", `{"encrypted_body":"header.payload.signature"}`)
+	formBody := url.Values{"encrypted_body": {"header.payload.signature"}}.Encode()
+	formStatus, formInputs := send(t, configuration.HTTPConfig{}, "application/x-www-form-urlencoded", formBody)
+
+	if jsonStatus != http.StatusNoContent || formStatus != jsonStatus {
+		t.Errorf("New() form status = %v, JSON status = %v", formStatus, jsonStatus)
+	}
+	if len(formInputs) != 1 || len(jsonInputs) != 1 {
+		t.Fatalf("New() form inputs = %v, JSON inputs = %v", formInputs, jsonInputs)
+	}
+
+	// Only the raw bodies differ, each being the bytes received.
+	if string(formInputs[0].RawBody) != formBody {
+		t.Errorf("New() form raw body = %q, want %q", formInputs[0].RawBody, formBody)
+	}
+	formInputs[0].RawBody, jsonInputs[0].RawBody = nil, nil
+	if !reflect.DeepEqual(formInputs, jsonInputs) {
+		t.Errorf("New() form inputs = %v, JSON inputs = %v", formInputs, jsonInputs)
+	}
+
+	tests := []struct {
+		name       string
+		cfg        configuration.HTTPConfig
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Missing field",
+			body:       "other=1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Not a JWS",
+			body:       "encrypted_body=garbage",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown fields in strict mode",
+			cfg:        configuration.HTTPConfig{StrictJSON: true},
+			body:       formBody + "&extra=1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Body too large",
+			cfg:        configuration.HTTPConfig{MaxBodySize: 32},
+			body:       formBody + "&padding=" + strings.Repeat("x", 64),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, inputs := send(t, tt.cfg, "application/x-www-form-urlencoded", tt.body)
+			if status != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", status, tt.wantStatus)
+			}
+			if len(inputs) != 0 {
+				t.Errorf("New() must not send an invalid form: %v", inputs)
+			}
+		})
+	}
+}
+
+// failingReader sends part of the body, then fails like a dropped connection.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestHandler_New_ClientDisconnect(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      io.Reader
+		wantLevel logrus.Level
+		wantCode  string
+	}{
+		{
+			name:      "Truncated body",
+			body:      &failingReader{data: `{"encrypted_body":"hea`, err: io.ErrUnexpectedEOF},
+			wantLevel: logrus.WarnLevel,
+			wantCode:  "disconnected",
+		},
+		{
+			name:      "Connection reset",
+			body:      &failingReader{data: `{"encrypted_body":"hea`, err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+			wantLevel: logrus.WarnLevel,
+			wantCode:  "disconnected",
+		},
+		{
+			name:      "Complete but invalid JSON",
+			body:      strings.NewReader(`{"encrypted_body":"hea`),
+			wantLevel: logrus.ErrorLevel,
+			wantCode:  "4xx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, hook := test.NewNullLogger()
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, log, validator.NewJSONValidator(), &fakeUsecase{}, nil, nil)
+
+			route := "/disconnect/" + strings.ReplaceAll(strings.ToLower(tt.name), " ", "-")
+			router := mux.NewRouter()
+			router.HandleFunc(route, h.New)
+
+			r := httptest.NewRequest(http.MethodPost, route, tt.body)
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+			w := httptest.NewRecorder()
+			middleware.Metrics(router).ServeHTTP(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("New() status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+			if entry := hook.LastEntry(); entry == nil || entry.Level != tt.wantLevel {
+				t.Errorf("New() log = %v, want level %v", entry, tt.wantLevel)
+			}
+			if got := requestCode(t, route); got != tt.wantCode {
+				t.Errorf("New() counted code %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+// requestCode returns the code label of the requests counted for the route.
+func requestCode(t *testing.T, route string) string {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "webhook_consumer_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["route"] == route {
+				return labels["code"]
+			}
+		}
+	}
+
+	return ""
+}
+
+func TestHandler_New_EventTypeLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    domain.NotificationOutput
+		err       error
+		wantLabel string
+	}{
+		{
+			name:      "Verified event type is admitted",
+			output:    domain.NotificationOutput{Verified: true},
+			wantLabel: "cash_out_internal_transfer",
+		},
+		{
+			name:      "Unverified event type is other",
+			err:       fmt.Errorf("unable to verify signature: %w", &domain.VerificationError{Err: fmt.Errorf("invalid")}),
+			wantLabel: labelguard.Other,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usecase := &fakeUsecase{output: tt.output, err: tt.err}
+			srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+			guard := labelguard.New(10)
+			srv.handler.SetEventTypeLabels(guard)
+
+			postNotification(t, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+
+			if got := guard.Known("cash_out_internal_transfer"); got != tt.wantLabel {
+				t.Errorf("New() event type label = %v, want %v", got, tt.wantLabel)
+			}
+		})
+	}
+}
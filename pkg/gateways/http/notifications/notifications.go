@@ -1,22 +1,121 @@
 package notifications
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/sirupsen/logrus"
 
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventtype"
+	"github.com/stone-co/webhook-consumer/pkg/common/eventversion"
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/common/maintenance"
+	"github.com/stone-co/webhook-consumer/pkg/common/ratelimit"
+	"github.com/stone-co/webhook-consumer/pkg/common/shedding"
+	"github.com/stone-co/webhook-consumer/pkg/common/tail"
 	"github.com/stone-co/webhook-consumer/pkg/common/validator"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
 )
 
 type Handler struct {
 	log *logrus.Logger
 	*validator.JSONValidator
-	usecase domain.NotificationUsecase
+	usecase     domain.NotificationUsecase
+	maxBodySize int64
+	strictJSON  bool
+	// batchMaxItems limits the notifications of a batch.
+	batchMaxItems int
+	// timestampHeader has the event creation timestamp, when not empty.
+	timestampHeader string
+	// exposeInternalErrors adds the error details to the responses.
+	exposeInternalErrors bool
+	// successStatus answers the notifications sent without a response body.
+	successStatus int
+	maintenance   *maintenance.Mode
+	// events is the tail of processed notifications, it's optional.
+	events *tail.Buffer
+	// acks has the success responders, by event type.
+	acks map[string]AckResponder
+	// versions rejects the unsupported event type versions, it's optional.
+	versions *eventversion.Policy
+	// probeHeader and probeValue recognize the health probes, when set.
+	probeHeader string
+	probeValue  string
+	// bodySignature verifies the raw body, it's optional.
+	bodySignature *BodySignature
+	// shedding rejects the sources failing the verification, it's optional.
+	shedding *shedding.Tracker
+	// rateLimiter limits the requests of each source, and of all of them,
+	// it's optional.
+	rateLimiter *ratelimit.Limiter
+	// clientIP resolves the source of the shedding and the rate limits.
+	clientIP middleware.ClientIP
+	// eventTypes normalizes the event type header, it's optional.
+	eventTypes *eventtype.Normalizer
+	// eventTypeLabels bounds the event types in the metric labels. Without
+	// it, they're all labeled "other".
+	eventTypeLabels *labelguard.Guard
+}
+
+// CheckSuccessStatus checks if the status can answer the successful
+// notifications with an empty body.
+func CheckSuccessStatus(status int) error {
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return fmt.Errorf("invalid success status %d, only %d and %d are accepted", status, http.StatusNoContent, http.StatusOK)
+	}
+
+	return nil
 }
 
-func NewHandler(log *logrus.Logger, validator *validator.JSONValidator, usecase domain.NotificationUsecase) *Handler {
+func NewHandler(cfg configuration.HTTPConfig, log *logrus.Logger, validator *validator.JSONValidator, usecase domain.NotificationUsecase, maintenance *maintenance.Mode, events *tail.Buffer) *Handler {
 	return &Handler{
-		log:           log,
-		JSONValidator: validator,
-		usecase:       usecase,
+		log:                  log,
+		JSONValidator:        validator,
+		usecase:              usecase,
+		maxBodySize:          cfg.MaxBodySize,
+		strictJSON:           cfg.StrictJSON,
+		batchMaxItems:        cfg.BatchMaxItems,
+		timestampHeader:      cfg.TimestampHeader,
+		exposeInternalErrors: cfg.ExposeInternalErrors,
+		successStatus:        successStatus(cfg.SuccessStatus),
+		maintenance:          maintenance,
+		events:               events,
+		acks:                 map[string]AckResponder{},
 	}
 }
+
+// SetAckResponder replaces the 204 success response of the event type.
+func (h *Handler) SetAckResponder(eventType string, responder AckResponder) {
+	h.acks[eventType] = responder
+}
+
+// SetEventVersionPolicy rejects the event types out of the policy.
+func (h *Handler) SetEventVersionPolicy(policy *eventversion.Policy) {
+	h.versions = policy
+}
+
+// SetEventTypeNormalizer normalizes the event type header before it's used.
+func (h *Handler) SetEventTypeNormalizer(normalizer *eventtype.Normalizer) {
+	h.eventTypes = normalizer
+}
+
+// SetEventTypeLabels bounds the event types in the metric labels.
+func (h *Handler) SetEventTypeLabels(guard *labelguard.Guard) {
+	h.eventTypeLabels = guard
+}
+
+// SetBodySignature verifies the signature of the raw request bodies.
+func (h *Handler) SetBodySignature(signature *BodySignature) {
+	h.bodySignature = signature
+}
+
+// successStatus defaults to 204.
+func successStatus(status int) int {
+	if status == 0 {
+		return http.StatusNoContent
+	}
+
+	return status
+}
@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+type ProbeResponse struct {
+	Status string `json:"status"`
+}
+
+// ParseProbeHeader parses the "name=value" header of the provider health
+// probes.
+func ParseProbeHeader(header string) (string, string, error) {
+	parts := strings.SplitN(header, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("invalid probe header %q, must be name=value", header)
+	}
+
+	return http.CanonicalHeaderKey(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), nil
+}
+
+// SetProbeHeader recognizes the notifications with the exact header value as
+// health probes.
+func (h *Handler) SetProbeHeader(name, value string) {
+	h.probeHeader = name
+	h.probeValue = value
+}
+
+// Probe answers the provider health probes, without any verification. It
+// never calls the usecase, so nothing received here is sent onward.
+func (h Handler) Probe(w http.ResponseWriter, r *http.Request) {
+	h.log.Debug("health probe received")
+	_ = responses.Send(w, ProbeResponse{Status: "ok"}, http.StatusOK)
+}
+
+// isProbe checks if the request is a health probe: the probe header with the
+// exact value, and a body without an envelope. A probe header on a real
+// notification doesn't skip its verification.
+func (h Handler) isProbe(r *http.Request, body []byte) bool {
+	if h.probeHeader == "" || r.Header.Get(h.probeHeader) != h.probeValue {
+		return false
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return true
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	_, hasEnvelope := fields["encrypted_body"]
+	return !hasEnvelope
+}
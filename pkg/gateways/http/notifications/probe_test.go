@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/validator"
+)
+
+func TestParseProbeHeader(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{header: "x-stone-webhook-probe=ping", wantName: "X-Stone-Webhook-Probe", wantValue: "ping"},
+		{header: " X-Probe = a=b ", wantName: "X-Probe", wantValue: "a=b"},
+		{header: "X-Probe", wantErr: true},
+		{header: "X-Probe=", wantErr: true},
+		{header: "=ping", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			name, value, err := ParseProbeHeader(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProbeHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("ParseProbeHeader() = %q, %q, want %q, %q", name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestHandler_New_Probe(t *testing.T) {
+	tests := []struct {
+		name       string
+		probe      string
+		body       string
+		wantStatus int
+		wantSent   bool
+	}{
+		{
+			name:       "Probe without body",
+			probe:      "ping",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Probe with a JSON body",
+			probe:      "ping",
+			body:       `{"type":"ping"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Probe header on a notification still requires verification",
+			probe:      "ping",
+			body:       `{"encrypted_body":"header.payload.signature"}`,
+			wantStatus: http.StatusForbidden,
+			wantSent:   true,
+		},
+		{
+			name:       "Other probe header value",
+			probe:      "pong",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Notification without probe header",
+			body:       `{"encrypted_body":"header.payload.signature"}`,
+			wantStatus: http.StatusForbidden,
+			wantSent:   true,
+		},
+		{
+			name:       "Empty body without probe header",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake usecase fails like an invalid signature.
+			usecase := &fakeUsecase{err: errors.New("invalid signature")}
+			h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+			h.SetProbeHeader("X-Stone-Webhook-Probe", "ping")
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set(EventIDHeader, "930bbd6d")
+			r.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+			if tt.probe != "" {
+				r.Header.Set("X-Stone-Webhook-Probe", tt.probe)
+			}
+			w := httptest.NewRecorder()
+			h.New(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("New() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if sent := len(usecase.inputs) == 1; sent != tt.wantSent {
+				t.Errorf("New() sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestHandler_New_ProbeDisabled(t *testing.T) {
+	usecase := &fakeUsecase{}
+	h := NewHandler(configuration.HTTPConfig{MaxBodySize: 1024}, logrus.New(), validator.NewJSONValidator(), usecase, nil, nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("X-Stone-Webhook-Probe", "ping")
+	w := httptest.NewRecorder()
+	h.New(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("New() status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
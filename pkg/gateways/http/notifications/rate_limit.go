@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/ratelimit"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/responses"
+)
+
+// SetRateLimiter limits the requests before reading their bodies.
+func (h *Handler) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.rateLimiter = limiter
+}
+
+// rateLimited answers 429 when the request is over the rate limits. A batch
+// takes a single token, like any request.
+func (h Handler) rateLimited(w http.ResponseWriter, r *http.Request) bool {
+	source := h.clientIP.Of(r)
+	scope, wait := h.rateLimiter.Allow(source)
+	if scope == "" {
+		return false
+	}
+
+	rateLimitedRequests.WithLabelValues(scope).Inc()
+	h.log.Warnf("request from %s over the rate limit, scope %s", source, scope)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	_ = responses.SendError(w, r, "too many requests", http.StatusTooManyRequests)
+	return true
+}
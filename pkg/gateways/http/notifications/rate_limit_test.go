@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/ratelimit"
+)
+
+func TestHandler_New_RateLimit(t *testing.T) {
+	usecase := &fakeUsecase{}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	limiter, err := ratelimit.New(configuration.RateLimitConfig{Rate: 0.5, Burst: 2, GlobalRate: 1, GlobalBurst: 3})
+	if err != nil {
+		t.Fatalf("ratelimit.New() error = %v", err)
+	}
+	srv.handler.SetRateLimiter(limiter)
+	srv.handler.SetClientIP(newClientIP(t))
+
+	limitedBefore := testutil.ToFloat64(rateLimitedRequests.WithLabelValues(ratelimit.ScopeSource))
+	globalBefore := testutil.ToFloat64(rateLimitedRequests.WithLabelValues(ratelimit.ScopeGlobal))
+
+	post := func(clientIP string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		req.Header.Set(EventIDHeader, "930bbd6d")
+		req.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+		req.Header.Set("X-Forwarded-For", clientIP)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for i := 1; i <= 2; i++ {
+		if resp := post("10.0.0.1"); resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("request %d status = %v, want %v", i, resp.StatusCode, http.StatusNoContent)
+		}
+	}
+
+	// Limited without calling the usecase.
+	resp := post("10.0.0.1")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Errorf("status = %v, Retry-After %q, want %v, 2", resp.StatusCode, resp.Header.Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// Other sources share the global limit.
+	if resp := post("10.0.0.2"); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("other source status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+	if resp := post("10.0.0.3"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("over the global limit status = %v, want %v", resp.StatusCode, http.StatusTooManyRequests)
+	}
+
+	if len(usecase.inputs) != 3 {
+		t.Errorf("usecase called %d times, want 3", len(usecase.inputs))
+	}
+	if got := testutil.ToFloat64(rateLimitedRequests.WithLabelValues(ratelimit.ScopeSource)) - limitedBefore; got != 1 {
+		t.Errorf("source rate limited requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(rateLimitedRequests.WithLabelValues(ratelimit.ScopeGlobal)) - globalBefore; got != 1 {
+		t.Errorf("global rate limited requests = %v, want 1", got)
+	}
+}
+
+func TestHandler_New_RateLimitForgedClientIP(t *testing.T) {
+	usecase := &fakeUsecase{}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	limiter, err := ratelimit.New(configuration.RateLimitConfig{Rate: 0.5, Burst: 1, GlobalRate: 100, GlobalBurst: 100, MaxSources: 10})
+	if err != nil {
+		t.Fatalf("ratelimit.New() error = %v", err)
+	}
+	srv.handler.SetRateLimiter(limiter)
+	srv.handler.SetClientIP(newClientIP(t))
+
+	// The client rotates the left of the header, the proxy appends the same
+	// peer address, so all the requests take from the same bucket.
+	for i, forged := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"encrypted_body":"header.payload.signature"}`))
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		req.Header.Set(EventIDHeader, "930bbd6d")
+		req.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+		req.Header.Set("X-Forwarded-For", forged+", 203.0.113.7")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+
+		want := http.StatusTooManyRequests
+		if i == 0 {
+			want = http.StatusNoContent
+		}
+		if resp.StatusCode != want {
+			t.Errorf("request %d status = %v, want %v", i, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"syscall"
+)
+
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// readBody reads up to limit bytes. The limit is checked against the bytes
+// actually received, so it works with chunked bodies without Content-Length.
+// An incomplete body, like a truncated chunk, results in io.ErrUnexpectedEOF.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+
+	return data, nil
+}
+
+// isClientDisconnect checks if the body read failed because the client went
+// away, like a truncated body or a connection reset, and not because of the
+// body contents.
+func isClientDisconnect(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,17 @@
+package notifications
+
+import (
+	"github.com/stone-co/webhook-consumer/pkg/common/shedding"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
+)
+
+// SetShedding rejects the requests of the sources failing the verification,
+// before any crypto work.
+func (h *Handler) SetShedding(tracker *shedding.Tracker) {
+	h.shedding = tracker
+}
+
+// SetClientIP resolves the client IP of the shedding and the rate limits.
+func (h *Handler) SetClientIP(clientIP middleware.ClientIP) {
+	h.clientIP = clientIP
+}
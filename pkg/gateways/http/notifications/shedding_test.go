@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/common/shedding"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/http/middleware"
+)
+
+func TestHandler_New_Shedding(t *testing.T) {
+	usecase := &fakeUsecase{err: fmt.Errorf("unable to verify signature: %w", &domain.VerificationError{Err: fmt.Errorf("invalid signature")})}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	tracker := shedding.New(configuration.SheddingConfig{Threshold: 3, Duration: time.Minute})
+	srv.handler.SetShedding(tracker)
+	srv.handler.SetClientIP(newClientIP(t))
+
+	failuresBefore := testutil.ToFloat64(verificationFailures)
+	shedBefore := testutil.ToFloat64(shedRequests.WithLabelValues(shedding.ScopeSource))
+
+	body, err := json.Marshal(NotificationRequest{EncryptedBody: "header.payload.signature"})
+	if err != nil {
+		t.Fatalf("marshaling body: %v", err)
+	}
+	post := func(clientIP string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("creating request: %v", err)
+		}
+		req.Header.Set(EventIDHeader, "930bbd6d")
+		req.Header.Set(EventTypeHeader, "cash_out_internal_transfer")
+		// The client writes the left of the header, the proxy appends the peer.
+		req.Header.Set("X-Forwarded-For", "10.0.0.254, "+clientIP)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("sending request: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for i := 1; i <= 3; i++ {
+		if resp := post("10.0.0.1"); resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("attempt %d status = %v, want %v", i, resp.StatusCode, http.StatusForbidden)
+		}
+	}
+
+	// Shed without calling the usecase.
+	resp := post("10.0.0.1")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "60" {
+		t.Errorf("status = %v, Retry-After %q, want %v, 60", resp.StatusCode, resp.Header.Get("Retry-After"), http.StatusTooManyRequests)
+	}
+	if len(usecase.inputs) != 3 {
+		t.Errorf("usecase called %d times, want 3", len(usecase.inputs))
+	}
+
+	// Other sources aren't shed.
+	if resp := post("10.0.0.2"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("other source status = %v, want %v", resp.StatusCode, http.StatusForbidden)
+	}
+
+	if got := testutil.ToFloat64(verificationFailures) - failuresBefore; got != 4 {
+		t.Errorf("verification failures = %v, want 4", got)
+	}
+	if got := testutil.ToFloat64(shedRequests.WithLabelValues(shedding.ScopeSource)) - shedBefore; got != 1 {
+		t.Errorf("shed requests = %v, want 1", got)
+	}
+}
+
+func TestHandler_New_SheddingReset(t *testing.T) {
+	usecase := &fakeUsecase{output: domain.NotificationOutput{Verified: true}, err: fmt.Errorf("notifier failed")}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	srv.handler.SetShedding(shedding.New(configuration.SheddingConfig{Threshold: 1, Duration: time.Minute}))
+
+	body, err := json.Marshal(NotificationRequest{EncryptedBody: "header.payload.signature"})
+	if err != nil {
+		t.Fatalf("marshaling body: %v", err)
+	}
+
+	// The failures after the verification don't shed the source.
+	for i := 0; i < 2; i++ {
+		if resp := postNotification(t, srv.URL, strings.NewReader(string(body))); resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("status = %v, want %v", resp.StatusCode, http.StatusForbidden)
+		}
+	}
+}
+
+func TestHandler_New_SheddingUnverifiedFailure(t *testing.T) {
+	usecase := &fakeUsecase{}
+	srv := newTestServer(t, configuration.HTTPConfig{}, usecase, nil)
+	srv.handler.SetShedding(shedding.New(configuration.SheddingConfig{Threshold: 2, Duration: time.Minute}))
+
+	body, err := json.Marshal(NotificationRequest{EncryptedBody: "header.payload.signature"})
+	if err != nil {
+		t.Fatalf("marshaling body: %v", err)
+	}
+	verificationErr := fmt.Errorf("unable to verify signature: %w", &domain.VerificationError{Err: fmt.Errorf("invalid signature")})
+
+	// A failure before the verification, like the archive, doesn't prove the
+	// source, so it doesn't reset its verification failures.
+	for _, err := range []error{verificationErr, domain.ErrArchiveFailed, verificationErr} {
+		usecase.mu.Lock()
+		usecase.err = err
+		usecase.mu.Unlock()
+		postNotification(t, srv.URL, strings.NewReader(string(body)))
+	}
+
+	if resp := postNotification(t, srv.URL, strings.NewReader(string(body))); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %v, want %v", resp.StatusCode, http.StatusTooManyRequests)
+	}
+}
+
+func newClientIP(t *testing.T) middleware.ClientIP {
+	t.Helper()
+
+	clientIP, err := middleware.NewClientIP(configuration.ClientIPConfig{Header: "X-Forwarded-For", TrustedHops: 1})
+	if err != nil {
+		t.Fatalf("NewClientIP() error = %v", err)
+	}
+
+	return clientIP
+}
@@ -1,8 +1,13 @@
 package responses
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type Error struct {
@@ -13,14 +18,52 @@ func (e Error) Error() string {
 	return e.Message
 }
 
+// Send writes the response as JSON, with its Content-Length, and flushes it
+// so the client sees the whole response even if the connection is closed
+// right after. A nil response has no body, and a 204 has no body headers.
 func Send(w http.ResponseWriter, response interface{}, statusCode int) error {
+	defer flush(w)
+
+	if statusCode == http.StatusNoContent {
+		w.WriteHeader(statusCode)
+		return nil
+	}
+
+	var body bytes.Buffer
+	if response != nil {
+		if err := json.NewEncoder(&body).Encode(response); err != nil {
+			return err
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
 	w.WriteHeader(statusCode)
 
-	return json.NewEncoder(w).Encode(response)
+	_, err := w.Write(body.Bytes())
+	return err
 }
 
-func SendError(w http.ResponseWriter, message string, statusCode int) error {
+// flush sends the buffered response, when the writer supports it.
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// SendError writes the error as JSON, unless the request Accept header
+// rules JSON out, in which case the message is written as plain text.
+func SendError(w http.ResponseWriter, r *http.Request, message string, statusCode int) error {
+	defer flush(w)
+
+	if !acceptsJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(statusCode)
+
+		_, err := fmt.Fprintln(w, message)
+		return err
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -28,3 +71,29 @@ func SendError(w http.ResponseWriter, message string, statusCode int) error {
 		Message: message,
 	})
 }
+
+// acceptsJSON is true when there is no Accept header or when some of its
+// media ranges, not refused with q=0, matches application/json.
+func acceptsJSON(accept string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		if q, ok := params["q"]; ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// plainWriter hides the recorder Flush method.
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name              string
+		response          interface{}
+		statusCode        int
+		wantContentType   string
+		wantContentLength string
+		wantBody          string
+	}{
+		{
+			name:       "No content",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "No content ignores the response",
+			response:   map[string]string{"receipt": "token"},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:              "OK without body",
+			statusCode:        http.StatusOK,
+			wantContentType:   "application/json",
+			wantContentLength: "0",
+		},
+		{
+			name:              "Accepted with body",
+			response:          map[string]string{"receipt": "token"},
+			statusCode:        http.StatusAccepted,
+			wantContentType:   "application/json",
+			wantContentLength: "20",
+			wantBody:          `{"receipt":"token"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			if err := Send(w, tt.response, tt.statusCode); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if w.Code != tt.statusCode {
+				t.Errorf("Send() status = %v, want %v", w.Code, tt.statusCode)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Send() content type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := w.Header().Get("Content-Length"); got != tt.wantContentLength {
+				t.Errorf("Send() content length = %q, want %q", got, tt.wantContentLength)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("Send() body = %q, want %q", got, tt.wantBody)
+			}
+			if !w.Flushed {
+				t.Error("Send() must flush the response")
+			}
+		})
+	}
+}
+
+func TestSend_WithoutFlusher(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	if err := Send(plainWriter{w}, nil, http.StatusNoContent); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if w.Code != http.StatusNoContent || w.Flushed {
+		t.Errorf("Send() status = %v, flushed = %v, want %v and not flushed", w.Code, w.Flushed, http.StatusNoContent)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "No Accept header defaults to JSON",
+			accept:          "",
+			wantContentType: "application/json",
+			wantBody:        `{"message":"invalid signature"}` + "\n",
+		},
+		{
+			name:            "JSON is accepted",
+			accept:          "application/json",
+			wantContentType: "application/json",
+			wantBody:        `{"message":"invalid signature"}` + "\n",
+		},
+		{
+			name:            "Any media type is accepted",
+			accept:          "*/*",
+			wantContentType: "application/json",
+			wantBody:        `{"message":"invalid signature"}` + "\n",
+		},
+		{
+			name:            "Plain text is accepted",
+			accept:          "text/plain",
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "invalid signature\n",
+		},
+		{
+			name:            "JSON refused with q=0",
+			accept:          "text/plain, application/json;q=0",
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "invalid signature\n",
+		},
+		{
+			name:            "JSON among other types",
+			accept:          "text/html, application/json;q=0.9",
+			wantContentType: "application/json",
+			wantBody:        `{"message":"invalid signature"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			if err := SendError(w, r, "invalid signature", http.StatusBadRequest); err != nil {
+				t.Fatalf("SendError() error = %v", err)
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("SendError() status = %v, want %v", w.Code, http.StatusBadRequest)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("SendError() content type = %v, want %v", got, tt.wantContentType)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("SendError() body = %q, want %q", got, tt.wantBody)
+			}
+			if !w.Flushed {
+				t.Errorf("SendError() must flush the response")
+			}
+			if tt.wantContentType == "application/json" && !json.Valid(w.Body.Bytes()) {
+				t.Errorf("SendError() body is not a valid JSON")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// loadTLSConfig returns nil when TLS is disabled.
+func loadTLSConfig(certPath, keyPath string) (*tls.Config, error) {
+	if certPath == "" && keyPath == "" {
+		return nil, nil
+	}
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("tls requires both the certificate and the key")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading the tls certificate: %v", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// requireClientCertificates verifies the client certificates with the CAs in
+// PEM. They're only asked for, and required by the inbound authentication of
+// the notification routes.
+func requireClientCertificates(tlsConfig *tls.Config, caPath string) error {
+	if caPath == "" {
+		return nil
+	}
+	if tlsConfig == nil {
+		return fmt.Errorf("the client certificates require the tls certificate and key of the public listener")
+	}
+
+	data, err := ioutil.ReadFile(caPath)
+	if err != nil {
+		return fmt.Errorf("reading the client CAs: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no certificate found in the client CAs %s", caPath)
+	}
+
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	return nil
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_loadTLSConfig(t *testing.T) {
+	certPath, keyPath := writeCertificate(t)
+
+	tests := []struct {
+		name     string
+		certPath string
+		keyPath  string
+		wantTLS  bool
+		wantErr  bool
+	}{
+		{name: "Disabled"},
+		{name: "Certificate and key", certPath: certPath, keyPath: keyPath, wantTLS: true},
+		{name: "Key without the certificate", keyPath: keyPath, wantErr: true},
+		{name: "Key as the certificate", certPath: keyPath, keyPath: keyPath, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTLSConfig(tt.certPath, tt.keyPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got != nil) != tt.wantTLS {
+				t.Errorf("loadTLSConfig() = %v, want tls %v", got, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func Test_requireClientCertificates(t *testing.T) {
+	certPath, keyPath := writeCertificate(t)
+
+	tests := []struct {
+		name       string
+		caPath     string
+		withTLS    bool
+		wantClient bool
+		wantErr    bool
+	}{
+		{name: "Disabled", withTLS: true},
+		{name: "Client CAs", caPath: certPath, withTLS: true, wantClient: true},
+		{name: "Without TLS", caPath: certPath, wantErr: true},
+		{name: "Missing CAs", caPath: certPath + ".missing", withTLS: true, wantErr: true},
+		{name: "Key as the CAs", caPath: keyPath, withTLS: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tlsConfig *tls.Config
+			if tt.withTLS {
+				var err error
+				if tlsConfig, err = loadTLSConfig(certPath, keyPath); err != nil {
+					t.Fatalf("loadTLSConfig() error = %v", err)
+				}
+			}
+
+			err := requireClientCertificates(tlsConfig, tt.caPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireClientCertificates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := tlsConfig.ClientAuth == tls.VerifyClientCertIfGiven && tlsConfig.ClientCAs != nil; got != tt.wantClient {
+				t.Errorf("requireClientCertificates() client auth = %v, want %v", got, tt.wantClient)
+			}
+		})
+	}
+}
+
+// writeCertificate writes a self-signed certificate and its key.
+func writeCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("writing certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("writing key: %v", err)
+	}
+
+	return certPath, keyPath
+}
@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	MaxEvents int `envconfig:"IDEMPOTENCY_MEMORY_MAX_EVENTS" default:"100000"`
+}
+
+func (s *MemoryStore) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	s.log = log
+	log.WithField("idempotency_store", "memory").Infof("config:[%+v]", config)
+
+	if config.MaxEvents < 1 {
+		return fmt.Errorf("the max events must be positive")
+	}
+
+	s.maxEvents = config.MaxEvents
+	return nil
+}
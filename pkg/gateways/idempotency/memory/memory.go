@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.IdempotencyStore = &MemoryStore{}
+
+// MemoryStore keeps the event ids in memory, so they're lost on restart and
+// not shared by the instances. Only the last maxEvents event ids are kept,
+// the least recently remembered are forgotten first.
+type MemoryStore struct {
+	log       *logrus.Logger
+	maxEvents int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order has the most recently remembered event ids first.
+	order *list.List
+}
+
+type entry struct {
+	eventID   string
+	expiresAt time.Time
+}
+
+func New() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+func (s *MemoryStore) Seen(ctx context.Context, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[eventID]
+	if !ok {
+		return false, nil
+	}
+
+	if !s.now().Before(element.Value.(*entry).expiresAt) {
+		s.remove(element)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (s *MemoryStore) Remember(ctx context.Context, eventID string, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := s.now().Add(window)
+	if element, ok := s.entries[eventID]; ok {
+		element.Value.(*entry).expiresAt = expiresAt
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[eventID] = s.order.PushFront(&entry{eventID: eventID, expiresAt: expiresAt})
+	for s.maxEvents > 0 && s.order.Len() > s.maxEvents {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*entry).eventID)
+}
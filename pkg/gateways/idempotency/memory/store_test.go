@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	s := New()
+	s.maxEvents = 2
+	s.now = func() time.Time { return now }
+
+	seen := func(eventID string) bool {
+		ok, err := s.Seen(ctx, eventID)
+		if err != nil {
+			t.Fatalf("Seen() error = %v", err)
+		}
+		return ok
+	}
+
+	if seen("1") {
+		t.Errorf("Seen() = true before remembering")
+	}
+
+	_ = s.Remember(ctx, "1", time.Minute)
+	if !seen("1") {
+		t.Errorf("Seen() = false after remembering")
+	}
+
+	now = now.Add(time.Minute)
+	if seen("1") {
+		t.Errorf("Seen() = true after the window")
+	}
+
+	_ = s.Remember(ctx, "1", time.Minute)
+	_ = s.Remember(ctx, "2", time.Minute)
+	_ = s.Remember(ctx, "1", time.Minute)
+	_ = s.Remember(ctx, "3", time.Minute)
+	if seen("2") {
+		t.Errorf("Seen() = true for the least recently remembered event id")
+	}
+	if !seen("1") || !seen("3") {
+		t.Errorf("Seen() = false for the most recently remembered event ids")
+	}
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type Config struct {
+	Address            string        `envconfig:"IDEMPOTENCY_REDIS_ADDR" required:"true"`
+	Port               string        `envconfig:"IDEMPOTENCY_REDIS_PORT" required:"true"`
+	Password           string        `envconfig:"IDEMPOTENCY_REDIS_PASSWORD"`
+	UseTLS             bool          `envconfig:"IDEMPOTENCY_REDIS_USE_TLS" default:"false"`
+	MaxIdle            int           `envconfig:"IDEMPOTENCY_REDIS_MAX_IDLE" default:"100"`
+	MaxActive          int           `envconfig:"IDEMPOTENCY_REDIS_MAX_ACTIVE" default:"1000"`
+	IdleTimeout        time.Duration `envconfig:"IDEMPOTENCY_REDIS_IDLE_TIMEOUT" default:"1m"`
+	DialConnectTimeout time.Duration `envconfig:"IDEMPOTENCY_REDIS_CONNECT_TIMEOUT" default:"1s"`
+	DialReadTimeout    time.Duration `envconfig:"IDEMPOTENCY_REDIS_READ_TIMEOUT" default:"300ms"`
+	DialWriteTimeout   time.Duration `envconfig:"IDEMPOTENCY_REDIS_WRITE_TIMEOUT" default:"300ms"`
+	// KeyPrefix is added to the event ids, to share the database.
+	KeyPrefix string `envconfig:"IDEMPOTENCY_REDIS_KEY_PREFIX" default:"webhook-consumer:event:"`
+}
+
+// String leaves the password out of the logs.
+func (c Config) String() string {
+	return fmt.Sprintf("addr:[%s] use_tls:[%t] max_idle:[%d] max_active:[%d] idle_timeout:[%s] connect_timeout:[%s] read_timeout:[%s] write_timeout:[%s] key_prefix:[%s]",
+		c.Addr(), c.UseTLS, c.MaxIdle, c.MaxActive, c.IdleTimeout, c.DialConnectTimeout, c.DialReadTimeout, c.DialWriteTimeout, c.KeyPrefix)
+}
+
+func (c Config) Addr() string {
+	return strings.Join([]string{c.Address, c.Port}, ":")
+}
+
+func initPool(cfg Config) (*redis.Pool, error) {
+	redisPool := &redis.Pool{
+		MaxIdle:     cfg.MaxIdle,
+		MaxActive:   cfg.MaxActive,
+		IdleTimeout: cfg.IdleTimeout,
+		Dial: func() (redis.Conn, error) {
+			conn, err := redis.Dial("tcp", cfg.Addr(),
+				redis.DialPassword(cfg.Password),
+				redis.DialUseTLS(cfg.UseTLS),
+				redis.DialConnectTimeout(cfg.DialConnectTimeout),
+				redis.DialReadTimeout(cfg.DialReadTimeout),
+				redis.DialWriteTimeout(cfg.DialWriteTimeout))
+			if err != nil {
+				return nil, fmt.Errorf("could not connect to redis: %w", err)
+			}
+			return conn, nil
+		},
+	}
+
+	conn := redisPool.Get()
+	defer conn.Close()
+
+	if _, err := redis.String(conn.Do("PING")); err != nil {
+		return nil, err
+	}
+
+	return redisPool, nil
+}
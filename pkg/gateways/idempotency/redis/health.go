@@ -0,0 +1,15 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/redisclient"
+)
+
+var _ domain.HealthChecker = &RedisStore{}
+
+// CheckHealth pings the redis server.
+func (s *RedisStore) CheckHealth(ctx context.Context) error {
+	return redisclient.CheckHealth(ctx, s.pool)
+}
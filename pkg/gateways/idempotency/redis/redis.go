@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.IdempotencyStore = &RedisStore{}
+
+// RedisStore keeps the event ids as expiring keys, shared by the instances.
+type RedisStore struct {
+	log       *logrus.Logger
+	pool      *redis.Pool
+	keyPrefix string
+}
+
+func New() *RedisStore {
+	return &RedisStore{}
+}
@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+func (s *RedisStore) Seen(ctx context.Context, eventID string) (bool, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	seen, err := redis.Bool(conn.Do("EXISTS", s.keyPrefix+eventID))
+	if err != nil {
+		return false, fmt.Errorf("unable to check the event id: %w", err)
+	}
+
+	return seen, nil
+}
+
+func (s *RedisStore) Remember(ctx context.Context, eventID string, window time.Duration) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do("SET", s.keyPrefix+eventID, time.Now().UTC().Format(time.RFC3339), "PX", window.Milliseconds()); err != nil {
+		return fmt.Errorf("unable to remember the event id: %w", err)
+	}
+
+	return nil
+}
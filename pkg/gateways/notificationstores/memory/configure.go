@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	MaxNotifications int `envconfig:"NOTIFICATION_STORE_MEMORY_MAX_NOTIFICATIONS" default:"10000"`
+}
+
+func (s *MemoryStore) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	s.log = log
+	log.WithField("notification_store", "memory").Infof("config:[%+v]", config)
+
+	if config.MaxNotifications < 1 {
+		return fmt.Errorf("the max notifications must be positive")
+	}
+
+	s.maxNotifications = config.MaxNotifications
+	return nil
+}
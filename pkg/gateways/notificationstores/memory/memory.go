@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.NotificationStore = &MemoryStore{}
+
+// MemoryStore keeps the notifications in memory, so they're lost on restart
+// and not shared by the instances. Only the last maxNotifications are kept,
+// the least recently updated are forgotten first.
+type MemoryStore struct {
+	log              *logrus.Logger
+	maxNotifications int
+	now              func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order has the most recently updated notifications first.
+	order *list.List
+}
+
+type entry struct {
+	stored    domain.StoredNotification
+	expiresAt time.Time
+}
+
+func New() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
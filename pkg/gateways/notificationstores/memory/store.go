@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func (s *MemoryStore) Save(ctx context.Context, stored domain.StoredNotification, retention time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eventID := stored.Input.Header.EventID
+	stored.Attempts = 1
+	if element, ok := s.entries[eventID]; ok {
+		if previous := s.get(element); previous != nil {
+			stored.ReceivedAt = previous.stored.ReceivedAt
+			stored.Attempts = previous.stored.Attempts + 1
+		}
+	}
+
+	e := &entry{stored: stored, expiresAt: s.now().Add(retention)}
+	if element, ok := s.entries[eventID]; ok {
+		element.Value = e
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	s.entries[eventID] = s.order.PushFront(e)
+	for s.maxNotifications > 0 && s.order.Len() > s.maxNotifications {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, eventID string) (domain.StoredNotification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[eventID]
+	if !ok {
+		return domain.StoredNotification{}, domain.ErrNotificationNotFound
+	}
+
+	e := s.get(element)
+	if e == nil {
+		return domain.StoredNotification{}, domain.ErrNotificationNotFound
+	}
+
+	return e.stored, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, filter domain.NotificationFilter) ([]domain.StoredNotification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []domain.StoredNotification{}
+	for element := s.order.Front(); element != nil; {
+		next := element.Next()
+
+		e := s.get(element)
+		if e != nil && filter.Match(e.stored) {
+			result = append(result, e.stored)
+			if filter.Limit > 0 && len(result) == filter.Limit {
+				break
+			}
+		}
+
+		element = next
+	}
+
+	return result, nil
+}
+
+// get returns the entry, forgetting it when expired.
+func (s *MemoryStore) get(element *list.Element) *entry {
+	e := element.Value.(*entry)
+	if !s.now().Before(e.expiresAt) {
+		s.remove(element)
+		return nil
+	}
+
+	return e
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*entry).stored.Input.Header.EventID)
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func stored(eventID, eventType, status string, at time.Time) domain.StoredNotification {
+	return domain.StoredNotification{
+		Input: domain.NotificationInput{
+			Header:        domain.HeaderNotification{EventID: eventID, EventType: eventType},
+			EncryptedBody: "jws-" + eventID,
+		},
+		Status:     status,
+		ReceivedAt: at,
+		UpdatedAt:  at,
+	}
+}
+
+func eventIDs(list []domain.StoredNotification) []string {
+	result := []string{}
+	for _, stored := range list {
+		result = append(result, stored.Input.Header.EventID)
+	}
+	return result
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	s := New()
+	s.maxNotifications = 3
+	s.now = func() time.Time { return now }
+
+	if _, err := s.Get(ctx, "1"); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, domain.ErrNotificationNotFound)
+	}
+
+	_ = s.Save(ctx, stored("1", "cash_in", "delivery_failed", now), time.Hour)
+	_ = s.Save(ctx, stored("2", "cash_out", "sent", now.Add(time.Second)), time.Hour)
+
+	// A replay keeps the first reception.
+	_ = s.Save(ctx, stored("1", "cash_in", "sent", now.Add(2*time.Second)), time.Hour)
+	got, err := s.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != "sent" || got.Attempts != 2 || !got.ReceivedAt.Equal(now) || !got.UpdatedAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("Get() = %+v, want the second attempt received at %s", got, now)
+	}
+
+	list := func(filter domain.NotificationFilter) []string {
+		result, err := s.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		return eventIDs(result)
+	}
+
+	_ = s.Save(ctx, stored("3", "cash_in", "rejected", now.Add(3*time.Second)), time.Minute)
+
+	tests := []struct {
+		name   string
+		filter domain.NotificationFilter
+		want   []string
+	}{
+		{
+			name: "All, the most recently updated first",
+			want: []string{"3", "1", "2"},
+		},
+		{
+			name:   "By event type",
+			filter: domain.NotificationFilter{EventType: "cash_in"},
+			want:   []string{"3", "1"},
+		},
+		{
+			name:   "By status",
+			filter: domain.NotificationFilter{Status: "sent"},
+			want:   []string{"1", "2"},
+		},
+		{
+			name:   "By update time",
+			filter: domain.NotificationFilter{Since: now.Add(time.Second), Until: now.Add(2 * time.Second)},
+			want:   []string{"1", "2"},
+		},
+		{
+			name:   "Limited",
+			filter: domain.NotificationFilter{Limit: 1},
+			want:   []string{"3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list(tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The least recently updated is forgotten first.
+	_ = s.Save(ctx, stored("4", "cash_in", "sent", now.Add(4*time.Second)), time.Hour)
+	if got := list(domain.NotificationFilter{}); !reflect.DeepEqual(got, []string{"4", "3", "1"}) {
+		t.Errorf("List() = %v, want [4 3 1]", got)
+	}
+
+	// And the expired ones too.
+	now = now.Add(2 * time.Minute)
+	if got := list(domain.NotificationFilter{}); !reflect.DeepEqual(got, []string{"4", "1"}) {
+		t.Errorf("List() after the retention = %v, want [4 1]", got)
+	}
+	if _, err := s.Get(ctx, "3"); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Get() of an expired notification error = %v, want %v", err, domain.ErrNotificationNotFound)
+	}
+}
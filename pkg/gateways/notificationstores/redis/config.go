@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+type Config struct {
+	Address            string        `envconfig:"NOTIFICATION_STORE_REDIS_ADDR" required:"true"`
+	Port               string        `envconfig:"NOTIFICATION_STORE_REDIS_PORT" required:"true"`
+	Password           string        `envconfig:"NOTIFICATION_STORE_REDIS_PASSWORD"`
+	UseTLS             bool          `envconfig:"NOTIFICATION_STORE_REDIS_USE_TLS" default:"false"`
+	MaxIdle            int           `envconfig:"NOTIFICATION_STORE_REDIS_MAX_IDLE" default:"100"`
+	MaxActive          int           `envconfig:"NOTIFICATION_STORE_REDIS_MAX_ACTIVE" default:"1000"`
+	IdleTimeout        time.Duration `envconfig:"NOTIFICATION_STORE_REDIS_IDLE_TIMEOUT" default:"1m"`
+	DialConnectTimeout time.Duration `envconfig:"NOTIFICATION_STORE_REDIS_CONNECT_TIMEOUT" default:"1s"`
+	DialReadTimeout    time.Duration `envconfig:"NOTIFICATION_STORE_REDIS_READ_TIMEOUT" default:"300ms"`
+	DialWriteTimeout   time.Duration `envconfig:"NOTIFICATION_STORE_REDIS_WRITE_TIMEOUT" default:"300ms"`
+	// KeyPrefix is added to the event ids, to share the database, and
+	// IndexKey is the sorted set of the event ids by update time.
+	KeyPrefix string `envconfig:"NOTIFICATION_STORE_REDIS_KEY_PREFIX" default:"webhook-consumer:notification:"`
+	IndexKey  string `envconfig:"NOTIFICATION_STORE_REDIS_INDEX_KEY" default:"webhook-consumer:notifications"`
+}
+
+// String leaves the password out of the logs.
+func (c Config) String() string {
+	return fmt.Sprintf("addr:[%s] use_tls:[%t] max_idle:[%d] max_active:[%d] idle_timeout:[%s] connect_timeout:[%s] read_timeout:[%s] write_timeout:[%s] key_prefix:[%s] index_key:[%s]",
+		c.Addr(), c.UseTLS, c.MaxIdle, c.MaxActive, c.IdleTimeout, c.DialConnectTimeout, c.DialReadTimeout, c.DialWriteTimeout, c.KeyPrefix, c.IndexKey)
+}
+
+func (c Config) Addr() string {
+	return strings.Join([]string{c.Address, c.Port}, ":")
+}
+
+func initPool(cfg Config) (*redis.Pool, error) {
+	redisPool := &redis.Pool{
+		MaxIdle:     cfg.MaxIdle,
+		MaxActive:   cfg.MaxActive,
+		IdleTimeout: cfg.IdleTimeout,
+		Dial: func() (redis.Conn, error) {
+			conn, err := redis.Dial("tcp", cfg.Addr(),
+				redis.DialPassword(cfg.Password),
+				redis.DialUseTLS(cfg.UseTLS),
+				redis.DialConnectTimeout(cfg.DialConnectTimeout),
+				redis.DialReadTimeout(cfg.DialReadTimeout),
+				redis.DialWriteTimeout(cfg.DialWriteTimeout))
+			if err != nil {
+				return nil, fmt.Errorf("could not connect to redis: %w", err)
+			}
+			return conn, nil
+		},
+	}
+
+	conn := redisPool.Get()
+	defer conn.Close()
+
+	if _, err := redis.String(conn.Do("PING")); err != nil {
+		return nil, err
+	}
+
+	return redisPool, nil
+}
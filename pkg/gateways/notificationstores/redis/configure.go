@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *RedisStore) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	s.log = log
+	log.WithField("notification_store", "redis").Infof("config:[%s]", config)
+
+	var err error
+	s.pool, err = initPool(config)
+	if err != nil {
+		return err
+	}
+
+	s.keyPrefix = config.KeyPrefix
+	s.indexKey = config.IndexKey
+	return nil
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.NotificationStore = &RedisStore{}
+
+// RedisStore keeps each notification as an expiring key, indexed by its
+// update time in a sorted set, shared by the instances.
+type RedisStore struct {
+	log       *logrus.Logger
+	pool      *redis.Pool
+	keyPrefix string
+	indexKey  string
+}
+
+func New() *RedisStore {
+	return &RedisStore{}
+}
+
+// record is the stored notification as JSON.
+type record struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	RawEventType  string    `json:"raw_event_type,omitempty"`
+	CreatedAt     string    `json:"created_at,omitempty"`
+	EncryptedBody string    `json:"encrypted_body"`
+	Unsigned      bool      `json:"unsigned,omitempty"`
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	Attempts      int       `json:"attempts"`
+	ReceivedAt    time.Time `json:"received_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func newRecord(stored domain.StoredNotification) record {
+	return record{
+		EventID:       stored.Input.Header.EventID,
+		EventType:     stored.Input.Header.EventType,
+		RawEventType:  stored.Input.Header.RawEventType,
+		CreatedAt:     stored.Input.Header.CreatedAt,
+		EncryptedBody: stored.Input.EncryptedBody,
+		Unsigned:      stored.Input.Unsigned,
+		Status:        stored.Status,
+		Error:         stored.Error,
+		Attempts:      stored.Attempts,
+		ReceivedAt:    stored.ReceivedAt,
+		UpdatedAt:     stored.UpdatedAt,
+	}
+}
+
+func (r record) stored() domain.StoredNotification {
+	return domain.StoredNotification{
+		Input: domain.NotificationInput{
+			Header: domain.HeaderNotification{
+				EventID:      r.EventID,
+				EventType:    r.EventType,
+				RawEventType: r.RawEventType,
+				CreatedAt:    r.CreatedAt,
+			},
+			EncryptedBody: r.EncryptedBody,
+			Unsigned:      r.Unsigned,
+		},
+		Status:     r.Status,
+		Error:      r.Error,
+		Attempts:   r.Attempts,
+		ReceivedAt: r.ReceivedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// listPageSize is the number of event ids read from the index at a time.
+const listPageSize = 100
+
+// Save reads the previous record to keep its first reception. Two instances
+// saving the same event id at once may count a single attempt.
+func (s *RedisStore) Save(ctx context.Context, stored domain.StoredNotification, retention time.Duration) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	eventID := stored.Input.Header.EventID
+	stored.Attempts = 1
+	previous, err := s.get(conn, eventID)
+	switch {
+	case err == nil:
+		stored.ReceivedAt = previous.ReceivedAt
+		stored.Attempts = previous.Attempts + 1
+	case !errors.Is(err, domain.ErrNotificationNotFound):
+		return err
+	}
+
+	data, err := json.Marshal(newRecord(stored))
+	if err != nil {
+		return fmt.Errorf("unable to encode the notification: %w", err)
+	}
+
+	// The index is trimmed of the event ids expired by the retention.
+	oldest := stored.UpdatedAt.Add(-retention)
+	_ = conn.Send("MULTI")
+	_ = conn.Send("SET", s.keyPrefix+eventID, data, "PX", retention.Milliseconds())
+	_ = conn.Send("ZADD", s.indexKey, score(stored.UpdatedAt), eventID)
+	_ = conn.Send("ZREMRANGEBYSCORE", s.indexKey, "-inf", "("+score(oldest))
+	if _, err := conn.Do("EXEC"); err != nil {
+		return fmt.Errorf("unable to store the notification: %w", err)
+	}
+
+	return nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, eventID string) (domain.StoredNotification, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return domain.StoredNotification{}, fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	return s.get(conn, eventID)
+}
+
+func (s *RedisStore) List(ctx context.Context, filter domain.NotificationFilter) ([]domain.StoredNotification, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	max, min := "+inf", "-inf"
+	if !filter.Until.IsZero() {
+		max = score(filter.Until)
+	}
+	if !filter.Since.IsZero() {
+		min = score(filter.Since)
+	}
+
+	result := []domain.StoredNotification{}
+	for offset := 0; ; offset += listPageSize {
+		eventIDs, err := redis.Strings(conn.Do("ZREVRANGEBYSCORE", s.indexKey, max, min, "LIMIT", offset, listPageSize))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the notifications: %w", err)
+		}
+		if len(eventIDs) == 0 {
+			return result, nil
+		}
+
+		keys := make([]interface{}, len(eventIDs))
+		for i, eventID := range eventIDs {
+			keys[i] = s.keyPrefix + eventID
+		}
+		values, err := redis.ByteSlices(conn.Do("MGET", keys...))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the notifications: %w", err)
+		}
+
+		for i, value := range values {
+			// The expired ones are still in the index until the next save.
+			if value == nil {
+				continue
+			}
+
+			var r record
+			if err := json.Unmarshal(value, &r); err != nil {
+				return nil, fmt.Errorf("unable to decode notification %s: %w", eventIDs[i], err)
+			}
+			if stored := r.stored(); filter.Match(stored) {
+				result = append(result, stored)
+				if filter.Limit > 0 && len(result) == filter.Limit {
+					return result, nil
+				}
+			}
+		}
+
+		if len(eventIDs) < listPageSize {
+			return result, nil
+		}
+	}
+}
+
+func (s *RedisStore) get(conn redis.Conn, eventID string) (domain.StoredNotification, error) {
+	value, err := redis.Bytes(conn.Do("GET", s.keyPrefix+eventID))
+	if errors.Is(err, redis.ErrNil) {
+		return domain.StoredNotification{}, domain.ErrNotificationNotFound
+	}
+	if err != nil {
+		return domain.StoredNotification{}, fmt.Errorf("unable to read the notification: %w", err)
+	}
+
+	var r record
+	if err := json.Unmarshal(value, &r); err != nil {
+		return domain.StoredNotification{}, fmt.Errorf("unable to decode the notification: %w", err)
+	}
+
+	return r.stored(), nil
+}
+
+// score is the index score of the update time, in milliseconds.
+func score(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
@@ -0,0 +1,69 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/checkpoint"
+)
+
+var _ domain.DeferredNotifier = &QueuedNotifier{}
+var _ domain.NamedNotifier = &QueuedNotifier{}
+
+// QueuedNotifier queues the notifications and sends them to the wrapped
+// notifier with a pool of workers, so a slow downstream doesn't hold the
+// request of Stone.
+type QueuedNotifier struct {
+	log            *logrus.Logger
+	name           string
+	notifier       domain.Notifier
+	workers        int
+	enqueueTimeout time.Duration
+	sendTimeout    time.Duration
+	queue          chan domain.Notification
+	stop           chan struct{}
+	running        sync.WaitGroup
+	dropped        int64
+	// ctx is the context of the sends, canceled when the shutdown deadline
+	// is reached.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Checkpointer keeps the notifications that failed or were dropped on
+	// shutdown.
+	checkpoint.Checkpointer
+
+	// The senders waiting for room are tracked instead of holding mu, so the
+	// shutdown doesn't wait for them.
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	sending sync.WaitGroup
+}
+
+// New wraps the notifier, sending up to workers notifications at a time. A
+// full queue waits up to enqueueTimeout for room before rejecting, and each
+// send is bounded by sendTimeout.
+func New(name string, notifier domain.Notifier, workers, queueSize int, enqueueTimeout, sendTimeout time.Duration) *QueuedNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &QueuedNotifier{
+		name:           name,
+		Checkpointer:   checkpoint.New(notifier),
+		notifier:       notifier,
+		workers:        workers,
+		enqueueTimeout: enqueueTimeout,
+		sendTimeout:    sendTimeout,
+		queue:          make(chan domain.Notification, queueSize),
+		stop:           make(chan struct{}),
+		ctx:            ctx,
+		cancel:         cancel,
+		closing:        make(chan struct{}),
+	}
+}
+
+// Name is the name of the wrapped notifier.
+func (n *QueuedNotifier) Name() string {
+	return n.name
+}
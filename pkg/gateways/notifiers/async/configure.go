@@ -0,0 +1,21 @@
+package async
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+func (n *QueuedNotifier) Configure(log *logrus.Logger) error {
+	if err := n.notifier.Configure(log); err != nil {
+		return err
+	}
+
+	n.log = log
+	log.WithField("notifier", n.name).Infof("async: workers:[%d] queue_size:[%d] enqueue_timeout:[%s]", n.workers, cap(n.queue), n.enqueueTimeout)
+
+	for i := 0; i < n.workers; i++ {
+		n.running.Add(1)
+		go n.run()
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package async
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_consumer_async_queue_depth",
+		Help: "Number of notifications waiting to be sent by an async notifier.",
+	}, []string{"notifier"})
+
+	sentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_async_sent_total",
+		Help: "Number of notifications handled by an async notifier, by result.",
+	}, []string{"notifier", "result"})
+)
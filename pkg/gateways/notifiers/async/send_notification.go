@@ -0,0 +1,103 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var (
+	ErrQueueFull = errors.New("async queue is full")
+	ErrShutdown  = errors.New("async notifier is shutting down")
+)
+
+// Send only queues the notification. When the queue is full, it waits for
+// room up to the enqueue timeout, or until the request is done, failing then.
+func (n *QueuedNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	n.mu.RLock()
+	if n.closed {
+		n.mu.RUnlock()
+		return ErrShutdown
+	}
+	n.sending.Add(1)
+	n.mu.RUnlock()
+	defer n.sending.Done()
+
+	select {
+	case n.queue <- notification:
+		queueDepth.WithLabelValues(n.name).Inc()
+		return nil
+	default:
+	}
+
+	if n.enqueueTimeout > 0 {
+		timer := time.NewTimer(n.enqueueTimeout)
+		defer timer.Stop()
+
+		select {
+		case n.queue <- notification:
+			queueDepth.WithLabelValues(n.name).Inc()
+			return nil
+		case <-timer.C:
+		case <-ctx.Done():
+		case <-n.closing:
+			return ErrShutdown
+		}
+	}
+
+	sentTotal.WithLabelValues(n.name, "rejected").Inc()
+	n.log.WithField("notifier", n.name).Errorf("queue is full, rejecting notification %s", notification.Header.EventID)
+	return ErrQueueFull
+}
+
+func (n *QueuedNotifier) run() {
+	defer n.running.Done()
+
+	log := n.log.WithField("notifier", n.name)
+
+	for notification := range n.queue {
+		select {
+		case <-n.stop:
+			n.drop(notification)
+			continue
+		default:
+		}
+
+		queueDepth.WithLabelValues(n.name).Dec()
+
+		err := n.send(notification)
+		if err != nil {
+			log.WithError(err).Errorf("unable to send async notification %s", notification.Header.EventID)
+			sentTotal.WithLabelValues(n.name, "failure").Inc()
+			n.Checkpoint(log, notification, fmt.Sprintf("not sent by the async notifier %s: %v", n.name, err))
+		} else {
+			sentTotal.WithLabelValues(n.name, "success").Inc()
+		}
+	}
+}
+
+// send is bound by the send timeout and the shutdown, as the request that
+// queued the notification is already finished.
+func (n *QueuedNotifier) send(notification domain.Notification) error {
+	ctx := n.ctx
+	if n.sendTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.sendTimeout)
+		defer cancel()
+	}
+
+	return n.notifier.Send(ctx, notification)
+}
+
+func (n *QueuedNotifier) drop(notification domain.Notification) {
+	queueDepth.WithLabelValues(n.name).Dec()
+	sentTotal.WithLabelValues(n.name, "dropped").Inc()
+	atomic.AddInt64(&n.dropped, 1)
+
+	n.log.WithField("notifier", n.name).Errorf("dropping notification %s on shutdown: type[%s]", notification.Header.EventID, notification.Header.EventType)
+	n.Checkpoint(n.log.WithField("notifier", n.name), notification, fmt.Sprintf("dropped on shutdown by the async notifier %s", n.name))
+}
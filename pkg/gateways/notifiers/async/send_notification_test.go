@@ -0,0 +1,201 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// blockingNotifier holds each notification until released, tracking how many
+// are sent at a time.
+type blockingNotifier struct {
+	release chan struct{}
+	sent    chan string
+	running int32
+	peak    int32
+
+	mu sync.Mutex
+}
+
+func newBlockingNotifier() *blockingNotifier {
+	return &blockingNotifier{
+		release: make(chan struct{}),
+		sent:    make(chan string, 100),
+	}
+}
+
+func (b *blockingNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (b *blockingNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	running := atomic.AddInt32(&b.running, 1)
+	b.mu.Lock()
+	if running > b.peak {
+		b.peak = running
+	}
+	b.mu.Unlock()
+
+	<-b.release
+	atomic.AddInt32(&b.running, -1)
+
+	b.sent <- notification.Header.EventID
+	return nil
+}
+
+func testNotification(eventID string) domain.Notification {
+	return domain.Notification{
+		Header: domain.HeaderNotification{EventID: eventID, EventType: "type"},
+		Body:   "{}",
+	}
+}
+
+func newTestNotifier(t *testing.T, notifier domain.Notifier, workers, queueSize int, enqueueTimeout time.Duration) *QueuedNotifier {
+	t.Helper()
+
+	queued := New("test", notifier, workers, queueSize, enqueueTimeout, 0)
+	if err := queued.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	return queued
+}
+
+// waitRunning waits until the notifier is sending n notifications.
+func waitRunning(t *testing.T, notifier *blockingNotifier, n int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&notifier.running) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("notifier is sending %d notifications, want %d", atomic.LoadInt32(&notifier.running), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueuedNotifier_Workers(t *testing.T) {
+	notifier := newBlockingNotifier()
+	queued := newTestNotifier(t, notifier, 3, 10, 0)
+
+	// Send returns at once, while the notifications are still being sent.
+	const total = 6
+	for i := 0; i < total; i++ {
+		if err := queued.Send(context.Background(), testNotification("id")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	waitRunning(t, notifier, 3)
+	close(notifier.release)
+
+	for i := 0; i < total; i++ {
+		select {
+		case <-notifier.sent:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Send() notification %d was not sent", i)
+		}
+	}
+
+	if err := queued.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if notifier.peak != 3 {
+		t.Errorf("Send() sent %d notifications at a time, want 3", notifier.peak)
+	}
+}
+
+func TestQueuedNotifier_QueueFull(t *testing.T) {
+	tests := []struct {
+		name           string
+		enqueueTimeout time.Duration
+		freeRoom       bool
+		wantErr        error
+	}{
+		{
+			name:    "Rejected at once",
+			wantErr: ErrQueueFull,
+		},
+		{
+			name:           "Rejected after the enqueue timeout",
+			enqueueTimeout: 20 * time.Millisecond,
+			wantErr:        ErrQueueFull,
+		},
+		{
+			name:           "Queued when there's room before the enqueue timeout",
+			enqueueTimeout: 2 * time.Second,
+			freeRoom:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := newBlockingNotifier()
+			queued := newTestNotifier(t, notifier, 1, 1, tt.enqueueTimeout)
+
+			// The first notification is taken by the worker, and the second
+			// fills the queue.
+			if err := queued.Send(context.Background(), testNotification("1")); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			waitRunning(t, notifier, 1)
+			if err := queued.Send(context.Background(), testNotification("2")); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if tt.freeRoom {
+				go func() {
+					time.Sleep(20 * time.Millisecond)
+					notifier.release <- struct{}{}
+				}()
+			}
+
+			if err := queued.Send(context.Background(), testNotification("3")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+
+			close(notifier.release)
+			if err := queued.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestQueuedNotifier_ShutdownDropsPending(t *testing.T) {
+	notifier := newBlockingNotifier()
+	queued := newTestNotifier(t, notifier, 1, 10, 0)
+
+	for i := 0; i < 5; i++ {
+		if err := queued.Send(context.Background(), testNotification("id")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	waitRunning(t, notifier, 1)
+
+	// The running notification finishes once the shutdown deadline stops the
+	// workers.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-queued.stop
+		close(notifier.release)
+	}()
+
+	if err := queued.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the dropped notifications")
+	}
+	if queued.dropped != 4 {
+		t.Errorf("Shutdown() dropped = %d, want 4", queued.dropped)
+	}
+	if err := queued.Send(context.Background(), testNotification("id")); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Send() after shutdown error = %v, want %v", err, ErrShutdown)
+	}
+}
@@ -0,0 +1,55 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Shutdown stops accepting notifications and keeps sending the queued ones
+// until the context is done, when the running sends are canceled. The
+// notifications still in the queue are dropped, and stored as dead letters
+// when there's a dead letter store. A wrapped deferred notifier is shut down
+// next.
+func (n *QueuedNotifier) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	closed := n.closed
+	n.closed = true
+	n.mu.Unlock()
+
+	// No sender is added once closed, and the waiting ones give up.
+	if !closed {
+		close(n.closing)
+		n.sending.Wait()
+		close(n.queue)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		close(n.stop)
+		n.cancel()
+		<-done
+	}
+	n.cancel()
+
+	if deferred, ok := n.notifier.(domain.DeferredNotifier); ok {
+		if err := deferred.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	if dropped := atomic.LoadInt64(&n.dropped); dropped > 0 {
+		return fmt.Errorf("%d notifications dropped on shutdown", dropped)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeDeadLetterStore struct {
+	mu          sync.Mutex
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func TestQueuedNotifier_ShutdownStoresDropped(t *testing.T) {
+	notifier := newBlockingNotifier()
+	queued := newTestNotifier(t, notifier, 1, 10, 0)
+	store := &fakeDeadLetterStore{}
+	queued.SetDeadLetterStore(store)
+
+	for _, eventID := range []string{"1", "2", "3"} {
+		if err := queued.Send(context.Background(), testNotification(eventID)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	waitRunning(t, notifier, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-queued.stop
+		close(notifier.release)
+	}()
+
+	if err := queued.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the dropped notifications")
+	}
+
+	if len(store.deadLetters) != 2 {
+		t.Fatalf("Shutdown() stored %d dead letters, want 2", len(store.deadLetters))
+	}
+	for i, eventID := range []string{"2", "3"} {
+		deadLetter := store.deadLetters[i]
+		if deadLetter.Input.Header.EventID != eventID || deadLetter.Notification.Body != "{}" {
+			t.Errorf("Shutdown() dead letter = %+v, want notification %s", deadLetter, eventID)
+		}
+		if deadLetter.Reason != "dropped on shutdown by the async notifier test" {
+			t.Errorf("Shutdown() dead letter reason = %q", deadLetter.Reason)
+		}
+	}
+}
+
+// failingNotifier fails each notification, once its context is done when it
+// waits for it.
+type failingNotifier struct {
+	wait bool
+}
+
+func (f *failingNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *failingNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	if f.wait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return errors.New("failed")
+}
+
+func TestQueuedNotifier_StoresFailed(t *testing.T) {
+	tests := []struct {
+		name        string
+		notifier    *failingNotifier
+		sendTimeout time.Duration
+	}{
+		{
+			name:     "Send failure",
+			notifier: &failingNotifier{},
+		},
+		{
+			name:        "Send timeout",
+			notifier:    &failingNotifier{wait: true},
+			sendTimeout: 20 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queued := New("test", tt.notifier, 1, 10, 0, tt.sendTimeout)
+			store := &fakeDeadLetterStore{}
+			queued.SetDeadLetterStore(store)
+			if err := queued.Configure(logrus.New()); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			if err := queued.Send(context.Background(), testNotification("1")); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if err := queued.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+
+			if len(store.deadLetters) != 1 || store.deadLetters[0].Input.Header.EventID != "1" {
+				t.Errorf("dead letters = %+v, want notification 1", store.deadLetters)
+			}
+		})
+	}
+}
+
+func TestQueuedNotifier_ShutdownDoesntWaitSenders(t *testing.T) {
+	notifier := newBlockingNotifier()
+	queued := newTestNotifier(t, notifier, 1, 1, time.Hour)
+
+	// The worker holds the first notification, and the second fills the
+	// queue, so the third waits for room.
+	for _, eventID := range []string{"1", "2"} {
+		if err := queued.Send(context.Background(), testNotification(eventID)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		waitRunning(t, notifier, 1)
+	}
+	waiting := make(chan error, 1)
+	go func() {
+		waiting <- queued.Send(context.Background(), testNotification("3"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-queued.stop
+		close(notifier.release)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		queued.Shutdown(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Shutdown() waited for the sender")
+	}
+	if err := <-waiting; !errors.Is(err, ErrShutdown) {
+		t.Errorf("Send() error = %v, want %v", err, ErrShutdown)
+	}
+}
@@ -0,0 +1,107 @@
+package awsclient
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// MaxAttributes is the limit of message attributes of SQS and SNS.
+const MaxAttributes = 10
+
+// attributeName has the characters allowed in the attribute names.
+var attributeName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,256}$`)
+
+// Config is the region, endpoint and credentials of an AWS client.
+type Config struct {
+	Region string
+	// Endpoint allows a compatible service, like localstack.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	// RoleARN is assumed with the credentials, like to publish to another
+	// account.
+	RoleARN string
+}
+
+// NewSession uses the static credentials when the access key is set, or else
+// the default chain: the AWS_* environment variables, the shared files and
+// the instance or task role.
+func NewSession(cfg Config) (*session.Session, error) {
+	if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+		return nil, fmt.Errorf("the access key id and the secret access key must be set together")
+	}
+
+	awsConfig := aws.NewConfig().WithRegion(cfg.Region)
+	if cfg.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.AccessKeyID != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create aws session: %w", err)
+	}
+
+	if cfg.RoleARN != "" {
+		sess = sess.Copy(aws.NewConfig().WithCredentials(stscreds.NewCredentials(sess, cfg.RoleARN)))
+	}
+
+	return sess, nil
+}
+
+// CheckAttributeNames validates the names of the configured attributes.
+func CheckAttributeNames(names ...string) error {
+	for _, name := range names {
+		if !validAttributeName(name) {
+			return fmt.Errorf("invalid message attribute name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Attributes merges the serializer headers and the mapping attributes, which
+// win. The empty values are left out, as they aren't accepted, and the names
+// are returned sorted.
+func Attributes(serializerHeaders, mapping map[string]string) (map[string]string, []string, error) {
+	values := map[string]string{}
+	for name, value := range serializerHeaders {
+		values[name] = value
+	}
+	for name, value := range mapping {
+		values[name] = value
+	}
+
+	names := make([]string, 0, len(values))
+	for name, value := range values {
+		if value == "" {
+			delete(values, name)
+			continue
+		}
+		if !validAttributeName(name) {
+			return nil, nil, fmt.Errorf("invalid message attribute name %q", name)
+		}
+		names = append(names, name)
+	}
+	if len(names) > MaxAttributes {
+		return nil, nil, fmt.Errorf("%d message attributes, more than the maximum of %d", len(names), MaxAttributes)
+	}
+	sort.Strings(names)
+
+	return values, names, nil
+}
+
+func validAttributeName(name string) bool {
+	lower := strings.ToLower(name)
+	return attributeName.MatchString(name) && !strings.HasPrefix(lower, "aws.") && !strings.HasPrefix(lower, "amazon.") &&
+		!strings.HasPrefix(name, ".") && !strings.HasSuffix(name, ".") && !strings.Contains(name, "..")
+}
@@ -0,0 +1,79 @@
+package awsclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "Default chain", cfg: Config{Region: "us-east-1"}},
+		{name: "Static credentials", cfg: Config{Region: "us-east-1", AccessKeyID: "id", SecretAccessKey: "secret"}},
+		{name: "Assumed role", cfg: Config{Region: "us-east-1", RoleARN: "arn:aws:iam::123456789012:role/publisher"}},
+		{name: "Access key without secret", cfg: Config{Region: "us-east-1", AccessKeyID: "id"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSession(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer map[string]string
+		mapping    map[string]string
+		want       map[string]string
+		wantNames  []string
+		wantErr    bool
+	}{
+		{
+			name:       "Merged and sorted",
+			serializer: map[string]string{"Content-Type": "application/json"},
+			mapping:    map[string]string{"X-Event-Type": "payment.created", "X-Event-Id": "1", "X-Empty": ""},
+			want:       map[string]string{"Content-Type": "application/json", "X-Event-Id": "1", "X-Event-Type": "payment.created"},
+			wantNames:  []string{"Content-Type", "X-Event-Id", "X-Event-Type"},
+		},
+		{
+			name:    "Reserved prefix",
+			mapping: map[string]string{"AWS.Trace": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid character",
+			mapping: map[string]string{"Event Id": "1"},
+			wantErr: true,
+		},
+		{
+			name: "Too many",
+			mapping: map[string]string{
+				"A1": "1", "A2": "1", "A3": "1", "A4": "1", "A5": "1", "A6": "1",
+				"A7": "1", "A8": "1", "A9": "1", "A10": "1", "A11": "1",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, names, err := Attributes(tt.serializer, tt.mapping)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Attributes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("Attributes() = %v, %v, want %v, %v", got, names, tt.want, tt.wantNames)
+			}
+		})
+	}
+}
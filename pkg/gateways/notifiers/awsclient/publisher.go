@@ -0,0 +1,109 @@
+package awsclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+)
+
+// The message groups of the FIFO queues and topics, which keep the order of
+// their messages.
+const (
+	MessageGroupEventID      = "event_id"
+	MessageGroupPartitionKey = "partition_key"
+)
+
+// PublisherConfig is the configuration shared by the SQS and SNS notifiers,
+// nested under their prefix, like SQS_REGION.
+type PublisherConfig struct {
+	Region string `split_words:"true" default:"us-east-1"`
+	// Endpoint allows a compatible service, like localstack.
+	Endpoint string `split_words:"true"`
+	// The static credentials, or the default chain when empty, and the role
+	// assumed with them.
+	AccessKeyID     string `split_words:"true"`
+	SecretAccessKey string `split_words:"true"`
+	RoleARN         string `split_words:"true"`
+	// MessageGroup is event_id, or partition_key for the extracted partition
+	// key, which falls back to the event id. Only used by FIFO queues and
+	// topics.
+	MessageGroup string `split_words:"true" default:"event_id"`
+	// The attributes carrying the event id, type and partition key, and the
+	// static attributes as "name=value" items separated by ';'. The partition
+	// key isn't sent when its attribute is empty.
+	EventIDAttribute      string `split_words:"true" default:"X-Stone-Webhook-Event-Id"`
+	EventTypeAttribute    string `split_words:"true" default:"X-Stone-Webhook-Event-Type"`
+	PartitionKeyAttribute string `split_words:"true"`
+	StaticAttributes      string `split_words:"true"`
+}
+
+// String leaves the secret access key out of the logs.
+func (c PublisherConfig) String() string {
+	return fmt.Sprintf("region:[%s] endpoint:[%s] access_key_id:[%s] role_arn:[%s] message_group:[%s] event_id_attribute:[%s] event_type_attribute:[%s] partition_key_attribute:[%s] static_attributes:[%s]",
+		c.Region, c.Endpoint, c.AccessKeyID, c.RoleARN, c.MessageGroup, c.EventIDAttribute, c.EventTypeAttribute, c.PartitionKeyAttribute, c.StaticAttributes)
+}
+
+// Publisher has the session, the attribute mapping and the message group of
+// a SQS or SNS notifier.
+type Publisher struct {
+	Session      *session.Session
+	Attributes   headers.Mapping
+	MessageGroup string
+}
+
+// NewPublisher validates the attributes and the message group, and creates
+// the session.
+func NewPublisher(cfg PublisherConfig) (Publisher, error) {
+	mapping, err := headers.New(cfg.EventIDAttribute, cfg.EventTypeAttribute, cfg.PartitionKeyAttribute, cfg.StaticAttributes)
+	if err != nil {
+		return Publisher{}, fmt.Errorf("invalid attributes: %v", err)
+	}
+	if err := checkAttributes(mapping); err != nil {
+		return Publisher{}, err
+	}
+
+	messageGroup := strings.ToLower(strings.TrimSpace(cfg.MessageGroup))
+	if messageGroup != MessageGroupEventID && messageGroup != MessageGroupPartitionKey {
+		return Publisher{}, fmt.Errorf("invalid message group %q, must be %s or %s", cfg.MessageGroup, MessageGroupEventID, MessageGroupPartitionKey)
+	}
+
+	sess, err := NewSession(Config{
+		Region:          cfg.Region,
+		Endpoint:        cfg.Endpoint,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		RoleARN:         cfg.RoleARN,
+	})
+	if err != nil {
+		return Publisher{}, err
+	}
+
+	return Publisher{Session: sess, Attributes: mapping, MessageGroup: messageGroup}, nil
+}
+
+func checkAttributes(mapping headers.Mapping) error {
+	names := []string{mapping.EventID, mapping.EventType}
+	if mapping.PartitionKey != "" {
+		names = append(names, mapping.PartitionKey)
+	}
+	for name := range mapping.Static {
+		names = append(names, name)
+	}
+
+	return CheckAttributeNames(names...)
+}
+
+// MessageGroupID returns the message group of the notification in a FIFO
+// queue or topic: its partition key with the partition_key group, when
+// extracted, or else its event id.
+func MessageGroupID(messageGroup string, notification domain.Notification) string {
+	if messageGroup == MessageGroupPartitionKey && notification.Fields.PartitionKey != "" {
+		return notification.Fields.PartitionKey
+	}
+
+	return notification.Header.EventID
+}
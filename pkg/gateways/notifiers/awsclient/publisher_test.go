@@ -0,0 +1,113 @@
+package awsclient
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestPublisherConfig_Keys(t *testing.T) {
+	var spec struct {
+		Publisher PublisherConfig `envconfig:"SQS"`
+	}
+
+	// Each key is only read with the prefix, with no unprefixed fallback.
+	var out bytes.Buffer
+	if err := envconfig.Usagef("", &spec, &out, "{{range .}}{{.Key}}={{.Alt}}\n{{end}}"); err != nil {
+		t.Fatalf("Usagef() error = %v", err)
+	}
+
+	want := []string{
+		"SQS_REGION=", "SQS_ENDPOINT=", "SQS_ACCESS_KEY_ID=", "SQS_SECRET_ACCESS_KEY=", "SQS_ROLE_ARN=", "SQS_MESSAGE_GROUP=",
+		"SQS_EVENT_ID_ATTRIBUTE=", "SQS_EVENT_TYPE_ATTRIBUTE=", "SQS_PARTITION_KEY_ATTRIBUTE=", "SQS_STATIC_ATTRIBUTES=",
+	}
+	if got := strings.Fields(out.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("PublisherConfig keys = %v, want %v", got, want)
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	valid := PublisherConfig{
+		Region:             "us-east-1",
+		MessageGroup:       " Partition_Key ",
+		EventIDAttribute:   "X-Stone-Webhook-Event-Id",
+		EventTypeAttribute: "X-Stone-Webhook-Event-Type",
+	}
+
+	tests := []struct {
+		name             string
+		cfg              func(c *PublisherConfig)
+		wantMessageGroup string
+		wantErr          bool
+	}{
+		{
+			name:             "Valid",
+			wantMessageGroup: MessageGroupPartitionKey,
+		},
+		{
+			name:    "Invalid message group",
+			cfg:     func(c *PublisherConfig) { c.MessageGroup = "account" },
+			wantErr: true,
+		},
+		{
+			name:    "Invalid attribute name",
+			cfg:     func(c *PublisherConfig) { c.StaticAttributes = "aws.team=payments" },
+			wantErr: true,
+		},
+		{
+			name:    "Access key without secret",
+			cfg:     func(c *PublisherConfig) { c.AccessKeyID = "id" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+
+			publisher, err := NewPublisher(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPublisher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if publisher.MessageGroup != tt.wantMessageGroup {
+				t.Errorf("NewPublisher() message group = %q, want %q", publisher.MessageGroup, tt.wantMessageGroup)
+			}
+		})
+	}
+}
+
+func TestMessageGroupID(t *testing.T) {
+	notification := func(partitionKey string) domain.Notification {
+		return domain.Notification{
+			Header: domain.HeaderNotification{EventID: "1"},
+			Fields: domain.NotificationFields{PartitionKey: partitionKey},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		messageGroup string
+		notification domain.Notification
+		want         string
+	}{
+		{name: "Event id", messageGroup: MessageGroupEventID, notification: notification("acc-1"), want: "1"},
+		{name: "Partition key", messageGroup: MessageGroupPartitionKey, notification: notification("acc-1"), want: "acc-1"},
+		{name: "Partition key falls back to the event id", messageGroup: MessageGroupPartitionKey, notification: notification(""), want: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageGroupID(tt.messageGroup, tt.notification); got != tt.want {
+				t.Errorf("MessageGroupID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
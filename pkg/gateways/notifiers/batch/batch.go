@@ -0,0 +1,69 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/checkpoint"
+)
+
+var _ domain.DeferredNotifier = &Batcher{}
+var _ domain.NamedNotifier = &Batcher{}
+
+var ErrClosed = errors.New("batcher is closed")
+
+type request struct {
+	// ctx is the context of the Send, done once it stops waiting.
+	ctx          context.Context
+	notification domain.Notification
+	result       chan error
+}
+
+// Batcher accumulates the notifications and sends them to the wrapped
+// notifier in batches, flushed at size notifications or after the interval
+// since the first one. Send still waits for the result of its notification.
+type Batcher struct {
+	log      *logrus.Logger
+	name     string
+	notifier domain.BatchNotifier
+	size     int
+	interval time.Duration
+	requests chan request
+	stop     chan struct{}
+	done     chan struct{}
+	// unsent counts the notifications of the last batch that failed on
+	// shutdown.
+	unsent int
+	// ctx is the context of the sends, canceled when the shutdown deadline
+	// is reached.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Checkpointer keeps the failed notifications nobody waits for anymore.
+	checkpoint.Checkpointer
+}
+
+// New wraps the notifier, sending batches of up to size notifications.
+func New(name string, notifier domain.BatchNotifier, size int, interval time.Duration) *Batcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Batcher{
+		name:     name,
+		notifier: notifier,
+		size:     size,
+		interval: interval,
+		requests: make(chan request),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+
+		Checkpointer: checkpoint.New(notifier),
+	}
+}
+
+// Name is the name of the wrapped notifier.
+func (b *Batcher) Name() string {
+	return b.name
+}
@@ -0,0 +1,18 @@
+package batch
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+func (b *Batcher) Configure(log *logrus.Logger) error {
+	if err := b.notifier.Configure(log); err != nil {
+		return err
+	}
+
+	b.log = log
+	log.WithField("notifier", b.name).Infof("batched: size:[%d] flush_interval:[%s]", b.size, b.interval)
+
+	go b.run()
+
+	return nil
+}
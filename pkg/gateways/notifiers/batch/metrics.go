@@ -0,0 +1,12 @@
+package batch
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var batchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "webhook_consumer_batch_size",
+	Help:    "Number of notifications sent in each batch, by notifier and flush trigger.",
+	Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+}, []string{"notifier", "trigger"})
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Send adds the notification to the next batch and waits for its result. If
+// the context is done first, the notification may still be sent, and is
+// stored as a dead letter if it fails.
+func (b *Batcher) Send(ctx context.Context, notification domain.Notification) error {
+	req := request{
+		ctx:          ctx,
+		notification: notification,
+		result:       make(chan error, 1),
+	}
+
+	select {
+	case b.requests <- req:
+	case <-b.stop:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Batcher) run() {
+	defer close(b.done)
+
+	batch := make([]request, 0, b.size)
+	var flushAfter <-chan time.Time
+
+	for {
+		select {
+		case req := <-b.requests:
+			batch = append(batch, req)
+			if len(batch) == 1 {
+				flushAfter = time.After(b.interval)
+			}
+			if len(batch) >= b.size {
+				b.flush(batch, "size")
+				batch = batch[:0]
+				flushAfter = nil
+			}
+
+		case <-flushAfter:
+			b.flush(batch, "interval")
+			batch = batch[:0]
+			flushAfter = nil
+
+		case <-b.stop:
+			b.unsent = countFailed(b.flush(batch, "close"))
+			return
+		}
+	}
+}
+
+// flush sends the batch, and returns the error of each notification.
+func (b *Batcher) flush(batch []request, trigger string) []error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	notifications := make([]domain.Notification, len(batch))
+	for i, req := range batch {
+		notifications[i] = req.notification
+	}
+
+	batchSize.WithLabelValues(b.name, trigger).Observe(float64(len(batch)))
+
+	// The requests that added the notifications can have distinct contexts,
+	// so the batch is only bound by the shutdown.
+	errs := b.notifier.SendBatch(b.ctx, notifications)
+	if len(errs) != len(batch) {
+		err := fmt.Errorf("batch notifier returned %d results to %d notifications", len(errs), len(batch))
+		b.log.WithField("notifier", b.name).WithError(err).Error("invalid batch result")
+		errs = make([]error, len(batch))
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+
+	for i, req := range batch {
+		req.result <- errs[i]
+		if errs[i] != nil && req.ctx.Err() != nil {
+			b.Checkpoint(b.log.WithField("notifier", b.name), req.notification, fmt.Sprintf("not sent by the batched notifier %s: %v", b.name, errs[i]))
+		}
+	}
+
+	return errs
+}
+
+func countFailed(errs []error) int {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
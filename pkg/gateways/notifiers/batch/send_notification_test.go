@@ -0,0 +1,267 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// recorderNotifier records the size of each batch, failing the notifications
+// with the event ids in fail.
+type recorderNotifier struct {
+	mu      sync.Mutex
+	batches []int
+	fail    map[string]bool
+}
+
+func (r *recorderNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (r *recorderNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	return r.SendBatch(ctx, []domain.Notification{notification})[0]
+}
+
+func (r *recorderNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	r.mu.Lock()
+	r.batches = append(r.batches, len(notifications))
+	r.mu.Unlock()
+
+	errs := make([]error, len(notifications))
+	for i, notification := range notifications {
+		if r.fail[notification.Header.EventID] {
+			errs[i] = errors.New("failed")
+		}
+	}
+
+	return errs
+}
+
+func (r *recorderNotifier) Batches() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int{}, r.batches...)
+}
+
+func testNotification(eventID string) domain.Notification {
+	return domain.Notification{
+		Header: domain.HeaderNotification{EventID: eventID, EventType: "type"},
+		Body:   "{}",
+	}
+}
+
+func newTestBatcher(t *testing.T, notifier *recorderNotifier, size int, interval time.Duration) *Batcher {
+	t.Helper()
+
+	batcher := New("test", notifier, size, interval)
+	if err := batcher.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() { batcher.Shutdown(context.Background()) })
+
+	return batcher
+}
+
+// sendAll sends the notifications concurrently, returning the error of each.
+func sendAll(batcher *Batcher, total int) []error {
+	errs := make([]error, total)
+
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = batcher.Send(context.Background(), testNotification(fmt.Sprintf("id-%d", i)))
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func TestBatcher_SizeFlush(t *testing.T) {
+	notifier := &recorderNotifier{fail: map[string]bool{"id-3": true}}
+
+	// The interval is never reached, so only full batches are sent.
+	batcher := newTestBatcher(t, notifier, 5, time.Hour)
+
+	errs := sendAll(batcher, 10)
+
+	for i, err := range errs {
+		if wantErr := i == 3; (err != nil) != wantErr {
+			t.Errorf("Send(id-%d) error = %v, wantErr %v", i, err, wantErr)
+		}
+	}
+
+	batches := notifier.Batches()
+	if len(batches) != 2 || batches[0] != 5 || batches[1] != 5 {
+		t.Errorf("batches = %v, want [5 5]", batches)
+	}
+}
+
+func TestBatcher_IntervalFlush(t *testing.T) {
+	notifier := &recorderNotifier{}
+	batcher := newTestBatcher(t, notifier, 100, 50*time.Millisecond)
+
+	start := time.Now()
+	errs := sendAll(batcher, 3)
+	elapsed := time.Since(start)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Send(id-%d) error = %v", i, err)
+		}
+	}
+
+	if batches := notifier.Batches(); len(batches) != 1 || batches[0] != 3 {
+		t.Errorf("batches = %v, want [3]", batches)
+	}
+
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("batch flushed after %s, before the interval", elapsed)
+	}
+}
+
+func TestBatcher_InvalidResult(t *testing.T) {
+	notifier := &shortNotifier{}
+	batcher := New("test", notifier, 2, time.Hour)
+	if err := batcher.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	defer batcher.Shutdown(context.Background())
+
+	for i, err := range sendAll(batcher, 2) {
+		if err == nil {
+			t.Errorf("Send(id-%d) error = nil, want error", i)
+		}
+	}
+}
+
+func TestBatcher_Shutdown(t *testing.T) {
+	notifier := &recorderNotifier{}
+	batcher := New("test", notifier, 100, time.Hour)
+	if err := batcher.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- batcher.Send(context.Background(), testNotification("id"))
+	}()
+
+	// Wait the notification to be pending, then shut down.
+	time.Sleep(20 * time.Millisecond)
+	if err := batcher.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+
+	if err := <-result; err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	if batches := notifier.Batches(); len(batches) != 1 || batches[0] != 1 {
+		t.Errorf("batches = %v, want [1]", batches)
+	}
+
+	if err := batcher.Send(context.Background(), testNotification("id")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Send() after Shutdown() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+// blockingNotifier only returns when the context is done.
+type blockingNotifier struct {
+	recorderNotifier
+}
+
+func (b *blockingNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	<-ctx.Done()
+	errs := make([]error, len(notifications))
+	for i := range errs {
+		errs[i] = ctx.Err()
+	}
+	return errs
+}
+
+func TestBatcher_ShutdownDeadline(t *testing.T) {
+	notifier := &blockingNotifier{}
+	batcher := New("test", notifier, 100, time.Hour)
+	if err := batcher.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- batcher.Send(context.Background(), testNotification("id"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := batcher.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the unsent notifications")
+	}
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("Send() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+type fakeDeadLetterStore struct {
+	mu          sync.Mutex
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func TestBatcher_ShutdownStoresAbandoned(t *testing.T) {
+	notifier := &blockingNotifier{}
+	batcher := New("test", notifier, 100, time.Hour)
+	store := &fakeDeadLetterStore{}
+	batcher.SetDeadLetterStore(store)
+	if err := batcher.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	// The request stops waiting before the batch fails.
+	sendCtx, cancelSend := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelSend()
+	if err := batcher.Send(sendCtx, testNotification("id")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := batcher.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the unsent notifications")
+	}
+
+	if len(store.deadLetters) != 1 || store.deadLetters[0].Input.Header.EventID != "id" {
+		t.Errorf("Shutdown() dead letters = %+v, want notification id", store.deadLetters)
+	}
+}
+
+// shortNotifier returns less results than notifications.
+type shortNotifier struct {
+	recorderNotifier
+}
+
+func (s *shortNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	return nil
+}
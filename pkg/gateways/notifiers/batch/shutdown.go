@@ -0,0 +1,40 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Shutdown stops accepting notifications and sends the pending batch until
+// the context is done, when the send is canceled, storing the failed
+// notifications nobody waits for as dead letters. A wrapped deferred notifier
+// is shut down next.
+func (b *Batcher) Shutdown(ctx context.Context) error {
+	select {
+	case <-b.stop:
+	default:
+		close(b.stop)
+	}
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		b.cancel()
+		<-b.done
+	}
+	b.cancel()
+
+	if deferred, ok := b.notifier.(domain.DeferredNotifier); ok {
+		if err := deferred.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	if b.unsent > 0 {
+		return fmt.Errorf("%d notifications not sent on shutdown", b.unsent)
+	}
+
+	return nil
+}
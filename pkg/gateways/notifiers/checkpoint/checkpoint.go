@@ -0,0 +1,54 @@
+package checkpoint
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Checkpointer stores the notifications a deferred notifier couldn't send as
+// dead letters, so they aren't lost. It's embedded by the notifiers, and
+// doesn't store anything without a dead letter store.
+type Checkpointer struct {
+	wrapped     domain.Notifier
+	deadLetters domain.DeadLetterStore
+}
+
+// New returns a checkpointer for the notifier wrapping the given one.
+func New(wrapped domain.Notifier) Checkpointer {
+	return Checkpointer{wrapped: wrapped}
+}
+
+// SetDeadLetterStore sets the dead letter store, also of the wrapped notifier.
+func (c *Checkpointer) SetDeadLetterStore(store domain.DeadLetterStore) {
+	c.deadLetters = store
+
+	if checkpointed, ok := c.wrapped.(interface {
+		SetDeadLetterStore(store domain.DeadLetterStore)
+	}); ok {
+		checkpointed.SetDeadLetterStore(store)
+	}
+}
+
+// Checkpoint stores the notification as a dead letter. It has no envelope as
+// received, only the header and the payload.
+func (c *Checkpointer) Checkpoint(log logrus.FieldLogger, notification domain.Notification, reason string) {
+	if c.deadLetters == nil {
+		return
+	}
+
+	deadLetter := domain.DeadLetter{
+		Input:        domain.NotificationInput{Header: notification.Header},
+		Notification: notification,
+		Reason:       reason,
+		FailedAt:     time.Now(),
+	}
+
+	// The shutdown context may be already done.
+	if err := c.deadLetters.Store(context.Background(), deadLetter); err != nil {
+		log.WithError(err).Errorf("unable to store the dead letter of notification %s: %s", notification.Header.EventID, reason)
+	}
+}
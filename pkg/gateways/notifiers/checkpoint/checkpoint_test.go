@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeDeadLetterStore struct {
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+// wrappedNotifier is a deferred notifier with its own checkpointer.
+type wrappedNotifier struct {
+	Checkpointer
+}
+
+func (w *wrappedNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (w *wrappedNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	return nil
+}
+
+func TestCheckpointer_Checkpoint(t *testing.T) {
+	notification := domain.Notification{Header: domain.HeaderNotification{EventID: "1", EventType: "type"}, Body: "{}"}
+
+	t.Run("Without a dead letter store", func(t *testing.T) {
+		checkpointer := New(nil)
+		checkpointer.Checkpoint(logrus.New(), notification, "dropped")
+	})
+
+	t.Run("Stores the dead letter, also on the wrapped notifier", func(t *testing.T) {
+		wrapped := &wrappedNotifier{}
+		checkpointer := New(wrapped)
+		store := &fakeDeadLetterStore{}
+		checkpointer.SetDeadLetterStore(store)
+
+		checkpointer.Checkpoint(logrus.New(), notification, "dropped")
+		wrapped.Checkpoint(logrus.New(), notification, "dropped by the wrapped")
+
+		if len(store.deadLetters) != 2 {
+			t.Fatalf("Checkpoint() stored %d dead letters, want 2", len(store.deadLetters))
+		}
+		deadLetter := store.deadLetters[0]
+		if deadLetter.Input.Header.EventID != "1" || deadLetter.Notification.Body != "{}" || deadLetter.Reason != "dropped" {
+			t.Errorf("Checkpoint() dead letter = %+v", deadLetter)
+		}
+	})
+}
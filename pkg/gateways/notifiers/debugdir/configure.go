@@ -0,0 +1,64 @@
+package debugdir
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+)
+
+// productionEnvironments refuse the notifier, whatever the dev flag.
+var productionEnvironments = []string{"prod", "production"}
+
+type Config struct {
+	// DevMode must be explicitly set, and Environment must not be a
+	// production one.
+	DevMode     bool   `envconfig:"DEV_MODE" default:"false"`
+	Environment string `envconfig:"ENVIRONMENT"`
+	Dir         string `envconfig:"DEBUG_DIR_PATH"`
+	// The oldest files are removed beyond MaxFiles or MaxBytes.
+	MaxFiles int   `envconfig:"DEBUG_DIR_MAX_FILES" default:"100"`
+	MaxBytes int64 `envconfig:"DEBUG_DIR_MAX_BYTES" default:"104857600"`
+}
+
+func (n *DebugDirNotifier) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	return n.configure(config, log)
+}
+
+func (n *DebugDirNotifier) configure(config Config, log *logrus.Logger) error {
+	for _, environment := range productionEnvironments {
+		if strings.EqualFold(strings.TrimSpace(config.Environment), environment) {
+			return fmt.Errorf("refusing to write cleartext payloads to disk in the %s environment", config.Environment)
+		}
+	}
+	if !config.DevMode {
+		return fmt.Errorf("the debugdir notifier writes cleartext payloads to disk, it requires DEV_MODE=true")
+	}
+	if config.Dir == "" {
+		return fmt.Errorf("the debugdir notifier requires DEBUG_DIR_PATH")
+	}
+	if config.MaxFiles < 1 || config.MaxBytes < 1 {
+		return fmt.Errorf("the debugdir max files and max bytes must be positive")
+	}
+
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return fmt.Errorf("creating the debug dir %s: %v", config.Dir, err)
+	}
+
+	n.log = log
+	n.dir = config.Dir
+	n.maxFiles = config.MaxFiles
+	n.maxBytes = config.MaxBytes
+
+	log.WithField("notifier", "debugdir").Warnf("DEV MODE: writing the cleartext payloads to %s, never enable it in production", config.Dir)
+
+	return nil
+}
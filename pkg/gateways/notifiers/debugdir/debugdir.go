@@ -0,0 +1,33 @@
+package debugdir
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.Notifier = &DebugDirNotifier{}
+var _ domain.NamedNotifier = &DebugDirNotifier{}
+
+// DebugDirNotifier writes each decrypted notification to a local directory,
+// as a JSON file named by the event id. It's only for local development, as
+// the files have the cleartext payloads.
+type DebugDirNotifier struct {
+	log      *logrus.Logger
+	dir      string
+	maxFiles int
+	maxBytes int64
+
+	// mu serializes the writes with the rotation of the directory.
+	mu sync.Mutex
+}
+
+func New() *DebugDirNotifier {
+	return &DebugDirNotifier{}
+}
+
+func (n *DebugDirNotifier) Name() string {
+	return "debugdir"
+}
@@ -0,0 +1,118 @@
+package debugdir
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
+)
+
+// The files are named with a prefix, so that only they are rotated, never the
+// other files of the directory.
+const (
+	filePrefix    = "notification-"
+	fileExtension = ".json"
+)
+
+// Record is the content of each file.
+type Record struct {
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	CreatedAt string          `json:"created_at,omitempty"`
+	Body      json.RawMessage `json:"body"`
+}
+
+func (n *DebugDirNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "debugdir", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n *DebugDirNotifier) send(ctx context.Context, notification domain.Notification) error {
+	record := Record{
+		EventID:   notification.Header.EventID,
+		EventType: notification.Header.EventType,
+		CreatedAt: notification.Header.CreatedAt,
+		Body:      json.RawMessage(notification.Body),
+	}
+	// The bodies that aren't JSON, like the empty ones, are kept as strings.
+	if !json.Valid(record.Body) {
+		body, _ := json.Marshal(notification.Body)
+		record.Body = body
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling the debug record: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	path := filepath.Join(n.dir, fileName(notification.Header.EventID))
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing the debug record: %w", err)
+	}
+
+	if err := n.rotate(); err != nil {
+		n.log.WithError(err).WithField("notifier", "debugdir").Warn("unable to rotate the debug dir")
+	}
+
+	return nil
+}
+
+// fileName keeps the event id from escaping the directory.
+func fileName(eventID string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, eventID)
+	if name == "" {
+		name = "_"
+	}
+
+	return filePrefix + name + fileExtension
+}
+
+// rotate removes the oldest notification files beyond the max files or max
+// bytes. The newest file is always kept.
+func (n *DebugDirNotifier) rotate() error {
+	infos, err := ioutil.ReadDir(n.dir)
+	if err != nil {
+		return err
+	}
+
+	files := []os.FileInfo{}
+	var total int64
+	for _, info := range infos {
+		name := info.Name()
+		if info.Mode().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileExtension) {
+			files = append(files, info)
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for len(files) > 1 && (len(files) > n.maxFiles || total > n.maxBytes) {
+		if err := os.Remove(filepath.Join(n.dir, files[0].Name())); err != nil {
+			return err
+		}
+		total -= files[0].Size()
+		files = files[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package debugdir
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestDebugDirNotifier_configure(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "Dev mode",
+			cfg:  Config{DevMode: true, Environment: "development", MaxFiles: 10, MaxBytes: 1024},
+		},
+		{
+			name:    "Without the dev flag",
+			cfg:     Config{Environment: "development", MaxFiles: 10, MaxBytes: 1024},
+			wantErr: true,
+		},
+		{
+			name:    "Production environment",
+			cfg:     Config{DevMode: true, Environment: "production", MaxFiles: 10, MaxBytes: 1024},
+			wantErr: true,
+		},
+		{
+			name:    "Production environment, in any case",
+			cfg:     Config{DevMode: true, Environment: " PROD ", MaxFiles: 10, MaxBytes: 1024},
+			wantErr: true,
+		},
+		{
+			name:    "Without limits",
+			cfg:     Config{DevMode: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Dir = filepath.Join(t.TempDir(), "payloads")
+
+			err := New().configure(tt.cfg, logrus.New())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			_, statErr := os.Stat(tt.cfg.Dir)
+			if tt.wantErr && statErr == nil {
+				t.Errorf("configure() created the dir %s, want refused", tt.cfg.Dir)
+			}
+		})
+	}
+}
+
+func TestDebugDirNotifier_Send(t *testing.T) {
+	dir := t.TempDir()
+	n := New()
+	if err := n.configure(Config{DevMode: true, Dir: dir, MaxFiles: 10, MaxBytes: 1024}, logrus.New()); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		notification domain.Notification
+		file         string
+		want         Record
+	}{
+		{
+			name: "JSON body",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				Body:   `{"amount":100}`,
+			},
+			file: "notification-930bbd6d.json",
+			want: Record{EventID: "930bbd6d", EventType: "cash_in_internal_transfer", Body: json.RawMessage(`{"amount":100}`)},
+		},
+		{
+			name: "Empty body",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", EventType: "heartbeat"},
+			},
+			file: "notification-1.json",
+			want: Record{EventID: "1", EventType: "heartbeat", Body: json.RawMessage(`""`)},
+		},
+		{
+			name: "Event id escaping the dir",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "../../etc/passwd", EventType: "heartbeat"},
+				Body:   `{}`,
+			},
+			file: "notification-______etc_passwd.json",
+			want: Record{EventID: "../../etc/passwd", EventType: "heartbeat", Body: json.RawMessage(`{}`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := n.Send(context.Background(), tt.notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Send() file not written: %v", err)
+			}
+
+			var got Record
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Send() invalid record %s: %v", data, err)
+			}
+			got.Body = compact(t, got.Body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Send() record = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugDirNotifier_Send_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	n := New()
+	if err := n.configure(Config{DevMode: true, Dir: dir, MaxFiles: 2, MaxBytes: 1024}, logrus.New()); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+
+	// The other files of the directory are never rotated.
+	other := filepath.Join(dir, "other.json")
+	if err := ioutil.WriteFile(other, []byte(`{}`), 0600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	if err := os.Chtimes(other, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("changing the file times: %v", err)
+	}
+
+	// The files get distinct modification times, oldest first.
+	start := time.Now().Add(-time.Hour)
+	for i, eventID := range []string{"1", "2", "3"} {
+		notification := domain.Notification{Header: domain.HeaderNotification{EventID: eventID}, Body: `{}`}
+		if err := n.Send(context.Background(), notification); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		modTime := start.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, fileName(eventID)), modTime, modTime); err != nil {
+			t.Fatalf("changing the file times: %v", err)
+		}
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading dir: %v", err)
+	}
+	got := []string{}
+	for _, info := range infos {
+		got = append(got, info.Name())
+	}
+
+	want := []string{"notification-2.json", "notification-3.json", "other.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Send() files = %v, want %v", got, want)
+	}
+}
+
+func compact(t *testing.T, body json.RawMessage) json.RawMessage {
+	t.Helper()
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Fatalf("invalid body %s: %v", body, err)
+	}
+	data, _ := json.Marshal(value)
+	return data
+}
@@ -0,0 +1,28 @@
+package failover
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Configure configures both notifiers and starts the reconciliation.
+func (f *Failover) Configure(log *logrus.Logger) error {
+	for _, member := range []Member{f.primary, f.secondary} {
+		if err := member.Notifier.Configure(log); err != nil {
+			return fmt.Errorf("configure failed in [%s] failover notifier: %v", member.Name, err)
+		}
+	}
+
+	f.log = log
+	log.WithField("notifier", f.primary.Name).Infof("failover: primary:[%s] secondary:[%s] reconcile_size:[%d] reconcile_interval:[%s]",
+		f.primary.Name, f.secondary.Name, f.size, f.interval)
+
+	if f.size > 0 {
+		go f.run()
+	} else {
+		close(f.done)
+	}
+
+	return nil
+}
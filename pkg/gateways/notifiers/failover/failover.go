@@ -0,0 +1,75 @@
+package failover
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.DeferredNotifier = &Failover{}
+var _ domain.NamedNotifier = &Failover{}
+
+// Member is a notifier of the failover, named for the logs and metrics.
+type Member struct {
+	Name     string
+	Notifier domain.Notifier
+}
+
+// Failover sends the notifications to the primary, and to the secondary only
+// when the primary fails. The failed over notifications are kept, up to the
+// reconcile size, and sent again to the primary when it recovers. They aren't
+// removed from the secondary, since the notifiers are write only.
+type Failover struct {
+	log       *logrus.Logger
+	primary   Member
+	secondary Member
+	size      int
+	interval  time.Duration
+
+	mu sync.Mutex
+	// pending has the failed over notifications not yet reconciled, the
+	// oldest first.
+	pending []domain.Notification
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New wraps the primary and the secondary notifiers. A zero reconcile size
+// disables the reconciliation.
+func New(primary, secondary Member, size int, interval time.Duration) (*Failover, error) {
+	if size < 0 || (size > 0 && interval <= 0) {
+		return nil, fmt.Errorf("invalid failover reconcile size %d or interval %s", size, interval)
+	}
+
+	return &Failover{
+		primary:   primary,
+		secondary: secondary,
+		size:      size,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Notifiers returns the wrapped notifiers, the primary first.
+func (f *Failover) Notifiers() []domain.Notifier {
+	return []domain.Notifier{f.primary.Notifier, f.secondary.Notifier}
+}
+
+// Pending returns the number of failed over notifications not yet
+// reconciled.
+func (f *Failover) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.pending)
+}
+
+// Name joins the names of the primary and the secondary.
+func (f *Failover) Name() string {
+	return f.primary.Name + "+" + f.secondary.Name
+}
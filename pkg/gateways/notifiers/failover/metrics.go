@@ -0,0 +1,24 @@
+package failover
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	secondaryUsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_failover_secondary_used_total",
+		Help: "Number of notifications sent to the secondary notifier, by result.",
+	}, []string{"notifier", "result"})
+
+	reconciled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_failover_reconciled_total",
+		Help: "Number of failed over notifications sent again to the primary notifier, by result.",
+	}, []string{"notifier", "result"})
+)
+
+const (
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"
+	resultDropped   = "dropped"
+)
@@ -0,0 +1,140 @@
+package failover
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Send sends the notification to the primary, failing over to the secondary.
+// It only fails when both fail.
+func (f *Failover) Send(ctx context.Context, notification domain.Notification) error {
+	primaryErr := f.primary.Notifier.Send(ctx, notification)
+	if primaryErr == nil {
+		return nil
+	}
+
+	log := f.log.WithField("notifier", f.primary.Name)
+	log.WithError(primaryErr).Warnf("primary notifier failed to send notification %s, failing over to [%s]", notification.Header.EventID, f.secondary.Name)
+
+	if err := f.secondary.Notifier.Send(ctx, notification); err != nil {
+		secondaryUsed.WithLabelValues(f.secondary.Name, resultFailed).Inc()
+		return fmt.Errorf("primary notifier [%s]: %v, secondary notifier [%s]: %w", f.primary.Name, primaryErr, f.secondary.Name, err)
+	}
+
+	secondaryUsed.WithLabelValues(f.secondary.Name, resultSucceeded).Inc()
+	f.keep(notification)
+
+	return nil
+}
+
+// Reconcile sends the failed over notifications again to the primary, the
+// oldest first, stopping at the first failure since the primary is still
+// down.
+func (f *Failover) Reconcile(ctx context.Context) error {
+	for {
+		notification, ok := f.next()
+		if !ok {
+			return nil
+		}
+
+		if err := f.primary.Notifier.Send(ctx, notification); err != nil {
+			reconciled.WithLabelValues(f.primary.Name, resultFailed).Inc()
+			f.putBack(notification)
+			return fmt.Errorf("unable to reconcile notification %s: %w", notification.Header.EventID, err)
+		}
+
+		reconciled.WithLabelValues(f.primary.Name, resultSucceeded).Inc()
+		f.log.WithField("notifier", f.primary.Name).Infof("failed over notification %s reconciled", notification.Header.EventID)
+	}
+}
+
+// Shutdown stops the reconciliation. The notifications not reconciled by then
+// are only in the secondary.
+func (f *Failover) Shutdown(ctx context.Context) error {
+	select {
+	case <-f.stop:
+	default:
+		close(f.stop)
+	}
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if pending := f.Pending(); pending > 0 {
+		return fmt.Errorf("%d failed over notifications not reconciled to [%s]", pending, f.primary.Name)
+	}
+
+	return nil
+}
+
+func (f *Failover) run() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			if f.Pending() == 0 {
+				continue
+			}
+			if err := f.Reconcile(context.Background()); err != nil {
+				f.log.WithError(err).WithField("notifier", f.primary.Name).Warn("primary notifier still failing, reconciliation postponed")
+			}
+		}
+	}
+}
+
+// keep queues the notification to be reconciled, dropping the oldest one when
+// full.
+func (f *Failover) keep(notification domain.Notification) {
+	if f.size == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = append(f.pending, notification)
+	f.trim()
+}
+
+func (f *Failover) next() (domain.Notification, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.pending) == 0 {
+		return domain.Notification{}, false
+	}
+
+	notification := f.pending[0]
+	f.pending = f.pending[1:]
+	return notification, true
+}
+
+// putBack returns a notification not reconciled to the front of the queue.
+func (f *Failover) putBack(notification domain.Notification) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = append([]domain.Notification{notification}, f.pending...)
+	f.trim()
+}
+
+func (f *Failover) trim() {
+	for len(f.pending) > f.size {
+		dropped := f.pending[0]
+		f.pending = f.pending[1:]
+		reconciled.WithLabelValues(f.primary.Name, resultDropped).Inc()
+		f.log.WithField("notifier", f.primary.Name).Errorf("failed over notification %s won't be reconciled, the queue is full", dropped.Header.EventID)
+	}
+}
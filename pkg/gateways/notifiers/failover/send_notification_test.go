@@ -0,0 +1,139 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// fakeNotifier fails with err, and keeps the event ids sent.
+type fakeNotifier struct {
+	err  error
+	sent []string
+}
+
+func (f *fakeNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.sent = append(f.sent, notification.Header.EventID)
+	return nil
+}
+
+func newFailover(t *testing.T, primary, secondary *fakeNotifier, size int) *Failover {
+	t.Helper()
+
+	// The interval is long enough to reconcile only when called by the test.
+	f, err := New(Member{Name: "proxy", Notifier: primary}, Member{Name: "redis", Notifier: secondary}, size, time.Hour)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := f.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() { _ = f.Shutdown(context.Background()) })
+
+	return f
+}
+
+func notification(eventID string) domain.Notification {
+	return domain.Notification{Header: domain.HeaderNotification{EventID: eventID}}
+}
+
+func TestFailover_Send(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name          string
+		primaryErr    error
+		secondaryErr  error
+		wantErr       bool
+		wantPrimary   int
+		wantSecondary int
+		wantPending   int
+	}{
+		{name: "Primary up", wantPrimary: 1},
+		{name: "Primary down, secondary up", primaryErr: failed, wantSecondary: 1, wantPending: 1},
+		{name: "Primary up, secondary down", secondaryErr: failed, wantPrimary: 1},
+		{name: "Both down", primaryErr: failed, secondaryErr: failed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeNotifier{err: tt.primaryErr}
+			secondary := &fakeNotifier{err: tt.secondaryErr}
+			f := newFailover(t, primary, secondary, 10)
+
+			err := f.Send(context.Background(), notification("1"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, failed) {
+				t.Errorf("Send() error = %v, want the secondary error", err)
+			}
+			if len(primary.sent) != tt.wantPrimary || len(secondary.sent) != tt.wantSecondary {
+				t.Errorf("sent = %d/%d, want %d/%d", len(primary.sent), len(secondary.sent), tt.wantPrimary, tt.wantSecondary)
+			}
+			if got := f.Pending(); got != tt.wantPending {
+				t.Errorf("Pending() = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestFailover_Reconcile(t *testing.T) {
+	primary := &fakeNotifier{err: errors.New("down")}
+	secondary := &fakeNotifier{}
+	f := newFailover(t, primary, secondary, 2)
+
+	for _, eventID := range []string{"1", "2", "3"} {
+		if err := f.Send(context.Background(), notification(eventID)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	// The oldest one is dropped, the queue keeps only two.
+	if got := f.Pending(); got != 2 {
+		t.Fatalf("Pending() = %d, want 2", got)
+	}
+
+	if err := f.Reconcile(context.Background()); err == nil {
+		t.Error("Reconcile() with the primary down, want an error")
+	}
+	if got := f.Pending(); got != 2 {
+		t.Errorf("Pending() after a failed reconciliation = %d, want 2", got)
+	}
+
+	primary.err = nil
+	if err := f.Reconcile(context.Background()); err != nil {
+		t.Errorf("Reconcile() error = %v", err)
+	}
+	if got := f.Pending(); got != 0 {
+		t.Errorf("Pending() = %d, want 0", got)
+	}
+	if len(primary.sent) != 2 || primary.sent[0] != "2" || primary.sent[1] != "3" {
+		t.Errorf("reconciled = %v, want [2 3]", primary.sent)
+	}
+}
+
+func TestFailover_Shutdown(t *testing.T) {
+	f := newFailover(t, &fakeNotifier{err: errors.New("down")}, &fakeNotifier{}, 10)
+
+	if err := f.Send(context.Background(), notification("1")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if err := f.Shutdown(context.Background()); err == nil {
+		t.Error("Shutdown() with a notification not reconciled, want an error")
+	}
+}
@@ -0,0 +1,99 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Mapping names the outgoing headers, or attributes, carrying the event id,
+// the event type and the partition key, with the static ones attached to
+// every notification.
+type Mapping struct {
+	EventID   string
+	EventType string
+	// PartitionKey is optional, the partition key isn't sent when empty.
+	PartitionKey string
+	Static       map[string]string
+}
+
+// New validates the mapping. The static headers are "name=value" items,
+// separated by ';'. The names are compared ignoring the case, as in HTTP.
+func New(eventID, eventType, partitionKey, static string, reserved ...string) (Mapping, error) {
+	mapping := Mapping{
+		EventID:      strings.TrimSpace(eventID),
+		EventType:    strings.TrimSpace(eventType),
+		PartitionKey: strings.TrimSpace(partitionKey),
+		Static:       map[string]string{},
+	}
+
+	if mapping.EventID == "" || mapping.EventType == "" {
+		return Mapping{}, fmt.Errorf("the event id and event type headers are mandatory")
+	}
+
+	used := append([]string{}, reserved...)
+	names := []string{mapping.EventID, mapping.EventType}
+	if mapping.PartitionKey != "" {
+		names = append(names, mapping.PartitionKey)
+	}
+	for _, name := range names {
+		if containsName(used, name) {
+			return Mapping{}, fmt.Errorf("duplicated header: %v", name)
+		}
+		used = append(used, name)
+	}
+
+	for _, item := range configuration.SplitList(static) {
+		parts := strings.SplitN(item, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return Mapping{}, fmt.Errorf("invalid static header, expected name=value: %v", item)
+		}
+
+		if containsName(used, name) {
+			return Mapping{}, fmt.Errorf("duplicated header: %v", name)
+		}
+		used = append(used, name)
+
+		mapping.Static[name] = strings.TrimSpace(parts[1])
+	}
+
+	return mapping, nil
+}
+
+// Values returns the headers of the notification.
+func (m Mapping) Values(notification domain.Notification) map[string]string {
+	values := make(map[string]string, len(m.Static)+3)
+	for name, value := range m.Static {
+		values[name] = value
+	}
+	values[m.EventID] = notification.Header.EventID
+	values[m.EventType] = notification.Header.EventType
+	if m.PartitionKey != "" {
+		values[m.PartitionKey] = partitionKey(notification)
+	}
+
+	return values
+}
+
+// partitionKey falls back to the event id when the notification has no
+// extracted fields.
+func partitionKey(notification domain.Notification) string {
+	if notification.Fields.PartitionKey != "" {
+		return notification.Fields.PartitionKey
+	}
+
+	return notification.Header.EventID
+}
+
+func containsName(names []string, name string) bool {
+	for _, used := range names {
+		if strings.EqualFold(used, name) {
+			return true
+		}
+	}
+
+	return false
+}
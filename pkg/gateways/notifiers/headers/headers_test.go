@@ -0,0 +1,150 @@
+package headers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		eventID      string
+		eventType    string
+		partitionKey string
+		static       string
+		reserved     []string
+		want         Mapping
+		wantErr      bool
+	}{
+		{
+			name:      "Without static headers",
+			eventID:   "X-Event-Id",
+			eventType: "X-Event-Type",
+			want:      Mapping{EventID: "X-Event-Id", EventType: "X-Event-Type", Static: map[string]string{}},
+		},
+		{
+			name:      "Static headers",
+			eventID:   "ce-id",
+			eventType: "ce-type",
+			static:    "ce-source = webhook-consumer; X-Token=a=b;",
+			want: Mapping{
+				EventID:   "ce-id",
+				EventType: "ce-type",
+				Static:    map[string]string{"ce-source": "webhook-consumer", "X-Token": "a=b"},
+			},
+		},
+		{
+			name:         "Partition key header",
+			eventID:      "X-Event-Id",
+			eventType:    "X-Event-Type",
+			partitionKey: " X-Partition-Key ",
+			want: Mapping{
+				EventID:      "X-Event-Id",
+				EventType:    "X-Event-Type",
+				PartitionKey: "X-Partition-Key",
+				Static:       map[string]string{},
+			},
+		},
+		{
+			name:         "Partition key header overriding the event id",
+			eventID:      "X-Event-Id",
+			eventType:    "X-Event-Type",
+			partitionKey: "x-event-id",
+			wantErr:      true,
+		},
+		{
+			name:      "Empty event id header",
+			eventType: "X-Event-Type",
+			wantErr:   true,
+		},
+		{
+			name:      "Same event id and type headers",
+			eventID:   "X-Event",
+			eventType: "x-event",
+			wantErr:   true,
+		},
+		{
+			name:      "Static header overriding the event type",
+			eventID:   "X-Event-Id",
+			eventType: "X-Event-Type",
+			static:    "x-event-type=other",
+			wantErr:   true,
+		},
+		{
+			name:      "Static header without value",
+			eventID:   "X-Event-Id",
+			eventType: "X-Event-Type",
+			static:    "X-Source",
+			wantErr:   true,
+		},
+		{
+			name:      "Reserved name",
+			eventID:   "Body",
+			eventType: "EventType",
+			reserved:  []string{"Body"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.eventID, tt.eventType, tt.partitionKey, tt.static, tt.reserved...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapping_Values(t *testing.T) {
+	mapping, err := New("ce-id", "ce-type", "", "ce-source=webhook-consumer")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got := mapping.Values(domain.Notification{Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"}})
+	want := map[string]string{"ce-id": "1", "ce-type": "payment.created", "ce-source": "webhook-consumer"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+}
+
+func TestMapping_Values_PartitionKey(t *testing.T) {
+	mapping, err := New("X-Event-Id", "X-Event-Type", "X-Partition-Key", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		notification domain.Notification
+		want         string
+	}{
+		{
+			name: "Extracted partition key",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1"},
+				Fields: domain.NotificationFields{PartitionKey: "acc-1"},
+			},
+			want: "acc-1",
+		},
+		{
+			name:         "Event id fallback",
+			notification: domain.Notification{Header: domain.HeaderNotification{EventID: "1"}},
+			want:         "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapping.Values(tt.notification)["X-Partition-Key"]; got != tt.want {
+				t.Errorf("Values() partition key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package instrument
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/labelguard"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// defaultMaxEventTypes is the default of METRICS_MAX_EVENT_TYPES.
+const defaultMaxEventTypes = 50
+
+// eventTypeLabels bounds the event types of the notifier metrics, it's
+// replaced by SetMaxEventTypes.
+var eventTypeLabels = labelguard.New(defaultMaxEventTypes)
+
+var tracer = otel.Tracer("github.com/stone-co/webhook-consumer/pkg/gateways/notifiers")
+
+var (
+	sendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_notifier_sends_total",
+		Help: "Number of notifications sent by each notifier, by event type and outcome.",
+	}, []string{"notifier", "event_type", "outcome"})
+
+	sendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_consumer_notifier_send_duration_seconds",
+		Help:    "Duration of the sends of each notifier, by event type and outcome.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"notifier", "event_type", "outcome"})
+)
+
+// SetMaxEventTypes bounds the distinct event types in the labels. It must be
+// called before the notifiers are used.
+func SetMaxEventTypes(max int) {
+	eventTypeLabels = labelguard.New(max)
+}
+
+// StartSend starts the span of a send of the notification, returning the
+// function that ends it and records the metrics. Each attempt of a retried
+// notification is a send.
+func StartSend(ctx context.Context, notifier string, notification domain.Notification) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "notify "+notifier, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("webhook.notifier", notifier),
+		attribute.String("webhook.event_id", notification.Header.EventID),
+		attribute.String("webhook.event_type", notification.Header.EventType),
+	))
+
+	return ctx, func(err error) {
+		observe(notifier, notification, time.Since(start), err)
+		endSpan(span, err)
+	}
+}
+
+// StartBatch starts the span of a send of the batch, returning the function
+// that ends it and records a send of each notification, with the batch
+// duration.
+func StartBatch(ctx context.Context, notifier string, notifications []domain.Notification) (context.Context, func([]error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "notify "+notifier+" batch", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("webhook.notifier", notifier),
+		attribute.Int("webhook.batch_size", len(notifications)),
+	))
+
+	return ctx, func(errs []error) {
+		elapsed := time.Since(start)
+		var failed error
+		for i, notification := range notifications {
+			observe(notifier, notification, elapsed, errs[i])
+			if errs[i] != nil {
+				failed = errs[i]
+			}
+		}
+		endSpan(span, failed)
+	}
+}
+
+func observe(notifier string, notification domain.Notification, elapsed time.Duration, err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeFailure
+	}
+
+	eventType := eventTypeLabels.Value(notification.Header.EventType)
+	sendsTotal.WithLabelValues(notifier, eventType, outcome).Inc()
+	sendDuration.WithLabelValues(notifier, eventType, outcome).Observe(elapsed.Seconds())
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,31 @@
+package instrument
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestStartBatch(t *testing.T) {
+	notification := domain.Notification{Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"}}
+	success := sendsTotal.WithLabelValues("test", "payment.created", outcomeSuccess)
+	failure := sendsTotal.WithLabelValues("test", "payment.created", outcomeFailure)
+	successBefore := testutil.ToFloat64(success)
+	failureBefore := testutil.ToFloat64(failure)
+
+	_, end := StartSend(context.Background(), "test", notification)
+	end(nil)
+	_, endBatch := StartBatch(context.Background(), "test", []domain.Notification{notification, notification})
+	endBatch([]error{nil, errors.New("failed")})
+
+	if got := testutil.ToFloat64(success) - successBefore; got != 2 {
+		t.Errorf("successful sends = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(failure) - failureBefore; got != 1 {
+		t.Errorf("failed sends = %v, want 1", got)
+	}
+}
@@ -0,0 +1,224 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// The message keys, which select the partition of the message.
+const (
+	MessageKeyEventID      = "event_id"
+	MessageKeyPartitionKey = "partition_key"
+)
+
+type Config struct {
+	// Brokers are the bootstrap brokers, "host:port" items separated by ';'.
+	Brokers string `envconfig:"KAFKA_BROKERS" required:"true"`
+	// Topic receives the notifications matching no rule in EventTypeTopics,
+	// "pattern=topic" items separated by ';', where the pattern is a glob
+	// over the event type. The first matching rule wins.
+	Topic           string `envconfig:"KAFKA_TOPIC" required:"true"`
+	EventTypeTopics string `envconfig:"KAFKA_EVENT_TYPE_TOPICS"`
+	// MessageKey is event_id, or partition_key for the extracted partition
+	// key, which falls back to the event id.
+	MessageKey string `envconfig:"KAFKA_MESSAGE_KEY" default:"event_id"`
+	// RequiredAcks is all, one or none. Only the acknowledged writes succeed.
+	RequiredAcks string        `envconfig:"KAFKA_REQUIRED_ACKS" default:"all"`
+	WriteTimeout time.Duration `envconfig:"KAFKA_WRITE_TIMEOUT" default:"10s"`
+	// BatchTimeout bounds the wait for more messages to send together.
+	BatchTimeout time.Duration `envconfig:"KAFKA_BATCH_TIMEOUT" default:"10ms"`
+	UseTLS       bool          `envconfig:"KAFKA_USE_TLS" default:"false"`
+	// TLSCAPath has the CAs trusted to verify the brokers, instead of the
+	// system ones.
+	TLSCAPath string `envconfig:"KAFKA_TLS_CA_PATH"`
+	// SASLMechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512. The SASL
+	// authentication is disabled when empty.
+	SASLMechanism string `envconfig:"KAFKA_SASL_MECHANISM"`
+	SASLUsername  string `envconfig:"KAFKA_SASL_USERNAME"`
+	SASLPassword  string `envconfig:"KAFKA_SASL_PASSWORD"`
+	// The headers carrying the event id, type and partition key, and the
+	// static headers as "name=value" items separated by ';'. The partition
+	// key isn't sent when its header is empty.
+	EventIDHeader      string `envconfig:"KAFKA_EVENT_ID_HEADER" default:"X-Stone-Webhook-Event-Id"`
+	EventTypeHeader    string `envconfig:"KAFKA_EVENT_TYPE_HEADER" default:"X-Stone-Webhook-Event-Type"`
+	PartitionKeyHeader string `envconfig:"KAFKA_PARTITION_KEY_HEADER"`
+	StaticHeaders      string `envconfig:"KAFKA_STATIC_HEADERS"`
+}
+
+// String leaves the SASL password out of the logs.
+func (c Config) String() string {
+	return fmt.Sprintf("brokers:[%s] topic:[%s] event_type_topics:[%s] message_key:[%s] required_acks:[%s] write_timeout:[%s] batch_timeout:[%s] use_tls:[%t] tls_ca_path:[%s] sasl_mechanism:[%s] sasl_username:[%s]",
+		c.Brokers, c.Topic, c.EventTypeTopics, c.MessageKey, c.RequiredAcks, c.WriteTimeout, c.BatchTimeout, c.UseTLS, c.TLSCAPath, c.SASLMechanism, c.SASLUsername)
+}
+
+// topics selects the topic of each event type.
+type topics struct {
+	rules    []topicRule
+	fallback string
+}
+
+type topicRule struct {
+	pattern string
+	topic   string
+}
+
+func parseTopics(fallback, rules string) (topics, error) {
+	t := topics{fallback: strings.TrimSpace(fallback)}
+	if t.fallback == "" {
+		return topics{}, fmt.Errorf("the topic is mandatory")
+	}
+
+	for _, item := range configuration.SplitList(rules) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return topics{}, fmt.Errorf("invalid event type topic, expected pattern=topic: %v", item)
+		}
+
+		rule := topicRule{pattern: strings.TrimSpace(parts[0]), topic: strings.TrimSpace(parts[1])}
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return topics{}, fmt.Errorf("invalid event type pattern %q: %v", rule.pattern, err)
+		}
+
+		t.rules = append(t.rules, rule)
+	}
+
+	return t, nil
+}
+
+// topic returns the topic of the first rule matching the event type.
+func (t topics) topic(eventType string) string {
+	for _, rule := range t.rules {
+		// The patterns are validated by parseTopics.
+		if ok, _ := path.Match(rule.pattern, eventType); ok {
+			return rule.topic
+		}
+	}
+
+	return t.fallback
+}
+
+func parseRequiredAcks(value string) (kafkago.RequiredAcks, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "all":
+		return kafkago.RequireAll, nil
+	case "one":
+		return kafkago.RequireOne, nil
+	case "none":
+		return kafkago.RequireNone, nil
+	default:
+		return 0, fmt.Errorf("invalid required acks %q, must be all, one or none", value)
+	}
+}
+
+func saslMechanism(cfg Config) (sasl.Mechanism, error) {
+	mechanism := strings.ToUpper(strings.TrimSpace(cfg.SASLMechanism))
+	if mechanism == "" {
+		return nil, nil
+	}
+
+	if cfg.SASLUsername == "" || cfg.SASLPassword == "" {
+		return nil, fmt.Errorf("the sasl mechanism %s requires the username and the password", mechanism)
+	}
+
+	switch mechanism {
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	default:
+		return nil, fmt.Errorf("invalid sasl mechanism %q, must be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512", cfg.SASLMechanism)
+	}
+}
+
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	if !cfg.UseTLS {
+		if cfg.TLSCAPath != "" {
+			return nil, fmt.Errorf("the tls ca path requires KAFKA_USE_TLS")
+		}
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.TLSCAPath == "" {
+		return config, nil
+	}
+
+	data, err := ioutil.ReadFile(cfg.TLSCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the tls ca: %w", err)
+	}
+
+	config.RootCAs = x509.NewCertPool()
+	if !config.RootCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate in the tls ca %s", cfg.TLSCAPath)
+	}
+
+	return config, nil
+}
+
+// newWriter builds the producer. Each write is tried once and waits for the
+// acknowledgement, so the failures reach the usecase, which retries them.
+func newWriter(cfg Config) (*kafkago.Writer, error) {
+	brokers := configuration.SplitList(cfg.Brokers)
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("the brokers are mandatory")
+	}
+
+	acks, err := parseRequiredAcks(cfg.RequiredAcks)
+	if err != nil {
+		return nil, err
+	}
+
+	mechanism, err := saslMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkago.Writer{
+		Addr: kafkago.TCP(brokers...),
+		// The message key selects the partition, as in the Java clients.
+		Balancer:     &kafkago.Murmur2Balancer{},
+		MaxAttempts:  1,
+		RequiredAcks: acks,
+		WriteTimeout: cfg.WriteTimeout,
+		BatchTimeout: cfg.BatchTimeout,
+		Transport: &kafkago.Transport{
+			TLS:  tlsConfig,
+			SASL: mechanism,
+		},
+	}, nil
+}
+
+// newDialer connects to the brokers like the producer, for the health check.
+func newDialer(cfg Config) (*kafkago.Dialer, error) {
+	mechanism, err := saslMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafkago.Dialer{TLS: tlsConfig, SASLMechanism: mechanism}, nil
+}
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"testing"
+)
+
+func TestParseTopics(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		rules   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "First match wins",
+			topic: "webhooks",
+			rules: "payment.refunded=refunds;payment.*=payments",
+			want: map[string]string{
+				"payment.refunded": "refunds",
+				"payment.created":  "payments",
+				"boleto.paid":      "webhooks",
+			},
+		},
+		{
+			name:    "Missing topic",
+			rules:   "payment.*=payments",
+			wantErr: true,
+		},
+		{
+			name:    "Missing rule topic",
+			topic:   "webhooks",
+			rules:   "payment.*=",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid pattern",
+			topic:   "webhooks",
+			rules:   "payment.[=payments",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topics, err := parseTopics(tt.topic, tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTopics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for eventType, want := range tt.want {
+				if got := topics.topic(eventType); got != want {
+					t.Errorf("topic(%q) = %q, want %q", eventType, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "Plaintext",
+			cfg:  Config{Brokers: "localhost:9092", RequiredAcks: "all"},
+		},
+		{
+			name: "SCRAM over TLS",
+			cfg:  Config{Brokers: "b1:9093;b2:9093", RequiredAcks: "one", UseTLS: true, SASLMechanism: "SCRAM-SHA-512", SASLUsername: "user", SASLPassword: "pass"},
+		},
+		{
+			name:    "Missing brokers",
+			cfg:     Config{Brokers: " ; ", RequiredAcks: "all"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid required acks",
+			cfg:     Config{Brokers: "localhost:9092", RequiredAcks: "leader"},
+			wantErr: true,
+		},
+		{
+			name:    "SASL without password",
+			cfg:     Config{Brokers: "localhost:9092", RequiredAcks: "all", SASLMechanism: "PLAIN", SASLUsername: "user"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid SASL mechanism",
+			cfg:     Config{Brokers: "localhost:9092", RequiredAcks: "all", SASLMechanism: "GSSAPI", SASLUsername: "user", SASLPassword: "pass"},
+			wantErr: true,
+		},
+		{
+			name:    "TLS CA without TLS",
+			cfg:     Config{Brokers: "localhost:9092", RequiredAcks: "all", TLSCAPath: "ca.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing TLS CA",
+			cfg:     Config{Brokers: "localhost:9092", RequiredAcks: "all", UseTLS: true, TLSCAPath: "missing.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newWriter(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("newWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
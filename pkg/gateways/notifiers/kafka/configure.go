@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+)
+
+func (n *KafkaNotifier) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	n.log = log
+	log.WithField("notifier", "kafka").Infof("config:[%s]", config)
+
+	var err error
+	n.headers, err = headers.New(config.EventIDHeader, config.EventTypeHeader, config.PartitionKeyHeader, config.StaticHeaders)
+	if err != nil {
+		return fmt.Errorf("invalid headers: %v", err)
+	}
+
+	n.topics, err = parseTopics(config.Topic, config.EventTypeTopics)
+	if err != nil {
+		return err
+	}
+
+	n.messageKey = strings.ToLower(strings.TrimSpace(config.MessageKey))
+	if n.messageKey != MessageKeyEventID && n.messageKey != MessageKeyPartitionKey {
+		return fmt.Errorf("invalid message key %q, must be %s or %s", config.MessageKey, MessageKeyEventID, MessageKeyPartitionKey)
+	}
+
+	n.writer, err = newWriter(config)
+	if err != nil {
+		return err
+	}
+
+	n.dialer, err = newDialer(config)
+	if err != nil {
+		return err
+	}
+	n.brokers = configuration.SplitList(config.Brokers)
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.HealthChecker = &KafkaNotifier{}
+
+// CheckHealth connects to the brokers, in order, until one accepts the
+// connection and its authentication.
+func (n *KafkaNotifier) CheckHealth(ctx context.Context) error {
+	var err error
+	for _, broker := range n.brokers {
+		conn, dialErr := n.dialer.DialContext(ctx, "tcp", broker)
+		if dialErr == nil {
+			return conn.Close()
+		}
+		err = fmt.Errorf("unable to connect to broker %s: %w", broker, dialErr)
+	}
+
+	if err == nil {
+		return fmt.Errorf("no brokers configured")
+	}
+	return err
+}
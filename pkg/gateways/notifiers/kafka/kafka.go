@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+var _ domain.SerializedNotifier = &KafkaNotifier{}
+var _ domain.BatchNotifier = &KafkaNotifier{}
+var _ domain.NamedNotifier = &KafkaNotifier{}
+
+// messageWriter is the kafka producer, a *kafkago.Writer outside the tests.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafkago.Message) error
+}
+
+type KafkaNotifier struct {
+	log    *logrus.Logger
+	writer messageWriter
+	// serializer builds the message value and some of its headers.
+	serializer domain.MessageSerializer
+	headers    headers.Mapping
+	topics     topics
+	messageKey string
+	// brokers are dialed by the health check, with the writer TLS and SASL.
+	brokers []string
+	dialer  *kafkago.Dialer
+}
+
+func New() *KafkaNotifier {
+	return &KafkaNotifier{
+		serializer: serializers.JSON{},
+	}
+}
+
+func (n *KafkaNotifier) SetSerializer(serializer domain.MessageSerializer) {
+	n.serializer = serializer
+}
+
+func (n *KafkaNotifier) Name() string {
+	return "kafka"
+}
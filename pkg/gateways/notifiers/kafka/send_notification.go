@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
+)
+
+// Send publishes the notification and waits for its acknowledgement.
+func (n KafkaNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "kafka", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n KafkaNotifier) send(ctx context.Context, notification domain.Notification) error {
+	log := n.log.WithField("notifier", "kafka")
+
+	message, err := n.message(notification)
+	if err != nil {
+		log.WithError(err).Info("unable to serialize the notification")
+		return fmt.Errorf("unable to serialize the notification: %w", err)
+	}
+
+	if err := n.writer.WriteMessages(ctx, message); err != nil {
+		log.WithError(err).Info("unable to publish the notification")
+		return fmt.Errorf("unable to publish the notification: %w", err)
+	}
+
+	return nil
+}
+
+// SendBatch publishes all the notifications at once, returning the error of
+// each message.
+func (n KafkaNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	ctx, end := instrument.StartBatch(ctx, "kafka", notifications)
+	errs := n.sendBatch(ctx, notifications)
+	end(errs)
+	return errs
+}
+
+func (n KafkaNotifier) sendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	log := n.log.WithField("notifier", "kafka")
+
+	errs := make([]error, len(notifications))
+	messages := make([]kafkago.Message, len(notifications))
+	for i, notification := range notifications {
+		message, err := n.message(notification)
+		if err != nil {
+			log.WithError(err).Info("unable to serialize the notification")
+			return fill(errs, fmt.Errorf("unable to serialize the notification: %w", err))
+		}
+
+		messages[i] = message
+	}
+
+	err := n.writer.WriteMessages(ctx, messages...)
+	if err == nil {
+		return errs
+	}
+
+	var writeErrs kafkago.WriteErrors
+	if !errors.As(err, &writeErrs) || len(writeErrs) != len(messages) {
+		log.WithError(err).Info("unable to publish the notifications")
+		return fill(errs, fmt.Errorf("unable to publish the notifications: %w", err))
+	}
+
+	for i, err := range writeErrs {
+		if err != nil {
+			errs[i] = fmt.Errorf("unable to publish the notification: %w", err)
+		}
+	}
+	log.WithError(err).Infof("unable to publish %d of %d notifications", writeErrs.Count(), len(messages))
+
+	return errs
+}
+
+// message builds the message of the notification, with the serializer and
+// mapping headers sorted by name.
+func (n KafkaNotifier) message(notification domain.Notification) (kafkago.Message, error) {
+	body, serializerHeaders, err := n.serializer.Serialize(notification)
+	if err != nil {
+		return kafkago.Message{}, err
+	}
+
+	values := map[string]string{}
+	for name, value := range serializerHeaders {
+		values[name] = value
+	}
+	for name, value := range n.headers.Values(notification) {
+		values[name] = value
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messageHeaders := make([]kafkago.Header, len(names))
+	for i, name := range names {
+		messageHeaders[i] = kafkago.Header{Key: name, Value: []byte(values[name])}
+	}
+
+	return kafkago.Message{
+		Topic:   n.topics.topic(notification.Header.EventType),
+		Key:     []byte(n.key(notification)),
+		Value:   body,
+		Headers: messageHeaders,
+	}, nil
+}
+
+func (n KafkaNotifier) key(notification domain.Notification) string {
+	if n.messageKey == MessageKeyPartitionKey && notification.Fields.PartitionKey != "" {
+		return notification.Fields.PartitionKey
+	}
+
+	return notification.Header.EventID
+}
+
+func fill(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
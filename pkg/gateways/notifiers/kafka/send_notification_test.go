@@ -0,0 +1,185 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+type fakeWriter struct {
+	messages []kafkago.Message
+	err      error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, messages ...kafkago.Message) error {
+	w.messages = append(w.messages, messages...)
+	return w.err
+}
+
+func newNotifier(t *testing.T, writer messageWriter, messageKey string) KafkaNotifier {
+	mapping, err := headers.New("X-Stone-Webhook-Event-Id", "X-Stone-Webhook-Event-Type", "", "X-Team=payments")
+	if err != nil {
+		t.Fatalf("headers.New() error = %v", err)
+	}
+	topics, err := parseTopics("webhooks", "payment.*=payments;transfer.created=transfers")
+	if err != nil {
+		t.Fatalf("parseTopics() error = %v", err)
+	}
+
+	return KafkaNotifier{
+		log:        logrus.New(),
+		writer:     writer,
+		serializer: serializers.JSON{},
+		headers:    mapping,
+		topics:     topics,
+		messageKey: messageKey,
+	}
+}
+
+func notification(eventID, eventType, partitionKey string) domain.Notification {
+	return domain.Notification{
+		Header: domain.HeaderNotification{EventID: eventID, EventType: eventType},
+		Body:   "{}",
+		Fields: domain.NotificationFields{PartitionKey: partitionKey},
+	}
+}
+
+func TestKafkaNotifier_Send(t *testing.T) {
+	tests := []struct {
+		name         string
+		messageKey   string
+		notification domain.Notification
+		wantTopic    string
+		wantKey      string
+	}{
+		{
+			name:         "Topic by event type",
+			messageKey:   MessageKeyEventID,
+			notification: notification("1", "payment.created", "acc-1"),
+			wantTopic:    "payments",
+			wantKey:      "1",
+		},
+		{
+			name:         "Default topic",
+			messageKey:   MessageKeyEventID,
+			notification: notification("2", "boleto.paid", ""),
+			wantTopic:    "webhooks",
+			wantKey:      "2",
+		},
+		{
+			name:         "Partition key",
+			messageKey:   MessageKeyPartitionKey,
+			notification: notification("3", "transfer.created", "acc-1"),
+			wantTopic:    "transfers",
+			wantKey:      "acc-1",
+		},
+		{
+			name:         "Partition key falls back to the event id",
+			messageKey:   MessageKeyPartitionKey,
+			notification: notification("4", "transfer.created", ""),
+			wantTopic:    "transfers",
+			wantKey:      "4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{}
+			n := newNotifier(t, writer, tt.messageKey)
+
+			if err := n.Send(context.Background(), tt.notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if len(writer.messages) != 1 {
+				t.Fatalf("Send() wrote %d messages, want 1", len(writer.messages))
+			}
+			message := writer.messages[0]
+			if message.Topic != tt.wantTopic {
+				t.Errorf("Send() topic = %q, want %q", message.Topic, tt.wantTopic)
+			}
+			if string(message.Key) != tt.wantKey {
+				t.Errorf("Send() key = %q, want %q", message.Key, tt.wantKey)
+			}
+
+			want := map[string]string{
+				"Content-Type":               "application/json",
+				"X-Stone-Webhook-Event-Id":   tt.notification.Header.EventID,
+				"X-Stone-Webhook-Event-Type": tt.notification.Header.EventType,
+				"X-Team":                     "payments",
+			}
+			got := map[string]string{}
+			for _, header := range message.Headers {
+				got[header.Key] = string(header.Value)
+			}
+			for name, value := range want {
+				if got[name] != value {
+					t.Errorf("Send() header %s = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
+
+func TestKafkaNotifier_Send_Error(t *testing.T) {
+	writeErr := errors.New("not enough replicas")
+	n := newNotifier(t, &fakeWriter{err: writeErr}, MessageKeyEventID)
+
+	err := n.Send(context.Background(), notification("1", "payment.created", ""))
+	if !errors.Is(err, writeErr) {
+		t.Errorf("Send() error = %v, want %v", err, writeErr)
+	}
+}
+
+func TestKafkaNotifier_SendBatch(t *testing.T) {
+	writeErr := errors.New("message too large")
+	tests := []struct {
+		name     string
+		err      error
+		wantErrs []bool
+	}{
+		{
+			name:     "All published",
+			wantErrs: []bool{false, false, false},
+		},
+		{
+			name:     "Errors by message",
+			err:      kafkago.WriteErrors{nil, writeErr, nil},
+			wantErrs: []bool{false, true, false},
+		},
+		{
+			name:     "Batch error",
+			err:      writeErr,
+			wantErrs: []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeWriter{err: tt.err}
+			n := newNotifier(t, writer, MessageKeyEventID)
+
+			errs := n.SendBatch(context.Background(), []domain.Notification{
+				notification("1", "payment.created", ""),
+				notification("2", "payment.created", ""),
+				notification("3", "boleto.paid", ""),
+			})
+
+			if len(writer.messages) != 3 {
+				t.Fatalf("SendBatch() wrote %d messages, want 3", len(writer.messages))
+			}
+			for i, wantErr := range tt.wantErrs {
+				if (errs[i] != nil) != wantErr {
+					t.Errorf("SendBatch() error %d = %v, wantErr %v", i, errs[i], wantErr)
+				}
+			}
+		})
+	}
+}
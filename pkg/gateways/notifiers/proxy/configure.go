@@ -7,11 +7,20 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
 )
 
 type Config struct {
 	Url     string        `envconfig:"PROXY_NOTIFIER_URL"`
 	Timeout time.Duration `envconfig:"PROXY_NOTIFIER_TIMEOUT" default:"10s"`
+	// The headers carrying the event id, type and partition key, and the
+	// static headers as "name=value" items separated by ';'. The partition
+	// key isn't sent when its header is empty.
+	EventIDHeader      string `envconfig:"PROXY_NOTIFIER_EVENT_ID_HEADER" default:"X-Stone-Webhook-Event-Id"`
+	EventTypeHeader    string `envconfig:"PROXY_NOTIFIER_EVENT_TYPE_HEADER" default:"X-Stone-Webhook-Event-Type"`
+	PartitionKeyHeader string `envconfig:"PROXY_NOTIFIER_PARTITION_KEY_HEADER"`
+	StaticHeaders      string `envconfig:"PROXY_NOTIFIER_STATIC_HEADERS"`
 }
 
 func (n *ProxyNotifier) Configure(log *logrus.Logger) error {
@@ -31,6 +40,10 @@ func (n *ProxyNotifier) Configure(log *logrus.Logger) error {
 	if err != nil || config.Url == "" {
 		return fmt.Errorf("failed to parse url '%s': %v", config.Url, err)
 	}
+	n.headers, err = headers.New(config.EventIDHeader, config.EventTypeHeader, config.PartitionKeyHeader, config.StaticHeaders)
+	if err != nil {
+		return fmt.Errorf("invalid headers: %v", err)
+	}
 	n.log = log
 
 	n.log.WithField("notifier", "proxy").Infof("url:[%s] timeout:[%s]", config.Url, n.timeout.String())
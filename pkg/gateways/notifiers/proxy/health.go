@@ -0,0 +1,31 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.HealthChecker = &ProxyNotifier{}
+
+// CheckHealth connects to the service host, without sending a request, so
+// the check has no side effect on the service.
+func (n *ProxyNotifier) CheckHealth(ctx context.Context) error {
+	port := n.serviceURL.Port()
+	if port == "" {
+		port = "80"
+		if n.serviceURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.serviceURL.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("unable to connect to the service: %w", err)
+	}
+
+	return conn.Close()
+}
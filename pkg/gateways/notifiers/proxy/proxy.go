@@ -6,16 +6,31 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
 )
 
-var _ domain.Notifier = &ProxyNotifier{}
+var _ domain.SerializedNotifier = &ProxyNotifier{}
+var _ domain.NamedNotifier = &ProxyNotifier{}
 
 type ProxyNotifier struct {
 	log        *logrus.Logger
 	serviceURL *url.URL
 	timeout    time.Duration
+	serializer domain.MessageSerializer
+	headers    headers.Mapping
 }
 
 func New() *ProxyNotifier {
-	return &ProxyNotifier{}
+	return &ProxyNotifier{
+		serializer: serializers.JSON{},
+	}
+}
+
+func (n *ProxyNotifier) SetSerializer(serializer domain.MessageSerializer) {
+	n.serializer = serializer
+}
+
+func (n *ProxyNotifier) Name() string {
+	return "proxy"
 }
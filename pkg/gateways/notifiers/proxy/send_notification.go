@@ -1,26 +1,48 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 
-	"github.com/stone-co/webhook-consumer/pkg/gateways/http/notifications"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
 )
 
-func (n ProxyNotifier) Send(ctx context.Context, eventTypeHeader, eventIDHeader, body string) error {
+func (n ProxyNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "proxy", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n ProxyNotifier) send(ctx context.Context, notification domain.Notification) error {
 	log := n.log.WithField("notifier", "proxy")
 
-	req, err := http.NewRequest(http.MethodPost, n.serviceURL.String(), strings.NewReader(body))
+	body, headers, err := n.serializer.Serialize(notification)
+	if err != nil {
+		log.WithError(err).Info("unable to serialize the notification")
+		return fmt.Errorf("unable to serialize the notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.serviceURL.String(), bytes.NewReader(body))
 	if err != nil {
 		log.WithError(err).Info("unable to create a request")
 		return fmt.Errorf("unable to create a request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set(notifications.EventIDHeader, eventIDHeader)
-	req.Header.Set(notifications.EventTypeHeader, eventTypeHeader)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	for key, value := range n.headers.Values(notification) {
+		req.Header.Set(key, value)
+	}
+	// The service continues the trace, with the traceparent header.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	client := &http.Client{
 		Timeout: n.timeout,
@@ -0,0 +1,193 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+func TestProxyNotifier_Send_Headers(t *testing.T) {
+	tests := []struct {
+		name         string
+		eventID      string
+		eventType    string
+		partitionKey string
+		static       string
+		want         map[string]string
+	}{
+		{
+			name:      "Default headers",
+			eventID:   "X-Stone-Webhook-Event-Id",
+			eventType: "X-Stone-Webhook-Event-Type",
+			want: map[string]string{
+				"X-Stone-Webhook-Event-Id":   "1",
+				"X-Stone-Webhook-Event-Type": "payment.created",
+				"Content-Type":               "application/json",
+			},
+		},
+		{
+			name:      "Custom headers",
+			eventID:   "ce-id",
+			eventType: "ce-type",
+			static:    "ce-source=webhook-consumer;X-Team=payments",
+			want: map[string]string{
+				"Ce-Id":                      "1",
+				"Ce-Type":                    "payment.created",
+				"Ce-Source":                  "webhook-consumer",
+				"X-Team":                     "payments",
+				"X-Stone-Webhook-Event-Id":   "",
+				"X-Stone-Webhook-Event-Type": "",
+			},
+		},
+		{
+			name:         "Partition key header",
+			eventID:      "X-Stone-Webhook-Event-Id",
+			eventType:    "X-Stone-Webhook-Event-Type",
+			partitionKey: "X-Partition-Key",
+			want: map[string]string{
+				"X-Stone-Webhook-Event-Id": "1",
+				"X-Partition-Key":          "acc-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			mapping, err := headers.New(tt.eventID, tt.eventType, tt.partitionKey, tt.static)
+			if err != nil {
+				t.Fatalf("headers.New() error = %v", err)
+			}
+			serviceURL, _ := url.Parse(srv.URL)
+
+			n := ProxyNotifier{
+				log:        logrus.New(),
+				serviceURL: serviceURL,
+				timeout:    time.Second,
+				serializer: serializers.JSON{},
+				headers:    mapping,
+			}
+
+			notification := domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"},
+				Body:   "{}",
+				Fields: domain.NotificationFields{PartitionKey: "acc-1"},
+			}
+			if err := n.Send(context.Background(), notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				if got := received.Get(name); got != want {
+					t.Errorf("Send() header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProxyNotifier_Send_TraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	mapping, err := headers.New("X-Stone-Webhook-Event-Id", "X-Stone-Webhook-Event-Type", "", "")
+	if err != nil {
+		t.Fatalf("headers.New() error = %v", err)
+	}
+	serviceURL, _ := url.Parse(srv.URL)
+	n := ProxyNotifier{
+		log:        logrus.New(),
+		serviceURL: serviceURL,
+		timeout:    time.Second,
+		serializer: serializers.JSON{},
+		headers:    mapping,
+	}
+
+	notification := domain.Notification{
+		Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"},
+		Body:   "{}",
+	}
+	if err := n.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "notify proxy" {
+		t.Fatalf("Send() ended spans = %v, want the notify proxy span", spans)
+	}
+	span := spans[0].SpanContext()
+	if want := "00-" + span.TraceID().String() + "-" + span.SpanID().String() + "-01"; traceparent != want {
+		t.Errorf("Send() traceparent = %q, want %q", traceparent, want)
+	}
+}
+
+func TestProxyNotifier_Send_ContextExpired(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	mapping, err := headers.New("X-Stone-Webhook-Event-Id", "X-Stone-Webhook-Event-Type", "", "")
+	if err != nil {
+		t.Fatalf("headers.New() error = %v", err)
+	}
+	serviceURL, _ := url.Parse(srv.URL)
+	n := ProxyNotifier{
+		log:        logrus.New(),
+		serviceURL: serviceURL,
+		timeout:    time.Minute,
+		serializer: serializers.JSON{},
+		headers:    mapping,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	notification := domain.Notification{
+		Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"},
+		Body:   "{}",
+	}
+	start := time.Now()
+	err = n.Send(ctx, notification)
+	if err == nil {
+		t.Fatal("Send() error = nil, want the context error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send() took %v, want it to stop with the context", elapsed)
+	}
+	if !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Send() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -19,6 +19,13 @@ type Config struct {
 	DialConnectTimeout time.Duration `envconfig:"REDIS_CONNECT_TIMEOUT" default:"1s"`
 	DialReadTimeout    time.Duration `envconfig:"REDIS_READ_TIMEOUT" default:"300ms"`
 	DialWriteTimeout   time.Duration `envconfig:"REDIS_WRITE_TIMEOUT" default:"300ms"`
+	// The record fields carrying the event id, type and partition key, and the
+	// static fields as "name=value" items separated by ';'. The partition key
+	// isn't stored when its field is empty.
+	EventIDField      string `envconfig:"REDIS_EVENT_ID_FIELD" default:"EventID"`
+	EventTypeField    string `envconfig:"REDIS_EVENT_TYPE_FIELD" default:"EventType"`
+	PartitionKeyField string `envconfig:"REDIS_PARTITION_KEY_FIELD"`
+	StaticFields      string `envconfig:"REDIS_STATIC_FIELDS"`
 }
 
 func (c Config) Addr() string {
@@ -1,8 +1,12 @@
 package redis
 
 import (
+	"fmt"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
 )
 
 func (n *RedisNotifier) Configure(log *logrus.Logger) error {
@@ -16,6 +20,11 @@ func (n *RedisNotifier) Configure(log *logrus.Logger) error {
 	log.WithField("notifier", "redis").Infof("config:[%+v]", config)
 
 	var err error
+	n.fields, err = headers.New(config.EventIDField, config.EventTypeField, config.PartitionKeyField, config.StaticFields, bodyField)
+	if err != nil {
+		return fmt.Errorf("invalid fields: %v", err)
+	}
+
 	n.pool, err = initPool(config)
 	if err != nil {
 		return err
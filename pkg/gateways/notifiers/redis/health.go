@@ -0,0 +1,15 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/redisclient"
+)
+
+var _ domain.HealthChecker = &RedisNotifier{}
+
+// CheckHealth pings the redis server.
+func (n *RedisNotifier) CheckHealth(ctx context.Context) error {
+	return redisclient.CheckHealth(ctx, n.pool)
+}
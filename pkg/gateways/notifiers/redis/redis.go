@@ -4,15 +4,33 @@ import (
 	"github.com/gomodule/redigo/redis"
 	"github.com/sirupsen/logrus"
 	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
 )
 
-var _ domain.Notifier = &RedisNotifier{}
+var _ domain.SerializedNotifier = &RedisNotifier{}
+var _ domain.BatchNotifier = &RedisNotifier{}
+var _ domain.NamedNotifier = &RedisNotifier{}
 
 type RedisNotifier struct {
 	log  *logrus.Logger
 	pool *redis.Pool
+	// serializer builds the stored body, the headers aren't stored.
+	serializer domain.MessageSerializer
+	// fields names the event id and type in the stored record.
+	fields headers.Mapping
 }
 
 func New() *RedisNotifier {
-	return &RedisNotifier{}
+	return &RedisNotifier{
+		serializer: serializers.JSON{},
+	}
+}
+
+func (n *RedisNotifier) SetSerializer(serializer domain.MessageSerializer) {
+	n.serializer = serializer
+}
+
+func (n *RedisNotifier) Name() string {
+	return "redis"
 }
@@ -4,36 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
 )
 
 const (
 	RedisNotificationList = "STONE-NOTIFICATIONS"
 )
 
-type Notification struct {
-	EventType string
-	EventID   string
-	Body      json.RawMessage
+// bodyField has the serialized body in the stored record.
+const bodyField = "Body"
+
+func (n RedisNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "redis", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
 }
 
-func (n RedisNotifier) Send(ctx context.Context, eventTypeHeader, eventIDHeader, body string) error {
+func (n RedisNotifier) send(ctx context.Context, notification domain.Notification) error {
 	log := n.log.WithField("notifier", "redis")
 
-	conn := n.pool.Get()
-	defer conn.Close()
-
-	notification := Notification{
-		EventType: eventTypeHeader,
-		EventID:   eventIDHeader,
-		Body:      []byte(body),
-	}
-
-	encoded, err := json.Marshal(notification)
+	encoded, err := n.encode(notification)
 	if err != nil {
 		log.WithError(err).Error("failed to marshal notification")
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
+	conn := n.pool.Get()
+	defer conn.Close()
+
 	_, err = conn.Do("RPUSH", RedisNotificationList, encoded)
 	if err != nil {
 		log.WithError(err).Info("unable to save the notifier")
@@ -42,3 +43,71 @@ func (n RedisNotifier) Send(ctx context.Context, eventTypeHeader, eventIDHeader,
 
 	return nil
 }
+
+// SendBatch stores all the notifications with a single RPUSH, so they are
+// all stored or all fail.
+func (n RedisNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	ctx, end := instrument.StartBatch(ctx, "redis", notifications)
+	errs := n.sendBatch(ctx, notifications)
+	end(errs)
+	return errs
+}
+
+func (n RedisNotifier) sendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	log := n.log.WithField("notifier", "redis")
+
+	errs := make([]error, len(notifications))
+	args := []interface{}{RedisNotificationList}
+	for _, notification := range notifications {
+		encoded, err := n.encode(notification)
+		if err != nil {
+			log.WithError(err).Error("failed to marshal notification")
+			return fill(errs, fmt.Errorf("failed to marshal notification: %w", err))
+		}
+
+		args = append(args, encoded)
+	}
+
+	conn := n.pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("RPUSH", args...); err != nil {
+		log.WithError(err).Info("unable to save the notifications")
+		return fill(errs, fmt.Errorf("unable to save the notifications: %w", err))
+	}
+
+	return errs
+}
+
+// encode stores the serialized body with the event fields.
+func (n RedisNotifier) encode(notification domain.Notification) ([]byte, error) {
+	body, _, err := n.serializer.Serialize(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	record := map[string]interface{}{}
+	for name, value := range n.fields.Values(notification) {
+		record[name] = value
+	}
+	switch {
+	case len(body) == 0:
+		// The payload of the empty payload event types is stored as null.
+		record[bodyField] = nil
+	case notification.Ciphertext && !json.Valid(body):
+		// The inner JWE, with the json serializer, isn't JSON.
+		record[bodyField] = string(body)
+	default:
+		record[bodyField] = json.RawMessage(body)
+	}
+
+	return json.Marshal(record)
+}
+
+func fill(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
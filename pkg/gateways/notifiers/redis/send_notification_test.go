@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+)
+
+func TestRedisNotifier_encode(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification domain.Notification
+		want         string
+	}{
+		{
+			name:         "JSON payload",
+			notification: domain.Notification{Body: `{"account_id":"acc-1"}`},
+			want:         `{"Body":{"account_id":"acc-1"},"EventID":"930bbd6d","EventType":"cash_in_internal_transfer"}`,
+		},
+		{
+			name:         "Empty payload",
+			notification: domain.Notification{},
+			want:         `{"Body":null,"EventID":"930bbd6d","EventType":"cash_in_internal_transfer"}`,
+		},
+		{
+			name:         "Inner JWE",
+			notification: domain.Notification{Body: "header.key.iv.ciphertext.tag", Ciphertext: true},
+			want:         `{"Body":"header.key.iv.ciphertext.tag","EventID":"930bbd6d","EventType":"cash_in_internal_transfer"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := New()
+			var err error
+			notifier.fields, err = headers.New("EventID", "EventType", "", "", bodyField)
+			if err != nil {
+				t.Fatalf("headers.New() error = %v", err)
+			}
+
+			tt.notification.Header = domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"}
+			got, err := notifier.encode(tt.notification)
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package sns
+
+import (
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+)
+
+type Config struct {
+	TopicARN string `envconfig:"SNS_TOPIC_ARN" required:"true"`
+	// Publisher has the region, credentials, message group and attributes,
+	// like SNS_REGION.
+	Publisher awsclient.PublisherConfig `envconfig:"SNS"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("topic_arn:[%s] %s", c.TopicARN, c.Publisher)
+}
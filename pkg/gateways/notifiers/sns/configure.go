@@ -0,0 +1,35 @@
+package sns
+
+import (
+	"strings"
+
+	awssns "github.com/aws/aws-sdk-go/service/sns"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+)
+
+func (n *SNSNotifier) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	n.log = log
+	log.WithField("notifier", "sns").Infof("config:[%s]", config)
+
+	publisher, err := awsclient.NewPublisher(config.Publisher)
+	if err != nil {
+		return err
+	}
+
+	n.attributes = publisher.Attributes
+	n.messageGroup = publisher.MessageGroup
+	n.client = awssns.New(publisher.Session)
+	n.topicARN = config.TopicARN
+	n.fifo = strings.HasSuffix(config.TopicARN, ".fifo")
+
+	return nil
+}
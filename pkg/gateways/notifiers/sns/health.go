@@ -0,0 +1,25 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssns "github.com/aws/aws-sdk-go/service/sns"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.HealthChecker = &SNSNotifier{}
+
+// CheckHealth checks if the topic exists and can be accessed.
+func (n *SNSNotifier) CheckHealth(ctx context.Context) error {
+	_, err := n.client.GetTopicAttributesWithContext(ctx, &awssns.GetTopicAttributesInput{
+		TopicArn: aws.String(n.topicARN),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to access the topic %s: %w", n.topicARN, err)
+	}
+
+	return nil
+}
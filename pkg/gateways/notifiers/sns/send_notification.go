@@ -0,0 +1,70 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssns "github.com/aws/aws-sdk-go/service/sns"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
+)
+
+// Send publishes the notification to the topic. The FIFO topics deduplicate
+// the retries by the event id.
+func (n SNSNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "sns", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n SNSNotifier) send(ctx context.Context, notification domain.Notification) error {
+	log := n.log.WithField("notifier", "sns")
+
+	input, err := n.input(notification)
+	if err != nil {
+		log.WithError(err).Info("unable to serialize the notification")
+		return fmt.Errorf("unable to serialize the notification: %w", err)
+	}
+
+	if _, err := n.client.PublishWithContext(ctx, input); err != nil {
+		log.WithError(err).Info("unable to publish the notification")
+		return fmt.Errorf("unable to publish the notification: %w", err)
+	}
+
+	return nil
+}
+
+func (n SNSNotifier) input(notification domain.Notification) (*awssns.PublishInput, error) {
+	body, serializerHeaders, err := n.serializer.Serialize(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	values, names, err := awsclient.Attributes(serializerHeaders, n.attributes.Values(notification))
+	if err != nil {
+		return nil, err
+	}
+
+	input := &awssns.PublishInput{
+		TopicArn:          aws.String(n.topicARN),
+		Message:           aws.String(string(body)),
+		MessageAttributes: map[string]*awssns.MessageAttributeValue{},
+	}
+	for _, name := range names {
+		input.MessageAttributes[name] = &awssns.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(values[name]),
+		}
+	}
+
+	if n.fifo {
+		input.MessageGroupId = aws.String(awsclient.MessageGroupID(n.messageGroup, notification))
+		input.MessageDeduplicationId = aws.String(notification.Header.EventID)
+	}
+
+	return input, nil
+}
@@ -0,0 +1,101 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awssns "github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+type fakeSNS struct {
+	snsiface.SNSAPI
+	messages []*awssns.PublishInput
+	err      error
+}
+
+func (f *fakeSNS) PublishWithContext(ctx aws.Context, input *awssns.PublishInput, opts ...request.Option) (*awssns.PublishOutput, error) {
+	f.messages = append(f.messages, input)
+	return &awssns.PublishOutput{}, f.err
+}
+
+func TestSNSNotifier_Send(t *testing.T) {
+	mapping, err := headers.New("X-Stone-Webhook-Event-Id", "X-Stone-Webhook-Event-Type", "X-Partition-Key", "")
+	if err != nil {
+		t.Fatalf("headers.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		topicARN  string
+		fifo      bool
+		err       error
+		wantGroup string
+		wantErr   bool
+	}{
+		{
+			name:     "Standard topic",
+			topicARN: "arn:aws:sns:us-east-1:123456789012:webhooks",
+		},
+		{
+			name:      "FIFO topic",
+			topicARN:  "arn:aws:sns:us-east-1:123456789012:webhooks.fifo",
+			fifo:      true,
+			wantGroup: "acc-1",
+		},
+		{
+			name:     "Publish error",
+			topicARN: "arn:aws:sns:us-east-1:123456789012:webhooks",
+			err:      errors.New("access denied"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSNS{err: tt.err}
+			n := SNSNotifier{
+				log:          logrus.New(),
+				client:       client,
+				serializer:   serializers.JSON{},
+				attributes:   mapping,
+				topicARN:     tt.topicARN,
+				fifo:         tt.fifo,
+				messageGroup: awsclient.MessageGroupPartitionKey,
+			}
+
+			notification := domain.Notification{
+				Header: domain.HeaderNotification{EventID: "1", EventType: "payment.created"},
+				Body:   "{}",
+				Fields: domain.NotificationFields{PartitionKey: "acc-1"},
+			}
+			err := n.Send(context.Background(), notification)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			message := client.messages[0]
+			if got := aws.StringValue(message.TopicArn); got != tt.topicARN {
+				t.Errorf("topic = %q, want %q", got, tt.topicARN)
+			}
+			if got := aws.StringValue(message.MessageGroupId); got != tt.wantGroup {
+				t.Errorf("message group = %q, want %q", got, tt.wantGroup)
+			}
+			if got := aws.StringValue(message.MessageAttributes["X-Partition-Key"].StringValue); got != "acc-1" {
+				t.Errorf("partition key attribute = %q, want %q", got, "acc-1")
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package sns
+
+import (
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+var _ domain.SerializedNotifier = &SNSNotifier{}
+var _ domain.NamedNotifier = &SNSNotifier{}
+
+type SNSNotifier struct {
+	log    *logrus.Logger
+	client snsiface.SNSAPI
+	// serializer builds the message body and some of its attributes.
+	serializer domain.MessageSerializer
+	attributes headers.Mapping
+	topicARN   string
+	// fifo topics receive the message group and deduplication ids.
+	fifo         bool
+	messageGroup string
+}
+
+func New() *SNSNotifier {
+	return &SNSNotifier{
+		serializer: serializers.JSON{},
+	}
+}
+
+func (n *SNSNotifier) SetSerializer(serializer domain.MessageSerializer) {
+	n.serializer = serializer
+}
+
+func (n *SNSNotifier) Name() string {
+	return "sns"
+}
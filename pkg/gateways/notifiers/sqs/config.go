@@ -0,0 +1,18 @@
+package sqs
+
+import (
+	"fmt"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+)
+
+type Config struct {
+	QueueURL string `envconfig:"SQS_QUEUE_URL" required:"true"`
+	// Publisher has the region, credentials, message group and attributes,
+	// like SQS_REGION.
+	Publisher awsclient.PublisherConfig `envconfig:"SQS"`
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("queue_url:[%s] %s", c.QueueURL, c.Publisher)
+}
@@ -0,0 +1,35 @@
+package sqs
+
+import (
+	"strings"
+
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+)
+
+func (n *SQSNotifier) Configure(log *logrus.Logger) error {
+	var config Config
+	prefix := ""
+	if err := envconfig.Process(prefix, &config); err != nil {
+		return err
+	}
+
+	n.log = log
+	log.WithField("notifier", "sqs").Infof("config:[%s]", config)
+
+	publisher, err := awsclient.NewPublisher(config.Publisher)
+	if err != nil {
+		return err
+	}
+
+	n.attributes = publisher.Attributes
+	n.messageGroup = publisher.MessageGroup
+	n.client = awssqs.New(publisher.Session)
+	n.queueURL = config.QueueURL
+	n.fifo = strings.HasSuffix(config.QueueURL, ".fifo")
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.HealthChecker = &SQSNotifier{}
+
+// CheckHealth checks if the queue exists and can be accessed.
+func (n *SQSNotifier) CheckHealth(ctx context.Context) error {
+	_, err := n.client.GetQueueAttributesWithContext(ctx, &awssqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(n.queueURL),
+		AttributeNames: aws.StringSlice([]string{awssqs.QueueAttributeNameQueueArn}),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to access the queue %s: %w", n.queueURL, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
+)
+
+// maxBatchSize is the limit of messages sent at once by SQS.
+const maxBatchSize = 10
+
+// message is the content of a SQS message, for a single or a batch send.
+type message struct {
+	body                   string
+	attributes             map[string]*awssqs.MessageAttributeValue
+	messageGroupID         *string
+	messageDeduplicationID *string
+}
+
+// Send publishes the notification to the queue.
+func (n SQSNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "sqs", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n SQSNotifier) send(ctx context.Context, notification domain.Notification) error {
+	log := n.log.WithField("notifier", "sqs")
+
+	m, err := n.message(notification)
+	if err != nil {
+		log.WithError(err).Info("unable to serialize the notification")
+		return fmt.Errorf("unable to serialize the notification: %w", err)
+	}
+
+	_, err = n.client.SendMessageWithContext(ctx, &awssqs.SendMessageInput{
+		QueueUrl:               aws.String(n.queueURL),
+		MessageBody:            aws.String(m.body),
+		MessageAttributes:      m.attributes,
+		MessageGroupId:         m.messageGroupID,
+		MessageDeduplicationId: m.messageDeduplicationID,
+	})
+	if err != nil {
+		log.WithError(err).Info("unable to publish the notification")
+		return fmt.Errorf("unable to publish the notification: %w", err)
+	}
+
+	return nil
+}
+
+// SendBatch publishes the notifications in batches of up to 10 messages,
+// returning the error of each message.
+func (n SQSNotifier) SendBatch(ctx context.Context, notifications []domain.Notification) []error {
+	ctx, end := instrument.StartBatch(ctx, "sqs", notifications)
+	errs := make([]error, len(notifications))
+	for start := 0; start < len(notifications); start += maxBatchSize {
+		stop := start + maxBatchSize
+		if stop > len(notifications) {
+			stop = len(notifications)
+		}
+		n.sendBatch(ctx, notifications[start:stop], errs[start:stop])
+	}
+	end(errs)
+	return errs
+}
+
+func (n SQSNotifier) sendBatch(ctx context.Context, notifications []domain.Notification, errs []error) {
+	log := n.log.WithField("notifier", "sqs")
+
+	entries := make([]*awssqs.SendMessageBatchRequestEntry, 0, len(notifications))
+	for i, notification := range notifications {
+		m, err := n.message(notification)
+		if err != nil {
+			log.WithError(err).Info("unable to serialize the notification")
+			errs[i] = fmt.Errorf("unable to serialize the notification: %w", err)
+			continue
+		}
+
+		// The entry id is the index in the batch.
+		entries = append(entries, &awssqs.SendMessageBatchRequestEntry{
+			Id:                     aws.String(strconv.Itoa(i)),
+			MessageBody:            aws.String(m.body),
+			MessageAttributes:      m.attributes,
+			MessageGroupId:         m.messageGroupID,
+			MessageDeduplicationId: m.messageDeduplicationID,
+		})
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	output, err := n.client.SendMessageBatchWithContext(ctx, &awssqs.SendMessageBatchInput{
+		QueueUrl: aws.String(n.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		log.WithError(err).Info("unable to publish the notifications")
+		for _, entry := range entries {
+			i, _ := strconv.Atoi(aws.StringValue(entry.Id))
+			errs[i] = fmt.Errorf("unable to publish the notifications: %w", err)
+		}
+		return
+	}
+
+	for _, failed := range output.Failed {
+		i, err := strconv.Atoi(aws.StringValue(failed.Id))
+		if err != nil || i < 0 || i >= len(errs) {
+			continue
+		}
+		errs[i] = fmt.Errorf("unable to publish the notification: %s: %s", aws.StringValue(failed.Code), aws.StringValue(failed.Message))
+	}
+	if len(output.Failed) > 0 {
+		log.Infof("unable to publish %d of %d notifications", len(output.Failed), len(entries))
+	}
+}
+
+// message builds the body and attributes of the notification. The FIFO queues
+// deduplicate the retries by the event id.
+func (n SQSNotifier) message(notification domain.Notification) (message, error) {
+	body, serializerHeaders, err := n.serializer.Serialize(notification)
+	if err != nil {
+		return message{}, err
+	}
+
+	values, names, err := awsclient.Attributes(serializerHeaders, n.attributes.Values(notification))
+	if err != nil {
+		return message{}, err
+	}
+
+	m := message{body: string(body), attributes: map[string]*awssqs.MessageAttributeValue{}}
+	for _, name := range names {
+		m.attributes[name] = &awssqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(values[name]),
+		}
+	}
+
+	if n.fifo {
+		m.messageGroupID = aws.String(awsclient.MessageGroupID(n.messageGroup, notification))
+		m.messageDeduplicationID = aws.String(notification.Header.EventID)
+	}
+
+	return m, nil
+}
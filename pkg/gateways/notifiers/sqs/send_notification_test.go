@@ -0,0 +1,187 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awssqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/awsclient"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	messages []*awssqs.SendMessageInput
+	batches  []*awssqs.SendMessageBatchInput
+	// failed are the entry ids failed by the batch sends.
+	failed []string
+	err    error
+}
+
+func (f *fakeSQS) SendMessageWithContext(ctx aws.Context, input *awssqs.SendMessageInput, opts ...request.Option) (*awssqs.SendMessageOutput, error) {
+	f.messages = append(f.messages, input)
+	return &awssqs.SendMessageOutput{}, f.err
+}
+
+func (f *fakeSQS) SendMessageBatchWithContext(ctx aws.Context, input *awssqs.SendMessageBatchInput, opts ...request.Option) (*awssqs.SendMessageBatchOutput, error) {
+	f.batches = append(f.batches, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	output := &awssqs.SendMessageBatchOutput{}
+	for _, id := range f.failed {
+		output.Failed = append(output.Failed, &awssqs.BatchResultErrorEntry{
+			Id:      aws.String(id),
+			Code:    aws.String("InternalError"),
+			Message: aws.String("failed"),
+		})
+	}
+	return output, nil
+}
+
+func newNotifier(t *testing.T, client sqsiface.SQSAPI, fifo bool, messageGroup string) SQSNotifier {
+	mapping, err := headers.New("X-Stone-Webhook-Event-Id", "X-Stone-Webhook-Event-Type", "", "X-Team=payments")
+	if err != nil {
+		t.Fatalf("headers.New() error = %v", err)
+	}
+
+	return SQSNotifier{
+		log:          logrus.New(),
+		client:       client,
+		serializer:   serializers.JSON{},
+		attributes:   mapping,
+		queueURL:     "https://sqs.us-east-1.amazonaws.com/123456789012/webhooks",
+		fifo:         fifo,
+		messageGroup: messageGroup,
+	}
+}
+
+func notification(eventID, eventType, partitionKey string) domain.Notification {
+	return domain.Notification{
+		Header: domain.HeaderNotification{EventID: eventID, EventType: eventType},
+		Body:   "{}",
+		Fields: domain.NotificationFields{PartitionKey: partitionKey},
+	}
+}
+
+func TestSQSNotifier_Send(t *testing.T) {
+	tests := []struct {
+		name         string
+		fifo         bool
+		messageGroup string
+		notification domain.Notification
+		wantGroup    string
+	}{
+		{
+			name:         "Standard queue",
+			messageGroup: awsclient.MessageGroupEventID,
+			notification: notification("1", "payment.created", "acc-1"),
+		},
+		{
+			name:         "FIFO queue by event id",
+			fifo:         true,
+			messageGroup: awsclient.MessageGroupEventID,
+			notification: notification("2", "payment.created", "acc-1"),
+			wantGroup:    "2",
+		},
+		{
+			name:         "FIFO queue by partition key",
+			fifo:         true,
+			messageGroup: awsclient.MessageGroupPartitionKey,
+			notification: notification("3", "payment.created", "acc-1"),
+			wantGroup:    "acc-1",
+		},
+		{
+			name:         "Partition key falls back to the event id",
+			fifo:         true,
+			messageGroup: awsclient.MessageGroupPartitionKey,
+			notification: notification("4", "payment.created", ""),
+			wantGroup:    "4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSQS{}
+			n := newNotifier(t, client, tt.fifo, tt.messageGroup)
+
+			if err := n.Send(context.Background(), tt.notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if len(client.messages) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(client.messages))
+			}
+
+			message := client.messages[0]
+			if got := aws.StringValue(message.MessageGroupId); got != tt.wantGroup {
+				t.Errorf("message group = %q, want %q", got, tt.wantGroup)
+			}
+			wantDeduplication := ""
+			if tt.fifo {
+				wantDeduplication = tt.notification.Header.EventID
+			}
+			if got := aws.StringValue(message.MessageDeduplicationId); got != wantDeduplication {
+				t.Errorf("deduplication id = %q, want %q", got, wantDeduplication)
+			}
+
+			wantAttributes := map[string]string{
+				"X-Stone-Webhook-Event-Id":   tt.notification.Header.EventID,
+				"X-Stone-Webhook-Event-Type": "payment.created",
+				"X-Team":                     "payments",
+			}
+			for name, want := range wantAttributes {
+				if got := aws.StringValue(message.MessageAttributes[name].StringValue); got != want {
+					t.Errorf("attribute %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSQSNotifier_SendError(t *testing.T) {
+	client := &fakeSQS{err: errors.New("access denied")}
+	n := newNotifier(t, client, false, awsclient.MessageGroupEventID)
+
+	if err := n.Send(context.Background(), notification("1", "payment.created", "")); err == nil {
+		t.Error("Send() error = nil, want error")
+	}
+}
+
+func TestSQSNotifier_SendBatch(t *testing.T) {
+	var notifications []domain.Notification
+	for i := 0; i < 12; i++ {
+		notifications = append(notifications, notification(fmt.Sprint(i), "payment.created", ""))
+	}
+
+	// The entry 1 of each batch fails, so the notifications 1 and 11.
+	client := &fakeSQS{failed: []string{"1"}}
+	n := newNotifier(t, client, false, awsclient.MessageGroupEventID)
+
+	errs := n.SendBatch(context.Background(), notifications)
+	if len(client.batches) != 2 || len(client.batches[0].Entries) != 10 || len(client.batches[1].Entries) != 2 {
+		t.Fatalf("sent %d batches, want batches of 10 and 2 messages", len(client.batches))
+	}
+	for i, err := range errs {
+		if wantErr := i == 1 || i == 11; (err != nil) != wantErr {
+			t.Errorf("SendBatch()[%d] error = %v, wantErr %v", i, err, wantErr)
+		}
+	}
+
+	client = &fakeSQS{err: errors.New("access denied")}
+	n = newNotifier(t, client, false, awsclient.MessageGroupEventID)
+	for i, err := range n.SendBatch(context.Background(), notifications[:3]) {
+		if err == nil {
+			t.Errorf("SendBatch()[%d] error = nil, want error", i)
+		}
+	}
+}
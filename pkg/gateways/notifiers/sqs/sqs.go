@@ -0,0 +1,40 @@
+package sqs
+
+import (
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/headers"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/serializers"
+)
+
+var _ domain.SerializedNotifier = &SQSNotifier{}
+var _ domain.BatchNotifier = &SQSNotifier{}
+var _ domain.NamedNotifier = &SQSNotifier{}
+
+type SQSNotifier struct {
+	log    *logrus.Logger
+	client sqsiface.SQSAPI
+	// serializer builds the message body and some of its attributes.
+	serializer domain.MessageSerializer
+	attributes headers.Mapping
+	queueURL   string
+	// fifo queues receive the message group and deduplication ids.
+	fifo         bool
+	messageGroup string
+}
+
+func New() *SQSNotifier {
+	return &SQSNotifier{
+		serializer: serializers.JSON{},
+	}
+}
+
+func (n *SQSNotifier) SetSerializer(serializer domain.MessageSerializer) {
+	n.serializer = serializer
+}
+
+func (n *SQSNotifier) Name() string {
+	return "sqs"
+}
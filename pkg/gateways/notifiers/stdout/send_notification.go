@@ -2,12 +2,22 @@ package stdout
 
 import (
 	"context"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/instrument"
 )
 
-func (n StdoutNotifier) Send(ctx context.Context, eventTypeHeader, eventIDHeader, body string) error {
+func (n StdoutNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	ctx, end := instrument.StartSend(ctx, "stdout", notification)
+	err := n.send(ctx, notification)
+	end(err)
+	return err
+}
+
+func (n StdoutNotifier) send(ctx context.Context, notification domain.Notification) error {
 	log := n.log.WithField("notifier", "stdout")
-	log.Printf("event headers: type[%s] id[%s]\n", eventTypeHeader, eventIDHeader)
-	log.Printf("body: %s\n", body)
+	log.Printf("event headers: type[%s] id[%s]\n", notification.Header.EventType, notification.Header.EventID)
+	log.Printf("body: %s\n", notification.Body)
 
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 )
 
 var _ domain.Notifier = &StdoutNotifier{}
+var _ domain.NamedNotifier = &StdoutNotifier{}
 
 type StdoutNotifier struct {
 	log *logrus.Logger
@@ -14,3 +15,7 @@ type StdoutNotifier struct {
 func New() *StdoutNotifier {
 	return &StdoutNotifier{}
 }
+
+func (n *StdoutNotifier) Name() string {
+	return "stdout"
+}
@@ -0,0 +1,22 @@
+package tee
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (t *Tee) Configure(log *logrus.Logger) error {
+	names := make([]string, len(t.members))
+	for i, member := range t.members {
+		if err := member.Notifier.Configure(log); err != nil {
+			return fmt.Errorf("configure failed in [%s] teed notifier: %v", member.Name, err)
+		}
+		names[i] = member.Name
+	}
+
+	t.log = log
+	log.WithField("notifier", names[0]).Infof("tee: notifiers:%v policy:[%s]", names, t.policy)
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package tee
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var teeFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "webhook_consumer_tee_failures_total",
+	Help: "Number of notifications not written by a teed notifier, by notifier and role.",
+}, []string{"notifier", "role"})
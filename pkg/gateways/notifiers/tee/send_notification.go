@@ -0,0 +1,54 @@
+package tee
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// Send writes the notification to all the notifiers concurrently, and waits
+// for all of them.
+func (t *Tee) Send(ctx context.Context, notification domain.Notification) error {
+	errs := make([]error, len(t.members))
+
+	var wg sync.WaitGroup
+	for i, member := range t.members {
+		wg.Add(1)
+		go func(i int, member Member) {
+			defer wg.Done()
+			errs[i] = member.Notifier.Send(ctx, notification)
+		}(i, member)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		teeFailures.WithLabelValues(t.members[0].Name, "primary").Inc()
+	}
+
+	var secondaryErr error
+	for i, err := range errs[1:] {
+		if err == nil {
+			continue
+		}
+
+		name := t.members[i+1].Name
+		teeFailures.WithLabelValues(name, "secondary").Inc()
+		t.log.WithError(err).WithField("notifier", name).Errorf("teed notifier failed to send notification %s", notification.Header.EventID)
+
+		if secondaryErr == nil {
+			secondaryErr = fmt.Errorf("secondary notifier [%s]: %w", name, err)
+		}
+	}
+
+	if errs[0] != nil {
+		return errs[0]
+	}
+
+	if t.policy == PolicyAll {
+		return secondaryErr
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package tee
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// fakeNotifier fails with err, and counts the notifications sent.
+type fakeNotifier struct {
+	err  error
+	sent int
+}
+
+func (f *fakeNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	f.sent++
+	return f.err
+}
+
+func TestNew(t *testing.T) {
+	members := []Member{{Name: "old", Notifier: &fakeNotifier{}}, {Name: "new", Notifier: &fakeNotifier{}}}
+
+	tests := []struct {
+		name    string
+		members []Member
+		policy  string
+		wantErr bool
+	}{
+		{name: "Primary policy", members: members, policy: PolicyPrimary},
+		{name: "All policy", members: members, policy: PolicyAll},
+		{name: "Invalid policy", members: members, policy: "any", wantErr: true},
+		{name: "Single notifier", members: members[:1], policy: PolicyPrimary, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.members, tt.policy); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTee_Send(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name         string
+		policy       string
+		primaryErr   error
+		secondaryErr error
+		wantErr      bool
+	}{
+		{name: "Primary policy, all succeed", policy: PolicyPrimary},
+		{name: "Primary policy, secondary fails", policy: PolicyPrimary, secondaryErr: failed},
+		{name: "Primary policy, primary fails", policy: PolicyPrimary, primaryErr: failed, wantErr: true},
+		{name: "Primary policy, both fail", policy: PolicyPrimary, primaryErr: failed, secondaryErr: failed, wantErr: true},
+		{name: "All policy, all succeed", policy: PolicyAll},
+		{name: "All policy, secondary fails", policy: PolicyAll, secondaryErr: failed, wantErr: true},
+		{name: "All policy, primary fails", policy: PolicyAll, primaryErr: failed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeNotifier{err: tt.primaryErr}
+			secondary := &fakeNotifier{err: tt.secondaryErr}
+
+			tee, err := New([]Member{{Name: "old", Notifier: primary}, {Name: "new", Notifier: secondary}}, tt.policy)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := tee.Configure(logrus.New()); err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+
+			err = tee.Send(context.Background(), domain.Notification{Header: domain.HeaderNotification{EventID: "1"}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.primaryErr != nil && !errors.Is(err, tt.primaryErr) {
+				t.Errorf("Send() error = %v, want the primary error", err)
+			}
+
+			// Both are always written, whatever the other result.
+			if primary.sent != 1 || secondary.sent != 1 {
+				t.Errorf("Send() sent primary %d, secondary %d, want 1 and 1", primary.sent, secondary.sent)
+			}
+		})
+	}
+}
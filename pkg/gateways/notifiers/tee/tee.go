@@ -0,0 +1,70 @@
+package tee
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.NamedNotifier = &Tee{}
+
+const (
+	// PolicyPrimary succeeds when the primary succeeds, the secondary
+	// failures are only logged.
+	PolicyPrimary = "primary"
+	// PolicyAll succeeds only when all the notifiers succeed.
+	PolicyAll = "all"
+)
+
+// Member is a notifier written by the tee, named for the logs and metrics.
+type Member struct {
+	Name     string
+	Notifier domain.Notifier
+}
+
+// Tee writes every notification to all its notifiers, like during a
+// migration between backends. The primary, the first one, always determines
+// the result.
+type Tee struct {
+	log     *logrus.Logger
+	members []Member
+	policy  string
+}
+
+// New wraps the members, the first one is the primary.
+func New(members []Member, policy string) (*Tee, error) {
+	if len(members) < 2 {
+		return nil, fmt.Errorf("tee needs at least two notifiers, got %d", len(members))
+	}
+
+	if policy != PolicyPrimary && policy != PolicyAll {
+		return nil, fmt.Errorf("invalid tee policy: %v", policy)
+	}
+
+	return &Tee{
+		members: members,
+		policy:  policy,
+	}, nil
+}
+
+// Notifiers returns the wrapped notifiers, the primary first.
+func (t *Tee) Notifiers() []domain.Notifier {
+	notifiers := make([]domain.Notifier, len(t.members))
+	for i, member := range t.members {
+		notifiers[i] = member.Notifier
+	}
+
+	return notifiers
+}
+
+// Name joins the names of the members, the primary first.
+func (t *Tee) Name() string {
+	names := make([]string, len(t.members))
+	for i, member := range t.members {
+		names[i] = member.Name
+	}
+
+	return strings.Join(names, "+")
+}
@@ -0,0 +1,19 @@
+package throttle
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+func (n *ThrottledNotifier) Configure(log *logrus.Logger) error {
+	if err := n.notifier.Configure(log); err != nil {
+		return err
+	}
+
+	n.log = log
+	log.WithField("notifier", n.name).Infof("throttled: rate:[%v/s] queue_size:[%d]", n.rate, cap(n.queue))
+
+	throttleRate.WithLabelValues(n.name).Set(n.rate)
+	go n.run()
+
+	return nil
+}
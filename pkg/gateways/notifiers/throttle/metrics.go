@@ -0,0 +1,23 @@
+package throttle
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_consumer_throttle_queue_depth",
+		Help: "Number of notifications waiting to be sent by a throttled notifier.",
+	}, []string{"notifier"})
+
+	throttleRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_consumer_throttle_rate",
+		Help: "Maximum number of notifications sent per second by a throttled notifier.",
+	}, []string{"notifier"})
+
+	sentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_consumer_throttle_sent_total",
+		Help: "Number of notifications released by a throttled notifier, by result.",
+	}, []string{"notifier", "result"})
+)
@@ -0,0 +1,85 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var (
+	ErrQueueFull = errors.New("throttle queue is full")
+	ErrShutdown  = errors.New("throttled notifier is shutting down")
+)
+
+// Send only queues the notification, failing when the queue is full.
+func (n *ThrottledNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return ErrShutdown
+	}
+
+	select {
+	case n.queue <- notification:
+		queueDepth.WithLabelValues(n.name).Inc()
+		return nil
+	default:
+		n.log.WithField("notifier", n.name).Errorf("queue is full, rejecting notification %s", notification.Header.EventID)
+		return ErrQueueFull
+	}
+}
+
+func (n *ThrottledNotifier) run() {
+	defer close(n.done)
+
+	log := n.log.WithField("notifier", n.name)
+	next := time.Now()
+
+	for notification := range n.queue {
+		select {
+		case <-n.stop:
+			n.drop(notification)
+			continue
+		default:
+		}
+
+		select {
+		case <-time.After(time.Until(next)):
+		case <-n.stop:
+			n.drop(notification)
+			continue
+		}
+
+		queueDepth.WithLabelValues(n.name).Dec()
+
+		// The request that queued the notification is already finished, so
+		// the send is only bound by the shutdown, and a failed one is kept as
+		// a dead letter.
+		err := n.notifier.Send(n.ctx, notification)
+		if err != nil {
+			log.WithError(err).Errorf("unable to send throttled notification %s", notification.Header.EventID)
+			sentTotal.WithLabelValues(n.name, "failure").Inc()
+			n.Checkpoint(log, notification, fmt.Sprintf("not sent by the throttled notifier %s: %v", n.name, err))
+		} else {
+			sentTotal.WithLabelValues(n.name, "success").Inc()
+		}
+
+		if now := time.Now(); now.After(next) {
+			next = now
+		}
+		next = next.Add(n.interval)
+	}
+}
+
+func (n *ThrottledNotifier) drop(notification domain.Notification) {
+	queueDepth.WithLabelValues(n.name).Dec()
+	sentTotal.WithLabelValues(n.name, "dropped").Inc()
+	n.dropped++
+
+	n.log.WithField("notifier", n.name).Errorf("dropping notification %s on shutdown: type[%s]", notification.Header.EventID, notification.Header.EventType)
+	n.Checkpoint(n.log.WithField("notifier", n.name), notification, fmt.Sprintf("dropped on shutdown by the throttled notifier %s", n.name))
+}
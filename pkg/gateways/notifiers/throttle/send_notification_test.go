@@ -0,0 +1,156 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// recorderNotifier records when each notification was sent, and can be
+// blocked until released.
+type recorderNotifier struct {
+	mu      sync.Mutex
+	sentAt  []time.Time
+	sent    chan struct{}
+	release chan struct{}
+	// err fails the sends.
+	err error
+}
+
+func newRecorderNotifier() *recorderNotifier {
+	return &recorderNotifier{
+		sent: make(chan struct{}, 100),
+	}
+}
+
+func (r *recorderNotifier) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (r *recorderNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	if r.release != nil {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	r.sentAt = append(r.sentAt, time.Now())
+	r.mu.Unlock()
+
+	r.sent <- struct{}{}
+	return r.err
+}
+
+func testNotification(eventID string) domain.Notification {
+	return domain.Notification{
+		Header: domain.HeaderNotification{EventID: eventID, EventType: "type"},
+		Body:   "{}",
+	}
+}
+
+func newTestThrottle(t *testing.T, notifier *recorderNotifier, rate float64, queueSize int) *ThrottledNotifier {
+	t.Helper()
+
+	throttled := New("test", notifier, rate, queueSize)
+	if err := throttled.Configure(logrus.New()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	return throttled
+}
+
+func TestThrottledNotifier_ReleaseRate(t *testing.T) {
+	notifier := newRecorderNotifier()
+	throttled := newTestThrottle(t, notifier, 50, 10)
+
+	// A burst is queued at once, and released one each 20ms.
+	const total = 5
+	for i := 0; i < total; i++ {
+		if err := throttled.Send(context.Background(), testNotification("id")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	for i := 0; i < total; i++ {
+		select {
+		case <-notifier.sent:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Send() notification %d was not released", i)
+		}
+	}
+
+	for i := 1; i < total; i++ {
+		interval := notifier.sentAt[i].Sub(notifier.sentAt[i-1])
+		if interval < 15*time.Millisecond {
+			t.Errorf("Send() released notifications %v apart, want at least 20ms", interval)
+		}
+	}
+
+	if err := throttled.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestThrottledNotifier_QueueFull(t *testing.T) {
+	notifier := newRecorderNotifier()
+	notifier.release = make(chan struct{})
+	throttled := newTestThrottle(t, notifier, 1000, 2)
+
+	// The first notification is taken by the blocked notifier, and the next
+	// two fill the queue.
+	if err := throttled.Send(context.Background(), testNotification("1")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	for _, id := range []string{"2", "3"} {
+		if err := throttled.Send(context.Background(), testNotification(id)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	if err := throttled.Send(context.Background(), testNotification("4")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Send() error = %v, want %v", err, ErrQueueFull)
+	}
+
+	close(notifier.release)
+	if err := throttled.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if len(notifier.sentAt) != 3 {
+		t.Errorf("Shutdown() sent %d notifications, want 3", len(notifier.sentAt))
+	}
+}
+
+func TestThrottledNotifier_ShutdownDropsPending(t *testing.T) {
+	notifier := newRecorderNotifier()
+	throttled := newTestThrottle(t, notifier, 1, 10)
+
+	for i := 0; i < 5; i++ {
+		if err := throttled.Send(context.Background(), testNotification("id")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := throttled.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the dropped notifications")
+	}
+	if throttled.dropped != 4 {
+		t.Errorf("Shutdown() dropped = %d, want 4", throttled.dropped)
+	}
+	if err := throttled.Send(context.Background(), testNotification("id")); !errors.Is(err, ErrShutdown) {
+		t.Errorf("Send() after shutdown error = %v, want %v", err, ErrShutdown)
+	}
+}
@@ -0,0 +1,34 @@
+package throttle
+
+import (
+	"context"
+	"fmt"
+)
+
+// Shutdown stops accepting notifications and keeps releasing the queued ones
+// until the context is done, when the running send is canceled. The
+// notifications still in the queue are dropped, and stored as dead letters
+// when there's a dead letter store.
+func (n *ThrottledNotifier) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+	case <-ctx.Done():
+		close(n.stop)
+		n.cancel()
+		<-n.done
+	}
+	n.cancel()
+
+	if n.dropped > 0 {
+		return fmt.Errorf("%d notifications dropped on shutdown", n.dropped)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+type fakeDeadLetterStore struct {
+	deadLetters []domain.DeadLetter
+}
+
+func (f *fakeDeadLetterStore) Configure(log *logrus.Logger) error {
+	return nil
+}
+
+func (f *fakeDeadLetterStore) Store(ctx context.Context, deadLetter domain.DeadLetter) error {
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func TestThrottledNotifier_ShutdownStoresDropped(t *testing.T) {
+	notifier := newRecorderNotifier()
+	throttled := newTestThrottle(t, notifier, 1, 10)
+	store := &fakeDeadLetterStore{}
+	throttled.SetDeadLetterStore(store)
+
+	for _, eventID := range []string{"1", "2", "3"} {
+		if err := throttled.Send(context.Background(), testNotification(eventID)); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := throttled.Shutdown(ctx); err == nil {
+		t.Errorf("Shutdown() must report the dropped notifications")
+	}
+
+	if len(store.deadLetters) != 2 {
+		t.Fatalf("Shutdown() stored %d dead letters, want 2", len(store.deadLetters))
+	}
+	for i, eventID := range []string{"2", "3"} {
+		deadLetter := store.deadLetters[i]
+		if deadLetter.Input.Header.EventID != eventID || deadLetter.Reason != "dropped on shutdown by the throttled notifier test" {
+			t.Errorf("Shutdown() dead letter = %+v, want notification %s", deadLetter, eventID)
+		}
+	}
+}
+
+func TestThrottledNotifier_ShutdownCancelsSend(t *testing.T) {
+	notifier := newRecorderNotifier()
+	// The send is blocked until its context is canceled.
+	notifier.release = make(chan struct{})
+	throttled := newTestThrottle(t, notifier, 1, 10)
+
+	if err := throttled.Send(context.Background(), testNotification("1")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- throttled.Shutdown(ctx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Shutdown() didn't cancel the running send")
+	}
+}
+
+func TestThrottledNotifier_StoresFailed(t *testing.T) {
+	notifier := newRecorderNotifier()
+	notifier.err = errors.New("429 too many requests")
+	throttled := newTestThrottle(t, notifier, 100, 10)
+	store := &fakeDeadLetterStore{}
+	throttled.SetDeadLetterStore(store)
+
+	if err := throttled.Send(context.Background(), testNotification("1")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	<-notifier.sent
+
+	if err := throttled.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := "not sent by the throttled notifier test: 429 too many requests"
+	if len(store.deadLetters) != 1 || store.deadLetters[0].Input.Header.EventID != "1" || store.deadLetters[0].Reason != want {
+		t.Errorf("dead letters = %+v, want notification 1", store.deadLetters)
+	}
+}
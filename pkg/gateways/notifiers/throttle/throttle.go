@@ -0,0 +1,60 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+	"github.com/stone-co/webhook-consumer/pkg/gateways/notifiers/checkpoint"
+)
+
+var _ domain.DeferredNotifier = &ThrottledNotifier{}
+var _ domain.NamedNotifier = &ThrottledNotifier{}
+
+// ThrottledNotifier queues the notifications and releases them to the wrapped
+// notifier at a fixed rate (leaky bucket), for downstreams that can only
+// accept a limited number of notifications per second.
+type ThrottledNotifier struct {
+	log      *logrus.Logger
+	name     string
+	notifier domain.Notifier
+	rate     float64
+	interval time.Duration
+	queue    chan domain.Notification
+	stop     chan struct{}
+	done     chan struct{}
+	dropped  int
+	// ctx is the context of the sends, canceled when the shutdown deadline
+	// is reached.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Checkpointer keeps the notifications dropped on shutdown.
+	checkpoint.Checkpointer
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New wraps the notifier, releasing up to rate notifications per second.
+func New(name string, notifier domain.Notifier, rate float64, queueSize int) *ThrottledNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ThrottledNotifier{
+		name:         name,
+		Checkpointer: checkpoint.New(notifier),
+		notifier:     notifier,
+		rate:         rate,
+		interval:     time.Duration(float64(time.Second) / rate),
+		queue:        make(chan domain.Notification, queueSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Name is the name of the wrapped notifier.
+func (n *ThrottledNotifier) Name() string {
+	return n.name
+}
@@ -0,0 +1,24 @@
+package redisclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// CheckHealth pings the redis server of the pool, for the readiness of the
+// redis notifier and stores.
+func CheckHealth(ctx context.Context, pool *redis.Pool) error {
+	conn, err := pool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := redis.String(conn.Do("PING")); err != nil {
+		return fmt.Errorf("unable to ping redis: %w", err)
+	}
+
+	return nil
+}
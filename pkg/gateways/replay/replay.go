@@ -0,0 +1,170 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/eventtype"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// maxLineSize bounds a line of the replay file, with its encrypted body.
+const maxLineSize = 4 << 20
+
+// Record is a line of the replay file, the raw envelope with its headers.
+type Record struct {
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	EncryptedBody string `json:"encrypted_body"`
+}
+
+// Summary counts the outcome of the imported records.
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	// Skipped are the records with an event id already imported.
+	Skipped int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("total:[%d] succeeded:[%d] failed:[%d] skipped:[%d]", s.Total, s.Succeeded, s.Failed, s.Skipped)
+}
+
+// Importer runs the records of a replay file through the usecase, like the
+// notifications received by the API.
+type Importer struct {
+	log            *logrus.Logger
+	usecase        domain.NotificationUsecase
+	concurrency    int
+	skipDuplicates bool
+	// progressEvery is the number of records between the progress logs.
+	progressEvery int
+	// eventTypes normalizes the event types, like the API, it's optional.
+	eventTypes *eventtype.Normalizer
+}
+
+func New(log *logrus.Logger, usecase domain.NotificationUsecase, concurrency int, skipDuplicates bool) *Importer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Importer{
+		log:            log,
+		usecase:        usecase,
+		concurrency:    concurrency,
+		skipDuplicates: skipDuplicates,
+		progressEvery:  100,
+	}
+}
+
+// SetEventTypeNormalizer normalizes the event types of the records.
+func (i *Importer) SetEventTypeNormalizer(normalizer *eventtype.Normalizer) {
+	i.eventTypes = normalizer
+}
+
+// Import reads the JSON lines and sends each record. A record failure is only
+// counted, so the import goes on; it fails only when the file can't be read.
+func (i *Importer) Import(ctx context.Context, r io.Reader) (Summary, error) {
+	var (
+		mu      sync.Mutex
+		summary Summary
+		wg      sync.WaitGroup
+	)
+
+	count := func(succeeded bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if succeeded {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+
+		if done := summary.Succeeded + summary.Failed + summary.Skipped; done%i.progressEvery == 0 {
+			i.log.Infof("replay progress: %s", summary)
+		}
+	}
+
+	records := make(chan Record)
+	for w := 0; w < i.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range records {
+				count(i.send(ctx, record))
+			}
+		}()
+	}
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		mu.Lock()
+		summary.Total++
+		mu.Unlock()
+
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil || record.EventID == "" || record.EventType == "" || record.EncryptedBody == "" {
+			i.log.Errorf("replay line %d is not a valid record", line)
+			count(false)
+			continue
+		}
+
+		if i.skipDuplicates && seen[record.EventID] {
+			i.log.Warnf("replay line %d skipped, event %s already imported", line, record.EventID)
+			mu.Lock()
+			summary.Skipped++
+			mu.Unlock()
+			continue
+		}
+		seen[record.EventID] = true
+
+		records <- record
+	}
+	close(records)
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("reading replay file at line %d: %v", line+1, err)
+	}
+
+	return summary, nil
+}
+
+func (i *Importer) send(ctx context.Context, record Record) bool {
+	input := domain.NotificationInput{
+		Header: domain.HeaderNotification{
+			EventID:   record.EventID,
+			EventType: i.eventTypes.Normalize(record.EventType),
+		},
+		EncryptedBody: record.EncryptedBody,
+	}
+	if input.Header.EventType != record.EventType {
+		input.Header.RawEventType = record.EventType
+	}
+
+	if _, err := i.usecase.SendNotification(ctx, input); err != nil {
+		i.log.WithError(err).Errorf("replay of notification %s failed", record.EventID)
+		return false
+	}
+
+	return true
+}
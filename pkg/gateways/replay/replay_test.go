@@ -0,0 +1,124 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// fakeUsecase fails the encrypted bodies in fail, and records the event ids.
+type fakeUsecase struct {
+	mu   sync.Mutex
+	sent []string
+	fail map[string]bool
+}
+
+func (f *fakeUsecase) SendNotification(ctx context.Context, input domain.NotificationInput) (domain.NotificationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sent = append(f.sent, input.Header.EventID)
+	if f.fail[input.EncryptedBody] {
+		return domain.NotificationOutput{}, errors.New("invalid signature")
+	}
+
+	return domain.NotificationOutput{}, nil
+}
+
+const replayFile = `{"event_id":"1","event_type":"cash_in","encrypted_body":"a.b.c"}
+{"event_id":"2","event_type":"cash_in","encrypted_body":"bad"}
+
+not json
+{"event_id":"3","event_type":"cash_in"}
+{"event_id":"1","event_type":"cash_in","encrypted_body":"a.b.c"}
+{"event_id":"4","event_type":"cash_out","encrypted_body":"d.e.f"}
+`
+
+func TestImporter_Import(t *testing.T) {
+	tests := []struct {
+		name           string
+		skipDuplicates bool
+		concurrency    int
+		want           Summary
+		wantSent       []string
+	}{
+		{
+			name:           "Skip duplicates",
+			skipDuplicates: true,
+			concurrency:    1,
+			want:           Summary{Total: 6, Succeeded: 2, Failed: 3, Skipped: 1},
+			wantSent:       []string{"1", "2", "4"},
+		},
+		{
+			name:        "Keep duplicates",
+			concurrency: 3,
+			want:        Summary{Total: 6, Succeeded: 3, Failed: 3},
+			wantSent:    []string{"1", "1", "2", "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := test.NewNullLogger()
+			usecase := &fakeUsecase{fail: map[string]bool{"bad": true}}
+			importer := New(log, usecase, tt.concurrency, tt.skipDuplicates)
+
+			got, err := importer.Import(context.Background(), strings.NewReader(replayFile))
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Import() = %v, want %v", got, tt.want)
+			}
+
+			sort.Strings(usecase.sent)
+			if !reflect.DeepEqual(usecase.sent, tt.wantSent) {
+				t.Errorf("Import() sent = %v, want %v", usecase.sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestImporter_Import_Progress(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	importer := New(log, &fakeUsecase{}, 2, true)
+	importer.progressEvery = 2
+
+	lines := []string{}
+	for _, id := range []string{"1", "2", "3", "4"} {
+		lines = append(lines, `{"event_id":"`+id+`","event_type":"cash_in","encrypted_body":"a.b.c"}`)
+	}
+
+	if _, err := importer.Import(context.Background(), strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	progress := 0
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.InfoLevel && strings.HasPrefix(entry.Message, "replay progress") {
+			progress++
+		}
+	}
+	if progress != 2 {
+		t.Errorf("Import() progress logs = %d, want 2", progress)
+	}
+}
+
+func TestImporter_Import_LineTooLong(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	importer := New(log, &fakeUsecase{}, 1, true)
+
+	_, err := importer.Import(context.Background(), strings.NewReader(strings.Repeat("x", maxLineSize+1)))
+	if err == nil {
+		t.Error("Import() must fail when the file can't be read")
+	}
+}
@@ -0,0 +1,82 @@
+package serializers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.MessageSerializer = CloudEvents{}
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+)
+
+// CloudEvents publishes the notification as a CloudEvents 1.0 envelope, in
+// the structured JSON mode, with the decrypted body as data.
+type CloudEvents struct {
+	Source string
+	// Metadata, when set, is added as extension attributes.
+	Metadata *Metadata
+}
+
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            string          `json:"time,omitempty"`
+	PartitionKey    string          `json:"partitionkey,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	// The processing metadata extensions.
+	ReceivedAt          string `json:"receivedat,omitempty"`
+	ProcessingLatencyMs string `json:"processinglatencyms,omitempty"`
+	ConsumerInstance    string `json:"consumerinstance,omitempty"`
+	KeyID               string `json:"kid,omitempty"`
+}
+
+func (c CloudEvents) Serialize(notification domain.Notification) ([]byte, map[string]string, error) {
+	event := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              notification.Header.EventID,
+		Source:          c.Source,
+		Type:            notification.Header.EventType,
+		PartitionKey:    notification.Fields.PartitionKey,
+		DataContentType: "application/json",
+		Data:            json.RawMessage(notification.Body),
+	}
+	// The inner JWE isn't JSON, so it's a string.
+	if notification.Ciphertext {
+		data, err := json.Marshal(notification.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshaling cloud event data: %w", err)
+		}
+		event.DataContentType = joseContentType
+		event.Data = data
+	}
+	if !notification.Fields.Timestamp.IsZero() {
+		event.Time = notification.Fields.Timestamp.Format(time.RFC3339Nano)
+	}
+	if c.Metadata != nil {
+		values := c.Metadata.Values(notification)
+		event.ReceivedAt = values[metadataReceivedAt]
+		event.ProcessingLatencyMs = values[metadataProcessingLatency]
+		event.ConsumerInstance = values[metadataInstanceID]
+		event.KeyID = values[metadataKeyID]
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling cloud event: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": cloudEventsContentType,
+	}
+
+	return body, headers, nil
+}
@@ -0,0 +1,117 @@
+package serializers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestCloudEvents_Serialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification domain.Notification
+		want         map[string]interface{}
+	}{
+		{
+			name: "Event with data",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				Body:   `{"amount":100}`,
+			},
+			want: map[string]interface{}{
+				"specversion":     "1.0",
+				"id":              "930bbd6d",
+				"source":          "webhook-consumer",
+				"type":            "cash_in_internal_transfer",
+				"datacontenttype": "application/json",
+				"data":            map[string]interface{}{"amount": float64(100)},
+			},
+		},
+		{
+			name: "Event with the extracted timestamp",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				Body:   `{}`,
+				Fields: domain.NotificationFields{Timestamp: time.Date(2020, 9, 1, 12, 30, 0, 0, time.UTC)},
+			},
+			want: map[string]interface{}{
+				"specversion":     "1.0",
+				"id":              "930bbd6d",
+				"source":          "webhook-consumer",
+				"type":            "cash_in_internal_transfer",
+				"time":            "2020-09-01T12:30:00Z",
+				"datacontenttype": "application/json",
+				"data":            map[string]interface{}{},
+			},
+		},
+		{
+			name: "Event with the partition key",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				Body:   `{}`,
+				Fields: domain.NotificationFields{PartitionKey: "acc-1"},
+			},
+			want: map[string]interface{}{
+				"specversion":     "1.0",
+				"id":              "930bbd6d",
+				"source":          "webhook-consumer",
+				"type":            "cash_in_internal_transfer",
+				"partitionkey":    "acc-1",
+				"datacontenttype": "application/json",
+				"data":            map[string]interface{}{},
+			},
+		},
+		{
+			name: "Event with the undecrypted JWE",
+			notification: domain.Notification{
+				Header:     domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+				Body:       "header.key.iv.ciphertext.tag",
+				Ciphertext: true,
+			},
+			want: map[string]interface{}{
+				"specversion":     "1.0",
+				"id":              "930bbd6d",
+				"source":          "webhook-consumer",
+				"type":            "cash_in_internal_transfer",
+				"datacontenttype": "application/jose",
+				"data":            "header.key.iv.ciphertext.tag",
+			},
+		},
+		{
+			name: "Event without data",
+			notification: domain.Notification{
+				Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "heartbeat"},
+			},
+			want: map[string]interface{}{
+				"specversion":     "1.0",
+				"id":              "930bbd6d",
+				"source":          "webhook-consumer",
+				"type":            "heartbeat",
+				"datacontenttype": "application/json",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, headers, err := CloudEvents{Source: "webhook-consumer"}.Serialize(tt.notification)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(body, &got); err != nil {
+				t.Fatalf("Serialize() invalid JSON %s: %v", body, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+			if headers["Content-Type"] != "application/cloudevents+json" {
+				t.Errorf("Serialize() headers = %v", headers)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package serializers
+
+import (
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var _ domain.MessageSerializer = JSON{}
+
+// joseContentType is the type of the inner JWE, in the verify-only mode.
+const joseContentType = "application/jose"
+
+// JSON publishes the decrypted body as is.
+type JSON struct{}
+
+func (JSON) Serialize(notification domain.Notification) ([]byte, map[string]string, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	if notification.Ciphertext {
+		headers["Content-Type"] = joseContentType
+	}
+
+	return []byte(notification.Body), headers, nil
+}
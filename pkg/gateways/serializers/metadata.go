@@ -0,0 +1,119 @@
+package serializers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+const (
+	MetadataTargetHeaders  = "headers"
+	MetadataTargetEnvelope = "envelope"
+)
+
+const (
+	metadataReceivedAt        = "received_at"
+	metadataProcessingLatency = "processing_latency"
+	metadataInstanceID        = "instance_id"
+	metadataKeyID             = "kid"
+)
+
+// metadataHeaders are the header names of the fields, after the prefix.
+var metadataHeaders = map[string]string{
+	metadataReceivedAt:        "Received-At",
+	metadataProcessingLatency: "Processing-Latency-Ms",
+	metadataInstanceID:        "Instance-Id",
+	metadataKeyID:             "Key-Id",
+}
+
+// Metadata has the processing metadata fields added to the messages.
+type Metadata struct {
+	fields     []string
+	instanceID string
+	now        func() time.Time
+}
+
+// NewMetadata returns nil when no field is configured.
+func NewMetadata(cfg configuration.MetadataConfig) (*Metadata, error) {
+	fields := []string{}
+	for _, field := range configuration.SplitList(cfg.Fields) {
+		field = strings.ToLower(field)
+		if _, ok := metadataHeaders[field]; !ok {
+			return nil, fmt.Errorf("undefined metadata field: %v", field)
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the instance id from the host name: %v", err)
+		}
+		instanceID = hostname
+	}
+
+	return &Metadata{fields: fields, instanceID: instanceID, now: time.Now}, nil
+}
+
+// Values returns the configured fields of the notification. The fields
+// without a value, like an empty kid, are left out.
+func (m *Metadata) Values(notification domain.Notification) map[string]string {
+	values := map[string]string{}
+	for _, field := range m.fields {
+		var value string
+		switch field {
+		case metadataReceivedAt:
+			if !notification.Processing.ReceivedAt.IsZero() {
+				value = notification.Processing.ReceivedAt.UTC().Format(time.RFC3339Nano)
+			}
+		case metadataProcessingLatency:
+			if !notification.Processing.ReceivedAt.IsZero() {
+				latency := m.now().Sub(notification.Processing.ReceivedAt)
+				value = strconv.FormatFloat(float64(latency)/float64(time.Millisecond), 'f', 3, 64)
+			}
+		case metadataInstanceID:
+			value = m.instanceID
+		case metadataKeyID:
+			value = notification.Processing.KeyID
+		}
+
+		if value != "" {
+			values[field] = value
+		}
+	}
+
+	return values
+}
+
+// metadataHeadersSerializer adds the metadata to the message headers.
+type metadataHeadersSerializer struct {
+	serializer domain.MessageSerializer
+	metadata   *Metadata
+	prefix     string
+}
+
+func (s metadataHeadersSerializer) Serialize(notification domain.Notification) ([]byte, map[string]string, error) {
+	body, headers, err := s.serializer.Serialize(notification)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	for field, value := range s.metadata.Values(notification) {
+		headers[s.prefix+metadataHeaders[field]] = value
+	}
+
+	return body, headers, nil
+}
@@ -0,0 +1,149 @@
+package serializers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+var metadataNotification = domain.Notification{
+	Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+	Body:   `{"amount":100}`,
+	Processing: domain.ProcessingMetadata{
+		ReceivedAt: time.Date(2020, 9, 1, 12, 30, 0, 0, time.UTC),
+		KeyID:      "stone-2020",
+	},
+}
+
+func newTestSerializer(t *testing.T, serializer string, metadata configuration.MetadataConfig) domain.MessageSerializer {
+	t.Helper()
+
+	got, err := New(configuration.SerializerConfig{Serializer: serializer, CloudEventsSource: "source"}, metadata)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The latency is measured from a fixed time.
+	now := func() time.Time { return metadataNotification.Processing.ReceivedAt.Add(1500 * time.Microsecond) }
+	switch s := got.(type) {
+	case metadataHeadersSerializer:
+		s.metadata.now = now
+	case CloudEvents:
+		s.Metadata.now = now
+	}
+
+	return got
+}
+
+func TestMetadata_Headers(t *testing.T) {
+	tests := []struct {
+		name         string
+		fields       string
+		notification domain.Notification
+		want         map[string]string
+	}{
+		{
+			name:         "All the fields",
+			fields:       "received_at;processing_latency;instance_id;kid",
+			notification: metadataNotification,
+			want: map[string]string{
+				"Content-Type":                             "application/json",
+				"X-Webhook-Consumer-Received-At":           "2020-09-01T12:30:00Z",
+				"X-Webhook-Consumer-Processing-Latency-Ms": "1.500",
+				"X-Webhook-Consumer-Instance-Id":           "consumer-1",
+				"X-Webhook-Consumer-Key-Id":                "stone-2020",
+			},
+		},
+		{
+			name:         "Only the configured fields",
+			fields:       "KID",
+			notification: metadataNotification,
+			want: map[string]string{
+				"Content-Type":              "application/json",
+				"X-Webhook-Consumer-Key-Id": "stone-2020",
+			},
+		},
+		{
+			name:         "Key without a kid",
+			fields:       "instance_id;kid",
+			notification: domain.Notification{Body: `{}`},
+			want: map[string]string{
+				"Content-Type":                   "application/json",
+				"X-Webhook-Consumer-Instance-Id": "consumer-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := newTestSerializer(t, "json", configuration.MetadataConfig{
+				Fields:       tt.fields,
+				Target:       MetadataTargetHeaders,
+				HeaderPrefix: "X-Webhook-Consumer-",
+				InstanceID:   "consumer-1",
+			})
+
+			body, headers, err := serializer.Serialize(tt.notification)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+			if string(body) != tt.notification.Body {
+				t.Errorf("Serialize() body = %s, want it unchanged", body)
+			}
+			if !reflect.DeepEqual(headers, tt.want) {
+				t.Errorf("Serialize() headers = %v, want %v", headers, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadata_Envelope(t *testing.T) {
+	serializer := newTestSerializer(t, "cloudevents", configuration.MetadataConfig{
+		Fields:     "received_at;processing_latency;instance_id;kid",
+		Target:     MetadataTargetEnvelope,
+		InstanceID: "consumer-1",
+	})
+
+	message, headers, err := serializer.Serialize(metadataNotification)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if len(headers) != 1 {
+		t.Errorf("Serialize() headers = %v, want only the content type", headers)
+	}
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(message, &event); err != nil {
+		t.Fatalf("decoding the event: %v", err)
+	}
+
+	want := map[string]string{
+		"receivedat":          "2020-09-01T12:30:00Z",
+		"processinglatencyms": "1.500",
+		"consumerinstance":    "consumer-1",
+		"kid":                 "stone-2020",
+	}
+	for name, value := range want {
+		if event[name] != value {
+			t.Errorf("Serialize() extension %s = %v, want %v", name, event[name], value)
+		}
+	}
+}
+
+func TestNewMetadata_InstanceID(t *testing.T) {
+	metadata, err := NewMetadata(configuration.MetadataConfig{Fields: "instance_id"})
+	if err != nil {
+		t.Fatalf("NewMetadata() error = %v", err)
+	}
+	if metadata.instanceID == "" {
+		t.Error("NewMetadata() instance id must default to the host name")
+	}
+
+	if metadata, err := NewMetadata(configuration.MetadataConfig{}); metadata != nil || err != nil {
+		t.Errorf("NewMetadata() = %v, %v, want nil when disabled", metadata, err)
+	}
+}
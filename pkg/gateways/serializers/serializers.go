@@ -0,0 +1,46 @@
+package serializers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+// New returns the configured serializer, adding the processing metadata to
+// the headers or to the cloudevents envelope.
+func New(cfg configuration.SerializerConfig, metadataCfg configuration.MetadataConfig) (domain.MessageSerializer, error) {
+	metadata, err := NewMetadata(metadataCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	target := strings.ToLower(strings.TrimSpace(metadataCfg.Target))
+	if metadata != nil && target != MetadataTargetHeaders && target != MetadataTargetEnvelope {
+		return nil, fmt.Errorf("invalid metadata target: %v", metadataCfg.Target)
+	}
+
+	var serializer domain.MessageSerializer
+	switch strings.ToLower(strings.TrimSpace(cfg.Serializer)) {
+	case "", "json":
+		if metadata != nil && target == MetadataTargetEnvelope {
+			return nil, fmt.Errorf("the metadata envelope target requires the cloudevents serializer")
+		}
+		serializer = JSON{}
+	case "cloudevents":
+		events := CloudEvents{Source: cfg.CloudEventsSource}
+		if target == MetadataTargetEnvelope {
+			events.Metadata = metadata
+		}
+		serializer = events
+	default:
+		return nil, fmt.Errorf("undefined serializer: %v", cfg.Serializer)
+	}
+
+	if metadata != nil && target == MetadataTargetHeaders {
+		serializer = metadataHeadersSerializer{serializer: serializer, metadata: metadata, prefix: metadataCfg.HeaderPrefix}
+	}
+
+	return serializer, nil
+}
@@ -0,0 +1,134 @@
+package serializers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+	"github.com/stone-co/webhook-consumer/pkg/domain"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer string
+		metadata   configuration.MetadataConfig
+		want       domain.MessageSerializer
+		wantErr    bool
+	}{
+		{
+			name:       "JSON",
+			serializer: "json",
+			want:       JSON{},
+		},
+		{
+			name:       "CloudEvents is case insensitive",
+			serializer: "CloudEvents",
+			want:       CloudEvents{Source: "source"},
+		},
+		{
+			name:       "Undefined serializer must fail",
+			serializer: "avro",
+			wantErr:    true,
+		},
+		{
+			name:       "Metadata target without fields is ignored",
+			serializer: "json",
+			metadata:   configuration.MetadataConfig{Target: "any"},
+			want:       JSON{},
+		},
+		{
+			name:       "Undefined metadata field must fail",
+			serializer: "json",
+			metadata:   configuration.MetadataConfig{Fields: "received_at;region", Target: MetadataTargetHeaders},
+			wantErr:    true,
+		},
+		{
+			name:       "Invalid metadata target must fail",
+			serializer: "json",
+			metadata:   configuration.MetadataConfig{Fields: "kid", Target: "body"},
+			wantErr:    true,
+		},
+		{
+			name:       "Metadata envelope requires cloudevents",
+			serializer: "json",
+			metadata:   configuration.MetadataConfig{Fields: "kid", Target: MetadataTargetEnvelope},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(configuration.SerializerConfig{Serializer: tt.serializer, CloudEventsSource: "source"}, tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializers_PreserveNumbers(t *testing.T) {
+	body := `{"account_id":9007199254740993,"amount":1234567890.123456789012345678}`
+	notification := domain.Notification{
+		Header: domain.HeaderNotification{EventID: "930bbd6d", EventType: "cash_in_internal_transfer"},
+		Body:   body,
+	}
+
+	tests := []struct {
+		name       string
+		serializer domain.MessageSerializer
+		data       func(t *testing.T, message []byte) []byte
+	}{
+		{
+			name:       "JSON",
+			serializer: JSON{},
+			data:       func(t *testing.T, message []byte) []byte { return message },
+		},
+		{
+			name:       "CloudEvents",
+			serializer: CloudEvents{Source: "source"},
+			data: func(t *testing.T, message []byte) []byte {
+				var event struct {
+					Data json.RawMessage `json:"data"`
+				}
+				if err := json.Unmarshal(message, &event); err != nil {
+					t.Fatalf("decoding the event: %v", err)
+				}
+				return event.Data
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, _, err := tt.serializer.Serialize(notification)
+			if err != nil {
+				t.Fatalf("Serialize() error = %v", err)
+			}
+
+			if got := string(tt.data(t, message)); got != body {
+				t.Errorf("Serialize() data = %s, want %s", got, body)
+			}
+		})
+	}
+}
+
+func TestJSON_Serialize_Ciphertext(t *testing.T) {
+	notification := domain.Notification{Body: "header.key.iv.ciphertext.tag", Ciphertext: true}
+
+	body, headers, err := JSON{}.Serialize(notification)
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	if string(body) != notification.Body {
+		t.Errorf("Serialize() body = %s, want the undecrypted JWE", body)
+	}
+	if headers["Content-Type"] != "application/jose" {
+		t.Errorf("Serialize() headers = %v", headers)
+	}
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+// Provider exports the spans of the whole service, set as the global
+// OpenTelemetry tracer provider.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// New sets the global tracer provider and the W3C trace context propagator.
+// It returns nil when the tracing is disabled, and the spans are then no-ops.
+func New(cfg configuration.TracingConfig) (*Provider, error) {
+	if cfg.Endpoint == "" {
+		return nil, nil
+	}
+
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid tracing sample ratio %v, must be from 0 to 1", cfg.SampleRatio)
+	}
+
+	options, err := exporterOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exporter connects lazily, so a collector down doesn't stop the
+	// service.
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(cfg.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return &Provider{provider: provider}, nil
+}
+
+// Shutdown exports the pending spans until the context is done.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	return p.provider.Shutdown(ctx)
+}
+
+// tracesPath is added to the path of the collector URL, as in the other
+// OpenTelemetry SDKs.
+const tracesPath = "/v1/traces"
+
+// exporterOptions parses the collector URL.
+func exporterOptions(cfg configuration.TracingConfig) ([]otlptracehttp.Option, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid otlp endpoint %q, must be an http or https url", cfg.Endpoint)
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if path := strings.TrimRight(u.Path, "/"); path != "" && !strings.HasSuffix(path, tracesPath) {
+		options = append(options, otlptracehttp.WithURLPath(path+tracesPath))
+	} else if path != "" {
+		options = append(options, otlptracehttp.WithURLPath(path))
+	}
+
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(headers))
+	}
+
+	return options, nil
+}
+
+// parseHeaders parses the "name=value" items separated by ','.
+func parseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			// The item isn't logged, it may have a credential.
+			return nil, fmt.Errorf("invalid otlp header, expected name=value")
+		}
+
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return headers, nil
+}
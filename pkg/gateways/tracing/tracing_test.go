@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stone-co/webhook-consumer/pkg/common/configuration"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          configuration.TracingConfig
+		wantProvider bool
+		wantErr      bool
+	}{
+		{
+			name: "Disabled",
+			cfg:  configuration.TracingConfig{SampleRatio: 1},
+		},
+		{
+			name:         "Collector",
+			cfg:          configuration.TracingConfig{Endpoint: "http://localhost:4318", ServiceName: "webhook-consumer", SampleRatio: 0.5},
+			wantProvider: true,
+		},
+		{
+			name:    "Endpoint without scheme",
+			cfg:     configuration.TracingConfig{Endpoint: "localhost:4318", SampleRatio: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid sample ratio",
+			cfg:     configuration.TracingConfig{Endpoint: "http://localhost:4318", SampleRatio: 2},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid header",
+			cfg:     configuration.TracingConfig{Endpoint: "http://localhost:4318", Headers: "api-key", SampleRatio: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (provider != nil) != tt.wantProvider {
+				t.Errorf("New() provider = %v, wantProvider %v", provider, tt.wantProvider)
+			}
+			if err := provider.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_parseHeaders(t *testing.T) {
+	got, err := parseHeaders("api-key=secret, x-team = payments,")
+	if err != nil {
+		t.Fatalf("parseHeaders() error = %v", err)
+	}
+
+	want := map[string]string{"api-key": "secret", "x-team": "payments"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHeaders() = %v, want %v", got, want)
+	}
+}